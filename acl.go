@@ -0,0 +1,522 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/paul39-33/imimix/internal/database"
+)
+
+// gin context keys of the calling user, stored by authorizeUser, and of
+// their library access, loaded at most once per request.
+const (
+	ctxUser      = "user"
+	ctxLibAccess = "libAccess"
+)
+
+var allowedLibPermission = map[string]database.LibPermission{
+	"read":    database.LibPermissionRead,
+	"request": database.LibPermissionRequest,
+	"manage":  database.LibPermissionManage,
+}
+
+// permissionRank orders the permissions; each one includes those below it.
+var permissionRank = map[database.LibPermission]int{
+	database.LibPermissionRead:    1,
+	database.LibPermissionRequest: 2,
+	database.LibPermissionManage:  3,
+}
+
+// libAccess is what one user may do in each library. Libraries are open to
+// everyone, as they were before ACLs existed, until a lead restricts them;
+// a restricted library is then hidden from everyone without a grant.
+type libAccess struct {
+	all        bool
+	restricted map[string]bool
+	granted    map[string]database.LibPermission
+}
+
+func (a libAccess) can(lib string, need database.LibPermission) bool {
+	if a.all || !a.restricted[lib] {
+		return true
+	}
+	have, ok := a.granted[lib]
+	return ok && permissionRank[have] >= permissionRank[need]
+}
+
+// hiddenLibs lists the restricted libraries a cannot read.
+func (a libAccess) hiddenLibs() []string {
+	hidden := []string{}
+	for lib := range a.restricted {
+		if !a.can(lib, database.LibPermissionRead) {
+			hidden = append(hidden, lib)
+		}
+	}
+	return hidden
+}
+
+// readableObjs keeps the objs in libraries a can read.
+func (a libAccess) readableObjs(objs []database.MimixObj) []database.MimixObj {
+	out := objs[:0:0]
+	for _, obj := range objs {
+		if a.can(obj.Lib, database.LibPermissionRead) {
+			out = append(out, obj)
+		}
+	}
+	return out
+}
+
+// readableObjReqs keeps the requests in libraries a can read.
+func (a libAccess) readableObjReqs(reqs []database.MimixObjReq) []database.MimixObjReq {
+	out := reqs[:0:0]
+	for _, req := range reqs {
+		if a.can(req.Lib, database.LibPermissionRead) {
+			out = append(out, req)
+		}
+	}
+	return out
+}
+
+// LibGrant is one entry of a library ACL, given to either a user or a team.
+type LibGrant struct {
+	ID         uuid.UUID  `json:"id"`
+	Lib        string     `json:"lib"`
+	UserID     *uuid.UUID `json:"user_id,omitempty"`
+	Username   string     `json:"username,omitempty"`
+	TeamID     *uuid.UUID `json:"team_id,omitempty"`
+	Team       string     `json:"team,omitempty"`
+	Permission string     `json:"permission"`
+	GrantedBy  string     `json:"granted_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// LibGrantSource is a grant a user's permission on a library comes from;
+// Team is empty for a grant made to the user directly.
+type LibGrantSource struct {
+	Permission string `json:"permission"`
+	Team       string `json:"team,omitempty"`
+}
+
+// EffectivePermission is the highest permission a user holds on a
+// restricted library, "none" when no grant reaches them.
+type EffectivePermission struct {
+	Lib        string           `json:"lib"`
+	Permission string           `json:"permission"`
+	Via        []LibGrantSource `json:"via"`
+}
+
+// UserPermissions is the effective access of one user. AllLibs is set for
+// users who bypass the ACLs; libraries not listed are open to everyone.
+type UserPermissions struct {
+	Username string                `json:"username"`
+	AllLibs  bool                  `json:"all_libs"`
+	Libs     []EffectivePermission `json:"libs"`
+}
+
+// bypassesLibACL reports whether a user holding perms sees every library
+// regardless of its grants: those who administer the ACLs.
+func bypassesLibACL(perms permSet) bool {
+	return perms[permACLManage]
+}
+
+// loadLibAccess loads the grants reaching user, directly or through the
+// teams they are a member of.
+func loadLibAccess(ctx context.Context, q *database.Queries, user database.GetUserByIDRow, perms permSet) (libAccess, error) {
+	if bypassesLibACL(perms) {
+		return libAccess{all: true}, nil
+	}
+
+	libs, err := q.ListRestrictedLibs(ctx)
+	if err != nil {
+		return libAccess{}, err
+	}
+	access := libAccess{restricted: make(map[string]bool, len(libs)), granted: map[string]database.LibPermission{}}
+	for _, lib := range libs {
+		access.restricted[lib] = true
+	}
+	if len(libs) == 0 {
+		return access, nil
+	}
+
+	grants, err := q.ListUserLibGrants(ctx, uuid.NullUUID{UUID: user.ID, Valid: true})
+	if err != nil {
+		return libAccess{}, err
+	}
+	for _, g := range grants {
+		if permissionRank[g.Permission] > permissionRank[access.granted[g.Lib]] {
+			access.granted[g.Lib] = g.Permission
+		}
+	}
+	return access, nil
+}
+
+// contextUser returns the user authorizeUser stored for this request. The
+// zero user holds no grants, so restricted libraries stay hidden.
+func contextUser(c *gin.Context) database.GetUserByIDRow {
+	v, _ := c.Get(ctxUser)
+	user, _ := v.(database.GetUserByIDRow)
+	return user
+}
+
+// libAccessFor returns the library access of user, writing the error
+// response itself when it cannot be loaded.
+func (cfg *apiConfig) libAccessFor(c *gin.Context, user database.GetUserByIDRow) (libAccess, bool) {
+	if v, ok := c.Get(ctxLibAccess); ok {
+		return v.(libAccess), true
+	}
+	ctx := c.Request.Context()
+	perms, err := cfg.permissions.get(ctx, cfg.dbQueries, user.ID)
+	if err != nil {
+		log.Printf("error getting user permissions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get lib permissions"})
+		return libAccess{}, false
+	}
+	access, err := loadLibAccess(ctx, cfg.dbQueries, user, perms)
+	if err != nil {
+		log.Printf("error loading lib access: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get lib permissions"})
+		return libAccess{}, false
+	}
+	c.Set(ctxLibAccess, access)
+	return access, true
+}
+
+// requireLib checks that user holds need on lib. When notFound is given, a
+// user who cannot even read the library gets it as a 404, exactly as if the
+// record did not exist, so restricted libraries do not leak through ids.
+func (cfg *apiConfig) requireLib(c *gin.Context, user database.GetUserByIDRow, lib string, need database.LibPermission, notFound string) bool {
+	access, ok := cfg.libAccessFor(c, user)
+	if !ok {
+		return false
+	}
+	if notFound != "" && !access.can(lib, database.LibPermissionRead) {
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+		return false
+	}
+	if !access.can(lib, need) {
+		c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("forbidden: %s permission on the lib required", need)})
+		return false
+	}
+	return true
+}
+
+// requireObjLib loads the obj with id and checks that user holds need on
+// its library, answering notFound when the obj is missing or hidden.
+func (cfg *apiConfig) requireObjLib(c *gin.Context, user database.GetUserByIDRow, id uuid.UUID, need database.LibPermission, notFound string) bool {
+	obj, err := cfg.dbQueries.GetObjByID(c.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+		return false
+	}
+	if err != nil {
+		log.Printf("error getting mimix object: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get mimix object"})
+		return false
+	}
+	return cfg.requireLib(c, user, obj.Lib, need, notFound)
+}
+
+// requireObjReqLib is requireObjLib for obj requests.
+func (cfg *apiConfig) requireObjReqLib(c *gin.Context, user database.GetUserByIDRow, id uuid.UUID, need database.LibPermission, notFound string) bool {
+	req, err := cfg.dbQueries.GetMimixObjReqByID(c.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+		return false
+	}
+	if err != nil {
+		log.Printf("error getting mimix object request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get mimix object request"})
+		return false
+	}
+	return cfg.requireLib(c, user, req.Lib, need, notFound)
+}
+
+func toLibGrant(lib string, row database.ListLibACLRow) LibGrant {
+	return LibGrant{
+		ID:         row.ID,
+		Lib:        lib,
+		UserID:     NullUUIDToPtr(row.UserID),
+		Username:   NullStringToString(row.Username),
+		TeamID:     NullUUIDToPtr(row.TeamID),
+		Team:       NullStringToString(row.TeamName),
+		Permission: string(row.Permission),
+		GrantedBy:  row.GrantedBy,
+		CreatedAt:  row.CreatedAt,
+	}
+}
+
+// ListLibACL returns the grants of a library. They only apply while the
+// library is restricted.
+func (cfg *apiConfig) ListLibACL(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c, permAdminView); !ok {
+		return
+	}
+
+	lib, err := normalizeObjName("lib", c.Param("lib"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	libRow, err := cfg.dbQueries.GetMimixLibByName(ctx, lib)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "lib not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("error getting lib: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get lib"})
+		return
+	}
+
+	rows, err := cfg.dbQueries.ListLibACL(ctx, libRow.ID)
+	if err != nil {
+		log.Printf("error listing lib acl: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get lib acl"})
+		return
+	}
+
+	grants := make([]LibGrant, 0, len(rows))
+	for _, row := range rows {
+		grants = append(grants, toLibGrant(lib, row))
+	}
+	c.JSON(http.StatusOK, grants)
+}
+
+// GrantLibAccess grants a permission on a library to a user or a team,
+// replacing the one they already hold. Grants do not restrict the library
+// themselves, see SetLibRestricted.
+func (cfg *apiConfig) GrantLibAccess(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permACLManage)
+	if !ok {
+		return
+	}
+
+	type parameters struct {
+		Username   string `json:"username"`
+		Team       string `json:"team"`
+		Permission string `json:"permission"`
+	}
+
+	var params parameters
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	params.Username = strings.TrimSpace(params.Username)
+	params.Team = strings.TrimSpace(params.Team)
+	if (params.Username == "") == (params.Team == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "give exactly one of username and team"})
+		return
+	}
+	permission, ok := allowedLibPermission[strings.ToLower(strings.TrimSpace(params.Permission))]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid permission"})
+		return
+	}
+
+	lib, err := normalizeObjName("lib", c.Param("lib"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	grant := LibGrant{Lib: lib, Permission: string(permission), GrantedBy: user.Username}
+	var userID, teamID uuid.NullUUID
+	if params.Username != "" {
+		target, err := cfg.dbQueries.GetUserByUsername(ctx, params.Username)
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "user not found"})
+			return
+		}
+		if err != nil {
+			log.Printf("error getting user by username: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get user"})
+			return
+		}
+		userID = uuid.NullUUID{UUID: target.ID, Valid: true}
+		grant.UserID, grant.Username = &target.ID, target.Username
+	} else {
+		team, err := teamByRef(ctx, cfg.dbQueries, params.Team)
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid team"})
+			return
+		}
+		if err != nil {
+			log.Printf("error getting team: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get team"})
+			return
+		}
+		teamID = uuid.NullUUID{UUID: team.ID, Valid: true}
+		grant.TeamID, grant.Team = &team.ID, team.Name
+	}
+
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		libID, err := getOrCreateLib(ctx, q, lib)
+		if err != nil {
+			return err
+		}
+		var row database.LibAcl
+		if userID.Valid {
+			row, err = q.UpsertLibUserGrant(ctx, database.UpsertLibUserGrantParams{
+				LibID: libID, UserID: userID, Permission: permission, GrantedBy: user.Username,
+			})
+		} else {
+			row, err = q.UpsertLibTeamGrant(ctx, database.UpsertLibTeamGrantParams{
+				LibID: libID, TeamID: teamID, Permission: permission, GrantedBy: user.Username,
+			})
+		}
+		grant.ID, grant.CreatedAt = row.ID, row.CreatedAt
+		return err
+	})
+	if err != nil {
+		log.Printf("error granting lib access: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not grant lib access"})
+		return
+	}
+
+	c.JSON(http.StatusOK, grant)
+}
+
+// RevokeLibAccess removes one grant. A restricted library stays restricted
+// when its last grant goes.
+func (cfg *apiConfig) RevokeLibAccess(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c, permACLManage); !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid grant id"})
+		return
+	}
+
+	rows, err := cfg.dbQueries.DeleteLibACL(c.Request.Context(), id)
+	if err != nil {
+		log.Printf("error revoking lib access: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not revoke lib access"})
+		return
+	}
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "grant not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "lib access revoked", "id": id})
+}
+
+// SetLibRestricted restricts a library to the users granted access, or opens
+// it to everyone again.
+func (cfg *apiConfig) SetLibRestricted(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c, permACLManage); !ok {
+		return
+	}
+
+	type parameters struct {
+		Restricted *bool `json:"restricted" binding:"required"`
+	}
+
+	var params parameters
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lib, err := normalizeObjName("lib", c.Param("lib"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		libID, err := getOrCreateLib(ctx, q, lib)
+		if err != nil {
+			return err
+		}
+		return q.SetLibRestricted(ctx, database.SetLibRestrictedParams{ID: libID, Restricted: *params.Restricted})
+	})
+	if err != nil {
+		log.Printf("error setting lib restricted: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not update lib"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "lib access updated", "lib": lib, "restricted": *params.Restricted})
+}
+
+// GetUserPermissions returns what a user may do in each restricted library
+// and the grants it comes from.
+func (cfg *apiConfig) GetUserPermissions(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c, permAdminView); !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	found, err := cfg.dbQueries.GetUserByUsername(ctx, c.Param("username"))
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("error getting user by username: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get user"})
+		return
+	}
+	target, err := cfg.dbQueries.GetUserByID(ctx, found.ID)
+	if err != nil {
+		log.Printf("error getting user by id: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get user"})
+		return
+	}
+	targetPerms, err := cfg.permissions.get(ctx, cfg.dbQueries, target.ID)
+	if err != nil {
+		log.Printf("error getting user permissions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get permissions"})
+		return
+	}
+
+	resp := UserPermissions{Username: target.Username, AllLibs: bypassesLibACL(targetPerms), Libs: []EffectivePermission{}}
+	libs, err := cfg.dbQueries.ListRestrictedLibs(ctx)
+	if err != nil {
+		log.Printf("error listing restricted libs: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get permissions"})
+		return
+	}
+	grants, err := cfg.dbQueries.ListUserLibGrants(ctx, uuid.NullUUID{UUID: target.ID, Valid: true})
+	if err != nil {
+		log.Printf("error listing user lib grants: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get permissions"})
+		return
+	}
+
+	byLib := make(map[string][]database.ListUserLibGrantsRow, len(libs))
+	for _, g := range grants {
+		byLib[g.Lib] = append(byLib[g.Lib], g)
+	}
+	for _, lib := range libs {
+		perm := EffectivePermission{Lib: lib, Permission: "none", Via: []LibGrantSource{}}
+		if resp.AllLibs {
+			perm.Permission = string(database.LibPermissionManage)
+		}
+		for _, g := range byLib[lib] {
+			perm.Via = append(perm.Via, LibGrantSource{Permission: string(g.Permission), Team: NullStringToString(g.TeamName)})
+			if !resp.AllLibs && permissionRank[g.Permission] > permissionRank[database.LibPermission(perm.Permission)] {
+				perm.Permission = string(g.Permission)
+			}
+		}
+		resp.Libs = append(resp.Libs, perm)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,519 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/paul39-33/imimix/internal/database"
+)
+
+// auto-assignment strategies for new requests, set with ASSIGNMENT_STRATEGY
+const (
+	assignRoundRobin  = "round_robin"
+	assignLeastLoaded = "least_loaded"
+)
+
+// autoAssigner is the assigned_by of requests assigned by a strategy.
+const autoAssigner = "auto"
+
+// notification kind
+const notifyAssigned = "assigned"
+
+// errAssignedElsewhere is returned when a DC operator acts on a request that
+// is assigned to someone else.
+var errAssignedElsewhere = errors.New("obj request is assigned to another dc operator")
+
+type Workload struct {
+	Username string `json:"username"`
+	Pending  int64  `json:"pending"`
+}
+
+// assignStrategyFromEnv reads ASSIGNMENT_STRATEGY. Empty or none leaves new
+// requests unassigned.
+func assignStrategyFromEnv() string {
+	strategy := strings.ToLower(strings.TrimSpace(os.Getenv("ASSIGNMENT_STRATEGY")))
+	switch strategy {
+	case "", "none":
+		return ""
+	case assignRoundRobin, assignLeastLoaded:
+		return strategy
+	default:
+		log.Printf("invalid ASSIGNMENT_STRATEGY %q, new requests stay unassigned", strategy)
+		return ""
+	}
+}
+
+// canActOn reports whether user, holding perms, may work on a request: it
+// is unassigned, assigned to them, or they may assign requests.
+func canActOn(user database.GetUserByIDRow, perms permSet, req database.MimixObjReq) bool {
+	return !req.Assignee.Valid || strings.EqualFold(req.Assignee.String, user.Username) || perms[permReqAssign]
+}
+
+// pickAssignee chooses the DC operator a new request goes to, or "" when
+// there is none.
+func (cfg *apiConfig) pickAssignee(ctx context.Context, q *database.Queries) (string, error) {
+	// operators ordered by pending requests, then name
+	workload, err := q.ListDCWorkload(ctx)
+	if err != nil || len(workload) == 0 {
+		return "", err
+	}
+
+	if cfg.assignStrategy == assignLeastLoaded {
+		return workload[0].Username, nil
+	}
+
+	// round robin: the operator after the last one a request went to
+	last, err := q.GetLastAutoAssignee(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", err
+	}
+	next := ""
+	for _, w := range workload {
+		if w.Username > last.String && (next == "" || w.Username < next) {
+			next = w.Username
+		}
+	}
+	if next == "" {
+		// wrap around to the first operator
+		for _, w := range workload {
+			if next == "" || w.Username < next {
+				next = w.Username
+			}
+		}
+	}
+	return next, nil
+}
+
+// autoAssign assigns a new request with the configured strategy and tells
+// the operator. It returns the assignee, or "" when nothing was assigned.
+func (cfg *apiConfig) autoAssign(ctx context.Context, id uuid.UUID) (string, error) {
+	if cfg.assignStrategy == "" {
+		return "", nil
+	}
+
+	assignee, err := cfg.pickAssignee(ctx, cfg.dbQueries)
+	if err != nil || assignee == "" {
+		return "", err
+	}
+	req, err := cfg.dbQueries.AssignObjReq(ctx, database.AssignObjReqParams{
+		Assignee:   sql.NullString{String: assignee, Valid: true},
+		AssignedBy: sql.NullString{String: autoAssigner, Valid: true},
+		ID:         id,
+	})
+	if err != nil {
+		return "", err
+	}
+	return assignee, notifyAssignee(ctx, cfg.dbQueries, req, autoAssigner)
+}
+
+// routeNewObjReq gives a new request its SLA and, if configured, an
+// assignee. Failures are logged rather than failing the request: the
+// sla_escalation job assigns missing SLAs and requests can still be
+// claimed. The owning team's approval step is set by routeToTeam when the
+// request is created.
+func (cfg *apiConfig) routeNewObjReq(ctx context.Context, id uuid.UUID) (sql.NullTime, string) {
+	due, err := cfg.applySLA(ctx, cfg.dbQueries, id)
+	if err != nil {
+		log.Printf("error assigning sla: %v", err)
+	}
+	assignee, err := cfg.autoAssign(ctx, id)
+	if err != nil {
+		log.Printf("error auto-assigning obj request: %v", err)
+	}
+	return due, assignee
+}
+
+func notifyAssignee(ctx context.Context, q *database.Queries, req database.MimixObjReq, by string) error {
+	_, err := notify(ctx, q, notice{
+		recipient: req.Assignee.String,
+		kind:      notifyAssigned,
+		message:   fmt.Sprintf("%s was assigned to you by %s", displayName(req.ObjKind, req.ObjName, req.Lib, req.Subtree), by),
+		objReqID:  req.ID,
+	})
+	return err
+}
+
+// getObjReqParam loads the pending request named by the :id path param,
+// writing the error response itself when it cannot.
+func (cfg *apiConfig) getObjReqParam(c *gin.Context) (database.MimixObjReq, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid obj request id"})
+		return database.MimixObjReq{}, false
+	}
+
+	req, err := cfg.dbQueries.GetMimixObjReqByID(c.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "obj request not found"})
+		return req, false
+	}
+	if err != nil {
+		log.Printf("error getting mimix object request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get mimix object request"})
+		return req, false
+	}
+	if !cfg.requireLib(c, contextUser(c), req.Lib, database.LibPermissionRead, "obj request not found") {
+		return req, false
+	}
+	if req.ReqStatus != database.ReqStatusPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "only pending requests can be assigned"})
+		return req, false
+	}
+	return req, true
+}
+
+// ClaimObjReq assigns a pending request to the calling DC operator.
+func (cfg *apiConfig) ClaimObjReq(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permReqWork)
+	if !ok {
+		return
+	}
+
+	req, ok := cfg.getObjReqParam(c)
+	if !ok {
+		return
+	}
+
+	claimed, err := cfg.dbQueries.ClaimObjReq(c.Request.Context(), database.ClaimObjReqParams{
+		ID:       req.ID,
+		Assignee: sql.NullString{String: user.Username, Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// someone else holds it, or took it since we looked
+		c.JSON(http.StatusConflict, gin.H{"error": "obj request is already assigned to another dc operator"})
+		return
+	}
+	if err != nil {
+		log.Printf("error claiming obj request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not claim obj request"})
+		return
+	}
+
+	c.Header("ETag", etag(claimed.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "obj request claimed",
+		"data":    toMimixObjReq(claimed),
+	})
+}
+
+// UnclaimObjReq releases a request back to the unassigned queue. Only the
+// assignee or a lead can release it.
+func (cfg *apiConfig) UnclaimObjReq(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permReqWork)
+	if !ok {
+		return
+	}
+
+	req, ok := cfg.getObjReqParam(c)
+	if !ok {
+		return
+	}
+	if !req.Assignee.Valid {
+		c.JSON(http.StatusConflict, gin.H{"error": "obj request is not assigned"})
+		return
+	}
+	if !canActOn(user, contextPermissions(c), req) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only the assignee or a lead can release this obj request"})
+		return
+	}
+
+	released, err := cfg.dbQueries.AssignObjReq(c.Request.Context(), database.AssignObjReqParams{
+		AssignedBy: sql.NullString{String: user.Username, Valid: true},
+		ID:         req.ID,
+	})
+	if err != nil {
+		log.Printf("error releasing obj request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not release obj request"})
+		return
+	}
+
+	c.Header("ETag", etag(released.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "obj request released",
+		"data":    toMimixObjReq(released),
+	})
+}
+
+// AssignObjReq assigns a request to any DC operator, or clears the
+// assignment with an empty assignee.
+func (cfg *apiConfig) AssignObjReq(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permReqAssign)
+	if !ok {
+		return
+	}
+
+	type parameters struct {
+		Assignee string `json:"assignee"`
+	}
+
+	var params parameters
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	req, ok := cfg.getObjReqParam(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	var assignee sql.NullString
+	if name := strings.TrimSpace(params.Assignee); name != "" {
+		operator, err := cfg.dbQueries.GetUserByUsername(ctx, name)
+		var perms permSet
+		if err == nil {
+			perms, err = cfg.permissions.get(ctx, cfg.dbQueries, operator.ID)
+		}
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && !perms[permReqWork]) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "assignee must be a dc user"})
+			return
+		}
+		if err != nil {
+			log.Printf("error getting user: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get user"})
+			return
+		}
+		assignee = sql.NullString{String: operator.Username, Valid: true}
+	}
+
+	assigned, err := cfg.dbQueries.AssignObjReq(ctx, database.AssignObjReqParams{
+		Assignee:   assignee,
+		AssignedBy: sql.NullString{String: user.Username, Valid: true},
+		ID:         req.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusConflict, gin.H{"error": "only pending requests can be assigned"})
+		return
+	}
+	if err != nil {
+		log.Printf("error assigning obj request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not assign obj request"})
+		return
+	}
+
+	if assigned.Assignee.Valid && !strings.EqualFold(assigned.Assignee.String, user.Username) {
+		if err := notifyAssignee(ctx, cfg.dbQueries, assigned, user.Username); err != nil {
+			log.Printf("error notifying assignee: %v", err)
+		}
+	}
+
+	c.Header("ETag", etag(assigned.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "obj request assigned",
+		"data":    toMimixObjReq(assigned),
+	})
+}
+
+// withSLA maps requests to the API model with their SLA state.
+func (cfg *apiConfig) withSLA(ctx context.Context, reqs []database.MimixObjReq) ([]MimixObjReq, error) {
+	s, err := loadSLASetup(ctx, cfg.dbQueries, cfg.slaCalendar)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	out := make([]MimixObjReq, 0, len(reqs))
+	for _, req := range reqs {
+		r := toMimixObjReq(req)
+		r.SLA = s.status(req, now)
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// MyQueue returns the pending requests assigned to the caller, soonest due
+// first.
+func (cfg *apiConfig) MyQueue(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permReqWork)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	rows, err := cfg.dbQueries.ListObjReqByAssignee(ctx, sql.NullString{String: user.Username, Valid: true})
+	if err != nil {
+		log.Printf("error listing assigned obj requests: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get queue"})
+		return
+	}
+	access, ok := cfg.libAccessFor(c, user)
+	if !ok {
+		return
+	}
+	reqs, err := cfg.withSLA(ctx, access.readableObjReqs(rows))
+	if err != nil {
+		log.Printf("error loading sla policies: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get queue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, reqs)
+}
+
+// ListUnassignedQueue returns the pending requests nobody has claimed,
+// soonest due first.
+func (cfg *apiConfig) ListUnassignedQueue(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permReqWork)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	rows, err := cfg.dbQueries.ListUnassignedObjReq(ctx)
+	if err != nil {
+		log.Printf("error listing unassigned obj requests: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get queue"})
+		return
+	}
+	access, ok := cfg.libAccessFor(c, user)
+	if !ok {
+		return
+	}
+	reqs, err := cfg.withSLA(ctx, access.readableObjReqs(rows))
+	if err != nil {
+		log.Printf("error loading sla policies: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get queue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, reqs)
+}
+
+// ListWorkload returns how many pending requests each DC operator holds.
+func (cfg *apiConfig) ListWorkload(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c, permReqWork); !ok {
+		return
+	}
+
+	rows, err := cfg.dbQueries.ListDCWorkload(c.Request.Context())
+	if err != nil {
+		log.Printf("error listing dc workload: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get workload"})
+		return
+	}
+
+	workload := make([]Workload, 0, len(rows))
+	for _, row := range rows {
+		workload = append(workload, Workload{Username: row.Username, Pending: row.Pending})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"strategy": cfg.assignStrategy,
+		"workload": workload,
+	})
+}
+
+// errLastLead is returned for a change that would leave nobody able to
+// manage roles.
+var errLastLead = errors.New("cannot demote the last lead")
+
+// keepsLead runs change, failing with errLastLead when it takes roles.manage
+// from the last users holding it. Those users stay locked until the
+// transaction of q ends, so two leads cannot demote each other at once.
+func keepsLead(ctx context.Context, q *database.Queries, change func() error) error {
+	leads, err := q.LockUsersWithPermission(ctx, permRolesManage)
+	if err != nil {
+		return err
+	}
+	if err := change(); err != nil {
+		return err
+	}
+	if len(leads) == 0 {
+		return nil
+	}
+	exists, err := q.CheckPermissionHeld(ctx, permRolesManage)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errLastLead
+	}
+	return nil
+}
+
+// bootstrapLead gives the user named by BOOTSTRAP_LEAD the lead role while
+// nobody can manage roles, so a new install has someone to appoint the
+// others.
+func (cfg *apiConfig) bootstrapLead(ctx context.Context) error {
+	username := strings.TrimSpace(os.Getenv("BOOTSTRAP_LEAD"))
+	if username == "" {
+		return nil
+	}
+	exists, err := cfg.dbQueries.CheckPermissionHeld(ctx, permRolesManage)
+	if err != nil || exists {
+		return err
+	}
+	user, err := cfg.dbQueries.GetUserByUsername(ctx, username)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("BOOTSTRAP_LEAD %s is not a user", username)
+	}
+	if err != nil {
+		return err
+	}
+	role, err := cfg.dbQueries.GetRoleByName(ctx, leadRole)
+	if err != nil {
+		return err
+	}
+	if err := cfg.dbQueries.AddUserRole(ctx, database.AddUserRoleParams{UserID: user.ID, RoleID: role.ID}); err != nil {
+		return err
+	}
+	log.Printf("appointed %s as the first lead", user.Username)
+	return nil
+}
+
+// SetUserLead gives a user the lead role, or takes it away. The first lead
+// is set with BOOTSTRAP_LEAD.
+func (cfg *apiConfig) SetUserLead(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c, permRolesManage); !ok {
+		return
+	}
+
+	type parameters struct {
+		IsLead bool `json:"is_lead"`
+	}
+
+	var params parameters
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	target, ok := cfg.getUserParam(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	err := cfg.withTx(ctx, func(q *database.Queries) error {
+		role, err := q.GetRoleByName(ctx, leadRole)
+		if err != nil {
+			return err
+		}
+		return keepsLead(ctx, q, func() error {
+			if params.IsLead {
+				return q.AddUserRole(ctx, database.AddUserRoleParams{UserID: target.ID, RoleID: role.ID})
+			}
+			return q.RemoveUserRole(ctx, database.RemoveUserRoleParams{UserID: target.ID, RoleID: role.ID})
+		})
+	})
+	if errors.Is(err, errLastLead) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("error setting user lead: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not update user"})
+		return
+	}
+	cfg.permissions.invalidate(target.ID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "user updated", "username": target.Username, "is_lead": params.IsLead})
+}
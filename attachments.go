@@ -0,0 +1,514 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/paul39-33/imimix/internal/blob"
+	"github.com/paul39-33/imimix/internal/database"
+)
+
+const (
+	defaultAttachmentMaxMB = 10
+	defaultAttachmentDir   = "attachments"
+	// room for the multipart boundaries and headers around the file
+	multipartOverhead      = 1 << 20
+	maxFileNameLength      = 255
+	checksumHeader         = "X-Checksum-Sha256"
+	defaultAttachmentTypes = "text/plain,text/csv,application/pdf,image/png,image/jpeg,application/zip," +
+		"application/msword,application/vnd.openxmlformats-officedocument.wordprocessingml.document," +
+		"application/vnd.ms-excel,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// oleContentType is what attachmentContentType reports for the OLE
+// compound files legacy Office documents are, which http.DetectContentType
+// does not recognise.
+const oleContentType = "application/x-ole-storage"
+
+var oleSignature = []byte{0xd0, 0xcf, 0x11, 0xe0, 0xa1, 0xb1, 0x1a, 0xe1}
+
+// sniffedTypes lists the types detected in files of a declared type whose
+// format http.DetectContentType only knows by its container.
+var sniffedTypes = map[string][]string{
+	"text/csv":                 {"text/plain"},
+	"application/msword":       {oleContentType},
+	"application/vnd.ms-excel": {oleContentType},
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document": {"application/zip"},
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":       {"application/zip"},
+}
+
+// canAttachTo reports whether user, holding perms, may add or delete the
+// attachments of req: they made it, it is assigned to them, or they may
+// assign requests. The req.attach permission is needed besides.
+func canAttachTo(user database.GetUserByIDRow, perms permSet, req database.MimixObjReq) bool {
+	return strings.EqualFold(req.Requester, user.Username) ||
+		(req.Assignee.Valid && strings.EqualFold(req.Assignee.String, user.Username)) ||
+		perms[permReqAssign]
+}
+
+type Attachment struct {
+	ID          uuid.UUID  `json:"id"`
+	ObjID       *uuid.UUID `json:"obj_id,omitempty"`
+	ObjReqID    *uuid.UUID `json:"obj_req_id,omitempty"`
+	FileName    string     `json:"file_name"`
+	ContentType string     `json:"content_type"`
+	Size        int64      `json:"size"`
+	SHA256      string     `json:"sha256"`
+	UploadedBy  string     `json:"uploaded_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func toAttachment(a database.Attachment) Attachment {
+	return Attachment{
+		ID:          a.ID,
+		ObjID:       NullUUIDToPtr(a.ObjID),
+		ObjReqID:    NullUUIDToPtr(a.ObjReqID),
+		FileName:    a.FileName,
+		ContentType: a.ContentType,
+		Size:        a.SizeBytes,
+		SHA256:      a.Sha256,
+		UploadedBy:  a.UploadedBy,
+		CreatedAt:   a.CreatedAt,
+	}
+}
+
+// attachmentStoreFromEnv picks the storage backend from ATTACHMENT_STORAGE:
+// "local" (the default) keeps files under ATTACHMENT_DIR, "s3" puts them in
+// the S3_BUCKET of an S3-compatible server.
+func attachmentStoreFromEnv() (blob.Store, error) {
+	switch backend := strings.ToLower(strings.TrimSpace(os.Getenv("ATTACHMENT_STORAGE"))); backend {
+	case "", "local":
+		dir := os.Getenv("ATTACHMENT_DIR")
+		if dir == "" {
+			dir = defaultAttachmentDir
+		}
+		store, err := blob.NewFS(dir)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	case "s3":
+		store, err := blob.NewS3(blob.S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+		}, nil)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown ATTACHMENT_STORAGE %q", backend)
+	}
+}
+
+// attachmentMaxBytesFromEnv reads ATTACHMENT_MAX_MB.
+func attachmentMaxBytesFromEnv() int64 {
+	return int64(intFromEnv("ATTACHMENT_MAX_MB", defaultAttachmentMaxMB)) << 20
+}
+
+// attachmentTypesFromEnv reads the comma separated content types of
+// ATTACHMENT_TYPES.
+func attachmentTypesFromEnv() map[string]bool {
+	list := os.Getenv("ATTACHMENT_TYPES")
+	if strings.TrimSpace(list) == "" {
+		list = defaultAttachmentTypes
+	}
+	types := map[string]bool{}
+	for _, t := range strings.Split(list, ",") {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			types[t] = true
+		}
+	}
+	return types
+}
+
+// attachmentContentType returns the media type the client declared, or ""
+// when it declared none, and the one sniffed from the first 512 bytes of
+// the file.
+func attachmentContentType(header *multipart.FileHeader, file multipart.File) (declared, sniffed string, err error) {
+	if mediaType, _, err := mime.ParseMediaType(header.Header.Get("Content-Type")); err == nil && mediaType != "application/octet-stream" {
+		declared = strings.ToLower(mediaType)
+	}
+
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", "", err
+	}
+	if bytes.HasPrefix(buf[:n], oleSignature) {
+		return declared, oleContentType, nil
+	}
+	sniffed, _, _ = mime.ParseMediaType(http.DetectContentType(buf[:n]))
+	return declared, sniffed, nil
+}
+
+// contentMatches reports whether a file sniffed as sniffed can be of the
+// declared type.
+func contentMatches(declared, sniffed string) bool {
+	return declared == sniffed || slices.Contains(sniffedTypes[declared], sniffed)
+}
+
+// attachmentFileName keeps the base name of an uploaded file, without
+// control characters.
+func attachmentFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	if len(name) > maxFileNameLength {
+		name = strings.ToValidUTF8(name[:maxFileNameLength], "")
+	}
+	return name
+}
+
+// attachmentKey files blobs under their owner, so a bucket listing still
+// shows what they belong to.
+func attachmentKey(owner entityRef, id uuid.UUID) string {
+	if owner.objID.Valid {
+		return "obj/" + owner.objID.UUID.String() + "/" + id.String()
+	}
+	return "obj_req/" + owner.objReqID.UUID.String() + "/" + id.String()
+}
+
+// ListObjAttachments returns the files attached to an obj.
+func (cfg *apiConfig) ListObjAttachments(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c); !ok {
+		return
+	}
+	owner, ok := cfg.objRefParam(c)
+	if !ok {
+		return
+	}
+	cfg.listAttachments(c, owner)
+}
+
+// ListObjReqAttachments returns the files attached to an obj request.
+func (cfg *apiConfig) ListObjReqAttachments(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c); !ok {
+		return
+	}
+	owner, ok := cfg.objReqRefParam(c)
+	if !ok {
+		return
+	}
+	cfg.listAttachments(c, owner)
+}
+
+func (cfg *apiConfig) listAttachments(c *gin.Context, owner entityRef) {
+	var (
+		rows []database.Attachment
+		err  error
+	)
+	if owner.objID.Valid {
+		rows, err = cfg.dbQueries.ListObjAttachments(c.Request.Context(), owner.objID)
+	} else {
+		rows, err = cfg.dbQueries.ListObjReqAttachments(c.Request.Context(), owner.objReqID)
+	}
+	if err != nil {
+		log.Printf("error listing attachments: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get attachments"})
+		return
+	}
+
+	attachments := make([]Attachment, 0, len(rows))
+	for _, row := range rows {
+		attachments = append(attachments, toAttachment(row))
+	}
+	c.JSON(http.StatusOK, attachments)
+}
+
+// UploadObjAttachment attaches the multipart "file" field to an obj. The
+// caller needs request permission on its library.
+func (cfg *apiConfig) UploadObjAttachment(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permObjAttach)
+	if !ok {
+		return
+	}
+	owner, ok := cfg.objRefParam(c)
+	if !ok {
+		return
+	}
+	if !cfg.requireLib(c, user, owner.lib, database.LibPermissionRequest, "") {
+		return
+	}
+	cfg.uploadAttachment(c, user.Username, owner)
+}
+
+// UploadObjReqAttachment attaches the multipart "file" field to an obj
+// request.
+func (cfg *apiConfig) UploadObjReqAttachment(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permReqAttach)
+	if !ok {
+		return
+	}
+	owner, ok := cfg.objReqRefParam(c)
+	if !ok {
+		return
+	}
+	if !cfg.requireLib(c, user, owner.lib, database.LibPermissionRequest, "") {
+		return
+	}
+	if !canAttachTo(user, contextPermissions(c), owner.objReq) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only the requester, the assignee or a lead can attach files"})
+		return
+	}
+	cfg.uploadAttachment(c, user.Username, owner)
+}
+
+func (cfg *apiConfig) uploadAttachment(c *gin.Context, uploader string, owner entityRef) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, cfg.attachmentMaxBytes+multipartOverhead)
+	file, header, err := c.Request.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("file cannot be larger than %d MB", cfg.attachmentMaxBytes>>20)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a multipart file field is required"})
+		return
+	}
+	defer file.Close()
+
+	if header.Size > cfg.attachmentMaxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("file cannot be larger than %d MB", cfg.attachmentMaxBytes>>20)})
+		return
+	}
+	if header.Size == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is empty"})
+		return
+	}
+
+	declared, sniffed, err := attachmentContentType(header, file)
+	if err != nil {
+		log.Printf("error reading attachment: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "could not read file"})
+		return
+	}
+	contentType := declared
+	if contentType == "" {
+		contentType = sniffed
+	}
+	if !cfg.attachmentTypes[contentType] {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "content type " + contentType + " is not allowed"})
+		return
+	}
+	if !contentMatches(contentType, sniffed) {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "file content is " + sniffed + ", not " + contentType})
+		return
+	}
+
+	ctx := c.Request.Context()
+	id := uuid.New()
+	key := attachmentKey(owner, id)
+	sum := sha256.New()
+	if err := cfg.attachments.Put(ctx, key, io.TeeReader(file, sum), header.Size); err != nil {
+		log.Printf("error storing attachment: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not store attachment"})
+		return
+	}
+
+	attachment, err := cfg.dbQueries.CreateAttachment(ctx, database.CreateAttachmentParams{
+		ID:          id,
+		ObjID:       owner.objID,
+		ObjReqID:    owner.objReqID,
+		FileName:    attachmentFileName(header.Filename),
+		ContentType: contentType,
+		SizeBytes:   header.Size,
+		Sha256:      hex.EncodeToString(sum.Sum(nil)),
+		StorageKey:  key,
+		UploadedBy:  uploader,
+	})
+	if err != nil {
+		log.Printf("error creating attachment: %v", err)
+		if err := cfg.attachments.Delete(ctx, key); err != nil {
+			log.Printf("error removing stored attachment: %v", err)
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not store attachment"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toAttachment(attachment))
+}
+
+// getAttachmentParam loads the attachment named by the :id path param and
+// checks that its owner is still there, writing the error response itself
+// when it cannot. Attachments in libraries the caller cannot read are not
+// found. It also reports whether the caller may change the attachment, which
+// needs request permission on the library.
+func (cfg *apiConfig) getAttachmentParam(c *gin.Context) (database.Attachment, bool, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment id"})
+		return database.Attachment{}, false, false
+	}
+
+	ctx := c.Request.Context()
+	var (
+		lib string
+		req database.MimixObjReq
+	)
+	attachment, err := cfg.dbQueries.GetAttachmentByID(ctx, id)
+	if err == nil {
+		// attachments of trashed or purged owners are hidden with them
+		switch {
+		case attachment.ObjID.Valid:
+			var obj database.MimixObj
+			obj, err = cfg.dbQueries.GetObjByID(ctx, attachment.ObjID.UUID)
+			lib = obj.Lib
+		case attachment.ObjReqID.Valid:
+			req, err = cfg.dbQueries.GetMimixObjReqByID(ctx, attachment.ObjReqID.UUID)
+			lib = req.Lib
+		default:
+			err = sql.ErrNoRows
+		}
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "attachment not found"})
+		return attachment, false, false
+	}
+	if err != nil {
+		log.Printf("error getting attachment: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get attachment"})
+		return attachment, false, false
+	}
+	user := contextUser(c)
+	if !cfg.requireLib(c, user, lib, database.LibPermissionRead, "attachment not found") {
+		return attachment, false, false
+	}
+
+	// changing attachments needs the same request permission as adding them
+	access, ok := cfg.libAccessFor(c, user)
+	if !ok {
+		return attachment, false, false
+	}
+	if !access.can(lib, database.LibPermissionRequest) {
+		return attachment, false, true
+	}
+	perms := contextPermissions(c)
+	if attachment.ObjID.Valid {
+		return attachment, perms[permObjAttach], true
+	}
+	return attachment, perms[permReqAttach] && canAttachTo(user, perms, req), true
+}
+
+// DownloadAttachment streams an attachment back with its checksum.
+func (cfg *apiConfig) DownloadAttachment(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c); !ok {
+		return
+	}
+
+	attachment, _, ok := cfg.getAttachmentParam(c)
+	if !ok {
+		return
+	}
+
+	r, err := cfg.attachments.Get(c.Request.Context(), attachment.StorageKey)
+	if errors.Is(err, blob.ErrNotFound) {
+		log.Printf("attachment %s has no stored file", attachment.ID)
+		c.JSON(http.StatusNotFound, gin.H{"error": "attachment file is missing"})
+		return
+	}
+	if err != nil {
+		log.Printf("error reading attachment: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get attachment"})
+		return
+	}
+	defer r.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.SizeBytes, attachment.ContentType, r, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+		checksumHeader:           attachment.Sha256,
+	})
+}
+
+// DeleteAttachment removes an attachment. Those who may attach files to the
+// owner may remove its attachments.
+func (cfg *apiConfig) DeleteAttachment(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c); !ok {
+		return
+	}
+
+	attachment, mayChange, ok := cfg.getAttachmentParam(c)
+	if !ok {
+		return
+	}
+	if !mayChange {
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden: insufficient permissions"})
+		return
+	}
+
+	// once detached the sweep job finishes the removal if it fails here
+	ctx := c.Request.Context()
+	if _, err := cfg.dbQueries.DetachAttachment(ctx, attachment.ID); err != nil {
+		log.Printf("error detaching attachment: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not delete attachment"})
+		return
+	}
+	if err := cfg.removeAttachment(ctx, attachment); err != nil {
+		log.Printf("error removing attachment: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "attachment deleted",
+		"attachment_id": attachment.ID,
+	})
+}
+
+// removeAttachment deletes the stored file, then the row.
+func (cfg *apiConfig) removeAttachment(ctx context.Context, a database.Attachment) error {
+	if err := cfg.attachments.Delete(ctx, a.StorageKey); err != nil {
+		return err
+	}
+	_, err := cfg.dbQueries.DeleteAttachment(ctx, a.ID)
+	return err
+}
+
+// sweepAttachments removes attachments that lost their owner to a purge or
+// whose delete did not finish.
+func (cfg *apiConfig) sweepAttachments(ctx context.Context) (string, error) {
+	orphans, err := cfg.dbQueries.ListOrphanAttachments(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	removed := 0
+	for _, a := range orphans {
+		if err := cfg.removeAttachment(ctx, a); err != nil {
+			log.Printf("error removing attachment %s: %v", a.ID, err)
+			continue
+		}
+		removed++
+	}
+	return fmt.Sprintf("%d of %d orphaned attachments removed", removed, len(orphans)), nil
+}
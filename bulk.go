@@ -0,0 +1,421 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/paul39-33/imimix/internal/database"
+)
+
+// maxBulkItems caps how many rows a single bulk call may touch.
+const maxBulkItems = 1000
+
+// BulkSelection picks the rows a bulk call acts on: either an explicit list
+// of ids or a search filter, never both.
+type BulkSelection struct {
+	IDs    []uuid.UUID `json:"ids"`
+	Filter *BulkFilter `json:"filter"`
+	Atomic bool        `json:"atomic"`
+}
+
+// BulkFilter narrows the regular search to the rows a bulk call should touch.
+// At least one field must be set so an empty filter never matches everything.
+type BulkFilter struct {
+	Query  string `json:"query"`
+	Lib    string `json:"lib"`
+	Status string `json:"status"`
+}
+
+type BulkItemResult struct {
+	ID      uuid.UUID  `json:"id"`
+	Name    string     `json:"name,omitempty"`
+	Success bool       `json:"success"`
+	ObjID   *uuid.UUID `json:"obj_id,omitempty"`
+	Error   string     `json:"error,omitempty"`
+}
+
+type BulkResponse struct {
+	Atomic    bool             `json:"atomic"`
+	Committed bool             `json:"committed"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+}
+
+// bulkItemError is a per-item failure whose message is safe to return to the
+// caller; any other error is logged and reported generically.
+type bulkItemError string
+
+func (e bulkItemError) Error() string { return string(e) }
+
+const (
+	errBulkNotFound   = bulkItemError("not found")
+	errBulkNotPending = bulkItemError("obj request is not pending")
+	errBulkForbidden  = bulkItemError("forbidden: manage permission on the lib required")
+)
+
+func (f *BulkFilter) normalize() {
+	f.Query = strings.ToLower(strings.TrimSpace(f.Query))
+	f.Lib = strings.ToLower(strings.TrimSpace(f.Lib))
+	f.Status = strings.ToLower(strings.TrimSpace(f.Status))
+}
+
+func (f BulkFilter) empty() bool {
+	return f.Query == "" && f.Lib == "" && f.Status == ""
+}
+
+// validate checks that exactly one selection mode is used.
+func (s *BulkSelection) validate() error {
+	if len(s.IDs) > 0 && s.Filter != nil {
+		return errors.New("use either ids or filter, not both")
+	}
+	if s.Filter != nil {
+		s.Filter.normalize()
+		if s.Filter.empty() {
+			return errors.New("filter must set at least one of query, lib or status")
+		}
+		return nil
+	}
+	if len(s.IDs) == 0 {
+		return errors.New("ids or filter is required")
+	}
+	if len(s.IDs) > maxBulkItems {
+		return fmt.Errorf("at most %d ids are allowed", maxBulkItems)
+	}
+	return nil
+}
+
+// resolveObjIDs turns a selection into mimix object ids. A filter only
+// selects objs in libraries access can read.
+func (cfg *apiConfig) resolveObjIDs(ctx context.Context, access libAccess, s BulkSelection) ([]uuid.UUID, error) {
+	if s.Filter == nil {
+		return s.IDs, nil
+	}
+
+	objs, err := cfg.dbQueries.SearchMimixObj(ctx, sql.NullString{String: s.Filter.Query, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uuid.UUID
+	for _, obj := range access.readableObjs(objs) {
+		if s.Filter.Lib != "" && obj.Lib != s.Filter.Lib {
+			continue
+		}
+		if s.Filter.Status != "" && string(obj.MimixStatus) != s.Filter.Status {
+			continue
+		}
+		ids = append(ids, obj.ID)
+	}
+	return ids, nil
+}
+
+// resolveObjReqIDs is resolveObjIDs for obj requests.
+func (cfg *apiConfig) resolveObjReqIDs(ctx context.Context, access libAccess, s BulkSelection) ([]uuid.UUID, error) {
+	if s.Filter == nil {
+		return s.IDs, nil
+	}
+
+	reqs, err := cfg.dbQueries.SearchMimixObjReq(ctx, sql.NullString{String: s.Filter.Query, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uuid.UUID
+	for _, req := range access.readableObjReqs(reqs) {
+		if s.Filter.Lib != "" && req.Lib != s.Filter.Lib {
+			continue
+		}
+		if s.Filter.Status != "" && string(req.ReqStatus) != s.Filter.Status {
+			continue
+		}
+		ids = append(ids, req.ID)
+	}
+	return ids, nil
+}
+
+// runBulk applies fn to every id. In atomic mode everything runs in a single
+// transaction that is rolled back on the first failure; otherwise each item
+// runs in its own transaction and failures do not affect other items.
+func (cfg *apiConfig) runBulk(ctx context.Context, ids []uuid.UUID, atomic bool, fn func(q *database.Queries, id uuid.UUID, result *BulkItemResult) error) BulkResponse {
+	resp := BulkResponse{Atomic: atomic, Results: make([]BulkItemResult, len(ids))}
+	for i, id := range ids {
+		resp.Results[i] = BulkItemResult{ID: id}
+	}
+
+	fail := func(result *BulkItemResult, err error) {
+		var itemErr bulkItemError
+		var fieldErr fieldError
+		if errors.As(err, &itemErr) || errors.As(err, &fieldErr) || errors.Is(err, errObjExists) || errors.Is(err, errTeamApproval) {
+			result.Error = err.Error()
+		} else {
+			log.Printf("error processing bulk item %s: %v", result.ID, err)
+			result.Error = "internal error"
+		}
+	}
+
+	if atomic {
+		failedAt := -1
+		err := cfg.withTx(ctx, func(q *database.Queries) error {
+			for i := range ids {
+				if err := fn(q, ids[i], &resp.Results[i]); err != nil {
+					failedAt = i
+					fail(&resp.Results[i], err)
+					return err
+				}
+				resp.Results[i].Success = true
+			}
+			return nil
+		})
+		if err != nil {
+			for i := range resp.Results {
+				switch {
+				case i < failedAt:
+					resp.Results[i].Success = false
+					resp.Results[i].ObjID = nil
+					resp.Results[i].Error = "rolled back"
+				case i > failedAt && failedAt >= 0:
+					resp.Results[i].Error = "not attempted"
+				case failedAt < 0:
+					// the commit itself failed
+					fail(&resp.Results[i], err)
+					resp.Results[i].Success = false
+				}
+			}
+		}
+		resp.Committed = err == nil
+	} else {
+		for i := range ids {
+			err := cfg.withTx(ctx, func(q *database.Queries) error {
+				return fn(q, ids[i], &resp.Results[i])
+			})
+			if err != nil {
+				resp.Results[i].ObjID = nil
+				fail(&resp.Results[i], err)
+				continue
+			}
+			resp.Results[i].Success = true
+		}
+		resp.Committed = true
+	}
+
+	for _, r := range resp.Results {
+		if r.Success {
+			resp.Succeeded++
+		} else {
+			resp.Failed++
+		}
+	}
+	return resp
+}
+
+// bindBulk binds the json body into params and validates its selection.
+func bindBulk(c *gin.Context, params any, s *BulkSelection) bool {
+	if err := c.ShouldBindJSON(params); err != nil {
+		log.Printf("error binding json: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid parameters"})
+		return false
+	}
+	if err := s.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+// checkBulkStatus rejects a filter status that is not one of allowed, which
+// would otherwise just select nothing.
+func checkBulkStatus[T any](c *gin.Context, s BulkSelection, allowed map[string]T) bool {
+	if s.Filter == nil || s.Filter.Status == "" {
+		return true
+	}
+	if _, ok := allowed[s.Filter.Status]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return false
+	}
+	return true
+}
+
+// checkBulkSize rejects filter selections that resolve to too many rows.
+func checkBulkSize(c *gin.Context, ids []uuid.UUID) bool {
+	if len(ids) > maxBulkItems {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("filter matches %d rows, at most %d are allowed", len(ids), maxBulkItems),
+		})
+		return false
+	}
+	return true
+}
+
+func (cfg *apiConfig) BulkRemoveObj(c *gin.Context) {
+	//same permission as RemoveObj
+	user, ok := cfg.authorizeUser(c, permObjDelete)
+	if !ok {
+		return
+	}
+
+	var params BulkSelection
+	if !bindBulk(c, &params, &params) || !checkBulkStatus(c, params, allowedMimixStatus) {
+		return
+	}
+
+	access, ok := cfg.libAccessFor(c, user)
+	if !ok {
+		return
+	}
+	ids, err := cfg.resolveObjIDs(c.Request.Context(), access, params)
+	if err != nil {
+		log.Printf("error resolving bulk filter: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not search mimix objects"})
+		return
+	}
+	if !checkBulkSize(c, ids) {
+		return
+	}
+
+	resp := cfg.runBulk(c.Request.Context(), ids, params.Atomic, func(q *database.Queries, id uuid.UUID, result *BulkItemResult) error {
+		obj, err := q.GetObjByID(c.Request.Context(), id)
+		if errors.Is(err, sql.ErrNoRows) || err == nil && !access.can(obj.Lib, database.LibPermissionRead) {
+			return errBulkNotFound
+		}
+		if err != nil {
+			return err
+		}
+		result.Name = obj.Obj
+		if !access.can(obj.Lib, database.LibPermissionManage) {
+			return errBulkForbidden
+		}
+		_, err = q.RemoveObjByID(c.Request.Context(), database.RemoveObjByIDParams{
+			ID:        id,
+			DeletedBy: sql.NullString{String: user.Username, Valid: true},
+		})
+		return err
+	})
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (cfg *apiConfig) BulkUpdateObjStatus(c *gin.Context) {
+	//same permission as UpdateObjStatus
+	user, ok := cfg.authorizeUser(c, permObjStatus)
+	if !ok {
+		return
+	}
+
+	var params struct {
+		BulkSelection
+		MimixStatus string `json:"mimix_status" binding:"required"`
+	}
+	if !bindBulk(c, &params, &params.BulkSelection) || !checkBulkStatus(c, params.BulkSelection, allowedMimixStatus) {
+		return
+	}
+
+	// validate incoming status string and convert to enum
+	statusKey := strings.ToLower(strings.TrimSpace(params.MimixStatus))
+	statusVal, ok := allowedMimixStatus[statusKey]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mimix_status"})
+		return
+	}
+
+	access, ok := cfg.libAccessFor(c, user)
+	if !ok {
+		return
+	}
+	ids, err := cfg.resolveObjIDs(c.Request.Context(), access, params.BulkSelection)
+	if err != nil {
+		log.Printf("error resolving bulk filter: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not search mimix objects"})
+		return
+	}
+	if !checkBulkSize(c, ids) {
+		return
+	}
+
+	resp := cfg.runBulk(c.Request.Context(), ids, params.Atomic, func(q *database.Queries, id uuid.UUID, result *BulkItemResult) error {
+		obj, err := q.GetObjByID(c.Request.Context(), id)
+		if errors.Is(err, sql.ErrNoRows) || err == nil && !access.can(obj.Lib, database.LibPermissionRead) {
+			return errBulkNotFound
+		}
+		if err != nil {
+			return err
+		}
+		result.Name = obj.Obj
+		if !access.can(obj.Lib, database.LibPermissionManage) {
+			return errBulkForbidden
+		}
+		return q.UpdateMimixStatus(c.Request.Context(), database.UpdateMimixStatusParams{
+			ID:          id,
+			MimixStatus: statusVal,
+		})
+	})
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (cfg *apiConfig) BulkObjReqToObj(c *gin.Context) {
+	//same permission as ObjReqToObj
+	user, ok := cfg.authorizeUser(c, permReqConvert)
+	if !ok {
+		return
+	}
+
+	var params BulkSelection
+	if !bindBulk(c, &params, &params) || !checkBulkStatus(c, params, allowedReqStatus) {
+		return
+	}
+	// only pending requests can be converted
+	if params.Filter != nil && params.Filter.Status == "" {
+		params.Filter.Status = string(database.ReqStatusPending)
+	}
+
+	access, ok := cfg.libAccessFor(c, user)
+	if !ok {
+		return
+	}
+	ids, err := cfg.resolveObjReqIDs(c.Request.Context(), access, params)
+	if err != nil {
+		log.Printf("error resolving bulk filter: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not search mimix object requests"})
+		return
+	}
+	if !checkBulkSize(c, ids) {
+		return
+	}
+
+	resp := cfg.runBulk(c.Request.Context(), ids, params.Atomic, func(q *database.Queries, id uuid.UUID, result *BulkItemResult) error {
+		objReq, err := q.GetMimixObjReqByID(c.Request.Context(), id)
+		if errors.Is(err, sql.ErrNoRows) || err == nil && !access.can(objReq.Lib, database.LibPermissionRead) {
+			return errBulkNotFound
+		}
+		if err != nil {
+			return err
+		}
+		result.Name = objReq.ObjName
+		if !access.can(objReq.Lib, database.LibPermissionManage) {
+			return errBulkForbidden
+		}
+		if objReq.ReqStatus != database.ReqStatusPending {
+			return errBulkNotPending
+		}
+		if !canActOn(user, contextPermissions(c), objReq) {
+			return bulkItemError("obj request is assigned to " + objReq.Assignee.String)
+		}
+
+		objID, _, err := convertObjReq(c.Request.Context(), q, objReq, user.Username)
+		if err != nil {
+			return err
+		}
+		result.ObjID = &objID
+		return nil
+	})
+
+	c.JSON(http.StatusOK, resp)
+}
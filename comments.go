@@ -0,0 +1,530 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/paul39-33/imimix/internal/database"
+)
+
+const notifyMention = "mention"
+
+const maxCommentLength = 4000
+
+// mentionPattern matches @username in a comment body. A trailing dot is
+// punctuation, not part of the name.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([\w.-]*\w)`)
+
+// Comment is one entry of a discussion thread. Replies are nested under
+// their parent; a deleted comment stays in the thread without its body.
+type Comment struct {
+	ID        uuid.UUID  `json:"id"`
+	ObjID     *uuid.UUID `json:"obj_id,omitempty"`
+	ObjReqID  *uuid.UUID `json:"obj_req_id,omitempty"`
+	ParentID  *uuid.UUID `json:"parent_id,omitempty"`
+	Author    string     `json:"author"`
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"created_at"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	DeletedBy string     `json:"deleted_by,omitempty"`
+	Replies   []*Comment `json:"replies,omitempty"`
+}
+
+type CommentRevision struct {
+	ID        uuid.UUID `json:"id"`
+	Change    string    `json:"change"`
+	Body      string    `json:"body"`
+	ChangedBy string    `json:"changed_by"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// entityRef is the obj or obj request that comments and attachments
+// belong to; exactly one of the ids is set.
+type entityRef struct {
+	objID    uuid.NullUUID
+	objReqID uuid.NullUUID
+	// objReq is the request itself when objReqID is set
+	objReq database.MimixObjReq
+	// lib is the library of the obj or request
+	lib string
+}
+
+func toComment(c database.Comment) *Comment {
+	return &Comment{
+		ID:        c.ID,
+		ObjID:     NullUUIDToPtr(c.ObjID),
+		ObjReqID:  NullUUIDToPtr(c.ObjReqID),
+		ParentID:  NullUUIDToPtr(c.ParentID),
+		Author:    c.Author,
+		Body:      c.Body,
+		CreatedAt: c.CreatedAt,
+		EditedAt:  NullTimeToPtr(c.EditedAt),
+		DeletedAt: NullTimeToPtr(c.DeletedAt),
+		DeletedBy: NullStringToString(c.DeletedBy),
+	}
+}
+
+// commentThread nests replies under their parents. Rows must be ordered
+// oldest first, so a parent is always seen before its replies.
+func commentThread(rows []database.Comment) []*Comment {
+	byID := make(map[uuid.UUID]*Comment, len(rows))
+	thread := []*Comment{}
+	for _, row := range rows {
+		comment := toComment(row)
+		byID[comment.ID] = comment
+		if parent, ok := byID[row.ParentID.UUID]; row.ParentID.Valid && ok {
+			parent.Replies = append(parent.Replies, comment)
+			continue
+		}
+		thread = append(thread, comment)
+	}
+	return thread
+}
+
+// mentions returns the distinct lowercased usernames mentioned in body.
+func mentions(body string) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, m := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		name := strings.ToLower(m[1])
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// notifyMentions tells every existing user mentioned in a comment, except
+// its author. A user is only told once per comment, so editing a comment
+// only reaches the users newly mentioned.
+func (cfg *apiConfig) notifyMentions(ctx context.Context, comment database.Comment) {
+	for _, name := range mentions(comment.Body) {
+		if strings.EqualFold(name, comment.Author) {
+			continue
+		}
+		exists, err := cfg.dbQueries.CheckUserExists(ctx, name)
+		if err != nil {
+			log.Printf("error checking mentioned user: %v", err)
+			continue
+		}
+		if !exists {
+			continue
+		}
+		if _, err := notify(ctx, cfg.dbQueries, notice{
+			recipient: name,
+			kind:      notifyMention,
+			message:   fmt.Sprintf("%s mentioned you in a comment", comment.Author),
+			objID:     comment.ObjID.UUID,
+			objReqID:  comment.ObjReqID.UUID,
+			dedupeKey: fmt.Sprintf("%s:%s:%s", notifyMention, comment.ID, name),
+		}); err != nil {
+			log.Printf("error notifying mentioned user: %v", err)
+		}
+	}
+}
+
+// commentBody trims a comment body and checks its length.
+func commentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", errors.New("comment cannot be empty")
+	}
+	if len(body) > maxCommentLength {
+		return "", fmt.Errorf("comment cannot be longer than %d characters", maxCommentLength)
+	}
+	return body, nil
+}
+
+// objRefParam resolves the obj named by the :id path param, writing
+// the error response itself when it cannot. An obj in a library the caller
+// cannot read is not found.
+func (cfg *apiConfig) objRefParam(c *gin.Context) (entityRef, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid obj id"})
+		return entityRef{}, false
+	}
+
+	obj, err := cfg.dbQueries.GetObjByID(c.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no matching obj found"})
+		return entityRef{}, false
+	}
+	if err != nil {
+		log.Printf("error getting mimix object: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get mimix object"})
+		return entityRef{}, false
+	}
+	if !cfg.requireLib(c, contextUser(c), obj.Lib, database.LibPermissionRead, "no matching obj found") {
+		return entityRef{}, false
+	}
+	return entityRef{objID: uuid.NullUUID{UUID: id, Valid: true}, lib: obj.Lib}, true
+}
+
+// objReqRefParam is objRefParam for obj requests.
+func (cfg *apiConfig) objReqRefParam(c *gin.Context) (entityRef, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid obj req id"})
+		return entityRef{}, false
+	}
+
+	req, err := cfg.dbQueries.GetMimixObjReqByID(c.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "obj request not found"})
+		return entityRef{}, false
+	}
+	if err != nil {
+		log.Printf("error getting mimix object request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get mimix object request"})
+		return entityRef{}, false
+	}
+	if !cfg.requireLib(c, contextUser(c), req.Lib, database.LibPermissionRead, "obj request not found") {
+		return entityRef{}, false
+	}
+	return entityRef{objReqID: uuid.NullUUID{UUID: id, Valid: true}, objReq: req, lib: req.Lib}, true
+}
+
+// ListObjComments returns the discussion thread of an obj.
+func (cfg *apiConfig) ListObjComments(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c); !ok {
+		return
+	}
+	target, ok := cfg.objRefParam(c)
+	if !ok {
+		return
+	}
+	cfg.listComments(c, target)
+}
+
+// ListObjReqComments returns the discussion thread of an obj request.
+func (cfg *apiConfig) ListObjReqComments(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c); !ok {
+		return
+	}
+	target, ok := cfg.objReqRefParam(c)
+	if !ok {
+		return
+	}
+	cfg.listComments(c, target)
+}
+
+func (cfg *apiConfig) listComments(c *gin.Context, target entityRef) {
+	var (
+		rows []database.Comment
+		err  error
+	)
+	if target.objID.Valid {
+		rows, err = cfg.dbQueries.ListObjComments(c.Request.Context(), target.objID)
+	} else {
+		rows, err = cfg.dbQueries.ListObjReqComments(c.Request.Context(), target.objReqID)
+	}
+	if err != nil {
+		log.Printf("error listing comments: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get comments"})
+		return
+	}
+	c.JSON(http.StatusOK, commentThread(rows))
+}
+
+// AddObjComment posts a comment on an obj, or a reply when parent_id is set.
+func (cfg *apiConfig) AddObjComment(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c)
+	if !ok || !cfg.requirePermission(c, permComment) {
+		return
+	}
+	target, ok := cfg.objRefParam(c)
+	if !ok {
+		return
+	}
+	cfg.addComment(c, user.Username, target)
+}
+
+// AddObjReqComment posts a comment on an obj request, or a reply when
+// parent_id is set.
+func (cfg *apiConfig) AddObjReqComment(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c)
+	if !ok || !cfg.requirePermission(c, permComment) {
+		return
+	}
+	target, ok := cfg.objReqRefParam(c)
+	if !ok {
+		return
+	}
+	cfg.addComment(c, user.Username, target)
+}
+
+func (cfg *apiConfig) addComment(c *gin.Context, author string, target entityRef) {
+	type parameters struct {
+		Body     string        `json:"body" binding:"required"`
+		ParentID uuid.NullUUID `json:"parent_id"`
+	}
+
+	var params parameters
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	body, err := commentBody(params.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if params.ParentID.Valid {
+		parent, err := cfg.dbQueries.GetCommentByID(ctx, params.ParentID.UUID)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && (parent.ObjID != target.objID || parent.ObjReqID != target.objReqID)) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "parent comment is not in this thread"})
+			return
+		}
+		if err != nil {
+			log.Printf("error getting parent comment: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not add comment"})
+			return
+		}
+		if parent.DeletedAt.Valid {
+			c.JSON(http.StatusConflict, gin.H{"error": "cannot reply to a deleted comment"})
+			return
+		}
+	}
+
+	comment, err := cfg.dbQueries.CreateComment(ctx, database.CreateCommentParams{
+		ObjID:    target.objID,
+		ObjReqID: target.objReqID,
+		ParentID: params.ParentID,
+		Author:   author,
+		Body:     body,
+	})
+	if err != nil {
+		log.Printf("error creating comment: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not add comment"})
+		return
+	}
+
+	cfg.notifyMentions(ctx, comment)
+	c.JSON(http.StatusCreated, toComment(comment))
+}
+
+// getCommentParam loads the comment named by the :id path param, writing
+// the error response itself when it cannot. Comments in libraries the
+// caller cannot read are not found.
+func (cfg *apiConfig) getCommentParam(c *gin.Context) (database.Comment, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment id"})
+		return database.Comment{}, false
+	}
+
+	comment, err := cfg.dbQueries.GetCommentByID(c.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "comment not found"})
+		return comment, false
+	}
+	if err != nil {
+		log.Printf("error getting comment: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get comment"})
+		return comment, false
+	}
+	lib, err := cfg.dbQueries.GetCommentLib(c.Request.Context(), id)
+	if err != nil {
+		log.Printf("error getting comment lib: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get comment"})
+		return comment, false
+	}
+	if !cfg.requireLib(c, contextUser(c), lib, database.LibPermissionRead, "comment not found") {
+		return comment, false
+	}
+	if comment.DeletedAt.Valid {
+		c.JSON(http.StatusGone, gin.H{"error": "comment was deleted"})
+		return comment, false
+	}
+	return comment, true
+}
+
+// UpdateComment edits a comment. Only its author can, and the previous body
+// is kept in the comment history.
+func (cfg *apiConfig) UpdateComment(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c)
+	if !ok || !cfg.requirePermission(c, permComment) {
+		return
+	}
+
+	type parameters struct {
+		Body string `json:"body" binding:"required"`
+	}
+
+	var params parameters
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	body, err := commentBody(params.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	current, ok := cfg.getCommentParam(c)
+	if !ok {
+		return
+	}
+	if !strings.EqualFold(current.Author, user.Username) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only the author can edit a comment"})
+		return
+	}
+	if current.Body == body {
+		c.JSON(http.StatusOK, toComment(current))
+		return
+	}
+
+	ctx := c.Request.Context()
+	var comment database.Comment
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		if err := q.CreateCommentRevision(ctx, database.CreateCommentRevisionParams{
+			CommentID: current.ID,
+			Change:    database.CommentChangeEdited,
+			Body:      current.Body,
+			ChangedBy: user.Username,
+		}); err != nil {
+			return err
+		}
+		comment, err = q.UpdateCommentBody(ctx, database.UpdateCommentBodyParams{
+			ID:   current.ID,
+			Body: body,
+		})
+		return err
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusGone, gin.H{"error": "comment was deleted"})
+		return
+	}
+	if err != nil {
+		log.Printf("error updating comment: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not update comment"})
+		return
+	}
+
+	cfg.notifyMentions(ctx, comment)
+	c.JSON(http.StatusOK, toComment(comment))
+}
+
+// DeleteComment removes a comment's body while keeping its place in the
+// thread. Its author or a DC user can delete it; the body is kept in the
+// comment history.
+func (cfg *apiConfig) DeleteComment(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c)
+	if !ok || !cfg.requirePermission(c, permComment) {
+		return
+	}
+
+	current, ok := cfg.getCommentParam(c)
+	if !ok {
+		return
+	}
+	if !strings.EqualFold(current.Author, user.Username) && !contextPermissions(c)[permCommentModerate] {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only the author or dc can delete a comment"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var comment database.Comment
+	err := cfg.withTx(ctx, func(q *database.Queries) error {
+		if err := q.CreateCommentRevision(ctx, database.CreateCommentRevisionParams{
+			CommentID: current.ID,
+			Change:    database.CommentChangeDeleted,
+			Body:      current.Body,
+			ChangedBy: user.Username,
+		}); err != nil {
+			return err
+		}
+		var err error
+		comment, err = q.DeleteComment(ctx, database.DeleteCommentParams{
+			ID:        current.ID,
+			DeletedBy: sql.NullString{String: user.Username, Valid: true},
+		})
+		return err
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusGone, gin.H{"error": "comment was deleted"})
+		return
+	}
+	if err != nil {
+		log.Printf("error deleting comment: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not delete comment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toComment(comment))
+}
+
+// ListCommentHistory returns the earlier bodies of a comment, oldest first.
+// Deleted comments keep their history, which only the author and dc can
+// read.
+func (cfg *apiConfig) ListCommentHistory(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment id"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	comment, err := cfg.dbQueries.GetCommentByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "comment not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("error getting comment: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get comment"})
+		return
+	}
+	lib, err := cfg.dbQueries.GetCommentLib(ctx, id)
+	if err != nil {
+		log.Printf("error getting comment lib: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get comment"})
+		return
+	}
+	if !cfg.requireLib(c, user, lib, database.LibPermissionRead, "comment not found") {
+		return
+	}
+	if !strings.EqualFold(comment.Author, user.Username) && !contextPermissions(c)[permCommentModerate] {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only the author or dc can see the history of a comment"})
+		return
+	}
+
+	rows, err := cfg.dbQueries.ListCommentRevisions(ctx, id)
+	if err != nil {
+		log.Printf("error listing comment revisions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get comment history"})
+		return
+	}
+
+	revisions := make([]CommentRevision, 0, len(rows))
+	for _, row := range rows {
+		revisions = append(revisions, CommentRevision{
+			ID:        row.ID,
+			Change:    string(row.Change),
+			Body:      row.Body,
+			ChangedBy: row.ChangedBy,
+			ChangedAt: row.ChangedAt,
+		})
+	}
+	c.JSON(http.StatusOK, revisions)
+}
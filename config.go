@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strings"
@@ -11,12 +13,43 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/paul39-33/imimix/internal/auth"
+	"github.com/paul39-33/imimix/internal/blob"
 	"github.com/paul39-33/imimix/internal/database"
+	"github.com/paul39-33/imimix/internal/sla"
 )
 
 type apiConfig struct {
+	db        *sql.DB
 	dbQueries *database.Queries
 	secret    string
+
+	// trashRetentionDays is how long deleted rows stay restorable
+	trashRetentionDays int32
+
+	// webhookSecret signs deployment events; empty disables the webhook
+	webhookSecret string
+
+	// assignStrategy auto-assigns new requests; empty leaves them unassigned
+	assignStrategy string
+
+	// slaCalendar is the business time SLA targets are counted in
+	slaCalendar sla.Calendar
+
+	// scheduler runs the background jobs
+	scheduler *scheduler
+
+	// attachments stores uploaded files, within the size and type limits
+	attachments        blob.Store
+	attachmentMaxBytes int64
+	attachmentTypes    map[string]bool
+
+	// permissions caches what the roles of each user allow
+	permissions *permCache
+
+	// authenticator checks logins; directory users are provisioned on first
+	// login with the roles groupRoles maps their groups to
+	authenticator auth.Authenticator
+	groupRoles    map[string][]string
 }
 
 type UserLogin struct {
@@ -28,20 +61,33 @@ type User struct {
 	ID       uuid.UUID `json:"id"`
 	Username string    `json:"username"`
 	Job      string    `json:"job"`
+	Roles    []string  `json:"roles,omitempty"`
 }
 
 type MimixObj struct {
-	ID          uuid.UUID `json:"id"`
-	Obj         string    `json:"obj"`
-	ObjType     string    `json:"obj_type"`
-	PromoteDate time.Time `json:"promote_date"`
-	ObjVer      string    `json:"obj_ver"`
-	Lib         string    `json:"lib"`
-	LibID       uuid.UUID `json:"lib_id"`
-	MimixStatus string    `json:"mimix_status"`
-	Developer   string    `json:"developer"`
-	Keterangan  string    `json:"keterangan"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID           uuid.UUID  `json:"id"`
+	Obj          string     `json:"obj"`
+	ObjType      string     `json:"obj_type"`
+	PromoteDate  time.Time  `json:"promote_date"`
+	ObjVer       string     `json:"obj_ver"`
+	Lib          string     `json:"lib"`
+	LibID        *uuid.UUID `json:"lib_id,omitempty"`
+	MimixStatus  string     `json:"mimix_status"`
+	Developer    string     `json:"developer"`
+	DeveloperID  *uuid.UUID `json:"developer_id,omitempty"`
+	Keterangan   string     `json:"keterangan"`
+	ObjKind      string     `json:"obj_kind"`
+	PrcType      string     `json:"prc_type"`
+	Subtree      bool       `json:"subtree"`
+	Folder       string     `json:"folder,omitempty"`
+	Display      string     `json:"display"`
+	RuleID       *uuid.UUID `json:"rule_id,omitempty"`
+	StageID      *uuid.UUID `json:"stage_id,omitempty"`
+	CommentCount *int64     `json:"comment_count,omitempty"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	Version      int32      `json:"version"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+	DeletedBy    string     `json:"deleted_by,omitempty"`
 }
 
 type MimixLib struct {
@@ -50,28 +96,42 @@ type MimixLib struct {
 }
 
 type CreateObjReqInput struct {
-	ObjName     string    `json:"obj_name" binding:"required"`
-	Lib         string    `json:"lib" binding:"required"`
-	ObjVer      string    `json:"obj_ver"`
-	ObjType     string    `json:"obj_type"`
-	PromoteDate time.Time `json:"promote_date"`
-	Developer   string    `json:"developer"`
+	ObjName     string        `json:"obj_name" binding:"required"`
+	Lib         string        `json:"lib"`
+	ObjVer      string        `json:"obj_ver"`
+	ObjType     string        `json:"obj_type"`
+	PromoteDate time.Time     `json:"promote_date"`
+	Developer   string        `json:"developer"`
+	TicketID    uuid.NullUUID `json:"ticket_id"`
+	ObjKind     string        `json:"obj_kind"`
+	PrcType     string        `json:"prc_type"`
+	Subtree     bool          `json:"subtree"`
+	Priority    string        `json:"priority"`
 }
 
 type ObjRequest struct {
-	ID            uuid.UUID `json:"id"`
-	ObjName       string    `json:"obj_name"`
-	Requester     string    `json:"requester"`
-	Developer     string    `json:"developer,omitempty"`
-	ReqStatus     string    `json:"req_status"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
-	Lib           string    `json:"lib"`
-	ObjVer        string    `json:"obj_ver"`
-	ObjType       string    `json:"obj_type"`
-	PromoteDate   time.Time `json:"promote_date"`
-	SourceObjID   uuid.UUID `json:"source_obj_id,omitempty"`
-	PromoteStatus string    `json:"promote_status,omitempty"`
+	ID            uuid.UUID  `json:"id"`
+	ObjName       string     `json:"obj_name"`
+	Requester     string     `json:"requester"`
+	Developer     string     `json:"developer,omitempty"`
+	ReqStatus     string     `json:"req_status"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	Lib           string     `json:"lib"`
+	ObjVer        string     `json:"obj_ver"`
+	ObjType       string     `json:"obj_type"`
+	PromoteDate   time.Time  `json:"promote_date"`
+	SourceObjID   uuid.UUID  `json:"source_obj_id,omitempty"`
+	PromoteStatus string     `json:"promote_status,omitempty"`
+	TicketID      *uuid.UUID `json:"ticket_id,omitempty"`
+	Version       int32      `json:"version"`
+	ObjKind       string     `json:"obj_kind"`
+	PrcType       string     `json:"prc_type"`
+	Subtree       bool       `json:"subtree"`
+	Display       string     `json:"display"`
+	Priority      string     `json:"priority"`
+	DueAt         *time.Time `json:"due_at,omitempty"`
+	Assignee      string     `json:"assignee,omitempty"`
 }
 
 type ObjStatus struct {
@@ -97,6 +157,7 @@ var allowedMimixStatus = map[string]database.MimixStatus{
 var allowedReqStatus = map[string]database.ReqStatus{
 	"pending":   database.ReqStatusPending,
 	"completed": database.ReqStatusCompleted,
+	"rejected":  database.ReqStatusRejected,
 }
 
 var allowedPromoteStatus = map[string]database.PromoteStatus{
@@ -104,6 +165,13 @@ var allowedPromoteStatus = map[string]database.PromoteStatus{
 	"deployed":    database.PromoteStatusDeployed,
 }
 
+var allowedReqPriority = map[string]database.ReqPriority{
+	"low":       database.ReqPriorityLow,
+	"normal":    database.ReqPriorityNormal,
+	"high":      database.ReqPriorityHigh,
+	"emergency": database.ReqPriorityEmergency,
+}
+
 func ToNullTime(t time.Time) sql.NullTime {
 	if t.IsZero() {
 		return sql.NullTime{Valid: false}
@@ -125,27 +193,226 @@ func NullStringToString(ns sql.NullString) string {
 	return ""
 }
 
-// automate middleware for authentication
-func AuthMiddleware(secret string) gin.HandlerFunc {
+func NullTimeToPtr(nt sql.NullTime) *time.Time {
+	if nt.Valid {
+		return &nt.Time
+	}
+	return nil
+}
+
+func NullUUIDToPtr(nu uuid.NullUUID) *uuid.UUID {
+	if nu.Valid {
+		return &nu.UUID
+	}
+	return nil
+}
+
+func toMimixObj(obj database.MimixObj) MimixObj {
+	return MimixObj{
+		ID:          obj.ID,
+		Obj:         obj.Obj,
+		ObjType:     obj.ObjType,
+		PromoteDate: NullTimeToTime(obj.PromoteDate),
+		Lib:         obj.Lib,
+		LibID:       NullUUIDToPtr(obj.LibID),
+		ObjVer:      obj.ObjVer,
+		MimixStatus: string(obj.MimixStatus),
+		Developer:   obj.Developer,
+		DeveloperID: NullUUIDToPtr(obj.DeveloperID),
+		Keterangan:  NullStringToString(obj.Keterangan),
+		ObjKind:     string(obj.ObjKind),
+		PrcType:     string(obj.PrcType),
+		Subtree:     obj.Subtree,
+		Folder:      dloFolder(obj.ObjKind, obj.Obj),
+		Display:     displayName(obj.ObjKind, obj.Obj, obj.Lib, obj.Subtree),
+		RuleID:      NullUUIDToPtr(obj.RuleID),
+		StageID:     NullUUIDToPtr(obj.StageID),
+		UpdatedAt:   obj.UpdatedAt,
+		Version:     obj.Version,
+		DeletedAt:   NullTimeToPtr(obj.DeletedAt),
+		DeletedBy:   NullStringToString(obj.DeletedBy),
+	}
+}
+
+func toMimixObjReq(req database.MimixObjReq) MimixObjReq {
+	// handle nullable promote_status
+	var ps string
+	if req.PromoteStatus.Valid {
+		ps = string(req.PromoteStatus.PromoteStatus)
+	}
+
+	return MimixObjReq{
+		ID:               req.ID,
+		ObjName:          req.ObjName,
+		Requester:        req.Requester,
+		ReqStatus:        string(req.ReqStatus),
+		Lib:              req.Lib,
+		ObjVer:           req.ObjVer,
+		ObjType:          req.ObjType,
+		PromoteDate:      req.PromoteDate,
+		Developer:        NullStringToString(req.Developer),
+		DeveloperID:      NullUUIDToPtr(req.DeveloperID),
+		PromoteStatus:    ps,
+		TicketID:         NullUUIDToPtr(req.TicketID),
+		SourceObjID:      NullUUIDToPtr(req.SourceObjID),
+		ObjKind:          string(req.ObjKind),
+		PrcType:          string(req.PrcType),
+		Subtree:          req.Subtree,
+		Folder:           dloFolder(req.ObjKind, req.ObjName),
+		Display:          displayName(req.ObjKind, req.ObjName, req.Lib, req.Subtree),
+		StageID:          NullUUIDToPtr(req.StageID),
+		Priority:         string(req.Priority),
+		DueAt:            NullTimeToPtr(req.DueAt),
+		Assignee:         NullStringToString(req.Assignee),
+		AssignedAt:       NullTimeToPtr(req.AssignedAt),
+		TeamID:           NullUUIDToPtr(req.TeamID),
+		TeamApproval:     string(req.TeamApproval.TeamApproval),
+		TeamApprovalBy:   NullStringToString(req.TeamApprovalBy),
+		TeamApprovalAt:   NullTimeToPtr(req.TeamApprovalAt),
+		TeamApprovalNote: NullStringToString(req.TeamApprovalNote),
+		CreatedAt:        req.CreatedAt,
+		UpdatedAt:        req.UpdatedAt,
+		Version:          req.Version,
+		DeletedAt:        NullTimeToPtr(req.DeletedAt),
+		DeletedBy:        NullStringToString(req.DeletedBy),
+	}
+}
+
+// AuthMiddleware validates the bearer token and resolves the calling user
+// and the permissions of their roles into the context, so handlers only
+// check the permission they need. Permissions come from the cache, which
+// keeps the role tables from being joined on every request.
+func (cfg *apiConfig) AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		token, err := auth.GetBearerToken(c.Request.Header)
-		if err != nil {
-			c.AbortWithStatus(http.StatusUnauthorized)
+		if _, _, ok := cfg.resolveUser(c); !ok {
+			c.Abort()
 			return
 		}
+		c.Next()
+	}
+}
 
-		userID, err := auth.ValidateJWT(token, secret)
-		if err != nil {
-			c.AbortWithStatus(http.StatusUnauthorized)
-			return
-		}
+// resolveUser returns the calling user and their permissions, loading them
+// from the bearer token unless AuthMiddleware already did. The error
+// response is written here.
+func (cfg *apiConfig) resolveUser(c *gin.Context) (database.GetUserByIDRow, permSet, bool) {
+	if v, ok := c.Get(ctxUser); ok {
+		return v.(database.GetUserByIDRow), contextPermissions(c), true
+	}
 
-		c.Set("userID", userID)
-		c.Next()
+	//get user token
+	token, err := auth.GetBearerToken(c.Request.Header)
+	if err != nil {
+		log.Printf("error getting bearer token: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid token",
+		})
+		return database.GetUserByIDRow{}, nil, false
+	}
+
+	//validate user token
+	id, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		log.Printf("error validating token: %v", err)
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return database.GetUserByIDRow{}, nil, false
+	}
+
+	user, err := cfg.dbQueries.GetUserByID(c.Request.Context(), id)
+	if err != nil {
+		log.Printf("error getting user by Username: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "could not get user",
+		})
+		return database.GetUserByIDRow{}, nil, false
 	}
+
+	perms, err := cfg.permissions.get(c.Request.Context(), cfg.dbQueries, user.ID)
+	if err != nil {
+		log.Printf("error getting user permissions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "could not get user",
+		})
+		return database.GetUserByIDRow{}, nil, false
+	}
+
+	c.Set(ctxUser, user)
+	c.Set(ctxPermissions, perms)
+	return user, perms, true
+}
+
+// authorizeUser returns the calling user when their roles grant one of
+// perms, or read access when none are given. The error response is written
+// here, so callers only need to return when ok is false.
+func (cfg *apiConfig) authorizeUser(c *gin.Context, perms ...string) (database.GetUserByIDRow, bool) {
+	user, userPerms, ok := cfg.resolveUser(c)
+	if !ok {
+		return database.GetUserByIDRow{}, false
+	}
+
+	if !userPerms.allows(perms...) {
+		log.Printf("user %s unauthorized for permissions %v", user.Username, perms)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden: insufficient permissions",
+		})
+		return database.GetUserByIDRow{}, false
+	}
+	return user, true
+}
+
+// withTx runs fn inside a single database transaction, rolling back when fn
+// returns an error.
+func (cfg *apiConfig) withTx(ctx context.Context, fn func(q *database.Queries) error) error {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(cfg.dbQueries.WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// getOrCreateLib returns the id of the named mimix lib, creating it if needed.
+func getOrCreateLib(ctx context.Context, q *database.Queries, lib string) (uuid.UUID, error) {
+	libRow, err := q.GetMimixLibByName(ctx, lib)
+	if err == nil {
+		return libRow.ID, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, fmt.Errorf("could not get lib: %w", err)
+	}
+
+	createdLib, err := q.CreateMimixLib(ctx, lib)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("could not create lib: %w", err)
+	}
+	return createdLib.ID, nil
+}
+
+// objLibID returns the mimix lib an obj of kind is filed under, creating it
+// if needed. IFS and DLO entries sit in pseudo libraries and get none.
+func objLibID(ctx context.Context, q *database.Queries, kind database.ObjKind, lib string) (uuid.NullUUID, error) {
+	if kind != database.ObjKindLib {
+		return uuid.NullUUID{}, nil
+	}
+	id, err := getOrCreateLib(ctx, q, lib)
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+	return uuid.NullUUID{UUID: id, Valid: true}, nil
 }
 
 func (cfg *apiConfig) CreateUser(c *gin.Context) {
+	if !cfg.usesLocalPasswords() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "users are created from the directory on first login"})
+		return
+	}
+
 	type parameters struct {
 		Username        string `json:"username" binding:"required"`
 		Password        string `json:"password" binding:"required"`
@@ -195,11 +462,20 @@ func (cfg *apiConfig) CreateUser(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create user"})
 		return
 	}
-	// Create user in the database
-	user, err := cfg.dbQueries.CreateUser(c.Request.Context(), database.CreateUserParams{
-		Username:       params.Username,
-		HashedPassword: hashedPassword,
-		Job:            job,
+	// Create user in the database, holding the role of their job
+	ctx := c.Request.Context()
+	var user database.CreateUserRow
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		var err error
+		user, err = q.CreateUser(ctx, database.CreateUserParams{
+			Username:       params.Username,
+			HashedPassword: hashedPassword,
+			Job:            job,
+		})
+		if err != nil {
+			return err
+		}
+		return q.AddJobRole(ctx, user.Username)
 	})
 	if err != nil {
 		log.Printf("error creating user: %v", err)
@@ -217,20 +493,46 @@ func (cfg *apiConfig) UserLogin(c *gin.Context) {
 		return
 	}
 
-
 	// normalize username
 	input.Username = strings.ToLower(strings.TrimSpace(input.Username))
 
-	user, err := cfg.dbQueries.GetUserByUsername(c.Request.Context(), input.Username)
-	if err != nil {
-		log.Printf("error getting user by username: %v", err)
+	//check password
+	ctx := c.Request.Context()
+	identity, err := cfg.authenticator.Authenticate(ctx, input.Username, input.Pass)
+	if errors.Is(err, auth.ErrInvalidCredentials) {
+		log.Printf("Password verification failed for user: %s", input.Username)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid username or password"})
 		return
 	}
+	if err != nil {
+		log.Printf("error authenticating user: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not log in"})
+		return
+	}
 
-	//check password
-	if !auth.CheckPasswordHash(input.Pass, user.HashedPassword) {
-		log.Printf("Password verification failed for user: %s", input.Username)
+	//provision directory users and refresh their roles
+	if identity.External {
+		err := cfg.syncDirectoryUser(ctx, identity)
+		if errors.Is(err, errNoMappedRole) {
+			log.Printf("no role mapped for directory user %s", identity.Username)
+			c.JSON(http.StatusForbidden, gin.H{"error": "none of your directory groups has access"})
+			return
+		}
+		if errors.Is(err, errLocalAccount) {
+			log.Printf("directory login for unlinked local user %s", identity.Username)
+			c.JSON(http.StatusForbidden, gin.H{"error": "this account is not linked to the directory, ask a lead to link it"})
+			return
+		}
+		if err != nil {
+			log.Printf("error provisioning directory user: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not log in"})
+			return
+		}
+	}
+
+	user, err := cfg.dbQueries.GetUserByUsername(ctx, identity.Username)
+	if err != nil {
+		log.Printf("error getting user by username: %v", err)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid username or password"})
 		return
 	}
@@ -246,10 +548,18 @@ func (cfg *apiConfig) UserLogin(c *gin.Context) {
 		return
 	}
 
+	roles, err := cfg.dbQueries.ListUserRoleNames(ctx, user.ID)
+	if err != nil {
+		log.Printf("error getting user roles: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get user"})
+		return
+	}
+
 	userInfo := User{
 		ID:       user.ID,
 		Username: user.Username,
 		Job:      string(user.Job),
+		Roles:    roles,
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -289,12 +599,8 @@ func (cfg *apiConfig) CreateObj(c *gin.Context) {
 		return
 	}
 
-	//check if user job is "cmt"
-	if userData.Job != "cmt" {
-		log.Printf("user unauthorized job: %v", userData.Job)
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "forbidden: insufficient permissions",
-		})
+	//check if the user may add objs
+	if !cfg.requireUserPermission(c, userData.ID, permObjCreate) {
 		return
 	}
 
@@ -308,55 +614,100 @@ func (cfg *apiConfig) CreateObj(c *gin.Context) {
 		return
 	}
 
+	//obj, lib and obj_type must follow the IBM i rules of the obj kind
+	kind, err := parseObjKind(params.ObjKind)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	prcType, err := parsePrcType(params.PrcType)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	identity := objIdentity{Kind: kind, Name: params.Obj, Lib: params.Lib, ObjType: params.ObjType, Subtree: params.Subtree}
+	if err := identity.normalize("obj"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	params.Obj, params.Lib, params.ObjType = identity.Name, identity.Lib, identity.ObjType
+
+	//the caller needs manage permission on the library
+	if !cfg.requireLib(c, userData, params.Lib, database.LibPermissionManage, "") {
+		return
+	}
+
 	// ensure lib exists (create if not)
-	var libID uuid.UUID
-	libRow, err := cfg.dbQueries.GetMimixLibByName(c.Request.Context(), params.Lib)
+	libID, err := objLibID(c.Request.Context(), cfg.dbQueries, kind, params.Lib)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			createdLib, err := cfg.dbQueries.CreateMimixLib(c.Request.Context(), params.Lib)
-			if err != nil {
-				log.Printf("error creating lib: %v", err)
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create lib"})
-				return
-			}
-			libID = createdLib.ID
-		} else {
-			log.Printf("error fetching lib: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get lib"})
-			return
-		}
-	} else {
-		libID = libRow.ID
+		log.Printf("error getting lib: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get lib"})
+		return
 	}
 
-	//clean obj name input
-	params.Obj = strings.ToLower(strings.TrimSpace(params.Obj))
-	params.Lib = strings.ToLower(strings.TrimSpace(params.Lib))
-	params.Developer = strings.ToLower(strings.TrimSpace(params.Developer))
+	//the developer must be an existing user
+	if params.Developer, err = resolveDeveloper(c.Request.Context(), cfg.dbQueries, params.Developer, ""); err != nil {
+		developerError(c, err)
+		return
+	}
 
-	// validate mimix status
+	// validate mimix status, empty leaves it to the coverage rules
 	statusKey := strings.ToLower(strings.TrimSpace(string(params.MimixStatus)))
+	if statusKey == "" {
+		statusKey = string(database.MimixStatusUnset)
+	}
 	statusVal, ok := allowedMimixStatus[statusKey]
 	if !ok {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mimix_status"})
 		return
 	}
 
+	//an unset status is classified by the generic coverage rules
+	var ruleID uuid.NullUUID
+	if statusVal == database.MimixStatusUnset {
+		status, id, matched, err := classifyObj(c.Request.Context(), cfg.dbQueries, identity)
+		if err != nil {
+			log.Printf("error applying coverage rules: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not apply coverage rules"})
+			return
+		}
+		if matched {
+			statusVal, ruleID = status, id
+		}
+	}
+
 	//fix promote date null issue
 	promoteDate := ToNullTime(params.PromoteDate)
 
-	//create mimix object
-	obj, err := cfg.dbQueries.AddObj(c.Request.Context(), database.AddObjParams{
-		Obj:         params.Obj,
-		ObjType:     params.ObjType,
-		PromoteDate: promoteDate,
-		Lib:         params.Lib,
-		LibID:       libID,
-		ObjVer:      params.ObjVer,
-		MimixStatus: statusVal,
-		Developer:   params.Developer,
+	//create mimix object and start its version timeline
+	var obj database.AddObjRow
+	err = cfg.withTx(c.Request.Context(), func(q *database.Queries) error {
+		var err error
+		obj, err = q.AddObj(c.Request.Context(), database.AddObjParams{
+			Obj:         params.Obj,
+			ObjType:     params.ObjType,
+			PromoteDate: promoteDate,
+			Lib:         params.Lib,
+			LibID:       libID,
+			ObjVer:      params.ObjVer,
+			MimixStatus: statusVal,
+			Developer:   params.Developer,
+			ObjKind:     kind,
+			PrcType:     prcType,
+			Subtree:     params.Subtree,
+			RuleID:      ruleID,
+		})
+		if err != nil {
+			return err
+		}
+		return recordObjVersion(c.Request.Context(), q, database.CreateObjectVersionParams{
+			ObjID:       obj.ID,
+			ObjVer:      obj.ObjVer,
+			PromoteDate: obj.PromoteDate,
+			Developer:   obj.Developer,
+			Source:      database.ObjectVersionSourceCreated,
+		}, userData.Username)
 	})
-
 	if err != nil {
 		log.Printf("error creating mimix object: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -365,37 +716,30 @@ func (cfg *apiConfig) CreateObj(c *gin.Context) {
 		return
 	}
 
-	// associate the created obj with the lib id
-	if err := cfg.dbQueries.UpdateObjLibID(c.Request.Context(), database.UpdateObjLibIDParams{
-		ID:    obj.ID,
-		LibID: libID,
-	}); err != nil {
-		log.Printf("error updating obj lib_id: %v", err)
-		// optionally: delete created obj or return partial success
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not associate obj with lib"})
-		return
-	}
-
 	createdObj := MimixObj{
 		ID:          obj.ID,
 		Obj:         obj.Obj,
 		ObjType:     obj.ObjType,
 		PromoteDate: NullTimeToTime(promoteDate),
 		Lib:         obj.Lib,
-		LibID:       libID,
+		LibID:       NullUUIDToPtr(libID),
 		ObjVer:      obj.ObjVer,
 		MimixStatus: string(obj.MimixStatus),
 		Developer:   obj.Developer,
+		ObjKind:     string(obj.ObjKind),
+		PrcType:     string(obj.PrcType),
+		Subtree:     obj.Subtree,
+		Folder:      dloFolder(obj.ObjKind, obj.Obj),
+		Display:     displayName(obj.ObjKind, obj.Obj, obj.Lib, obj.Subtree),
+		RuleID:      NullUUIDToPtr(obj.RuleID),
 		UpdatedAt:   obj.UpdatedAt,
+		Version:     obj.Version,
 	}
 
+	c.Header("ETag", etag(obj.Version))
 	c.JSON(http.StatusOK, createdObj)
 }
 
-
-
-
-
 func (cfg *apiConfig) RemoveObj(c *gin.Context) {
 	//get user token
 	token, err := auth.GetBearerToken(c.Request.Header)
@@ -427,12 +771,8 @@ func (cfg *apiConfig) RemoveObj(c *gin.Context) {
 		return
 	}
 
-	//check if user job is "cmt" or "dc"
-	if userData.Job != "cmt" && userData.Job != "dc" {
-		log.Printf("user unauthorized job: %v", userData.Job)
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "forbidden: insufficient permissions",
-		})
+	//check if the user may delete objs
+	if !cfg.requireUserPermission(c, userData.ID, permObjDelete) {
 		return
 	}
 
@@ -449,15 +789,30 @@ func (cfg *apiConfig) RemoveObj(c *gin.Context) {
 		return
 	}
 
-	err = cfg.dbQueries.RemoveObjByID(c.Request.Context(), objUUID)
-	//if no obj is found
+	//the caller needs manage permission on the obj's library
+	obj, err := cfg.dbQueries.GetObjByID(c.Request.Context(), objUUID)
 	if errors.Is(err, sql.ErrNoRows) {
-		log.Printf("No matching obj found: %v", err)
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "no matching obj found",
 		})
 		return
 	}
+	if err != nil {
+		log.Printf("error getting mimix object: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "could not get mimix object",
+		})
+		return
+	}
+	if !cfg.requireLib(c, userData, obj.Lib, database.LibPermissionManage, "no matching obj found") {
+		return
+	}
+
+	//move obj to trash, it can be restored until it is purged
+	rows, err := cfg.dbQueries.RemoveObjByID(c.Request.Context(), database.RemoveObjByIDParams{
+		ID:        objUUID,
+		DeletedBy: sql.NullString{String: userData.Username, Valid: true},
+	})
 	if err != nil {
 		log.Printf("error deleting mimix object: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -465,9 +820,16 @@ func (cfg *apiConfig) RemoveObj(c *gin.Context) {
 		})
 		return
 	}
+	//if no obj is found
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "no matching obj found",
+		})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "mimix object deleted successfully",
+		"message": "mimix object moved to trash",
 		"obj_id":  objUUID,
 	})
 }
@@ -503,12 +865,8 @@ func (cfg *apiConfig) UpdateObjStatus(c *gin.Context) {
 		return
 	}
 
-	//check if user job is "dev" or "cmt"
-	if user.Job != "dev" && user.Job != "cmt" && user.Job != "dc" {
-		log.Printf("user unauthorized job: %v", user.Job)
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "forbidden: insufficient permissions",
-		})
+	//check if the user may change obj status
+	if !cfg.requireUserPermission(c, user.ID, permObjStatus) {
 		return
 	}
 
@@ -516,7 +874,6 @@ func (cfg *apiConfig) UpdateObjStatus(c *gin.Context) {
 	objName := c.Param("obj")
 	objName = strings.ToLower(strings.TrimSpace(objName))
 
-
 	type parameters struct {
 		MimixStatus string `json:"mimix_status" binding:"required"`
 	}
@@ -540,6 +897,22 @@ func (cfg *apiConfig) UpdateObjStatus(c *gin.Context) {
 		return
 	}
 
+	//the status is set by name, so the caller needs manage permission on
+	//every library holding the obj
+	libs, err := cfg.dbQueries.ListObjLibsByName(c.Request.Context(), objName)
+	if err != nil {
+		log.Printf("error listing obj libs: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "could not update mimix object status",
+		})
+		return
+	}
+	for _, lib := range libs {
+		if !cfg.requireLib(c, user, lib, database.LibPermissionManage, "") {
+			return
+		}
+	}
+
 	err = cfg.dbQueries.UpdateObjStatus(c.Request.Context(), database.UpdateObjStatusParams{
 		Obj:         objName,
 		MimixStatus: statusVal,
@@ -595,12 +968,8 @@ func (cfg *apiConfig) CreateObjReq(c *gin.Context) {
 		return
 	}
 
-	//check if user job is "dev" or "cmt"
-	if user.Job != "dev" && user.Job != "cmt" {
-		log.Printf("user unauthorized job: %v", user.Job)
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "forbidden: insufficient permissions",
-		})
+	//check if the user may create obj requests
+	if !cfg.requireUserPermission(c, user.ID, permReqCreate) {
 		return
 	}
 
@@ -610,6 +979,57 @@ func (cfg *apiConfig) CreateObjReq(c *gin.Context) {
 		return
 	}
 
+	//obj_name, lib and obj_type must follow the IBM i rules of the obj kind
+	kind, err := parseObjKind(input.ObjKind)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	prcType, err := parsePrcType(input.PrcType)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	priority, err := parseReqPriority(input.Priority)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	identity := objIdentity{Kind: kind, Name: input.ObjName, Lib: input.Lib, ObjType: input.ObjType, Subtree: input.Subtree}
+	if err := identity.normalize("obj_name"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.ObjName, input.Lib, input.ObjType = identity.Name, identity.Lib, identity.ObjType
+
+	//the caller needs request permission on the library
+	access, ok := cfg.libAccessFor(c, user)
+	if !ok {
+		return
+	}
+	if !cfg.requireLib(c, user, input.Lib, database.LibPermissionRequest, "") {
+		return
+	}
+
+	if input.Developer, err = resolveDeveloper(c.Request.Context(), cfg.dbQueries, input.Developer, ""); err != nil {
+		developerError(c, err)
+		return
+	}
+
+	// ensure the change ticket exists when one is given
+	if input.TicketID.Valid {
+		_, err := cfg.dbQueries.GetChangeTicketByID(c.Request.Context(), input.TicketID.UUID)
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ticket_id"})
+			return
+		}
+		if err != nil {
+			log.Printf("error getting change ticket: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get change ticket"})
+			return
+		}
+	}
+
 	objReq := database.CreateMimixObjReqParams{
 		ObjName:     input.ObjName,
 		Requester:   user.Username,
@@ -622,10 +1042,27 @@ func (cfg *apiConfig) CreateObjReq(c *gin.Context) {
 			String: input.Developer,
 			Valid:  strings.TrimSpace(input.Developer) != "",
 		},
+		TicketID: input.TicketID,
+		ObjKind:  kind,
+		PrcType:  prcType,
+		Subtree:  input.Subtree,
+		Priority: priority,
 	}
 
-	ObjReqRow, err := cfg.dbQueries.CreateMimixObjReq(c.Request.Context(), objReq)
-	if err != nil {
+	var (
+		ObjReqRow database.CreateMimixObjReqRow
+		routed    database.MimixObjReq
+	)
+	err = cfg.withTx(c.Request.Context(), func(q *database.Queries) error {
+		var err error
+		if ObjReqRow, err = q.CreateMimixObjReq(c.Request.Context(), objReq); err != nil {
+			return err
+		}
+		//a request never exists without its owning team's approval step
+		routed, err = routeToTeam(c.Request.Context(), q, ObjReqRow.ID)
+		return err
+	})
+	if err != nil {
 		log.Printf("error creating mimix object request: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "could not create mimix object request",
@@ -644,12 +1081,29 @@ func (cfg *apiConfig) CreateObjReq(c *gin.Context) {
 		ObjVer:      ObjReqRow.ObjVer,
 		ObjType:     ObjReqRow.ObjType,
 		PromoteDate: ObjReqRow.PromoteDate,
+		TicketID:    NullUUIDToPtr(ObjReqRow.TicketID),
+		Version:     routed.Version,
+		ObjKind:     string(ObjReqRow.ObjKind),
+		PrcType:     string(ObjReqRow.PrcType),
+		Subtree:     ObjReqRow.Subtree,
+		Display:     displayName(ObjReqRow.ObjKind, ObjReqRow.ObjName, ObjReqRow.Lib, ObjReqRow.Subtree),
+		Priority:    string(ObjReqRow.Priority),
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	due, assignee := cfg.routeNewObjReq(c.Request.Context(), ObjReqRow.ID)
+	CreatedObjReq.DueAt = NullTimeToPtr(due)
+	CreatedObjReq.Assignee = assignee
+
+	resp := gin.H{
 		"message": "obj request created successfully",
 		"data":    CreatedObjReq,
-	})
+	}
+	//warn about dependencies that are not registered yet
+	if warnings := cfg.requestWarnings(c.Request.Context(), access, ObjReqRow.ObjKind, objRef{lib: ObjReqRow.Lib, obj: ObjReqRow.ObjName, objType: ObjReqRow.ObjType}); len(warnings) > 0 {
+		resp["warnings"] = warnings
+	}
+
+	c.JSON(http.StatusOK, resp)
 }
 
 func (cfg *apiConfig) RemoveMimixObjReq(c *gin.Context) {
@@ -683,12 +1137,8 @@ func (cfg *apiConfig) RemoveMimixObjReq(c *gin.Context) {
 		return
 	}
 
-	//check if user job is "dev" or "cmt" or "dc"
-	if user.Job != "dev" && user.Job != "cmt" && user.Job != "dc" {
-		log.Printf("user unauthorized job: %v", user.Job)
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "forbidden: insufficient permissions",
-		})
+	//check if the user may delete obj requests
+	if !cfg.requireUserPermission(c, user.ID, permReqDelete) {
 		return
 	}
 
@@ -722,11 +1172,17 @@ func (cfg *apiConfig) RemoveMimixObjReq(c *gin.Context) {
 		})
 		return
 	}
+	if !cfg.requireLib(c, user, objNameRow.Lib, database.LibPermissionRequest, "obj request not found") {
+		return
+	}
 	objName := objNameRow.ObjName
 
-	//delete obj request
+	//move obj request to trash
 
-	err = cfg.dbQueries.RemoveMimixObjReq(c.Request.Context(), objReqUUID)
+	_, err = cfg.dbQueries.RemoveMimixObjReq(c.Request.Context(), database.RemoveMimixObjReqParams{
+		ID:        objReqUUID,
+		DeletedBy: sql.NullString{String: user.Username, Valid: true},
+	})
 	if err != nil {
 		log.Printf("error removing mimix object request: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -736,13 +1192,11 @@ func (cfg *apiConfig) RemoveMimixObjReq(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":  "mimix object request removed successfully",
+		"message":  "mimix object request moved to trash",
 		"obj_name": objName,
 	})
 }
 
-
-
 func (cfg *apiConfig) UpdateObjInfo(c *gin.Context) {
 	//get user token
 	token, err := auth.GetBearerToken(c.Request.Header)
@@ -774,12 +1228,8 @@ func (cfg *apiConfig) UpdateObjInfo(c *gin.Context) {
 		return
 	}
 
-	//check if user job is "cmt"
-	if user.Job != "cmt" {
-		log.Printf("user unauthorized job: %v", user.Job)
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "forbidden: insufficient permissions",
-		})
+	//check if the user may edit objs
+	if !cfg.requireUserPermission(c, user.ID, permObjEdit) {
 		return
 	}
 
@@ -795,55 +1245,85 @@ func (cfg *apiConfig) UpdateObjInfo(c *gin.Context) {
 		return
 	}
 
-	type parameters struct {
-		Obj         string    `json:"obj"`
-		ObjType     string    `json:"obj_type"`
-		Lib         string    `json:"lib"`
-		PromoteDate time.Time `json:"promote_date"`
-		ObjVer      string    `json:"obj_ver"`
-		Developer   string    `json:"developer"`
-		MimixStatus string    `json:"mimix_status"`
-		Keterangan  string    `json:"keterangan"`
-	}
-
-	var params parameters
-
-	//bind json parameters
-	if err = c.ShouldBindJSON(&params); err != nil {
-		log.Printf("error binding json: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "invalid parameters",
-		})
+	// writes must name the version they were based on
+	ifMatch, anyVersion, ok := requireIfMatch(c)
+	if !ok {
 		return
 	}
 
-	// validate mimix status
-	statusKey := strings.ToLower(strings.TrimSpace(params.MimixStatus))
-	statusVal, ok := allowedMimixStatus[statusKey]
+	// only the fields present in the merge patch are changed
+	patch, ok := bindMergePatch(c, objPatchFields)
 	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mimix_status"})
 		return
 	}
 
-	//fix promote date null issue
-	promoteDate := ToNullTime(params.PromoteDate)
-
-	keteranganNull := sql.NullString{
-		String: params.Keterangan,
-		Valid:  strings.TrimSpace(params.Keterangan) != "",
+	current, err := cfg.dbQueries.GetObjByID(c.Request.Context(), objUUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no matching obj found"})
+		return
+	}
+	if err != nil {
+		log.Printf("error getting mimix object: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get mimix object"})
+		return
+	}
+	if !cfg.requireLib(c, user, current.Lib, database.LibPermissionManage, "no matching obj found") {
+		return
+	}
+	if anyVersion {
+		ifMatch = current.Version
 	}
 
-	updatedObj, err := cfg.dbQueries.UpdateObjInfo(c.Request.Context(), database.UpdateObjInfoParams{
+	updateParams := database.UpdateObjInfoParams{
 		ID:          objUUID,
-		Obj:         params.Obj,
-		ObjType:     params.ObjType,
-		PromoteDate: promoteDate,
-		ObjVer:      params.ObjVer,
-		Developer:   params.Developer,
-		MimixStatus: statusVal,
-		Lib:         params.Lib,
-		Keterangan:  keteranganNull,
+		Obj:         current.Obj,
+		ObjType:     current.ObjType,
+		PromoteDate: current.PromoteDate,
+		ObjVer:      current.ObjVer,
+		Developer:   current.Developer,
+		MimixStatus: current.MimixStatus,
+		Lib:         current.Lib,
+		Keterangan:  current.Keterangan,
+		Version:     ifMatch,
+		ObjKind:     current.ObjKind,
+		PrcType:     current.PrcType,
+		Subtree:     current.Subtree,
+	}
+	if err := applyObjPatch(patch, &updateParams); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// moving the obj needs manage permission on its new library too
+	if updateParams.Lib != current.Lib && !cfg.requireLib(c, user, updateParams.Lib, database.LibPermissionManage, "") {
+		return
+	}
+	if updateParams.Developer, err = resolveDeveloper(c.Request.Context(), cfg.dbQueries, updateParams.Developer, current.Developer); err != nil {
+		developerError(c, err)
+		return
+	}
+
+	var updatedObj database.MimixObj
+	err = cfg.withTx(c.Request.Context(), func(q *database.Queries) error {
+		var err error
+		if updatedObj, err = q.UpdateObjInfo(c.Request.Context(), updateParams); err != nil {
+			return err
+		}
+		if updatedObj.ObjVer == current.ObjVer {
+			return nil
+		}
+		return recordObjVersion(c.Request.Context(), q, database.CreateObjectVersionParams{
+			ObjID:       updatedObj.ID,
+			ObjVer:      updatedObj.ObjVer,
+			PromoteDate: updatedObj.PromoteDate,
+			Developer:   updatedObj.Developer,
+			Source:      database.ObjectVersionSourceUpdated,
+		}, user.Username)
 	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// either the obj is gone or someone else updated it first
+		cfg.objPreconditionFailed(c, objUUID)
+		return
+	}
 	if err != nil {
 		log.Printf("error updating obj info: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -853,20 +1333,9 @@ func (cfg *apiConfig) UpdateObjInfo(c *gin.Context) {
 	}
 
 	// map to api struct
-	respObj := MimixObj{
-		ID:          updatedObj.ID,
-		Obj:         updatedObj.Obj,
-		ObjType:     updatedObj.ObjType,
-		PromoteDate: NullTimeToTime(updatedObj.PromoteDate),
-		Lib:         updatedObj.Lib,
-		LibID:       updatedObj.LibID,
-		ObjVer:      updatedObj.ObjVer,
-		MimixStatus: string(updatedObj.MimixStatus),
-		Developer:   updatedObj.Developer,
-		Keterangan:  NullStringToString(updatedObj.Keterangan),
-		UpdatedAt:   updatedObj.UpdatedAt,
-	}
+	respObj := toMimixObj(updatedObj)
 
+	c.Header("ETag", etag(updatedObj.Version))
 	c.JSON(http.StatusOK, gin.H{
 		"message": "obj info updated successfully",
 		"data":    respObj,
@@ -904,12 +1373,8 @@ func (cfg *apiConfig) ObjtoObjReq(c *gin.Context) {
 		return
 	}
 
-	//check if user job is "dev" or "cmt"
-	if user.Job != "dev" && user.Job != "cmt" {
-		log.Printf("user unauthorized job: %v", user.Job)
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "forbidden: insufficient permissions",
-		})
+	//check if the user may create obj requests
+	if !cfg.requireUserPermission(c, user.ID, permReqCreate) {
 		return
 	}
 
@@ -934,6 +1399,15 @@ func (cfg *apiConfig) ObjtoObjReq(c *gin.Context) {
 		return
 	}
 
+	//the caller needs request permission on the obj's library
+	access, ok := cfg.libAccessFor(c, user)
+	if !ok {
+		return
+	}
+	if !cfg.requireLib(c, user, obj.Lib, database.LibPermissionRequest, "no matching obj found") {
+		return
+	}
+
 	// check if pending request exists
 	_, err = cfg.dbQueries.GetPendingObjReqByNameAndLib(c.Request.Context(), database.GetPendingObjReqByNameAndLibParams{
 		ObjName: obj.Obj,
@@ -948,10 +1422,38 @@ func (cfg *apiConfig) ObjtoObjReq(c *gin.Context) {
 		return
 	}
 
-	err = cfg.dbQueries.AddObjToObjReq(c.Request.Context(), database.AddObjToObjReqParams{
-		ID:        obj.ID,
-		Requester: user.Username,
-		ReqStatus: "pending",
+	var objReq database.MimixObjReq
+	err = cfg.withTx(c.Request.Context(), func(q *database.Queries) error {
+		err := q.AddObjToObjReq(c.Request.Context(), database.AddObjToObjReqParams{
+			ID:        obj.ID,
+			Requester: user.Username,
+			ReqStatus: "pending",
+		})
+		if err != nil {
+			return err
+		}
+
+		//update obj mimix status to "on progress"
+		err = q.UpdateObjStatus(c.Request.Context(), database.UpdateObjStatusParams{
+			Obj:         obj.Obj,
+			MimixStatus: database.MimixStatusOnprogress,
+		})
+		if err != nil {
+			return fmt.Errorf("updating mimix object status: %w", err)
+		}
+
+		objReq, err = q.GetPendingObjReqByNameAndLib(c.Request.Context(), database.GetPendingObjReqByNameAndLibParams{
+			ObjName: obj.Obj,
+			Lib:     obj.Lib,
+		})
+		if err != nil {
+			return fmt.Errorf("getting new obj request: %w", err)
+		}
+		//a request never exists without its owning team's approval step
+		if _, err := routeToTeam(c.Request.Context(), q, objReq.ID); err != nil {
+			return fmt.Errorf("routing obj request to team: %w", err)
+		}
+		return nil
 	})
 	if err != nil {
 		log.Printf("error adding obj to obj request: %v", err)
@@ -960,23 +1462,17 @@ func (cfg *apiConfig) ObjtoObjReq(c *gin.Context) {
 		})
 		return
 	}
+	cfg.routeNewObjReq(c.Request.Context(), objReq.ID)
 
-	//update obj mimix status to "on progress"
-	err = cfg.dbQueries.UpdateObjStatus(c.Request.Context(), database.UpdateObjStatusParams{
-		Obj:         obj.Obj,
-		MimixStatus: database.MimixStatusOnprogress,
-	})
-	if err != nil {
-		log.Printf("error updating mimix object status: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "could not update mimix object status",
-		})
-		return
+	resp := gin.H{
+		"message": "obj added to obj request successfully",
+	}
+	//warn about dependencies that are not registered yet
+	if warnings := cfg.requestWarnings(c.Request.Context(), access, obj.ObjKind, objRef{lib: obj.Lib, obj: obj.Obj, objType: obj.ObjType}); len(warnings) > 0 {
+		resp["warnings"] = warnings
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "obj added to obj request successfully",
-	})
+	c.JSON(http.StatusOK, resp)
 }
 
 func (cfg *apiConfig) ObjReqToObj(c *gin.Context) {
@@ -1010,12 +1506,8 @@ func (cfg *apiConfig) ObjReqToObj(c *gin.Context) {
 		return
 	}
 
-	//check if user job is "dc"
-	if userData.Job != "dc" {
-		log.Printf("user unauthorized job: %v", userData.Job)
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "forbidden: insufficient permissions",
-		})
+	//check if the user may convert obj requests
+	if !cfg.requireUserPermission(c, userData.ID, permReqConvert) {
 		return
 	}
 
@@ -1040,116 +1532,166 @@ func (cfg *apiConfig) ObjReqToObj(c *gin.Context) {
 		return
 	}
 
-	//change obj req status to "completed"
-	err = cfg.dbQueries.CompleteMimixObjReq(c.Request.Context(), objReq.ID)
-	if err != nil {
-		log.Printf("error updating mimix object request status: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "could not update mimix object request status",
-		})
+	//converting needs manage permission on the library
+	if !cfg.requireLib(c, userData, objReq.Lib, database.LibPermissionManage, "obj request not found") {
 		return
 	}
 
-	//check if obj req already exists as obj
-	var sourceObj database.MimixObj
-	var objExists bool
+	//a request assigned to someone else is theirs to convert
+	if !canActOn(userData, contextPermissions(c), objReq) {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("obj request is assigned to %s", objReq.Assignee.String)})
+		return
+	}
 
-	if objReq.SourceObjID.Valid {
-		sourceObjID := objReq.SourceObjID.UUID
+	var objID uuid.UUID
+	var objExisted bool
+	err = cfg.withTx(c.Request.Context(), func(q *database.Queries) error {
 		var err error
-		sourceObj, err = cfg.dbQueries.GetObjByID(c.Request.Context(), sourceObjID)
-		if err == nil {
-			objExists = true
-		} else if !errors.Is(err, sql.ErrNoRows) {
-			log.Printf("error getting mimix object by source obj id: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "could not get mimix object",
-			})
-			return
-		}
+		objID, objExisted, err = convertObjReq(c.Request.Context(), q, objReq, userData.Username)
+		return err
+	})
+	if errors.Is(err, errObjExists) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Object with this name and library already exists"})
+		return
 	}
-
-	if objExists {
-		//change obj mimix status to "completed"
-		err = cfg.dbQueries.UpdateObjStatus(c.Request.Context(), database.UpdateObjStatusParams{
-			Obj:         sourceObj.Obj,
-			MimixStatus: database.MimixStatusDone,
+	if errors.Is(err, errTeamApproval) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	var fieldErr fieldError
+	if errors.As(err, &fieldErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fieldErr.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("error converting mimix object request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "could not convert mimix object request",
 		})
-		if err != nil {
-			log.Printf("error updating mimix object status: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "could not update mimix object status",
-			})
-			return
-		}
+		return
+	}
 
+	if objExisted {
 		c.JSON(http.StatusOK, gin.H{
 			"message": "obj request already exists as obj, status updated to completed",
-			"obj_id":  sourceObj.ID,
+			"obj_id":  objID,
 		})
 		return
 	}
 
-	{
-		// check if object with same name and lib already exists
-		_, err := cfg.dbQueries.GetObjByNameAndLib(c.Request.Context(), database.GetObjByNameAndLibParams{
-			Obj: objReq.ObjName,
-			Lib: objReq.Lib,
-		})
+	c.JSON(http.StatusOK, gin.H{
+		"message": "obj request converted to obj successfully",
+		"obj_id":  objID,
+	})
+}
+
+// errObjExists is returned by convertObjReq when a new object would collide
+// with an existing object of the same name and library.
+var errObjExists = errors.New("object with this name and library already exists")
+
+// convertObjReq completes objReq and registers it in mimix_obj. A request that
+// was raised from an existing object (source_obj_id) moves that object to the
+// requested version and marks it done; any other request creates a new object. existed reports which path was taken.
+// Either way the registered version is added to the object's timeline. A
+// request routed to the owning team needs its approval first.
+func convertObjReq(ctx context.Context, q *database.Queries, objReq database.MimixObjReq, actor string) (objID uuid.UUID, existed bool, err error) {
+	reqID := uuid.NullUUID{UUID: objReq.ID, Valid: true}
+	if err := checkTeamApproval(objReq); err != nil {
+		return uuid.Nil, false, err
+	}
+
+	//check if obj req already exists as obj
+	if objReq.SourceObjID.Valid {
+		sourceObj, err := q.GetObjByID(ctx, objReq.SourceObjID.UUID)
 		if err == nil {
-			c.JSON(http.StatusConflict, gin.H{"error": "Object with this name and library already exists"})
-			return
+			//change obj req status to "completed"
+			if err := q.CompleteMimixObjReq(ctx, objReq.ID); err != nil {
+				return uuid.Nil, false, fmt.Errorf("could not update mimix object request status: %w", err)
+			}
+			//move the obj to the requested version, with mimix status "done"
+			converted, err := q.ConvertObjVersion(ctx, database.ConvertObjVersionParams{
+				ID:          sourceObj.ID,
+				ObjVer:      objReq.ObjVer,
+				PromoteDate: ToNullTime(objReq.PromoteDate),
+				Developer:   NullStringToString(objReq.Developer),
+			})
+			if err != nil {
+				return uuid.Nil, false, fmt.Errorf("could not update mimix object version: %w", err)
+			}
+			if err := recordObjVersion(ctx, q, database.CreateObjectVersionParams{
+				ObjID:       converted.ID,
+				ObjVer:      converted.ObjVer,
+				PromoteDate: converted.PromoteDate,
+				Developer:   converted.Developer,
+				ObjReqID:    reqID,
+				Source:      database.ObjectVersionSourceConverted,
+			}, actor); err != nil {
+				return uuid.Nil, false, fmt.Errorf("could not record mimix object version: %w", err)
+			}
+			return sourceObj.ID, true, nil
 		} else if !errors.Is(err, sql.ErrNoRows) {
-			log.Printf("error checking existing object: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not check existing object"})
-			return
+			return uuid.Nil, false, fmt.Errorf("could not get mimix object: %w", err)
 		}
+	}
 
-		//check if new obj lib exists (create if not)
-		var libID uuid.UUID
-		libRow, err := cfg.dbQueries.GetMimixLibByName(c.Request.Context(), objReq.Lib)
-		if err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-				createdLib, err := cfg.dbQueries.CreateMimixLib(c.Request.Context(), objReq.Lib)
-				if err != nil {
-					log.Printf("error creating lib: %v", err)
-					c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create lib"})
-					return
-				}
-				libID = createdLib.ID
-			} else {
-				log.Printf("error fetching lib: %v", err)
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get lib"})
-				return
-			}
-		} else {
-			libID = libRow.ID
-		}
+	//new objs must follow the IBM i rules of their kind
+	identity := objIdentity{Kind: objReq.ObjKind, Name: objReq.ObjName, Lib: objReq.Lib, ObjType: objReq.ObjType, Subtree: objReq.Subtree}
+	if err := identity.normalize("obj_name"); err != nil {
+		return uuid.Nil, false, err
+	}
+	objReq.ObjName, objReq.Lib, objReq.ObjType = identity.Name, identity.Lib, identity.ObjType
 
-		//create new obj from obj req
-		newObj, err := cfg.dbQueries.AddObj(c.Request.Context(), database.AddObjParams{
-			Obj:         objReq.ObjName,
-			ObjType:     objReq.ObjType,
-			PromoteDate: ToNullTime(objReq.PromoteDate),
-			Lib:         objReq.Lib,
-			LibID:       libID,
-			ObjVer:      objReq.ObjVer,
-			MimixStatus: database.MimixStatusDone,
-			Developer:   NullStringToString(objReq.Developer),
-		})
-		if err != nil {
-			log.Printf("error creating mimix object from obj request: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "could not create mimix object from obj request",
-			})
-			return
-		}
+	// check if object with same name and lib already exists
+	_, err = q.GetObjByNameAndLib(ctx, database.GetObjByNameAndLibParams{
+		Obj: objReq.ObjName,
+		Lib: objReq.Lib,
+	})
+	if err == nil {
+		return uuid.Nil, false, errObjExists
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, false, fmt.Errorf("could not check existing object: %w", err)
+	}
 
-		c.JSON(http.StatusOK, gin.H{
-			"message": "obj request converted to obj successfully",
-			"obj_id":  newObj.ID,
-		})
+	//check if new obj lib exists (create if not)
+	libID, err := objLibID(ctx, q, objReq.ObjKind, objReq.Lib)
+	if err != nil {
+		return uuid.Nil, false, err
+	}
+
+	//change obj req status to "completed"
+	if err := q.CompleteMimixObjReq(ctx, objReq.ID); err != nil {
+		return uuid.Nil, false, fmt.Errorf("could not update mimix object request status: %w", err)
+	}
+
+	//create new obj from obj req
+	newObj, err := q.AddObj(ctx, database.AddObjParams{
+		Obj:         objReq.ObjName,
+		ObjType:     objReq.ObjType,
+		PromoteDate: ToNullTime(objReq.PromoteDate),
+		Lib:         objReq.Lib,
+		LibID:       libID,
+		ObjVer:      objReq.ObjVer,
+		MimixStatus: database.MimixStatusDone,
+		Developer:   NullStringToString(objReq.Developer),
+		ObjKind:     objReq.ObjKind,
+		PrcType:     objReq.PrcType,
+		Subtree:     objReq.Subtree,
+	})
+	if err != nil {
+		return uuid.Nil, false, fmt.Errorf("could not create mimix object from obj request: %w", err)
+	}
+	if err := recordObjVersion(ctx, q, database.CreateObjectVersionParams{
+		ObjID:       newObj.ID,
+		ObjVer:      newObj.ObjVer,
+		PromoteDate: newObj.PromoteDate,
+		Developer:   newObj.Developer,
+		ObjReqID:    reqID,
+		Source:      database.ObjectVersionSourceConverted,
+	}, actor); err != nil {
+		return uuid.Nil, false, fmt.Errorf("could not record mimix object version: %w", err)
 	}
+
+	return newObj.ID, false, nil
 }
 
 func (cfg *apiConfig) UpdateObjReqInfo(c *gin.Context) {
@@ -1183,12 +1725,8 @@ func (cfg *apiConfig) UpdateObjReqInfo(c *gin.Context) {
 		return
 	}
 
-	//check if user job is "dev" or "cmt"
-	if user.Job != "dev" && user.Job != "cmt" {
-		log.Printf("user unauthorized job: %v", user.Job)
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "forbidden: insufficient permissions",
-		})
+	//check if the user may edit obj requests
+	if !cfg.requireUserPermission(c, user.ID, permReqCreate) {
 		return
 	}
 
@@ -1204,72 +1742,80 @@ func (cfg *apiConfig) UpdateObjReqInfo(c *gin.Context) {
 		return
 	}
 
-	type parameters struct {
-		ObjName       string    `json:"obj_name"`
-		Lib           string    `json:"lib"`
-		PromoteDate   time.Time `json:"promote_date"`
-		ObjVer        string    `json:"obj_ver"`
-		ObjType       string    `json:"obj_type"`
-		Developer     string    `json:"developer"`
-		PromoteStatus string    `json:"promote_status"`
-		ReqStatus     string    `json:"req_status"`
+	// writes must name the version they were based on
+	ifMatch, anyVersion, ok := requireIfMatch(c)
+	if !ok {
+		return
 	}
 
-	var params parameters
-
-	//bind json parameters
-	if err = c.ShouldBindJSON(&params); err != nil {
-		log.Printf("error binding json: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "invalid parameters",
-		})
+	// only the fields present in the merge patch are changed
+	patch, ok := bindMergePatch(c, objReqPatchFields)
+	if !ok {
 		return
 	}
 
-
-
-	// prepare developer as sql.NullString
-	devNull := sql.NullString{
-		String: params.Developer,
-		Valid:  strings.TrimSpace(params.Developer) != "",
+	current, err := cfg.dbQueries.GetMimixObjReqByID(c.Request.Context(), objReqUUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "obj request not found"})
+		return
 	}
-
-	// validate req_status against allowed values
-	reqKey := strings.ToLower(strings.TrimSpace(params.ReqStatus))
-	reqVal, ok := allowedReqStatus[reqKey]
-	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid req_status"})
+	if err != nil {
+		log.Printf("error getting mimix object request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get mimix object request"})
 		return
 	}
-
-	// prepare promote_status as nullable enum
-	var promoteStatus database.NullPromoteStatus
-	if strings.TrimSpace(params.PromoteStatus) != "" {
-		psKey := strings.ToLower(strings.TrimSpace(params.PromoteStatus))
-		psVal, ok := allowedPromoteStatus[psKey]
-		if !ok {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid promote_status"})
-			return
-		}
-		promoteStatus = database.NullPromoteStatus{
-			PromoteStatus: psVal,
-			Valid:         true,
-		}
-	} else {
-		promoteStatus = database.NullPromoteStatus{Valid: false}
+	if !cfg.requireLib(c, user, current.Lib, database.LibPermissionRequest, "obj request not found") {
+		return
+	}
+	if anyVersion {
+		ifMatch = current.Version
 	}
 
-	updatedMimixObjReq, err := cfg.dbQueries.UpdateMimixObjReqInfo(c.Request.Context(), database.UpdateMimixObjReqInfoParams{
+	updateParams := database.UpdateMimixObjReqInfoParams{
 		ID:            objReqUUID,
-		ObjName:       params.ObjName,
-		Lib:           params.Lib,
-		PromoteDate:   params.PromoteDate,
-		ObjVer:        params.ObjVer,
-		ObjType:       params.ObjType,
-		Developer:     devNull,
-		PromoteStatus: promoteStatus,
-		ReqStatus:     reqVal,
+		ObjName:       current.ObjName,
+		Lib:           current.Lib,
+		PromoteDate:   current.PromoteDate,
+		ObjVer:        current.ObjVer,
+		ObjType:       current.ObjType,
+		Developer:     current.Developer,
+		PromoteStatus: current.PromoteStatus,
+		ReqStatus:     current.ReqStatus,
+		Version:       ifMatch,
+		ObjKind:       current.ObjKind,
+		PrcType:       current.PrcType,
+		Subtree:       current.Subtree,
+		Priority:      current.Priority,
+	}
+	if err := applyObjReqPatch(patch, &updateParams); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// moving the request needs request permission on its new library too
+	if updateParams.Lib != current.Lib && !cfg.requireLib(c, user, updateParams.Lib, database.LibPermissionRequest, "") {
+		return
+	}
+	if updateParams.Developer.String, err = resolveDeveloper(c.Request.Context(), cfg.dbQueries, updateParams.Developer.String, current.Developer.String); err != nil {
+		developerError(c, err)
+		return
+	}
+
+	var updatedMimixObjReq database.MimixObjReq
+	err = cfg.withTx(c.Request.Context(), func(q *database.Queries) error {
+		var err error
+		updatedMimixObjReq, err = q.UpdateMimixObjReqInfo(c.Request.Context(), updateParams)
+		if err != nil || updatedMimixObjReq.ReqStatus != database.ReqStatusPending {
+			return err
+		}
+		//the lib may have changed, and with it the owning team
+		updatedMimixObjReq, err = routeToTeam(c.Request.Context(), q, updatedMimixObjReq.ID)
+		return err
 	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// either the request is gone or someone else updated it first
+		cfg.objReqPreconditionFailed(c, objReqUUID)
+		return
+	}
 	if err != nil {
 		log.Printf("error updating obj req info: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -1278,30 +1824,31 @@ func (cfg *apiConfig) UpdateObjReqInfo(c *gin.Context) {
 		return
 	}
 
+	//and the SLA policy
+	if updatedMimixObjReq.ReqStatus == database.ReqStatusPending {
+		due, err := cfg.applySLA(c.Request.Context(), cfg.dbQueries, updatedMimixObjReq.ID)
+		if err != nil {
+			log.Printf("error assigning sla: %v", err)
+		} else {
+			updatedMimixObjReq.DueAt = due
+		}
+	}
+
+	c.Header("ETag", etag(updatedMimixObjReq.Version))
 	c.JSON(http.StatusOK, gin.H{
 		"message": "obj req info updated successfully",
-		"data":    updatedMimixObjReq,
+		"data":    toMimixObjReq(updatedMimixObjReq),
 	})
 }
 
 func (cfg *apiConfig) SearchObj(c *gin.Context) {
-	// get user token
-	token, err := auth.GetBearerToken(c.Request.Header)
-	if err != nil {
-		log.Printf("error getting bearer token: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "invalid token",
-		})
+	// the caller only sees the libraries they can read
+	user, ok := cfg.authorizeUser(c)
+	if !ok {
 		return
 	}
-
-	// validate user token
-	_, err = auth.ValidateJWT(token, cfg.secret)
-	if err != nil {
-		log.Printf("error validating token: %v", err)
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "unauthorized",
-		})
+	access, ok := cfg.libAccessFor(c, user)
+	if !ok {
 		return
 	}
 
@@ -1313,6 +1860,23 @@ func (cfg *apiConfig) SearchObj(c *gin.Context) {
 		Valid:  true,
 	}
 
+	// optional ?kind=, ?status=, ?team= filters and ?limit= / ?offset= window
+	filter, err := parseObjFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	teamLibs, ok := cfg.teamLibsParam(c)
+	if !ok {
+		return
+	}
+	filter.libs, filter.access = teamLibs, access
+	p, err := parsePage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// search objs by obj / lib / developer
 	objs, err := cfg.dbQueries.SearchMimixObj(
 		c.Request.Context(),
@@ -1329,56 +1893,61 @@ func (cfg *apiConfig) SearchObj(c *gin.Context) {
 	// map DB models → API models
 	var resultObjs []MimixObj
 	for _, obj := range objs {
-		resultObjs = append(resultObjs, MimixObj{
-			ID:          obj.ID,
-			Obj:         obj.Obj,
-			ObjType:     obj.ObjType,
-			PromoteDate: NullTimeToTime(obj.PromoteDate),
-			Lib:         obj.Lib,
-			ObjVer:      obj.ObjVer,
-			MimixStatus: string(obj.MimixStatus),
-			Developer:   obj.Developer,
-			Keterangan:  NullStringToString(obj.Keterangan),
-			UpdatedAt:   obj.UpdatedAt,
-		})
+		if !filter.match(obj) {
+			continue
+		}
+		resultObjs = append(resultObjs, toMimixObj(obj))
 	}
 
-	c.JSON(http.StatusOK, resultObjs)
+	c.JSON(http.StatusOK, paginate(c, p, resultObjs))
 }
 
 type MimixObjReq struct {
-	ID            uuid.UUID `json:"id"`
-	ObjName       string    `json:"obj_name"`
-	Requester     string    `json:"requester"`
-	ReqStatus     string    `json:"req_status"`
-	Lib           string    `json:"lib"`
-	ObjVer        string    `json:"obj_ver"`
-	ObjType       string    `json:"obj_type"`
-	PromoteDate   time.Time `json:"promote_date"`
-	Developer     string    `json:"developer"`
-	PromoteStatus string    `json:"promote_status"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID               uuid.UUID  `json:"id"`
+	ObjName          string     `json:"obj_name"`
+	Requester        string     `json:"requester"`
+	ReqStatus        string     `json:"req_status"`
+	Lib              string     `json:"lib"`
+	ObjVer           string     `json:"obj_ver"`
+	ObjType          string     `json:"obj_type"`
+	PromoteDate      time.Time  `json:"promote_date"`
+	Developer        string     `json:"developer"`
+	DeveloperID      *uuid.UUID `json:"developer_id,omitempty"`
+	PromoteStatus    string     `json:"promote_status"`
+	TicketID         *uuid.UUID `json:"ticket_id,omitempty"`
+	SourceObjID      *uuid.UUID `json:"source_obj_id,omitempty"`
+	ObjKind          string     `json:"obj_kind"`
+	PrcType          string     `json:"prc_type"`
+	Subtree          bool       `json:"subtree"`
+	Folder           string     `json:"folder,omitempty"`
+	Display          string     `json:"display"`
+	StageID          *uuid.UUID `json:"stage_id,omitempty"`
+	Priority         string     `json:"priority"`
+	DueAt            *time.Time `json:"due_at,omitempty"`
+	SLA              *ObjReqSLA `json:"sla,omitempty"`
+	Assignee         string     `json:"assignee,omitempty"`
+	AssignedAt       *time.Time `json:"assigned_at,omitempty"`
+	TeamID           *uuid.UUID `json:"team_id,omitempty"`
+	TeamApproval     string     `json:"team_approval,omitempty"`
+	TeamApprovalBy   string     `json:"team_approval_by,omitempty"`
+	TeamApprovalAt   *time.Time `json:"team_approval_at,omitempty"`
+	TeamApprovalNote string     `json:"team_approval_note,omitempty"`
+	CommentCount     *int64     `json:"comment_count,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	Version          int32      `json:"version"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty"`
+	DeletedBy        string     `json:"deleted_by,omitempty"`
 }
 
 func (cfg *apiConfig) SearchObjReq(c *gin.Context) {
-	// get user token
-	token, err := auth.GetBearerToken(c.Request.Header)
-	if err != nil {
-		log.Printf("error getting bearer token: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "invalid token",
-		})
+	// the caller only sees the libraries they can read
+	user, ok := cfg.authorizeUser(c)
+	if !ok {
 		return
 	}
-
-	// validate user token
-	_, err = auth.ValidateJWT(token, cfg.secret)
-	if err != nil {
-		log.Printf("error validating token: %v", err)
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "unauthorized",
-		})
+	access, ok := cfg.libAccessFor(c, user)
+	if !ok {
 		return
 	}
 
@@ -1390,6 +1959,32 @@ func (cfg *apiConfig) SearchObjReq(c *gin.Context) {
 		Valid:  true,
 	}
 
+	// optional ?kind=, ?status=, ?sla=, ?team= filters and ?limit= / ?offset= window
+	filter, err := parseObjReqFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	teamLibs, ok := cfg.teamLibsParam(c)
+	if !ok {
+		return
+	}
+	filter.libs, filter.access = teamLibs, access
+	p, err := parsePage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	slas, err := loadSLASetup(c.Request.Context(), cfg.dbQueries, cfg.slaCalendar)
+	if err != nil {
+		log.Printf("error loading sla policies: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "could not search mimix object requests",
+		})
+		return
+	}
+
 	// search obj requests
 	reqs, err := cfg.dbQueries.SearchMimixObjReq(
 		c.Request.Context(),
@@ -1403,32 +1998,17 @@ func (cfg *apiConfig) SearchObjReq(c *gin.Context) {
 		return
 	}
 
-	// map DB models → API models
+	// map DB models → API models, with breach flags
+	now := time.Now()
 	var resultReqs []MimixObjReq
 	for _, req := range reqs {
-		// handle nullable promote_status
-		var ps string
-		if req.PromoteStatus.Valid {
-			ps = string(req.PromoteStatus.PromoteStatus)
-		} else {
-			ps = ""
+		result := toMimixObjReq(req)
+		result.SLA = slas.status(req, now)
+		if !filter.match(result) {
+			continue
 		}
-
-		resultReqs = append(resultReqs, MimixObjReq{
-			ID:            req.ID,
-			ObjName:       req.ObjName,
-			Requester:     req.Requester,
-			ReqStatus:     string(req.ReqStatus),
-			Lib:           req.Lib,
-			ObjVer:        req.ObjVer,
-			ObjType:       req.ObjType,
-			PromoteDate:   req.PromoteDate,
-			Developer:     NullStringToString(req.Developer),
-			PromoteStatus: ps,
-			CreatedAt:     req.CreatedAt,
-			UpdatedAt:     req.UpdatedAt,
-		})
+		resultReqs = append(resultReqs, result)
 	}
 
-	c.JSON(http.StatusOK, resultReqs)
+	c.JSON(http.StatusOK, paginate(c, p, resultReqs))
 }
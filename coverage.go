@@ -0,0 +1,620 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/paul39-33/imimix/internal/coverage"
+	"github.com/paul39-33/imimix/internal/database"
+	"github.com/paul39-33/imimix/internal/ibmi"
+)
+
+// CoverageRule is a generic data group entry, e.g. PAYLIB/PAY* *ALL, that
+// decides the mimix_status of every obj it matches.
+type CoverageRule struct {
+	ID          uuid.UUID `json:"id"`
+	ObjKind     string    `json:"obj_kind"`
+	LibPattern  string    `json:"lib_pattern"`
+	ObjPattern  string    `json:"obj_pattern"`
+	ObjType     string    `json:"obj_type"`
+	PrcType     string    `json:"prc_type"`
+	MimixStatus string    `json:"mimix_status"`
+	Description string    `json:"description"`
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type CoverageRuleInput struct {
+	ObjKind     string `json:"obj_kind"`
+	LibPattern  string `json:"lib_pattern"`
+	ObjPattern  string `json:"obj_pattern"`
+	ObjType     string `json:"obj_type"`
+	PrcType     string `json:"prc_type"`
+	MimixStatus string `json:"mimix_status"`
+	Description string `json:"description"`
+}
+
+// CoveragePreviewItem is an existing obj matched by a proposed rule.
+type CoveragePreviewItem struct {
+	Obj           MimixObj   `json:"obj"`
+	CurrentStatus string     `json:"current_status"`
+	CurrentRuleID *uuid.UUID `json:"current_rule_id,omitempty"`
+	NewStatus     string     `json:"new_status"`
+	WillChange    bool       `json:"will_change"`
+	Reason        string     `json:"reason"`
+}
+
+type CoveragePreview struct {
+	Rule    CoverageRule          `json:"rule"`
+	Matched int                   `json:"matched"`
+	Changes int                   `json:"changes"`
+	Items   []CoveragePreviewItem `json:"items"`
+}
+
+// CoverageExplanation says where an obj's mimix_status comes from.
+type CoverageExplanation struct {
+	Obj MimixObj `json:"obj"`
+	// Source is "rule" when a coverage rule set the status, "manual" when it
+	// was set by hand and "unset" when nothing has classified the obj yet
+	Source        string         `json:"source"`
+	Rule          *CoverageRule  `json:"rule,omitempty"`
+	Winner        *CoverageRule  `json:"winner,omitempty"`
+	MatchingRules []CoverageRule `json:"matching_rules"`
+	Note          string         `json:"note"`
+}
+
+// objCoverage is the status and rule a reclassification gives an obj.
+type objCoverage struct {
+	obj    database.MimixObj
+	status database.MimixStatus
+	ruleID uuid.NullUUID
+}
+
+// defaultRuleStatus is the status a rule gives when none is set: included
+// objs are replicated, excluded ones need no registration.
+var defaultRuleStatus = map[database.PrcType]database.MimixStatus{
+	database.PrcTypeInclude: database.MimixStatusDone,
+	database.PrcTypeExclude: database.MimixStatusTidakperludaftar,
+}
+
+// normalizeNamePattern validates a library or object pattern: *ALL, a generic
+// name such as PAY* or a single name.
+func normalizeNamePattern(field, pattern string) (string, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" || strings.EqualFold(pattern, coverage.All) {
+		return coverage.All, nil
+	}
+
+	prefix, generic := strings.CutSuffix(pattern, "*")
+	name, err := normalizeObjName(field, prefix)
+	if err != nil {
+		return "", err
+	}
+	if generic {
+		if ibmi.IsQuoted(name) {
+			return "", fieldError{field: field, err: errors.New("a generic name cannot be quoted")}
+		}
+		name += "*"
+	}
+	return name, nil
+}
+
+// normalizePathPattern validates an IFS or DLO pattern: *ALL, a path or a
+// path prefix ending in '*', e.g. /home/app/*.
+func normalizePathPattern(kind database.ObjKind, pattern string) (string, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" || strings.EqualFold(pattern, coverage.All) {
+		return coverage.All, nil
+	}
+
+	prefix, generic := strings.CutSuffix(pattern, "*")
+	dir := generic && strings.HasSuffix(prefix, "/")
+
+	var (
+		path string
+		err  error
+	)
+	if kind == database.ObjKindIfs {
+		path, err = ibmi.NormalizeIFSPath(prefix)
+	} else {
+		path, err = ibmi.NormalizeDLOPath(prefix)
+	}
+	if err != nil {
+		return "", fieldError{field: "obj_pattern", err: err}
+	}
+
+	if dir {
+		path += "/"
+	}
+	if generic {
+		path += "*"
+	}
+	return path, nil
+}
+
+// normalize validates the input and returns it in stored form.
+func (in CoverageRuleInput) normalize(createdBy string) (database.CreateCoverageRuleParams, error) {
+	kind, err := parseObjKind(in.ObjKind)
+	if err != nil {
+		return database.CreateCoverageRuleParams{}, err
+	}
+	prcType, err := parsePrcType(in.PrcType)
+	if err != nil {
+		return database.CreateCoverageRuleParams{}, err
+	}
+
+	params := database.CreateCoverageRuleParams{
+		ObjKind:     kind,
+		LibPattern:  coverage.All,
+		PrcType:     prcType,
+		Description: strings.TrimSpace(in.Description),
+		CreatedBy:   createdBy,
+	}
+
+	if kind == database.ObjKindLib {
+		if params.LibPattern, err = normalizeNamePattern("lib_pattern", in.LibPattern); err != nil {
+			return params, err
+		}
+		params.ObjPattern, err = normalizeNamePattern("obj_pattern", in.ObjPattern)
+	} else {
+		params.ObjPattern, err = normalizePathPattern(kind, in.ObjPattern)
+	}
+	if err != nil {
+		return params, err
+	}
+
+	params.ObjType = coverage.All
+	if objType := strings.TrimSpace(in.ObjType); objType != "" && !strings.EqualFold(objType, coverage.All) {
+		if params.ObjType, err = normalizeObjType(objType); err != nil {
+			return params, err
+		}
+		if err := checkKindType(kind, params.ObjType); err != nil {
+			return params, err
+		}
+	}
+
+	statusKey := strings.ToLower(strings.TrimSpace(in.MimixStatus))
+	if statusKey == "" {
+		params.MimixStatus = defaultRuleStatus[prcType]
+	} else {
+		status, ok := allowedMimixStatus[statusKey]
+		if !ok || status == database.MimixStatusUnset {
+			return params, fieldError{field: "mimix_status", err: errors.New("must be a status other than unset")}
+		}
+		params.MimixStatus = status
+	}
+	return params, nil
+}
+
+func toCoverageRule(r database.CoverageRule) CoverageRule {
+	return CoverageRule{
+		ID:          r.ID,
+		ObjKind:     string(r.ObjKind),
+		LibPattern:  r.LibPattern,
+		ObjPattern:  r.ObjPattern,
+		ObjType:     r.ObjType,
+		PrcType:     string(r.PrcType),
+		MimixStatus: string(r.MimixStatus),
+		Description: r.Description,
+		CreatedBy:   r.CreatedBy,
+		CreatedAt:   r.CreatedAt,
+	}
+}
+
+func toEngineRule(r database.CoverageRule) coverage.Rule {
+	return coverage.Rule{
+		ID:      r.ID,
+		Kind:    string(r.ObjKind),
+		Lib:     r.LibPattern,
+		Obj:     r.ObjPattern,
+		ObjType: r.ObjType,
+		Exclude: r.PrcType == database.PrcTypeExclude,
+		Status:  string(r.MimixStatus),
+	}
+}
+
+func engineObject(kind database.ObjKind, name, lib, objType string) coverage.Object {
+	return coverage.Object{Kind: string(kind), Lib: lib, Obj: name, ObjType: objType}
+}
+
+// loadCoverageRules returns the stored rules for the engine, oldest first so
+// that rules which tie are decided by age.
+func loadCoverageRules(ctx context.Context, q *database.Queries) ([]coverage.Rule, map[uuid.UUID]database.CoverageRule, error) {
+	rows, err := q.ListCoverageRules(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rules := make([]coverage.Rule, 0, len(rows))
+	byID := make(map[uuid.UUID]database.CoverageRule, len(rows))
+	for _, row := range rows {
+		rules = append(rules, toEngineRule(row))
+		byID[row.ID] = row
+	}
+	return rules, byID, nil
+}
+
+// classifyObj returns the status the coverage rules give a new obj. ok is
+// false when no rule matches.
+func classifyObj(ctx context.Context, q *database.Queries, id objIdentity) (status database.MimixStatus, ruleID uuid.NullUUID, ok bool, err error) {
+	rules, _, err := loadCoverageRules(ctx, q)
+	if err != nil {
+		return "", uuid.NullUUID{}, false, err
+	}
+
+	winner, ok := coverage.Winner(rules, engineObject(id.Kind, id.Name, id.Lib, id.ObjType))
+	if !ok {
+		return "", uuid.NullUUID{}, false, nil
+	}
+	return database.MimixStatus(winner.Status), uuid.NullUUID{UUID: winner.ID, Valid: true}, true, nil
+}
+
+// ruleGoverned reports whether the rules may change an obj's status. A status
+// set by hand is left alone.
+func ruleGoverned(obj database.MimixObj) bool {
+	return obj.RuleID.Valid || obj.MimixStatus == database.MimixStatusUnset
+}
+
+// planCoverage returns the status and rule every rule governed obj should
+// have under the given rules. Objs that no longer match any rule go back to
+// unset.
+func planCoverage(objs []database.MimixObj, rules []coverage.Rule) []objCoverage {
+	var plan []objCoverage
+	for _, obj := range objs {
+		if !ruleGoverned(obj) {
+			continue
+		}
+
+		want := objCoverage{obj: obj, status: database.MimixStatusUnset}
+		if winner, ok := coverage.Winner(rules, engineObject(obj.ObjKind, obj.Obj, obj.Lib, obj.ObjType)); ok {
+			want.status = database.MimixStatus(winner.Status)
+			want.ruleID = uuid.NullUUID{UUID: winner.ID, Valid: true}
+		}
+		if want.status != obj.MimixStatus || want.ruleID != obj.RuleID {
+			plan = append(plan, want)
+		}
+	}
+	return plan
+}
+
+// reclassifyObjs applies the stored rules to every rule governed obj and
+// returns how many changed.
+func reclassifyObjs(ctx context.Context, q *database.Queries) (int, error) {
+	rules, _, err := loadCoverageRules(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	objs, err := q.SearchMimixObj(ctx, sql.NullString{String: "", Valid: true})
+	if err != nil {
+		return 0, err
+	}
+
+	plan := planCoverage(objs, rules)
+	for _, change := range plan {
+		if err := q.SetObjCoverage(ctx, database.SetObjCoverageParams{
+			ID:          change.obj.ID,
+			MimixStatus: change.status,
+			RuleID:      change.ruleID,
+		}); err != nil {
+			return 0, err
+		}
+	}
+	return len(plan), nil
+}
+
+// ListCoverageRules returns every coverage rule, oldest first.
+func (cfg *apiConfig) ListCoverageRules(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c); !ok {
+		return
+	}
+
+	rows, err := cfg.dbQueries.ListCoverageRules(c.Request.Context())
+	if err != nil {
+		log.Printf("error listing coverage rules: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get coverage rules"})
+		return
+	}
+
+	rules := make([]CoverageRule, 0, len(rows))
+	for _, row := range rows {
+		rules = append(rules, toCoverageRule(row))
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// bindCoverageRule reads and validates a rule from the request body.
+func bindCoverageRule(c *gin.Context, createdBy string) (database.CreateCoverageRuleParams, bool) {
+	var input CoverageRuleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		log.Printf("error binding json: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid parameters"})
+		return database.CreateCoverageRuleParams{}, false
+	}
+
+	params, err := input.normalize(createdBy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return params, false
+	}
+	return params, true
+}
+
+// CreateCoverageRule adds a rule and reclassifies the objs it now decides.
+func (cfg *apiConfig) CreateCoverageRule(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permCoverageManage)
+	if !ok {
+		return
+	}
+
+	params, ok := bindCoverageRule(c, user.Username)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	exists, err := cfg.dbQueries.CheckCoverageRuleExists(ctx, database.CheckCoverageRuleExistsParams{
+		ObjKind:    params.ObjKind,
+		LibPattern: params.LibPattern,
+		ObjPattern: params.ObjPattern,
+		ObjType:    params.ObjType,
+	})
+	if err != nil {
+		log.Printf("error checking coverage rule: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create coverage rule"})
+		return
+	}
+	if exists {
+		c.JSON(http.StatusConflict, gin.H{"error": "a rule for this lib, obj and type already exists"})
+		return
+	}
+
+	var (
+		rule         database.CoverageRule
+		reclassified int
+	)
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		if rule, err = q.CreateCoverageRule(ctx, params); err != nil {
+			return err
+		}
+		reclassified, err = reclassifyObjs(ctx, q)
+		return err
+	})
+	if err != nil {
+		log.Printf("error creating coverage rule: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create coverage rule"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"rule":         toCoverageRule(rule),
+		"reclassified": reclassified,
+	})
+}
+
+// DeleteCoverageRule removes a rule. Objs it decided are reclassified by the
+// remaining rules, or go back to unset.
+func (cfg *apiConfig) DeleteCoverageRule(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c, permCoverageManage); !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var reclassified int
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		if err := q.ResetObjCoverageByRule(ctx, uuid.NullUUID{UUID: id, Valid: true}); err != nil {
+			return err
+		}
+		rows, err := q.DeleteCoverageRule(ctx, id)
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return sql.ErrNoRows
+		}
+		reclassified, err = reclassifyObjs(ctx, q)
+		return err
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "coverage rule not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("error deleting coverage rule: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not delete coverage rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "coverage rule deleted",
+		"reclassified": reclassified,
+	})
+}
+
+// PreviewCoverageRule shows which existing objs a proposed rule matches and
+// how their status would change, without saving anything.
+func (cfg *apiConfig) PreviewCoverageRule(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permCoveragePreview)
+	if !ok {
+		return
+	}
+
+	params, ok := bindCoverageRule(c, user.Username)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	rules, _, err := loadCoverageRules(ctx, cfg.dbQueries)
+	if err != nil {
+		log.Printf("error listing coverage rules: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get coverage rules"})
+		return
+	}
+	objs, err := cfg.dbQueries.SearchMimixObj(ctx, sql.NullString{String: "", Valid: true})
+	if err != nil {
+		log.Printf("error listing mimix objects: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get mimix objects"})
+		return
+	}
+	// the preview only covers the libraries the caller can read
+	access, ok := cfg.libAccessFor(c, user)
+	if !ok {
+		return
+	}
+	objs = access.readableObjs(objs)
+
+	// the proposed rule has no id yet; uuid.Nil stands in for it
+	proposed := toEngineRule(database.CoverageRule{
+		ObjKind:     params.ObjKind,
+		LibPattern:  params.LibPattern,
+		ObjPattern:  params.ObjPattern,
+		ObjType:     params.ObjType,
+		PrcType:     params.PrcType,
+		MimixStatus: params.MimixStatus,
+	})
+	for _, r := range rules {
+		if r.Kind == proposed.Kind && r.Lib == proposed.Lib && r.Obj == proposed.Obj && r.ObjType == proposed.ObjType {
+			c.JSON(http.StatusConflict, gin.H{"error": "a rule for this lib, obj and type already exists"})
+			return
+		}
+	}
+	withProposed := append(rules, proposed)
+
+	preview := CoveragePreview{
+		Rule: CoverageRule{
+			ObjKind:     string(params.ObjKind),
+			LibPattern:  params.LibPattern,
+			ObjPattern:  params.ObjPattern,
+			ObjType:     params.ObjType,
+			PrcType:     string(params.PrcType),
+			MimixStatus: string(params.MimixStatus),
+			Description: params.Description,
+		},
+		Items: []CoveragePreviewItem{},
+	}
+	for _, obj := range objs {
+		o := engineObject(obj.ObjKind, obj.Obj, obj.Lib, obj.ObjType)
+		if !proposed.Matches(o) {
+			continue
+		}
+		preview.Matched++
+
+		item := CoveragePreviewItem{
+			Obj:           toMimixObj(obj),
+			CurrentStatus: string(obj.MimixStatus),
+			CurrentRuleID: NullUUIDToPtr(obj.RuleID),
+			NewStatus:     string(obj.MimixStatus),
+		}
+		winner, _ := coverage.Winner(withProposed, o)
+		switch {
+		case !ruleGoverned(obj):
+			item.Reason = "status was set by hand"
+		case winner.ID != uuid.Nil:
+			item.Reason = "a more specific rule decides the status"
+		case database.MimixStatus(winner.Status) == obj.MimixStatus:
+			item.NewStatus = winner.Status
+			item.WillChange = obj.RuleID.Valid
+			item.Reason = "status stays the same, the new rule explains it"
+		default:
+			item.NewStatus = winner.Status
+			item.WillChange = true
+			item.Reason = "the new rule decides the status"
+		}
+		if item.WillChange {
+			preview.Changes++
+		}
+		preview.Items = append(preview.Items, item)
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
+// ExplainObjCoverage says which rule, if any, explains an obj's status and
+// lists every rule that matches it, most specific first.
+func (cfg *apiConfig) ExplainObjCoverage(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	obj, err := cfg.dbQueries.GetObjByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "mimix object not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("error getting mimix object: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get mimix object"})
+		return
+	}
+	if !cfg.requireLib(c, user, obj.Lib, database.LibPermissionRead, "mimix object not found") {
+		return
+	}
+
+	rules, byID, err := loadCoverageRules(ctx, cfg.dbQueries)
+	if err != nil {
+		log.Printf("error listing coverage rules: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get coverage rules"})
+		return
+	}
+
+	explanation := CoverageExplanation{
+		Obj:           toMimixObj(obj),
+		MatchingRules: []CoverageRule{},
+	}
+	matched := coverage.Evaluate(rules, engineObject(obj.ObjKind, obj.Obj, obj.Lib, obj.ObjType))
+	for _, r := range matched {
+		explanation.MatchingRules = append(explanation.MatchingRules, toCoverageRule(byID[r.ID]))
+	}
+	if len(matched) > 0 {
+		explanation.Winner = &explanation.MatchingRules[0]
+	}
+
+	switch {
+	case obj.RuleID.Valid:
+		rule := toCoverageRule(byID[obj.RuleID.UUID])
+		explanation.Source = "rule"
+		explanation.Rule = &rule
+		explanation.Note = "status set by rule " + displayRule(rule)
+	case obj.MimixStatus == database.MimixStatusUnset:
+		explanation.Source = "unset"
+		explanation.Note = "no rule matches and no status has been set"
+	default:
+		explanation.Source = "manual"
+		explanation.Note = "status set by hand"
+		if explanation.Winner != nil && explanation.Winner.MimixStatus != string(obj.MimixStatus) {
+			explanation.Note += "; rule " + displayRule(*explanation.Winner) + " would give " + explanation.Winner.MimixStatus
+		}
+	}
+
+	c.JSON(http.StatusOK, explanation)
+}
+
+// displayRule formats a rule like a data group entry, e.g. PAYLIB/PAY* *ALL.
+func displayRule(r CoverageRule) string {
+	name := r.ObjPattern
+	if r.ObjKind == string(database.ObjKindLib) {
+		name = r.LibPattern + "/" + r.ObjPattern
+	}
+	return name + " " + r.ObjType + " (" + r.PrcType + ")"
+}
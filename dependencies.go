@@ -0,0 +1,582 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/paul39-33/imimix/internal/database"
+	"github.com/paul39-33/imimix/internal/ibmi"
+)
+
+const (
+	// maxDependencyDepth bounds a transitive traversal.
+	maxDependencyDepth = 20
+	// maxDependencyNodes bounds the size of a returned graph.
+	maxDependencyNodes = 500
+	// maxDependencyImportBytes bounds an uploaded outfile.
+	maxDependencyImportBytes = 10 << 20
+)
+
+type ObjDependency struct {
+	ID        uuid.UUID `json:"id"`
+	Lib       string    `json:"lib"`
+	Obj       string    `json:"obj"`
+	ObjType   string    `json:"obj_type"`
+	DepLib    string    `json:"dep_lib"`
+	DepObj    string    `json:"dep_obj"`
+	DepType   string    `json:"dep_type"`
+	Source    string    `json:"source"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ObjDependencyInput struct {
+	DepLib  string `json:"dep_lib" binding:"required"`
+	DepObj  string `json:"dep_obj" binding:"required"`
+	DepType string `json:"dep_type" binding:"required"`
+}
+
+// DependencyNode is an object reached while walking the graph. Via is the
+// object it was reached from.
+type DependencyNode struct {
+	Lib          string     `json:"lib"`
+	Obj          string     `json:"obj"`
+	ObjType      string     `json:"obj_type"`
+	Display      string     `json:"display"`
+	Depth        int        `json:"depth"`
+	Via          string     `json:"via"`
+	DependencyID uuid.UUID  `json:"dependency_id"`
+	Source       string     `json:"source"`
+	Registered   bool       `json:"registered"`
+	ObjID        *uuid.UUID `json:"obj_id,omitempty"`
+	MimixStatus  string     `json:"mimix_status,omitempty"`
+}
+
+type DependencyGraph struct {
+	Obj          MimixObj         `json:"obj"`
+	Direction    string           `json:"direction"`
+	Nodes        []DependencyNode `json:"nodes"`
+	Unregistered int              `json:"unregistered"`
+	Truncated    bool             `json:"truncated"`
+}
+
+// DependencyWarning is a dependency of a requested obj that is not
+// registered yet.
+type DependencyWarning struct {
+	Lib     string `json:"lib"`
+	Obj     string `json:"obj"`
+	ObjType string `json:"obj_type"`
+	Display string `json:"display"`
+	Reason  string `json:"reason"`
+}
+
+// DependencyImportResult reports an import. References of objects in
+// libraries the caller may not request in are left out and their libraries
+// listed in ForbiddenLibs.
+type DependencyImportResult struct {
+	Format        string           `json:"format"`
+	References    int              `json:"references"`
+	Imported      int64            `json:"imported"`
+	Duplicates    int64            `json:"duplicates"`
+	Forbidden     int64            `json:"forbidden"`
+	ForbiddenLibs []string         `json:"forbidden_libs"`
+	Skipped       []importRowError `json:"skipped"`
+}
+
+// importRowError is an outfile row that was skipped.
+type importRowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// objRef names a library object in the dependency graph.
+type objRef struct {
+	lib     string
+	obj     string
+	objType string
+}
+
+func (r objRef) display() string {
+	return displayName(database.ObjKindLib, r.obj, r.lib, false) + " " + r.objType
+}
+
+const (
+	dependencyUses   = "uses"
+	dependencyUsedBy = "used_by"
+)
+
+func toObjDependency(d database.ObjectDependency) ObjDependency {
+	return ObjDependency{
+		ID:        d.ID,
+		Lib:       d.Lib,
+		Obj:       d.Obj,
+		ObjType:   d.ObjType,
+		DepLib:    d.DepLib,
+		DepObj:    d.DepObj,
+		DepType:   d.DepType,
+		Source:    string(d.Source),
+		CreatedBy: d.CreatedBy,
+		CreatedAt: d.CreatedAt,
+	}
+}
+
+// storedName puts a name returned by the ibmi package in the case the
+// registry stores it.
+func storedName(name string) string {
+	n, err := normalizeObjName("name", name)
+	if err != nil {
+		return name
+	}
+	return n
+}
+
+// registeredObj looks up the registered obj for a reference. An obj with the
+// same name and lib but another type does not count.
+func registeredObj(ctx context.Context, q *database.Queries, ref objRef) (database.MimixObj, bool, error) {
+	obj, err := q.GetObjByNameAndLib(ctx, database.GetObjByNameAndLibParams{Obj: ref.obj, Lib: ref.lib})
+	if errors.Is(err, sql.ErrNoRows) {
+		return obj, false, nil
+	}
+	if err != nil {
+		return obj, false, err
+	}
+	return obj, obj.ObjType == ref.objType, nil
+}
+
+// isRegistered reports whether a status means the obj needs no more work
+// before its dependents are registered.
+func isRegistered(status database.MimixStatus) bool {
+	return status == database.MimixStatusDone || status == database.MimixStatusTidakperludaftar
+}
+
+// walkDependencies walks the graph breadth first from root, following what
+// objects use (dependencyUses) or what uses them (dependencyUsedBy), up to
+// maxDepth levels. Every object is visited once, so cycles end the walk.
+// Objects in libraries access cannot read are neither listed nor walked
+// through.
+func walkDependencies(ctx context.Context, q *database.Queries, access libAccess, root objRef, direction string, maxDepth int) (nodes []DependencyNode, truncated bool, err error) {
+	visited := map[objRef]bool{root: true}
+	level := []objRef{root}
+
+	for depth := 1; depth <= maxDepth && len(level) > 0; depth++ {
+		var next []objRef
+		for _, from := range level {
+			var edges []database.ObjectDependency
+			if direction == dependencyUsedBy {
+				edges, err = q.ListObjDependents(ctx, database.ListObjDependentsParams{DepLib: from.lib, DepObj: from.obj, DepType: from.objType})
+			} else {
+				edges, err = q.ListObjDependencies(ctx, database.ListObjDependenciesParams{Lib: from.lib, Obj: from.obj, ObjType: from.objType})
+			}
+			if err != nil {
+				return nil, false, err
+			}
+
+			for _, edge := range edges {
+				to := objRef{lib: edge.DepLib, obj: edge.DepObj, objType: edge.DepType}
+				if direction == dependencyUsedBy {
+					to = objRef{lib: edge.Lib, obj: edge.Obj, objType: edge.ObjType}
+				}
+				if visited[to] {
+					continue
+				}
+				if !access.can(to.lib, database.LibPermissionRead) {
+					visited[to] = true
+					continue
+				}
+				if len(nodes) == maxDependencyNodes {
+					return nodes, true, nil
+				}
+				visited[to] = true
+
+				node := DependencyNode{
+					Lib:          to.lib,
+					Obj:          to.obj,
+					ObjType:      to.objType,
+					Display:      to.display(),
+					Depth:        depth,
+					Via:          from.display(),
+					DependencyID: edge.ID,
+					Source:       string(edge.Source),
+				}
+				obj, found, err := registeredObj(ctx, q, to)
+				if err != nil {
+					return nil, false, err
+				}
+				if found {
+					node.ObjID = &obj.ID
+					node.MimixStatus = string(obj.MimixStatus)
+					node.Registered = isRegistered(obj.MimixStatus)
+				}
+				nodes = append(nodes, node)
+				next = append(next, to)
+			}
+		}
+		level = next
+	}
+	return nodes, false, nil
+}
+
+// dependencyWarnings lists everything a library object transitively uses
+// that is not registered yet. The graph is walked in one recursive query,
+// as it runs on every request create and update; like walkDependencies it
+// does not walk through libraries access cannot read.
+func dependencyWarnings(ctx context.Context, q *database.Queries, access libAccess, kind database.ObjKind, ref objRef) ([]DependencyWarning, error) {
+	if kind != database.ObjKindLib {
+		return nil, nil
+	}
+
+	rows, err := q.ListDependencyClosure(ctx, database.ListDependencyClosureParams{
+		Lib:        ref.lib,
+		Obj:        ref.obj,
+		ObjType:    ref.objType,
+		HiddenLibs: access.hiddenLibs(),
+		MaxDepth:   maxDependencyDepth,
+		MaxNodes:   maxDependencyNodes,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []DependencyWarning
+	for _, row := range rows {
+		if row.MimixStatus.Valid && isRegistered(row.MimixStatus.MimixStatus) {
+			continue
+		}
+		reason := "not registered"
+		if row.MimixStatus.Valid {
+			reason = "not registered yet (status " + string(row.MimixStatus.MimixStatus) + ")"
+		}
+		to := objRef{lib: row.Lib, obj: row.Obj, objType: row.ObjType}
+		warnings = append(warnings, DependencyWarning{
+			Lib:     to.lib,
+			Obj:     to.obj,
+			ObjType: to.objType,
+			Display: to.display(),
+			Reason:  reason,
+		})
+	}
+	return warnings, nil
+}
+
+// requestWarnings is dependencyWarnings for a request response. A failure is
+// logged and leaves the request untouched.
+func (cfg *apiConfig) requestWarnings(ctx context.Context, access libAccess, kind database.ObjKind, ref objRef) []DependencyWarning {
+	warnings, err := dependencyWarnings(ctx, cfg.dbQueries, access, kind, ref)
+	if err != nil {
+		log.Printf("error checking dependencies of %s: %v", ref.display(), err)
+		return nil
+	}
+	return warnings
+}
+
+// getDependencyObj loads the obj named by :id for the dependency handlers.
+func (cfg *apiConfig) getDependencyObj(c *gin.Context) (database.MimixObj, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return database.MimixObj{}, false
+	}
+
+	obj, err := cfg.dbQueries.GetObjByID(c.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "mimix object not found"})
+		return obj, false
+	}
+	if err != nil {
+		log.Printf("error getting mimix object: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get mimix object"})
+		return obj, false
+	}
+	if !cfg.requireLib(c, contextUser(c), obj.Lib, database.LibPermissionRead, "mimix object not found") {
+		return obj, false
+	}
+	return obj, true
+}
+
+// GetObjDependencies walks the dependency graph of an obj. ?direction=uses
+// (default) follows what the obj uses, ?direction=used_by what uses it.
+// ?depth limits the number of levels.
+func (cfg *apiConfig) GetObjDependencies(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c)
+	if !ok {
+		return
+	}
+
+	direction := c.DefaultQuery("direction", dependencyUses)
+	if direction != dependencyUses && direction != dependencyUsedBy {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "direction must be uses or used_by"})
+		return
+	}
+
+	depth := maxDependencyDepth
+	if val := c.Query("depth"); val != "" {
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid depth"})
+			return
+		}
+		depth = min(n, maxDependencyDepth)
+	}
+
+	obj, ok := cfg.getDependencyObj(c)
+	if !ok {
+		return
+	}
+	access, ok := cfg.libAccessFor(c, user)
+	if !ok {
+		return
+	}
+
+	graph := DependencyGraph{
+		Obj:       toMimixObj(obj),
+		Direction: direction,
+		Nodes:     []DependencyNode{},
+	}
+	if obj.ObjKind == database.ObjKindLib {
+		root := objRef{lib: obj.Lib, obj: obj.Obj, objType: obj.ObjType}
+		nodes, truncated, err := walkDependencies(c.Request.Context(), cfg.dbQueries, access, root, direction, depth)
+		if err != nil {
+			log.Printf("error walking dependencies: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get dependencies"})
+			return
+		}
+		graph.Truncated = truncated
+		if nodes != nil {
+			graph.Nodes = nodes
+		}
+	}
+	for _, node := range graph.Nodes {
+		if !node.Registered {
+			graph.Unregistered++
+		}
+	}
+
+	c.JSON(http.StatusOK, graph)
+}
+
+// AddObjDependency records by hand that an obj uses another object.
+func (cfg *apiConfig) AddObjDependency(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permDependencyEdit)
+	if !ok {
+		return
+	}
+
+	obj, ok := cfg.getDependencyObj(c)
+	if !ok {
+		return
+	}
+	if !cfg.requireLib(c, user, obj.Lib, database.LibPermissionRequest, "") {
+		return
+	}
+	if obj.ObjKind != database.ObjKindLib {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dependencies are tracked for library objects only"})
+		return
+	}
+
+	var input ObjDependencyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		log.Printf("error binding json: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid parameters"})
+		return
+	}
+
+	dep := objRef{}
+	var err error
+	if dep.lib, err = normalizeObjName("dep_lib", input.DepLib); err == nil {
+		if dep.obj, err = normalizeObjName("dep_obj", input.DepObj); err == nil {
+			dep.objType, err = normalizeObjType(input.DepType)
+		}
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if t, _ := ibmi.LookupType(dep.objType); t.Kind != ibmi.KindLib {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dep_type: " + dep.objType + " is not a lib object type"})
+		return
+	}
+	if dep == (objRef{lib: obj.Lib, obj: obj.Obj, objType: obj.ObjType}) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "an object cannot depend on itself"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	exists, err := cfg.dbQueries.CheckObjDependencyExists(ctx, database.CheckObjDependencyExistsParams{
+		Lib:     obj.Lib,
+		Obj:     obj.Obj,
+		ObjType: obj.ObjType,
+		DepLib:  dep.lib,
+		DepObj:  dep.obj,
+		DepType: dep.objType,
+	})
+	if err != nil {
+		log.Printf("error checking dependency: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not add dependency"})
+		return
+	}
+	if exists {
+		c.JSON(http.StatusConflict, gin.H{"error": "dependency already exists"})
+		return
+	}
+
+	row, err := cfg.dbQueries.CreateObjDependency(ctx, database.CreateObjDependencyParams{
+		Lib:       obj.Lib,
+		Obj:       obj.Obj,
+		ObjType:   obj.ObjType,
+		DepLib:    dep.lib,
+		DepObj:    dep.obj,
+		DepType:   dep.objType,
+		Source:    database.DepSourceManual,
+		CreatedBy: user.Username,
+	})
+	if err != nil {
+		log.Printf("error creating dependency: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not add dependency"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toObjDependency(row))
+}
+
+// DeleteObjDependency removes a dependency, whether added by hand or imported.
+func (cfg *apiConfig) DeleteObjDependency(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permDependencyEdit)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	dep, err := cfg.dbQueries.GetObjDependencyByID(c.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "dependency not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("error getting dependency: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not delete dependency"})
+		return
+	}
+	if !cfg.requireLib(c, user, dep.Lib, database.LibPermissionRequest, "dependency not found") {
+		return
+	}
+
+	rows, err := cfg.dbQueries.DeleteObjDependency(c.Request.Context(), id)
+	if err != nil {
+		log.Printf("error deleting dependency: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not delete dependency"})
+		return
+	}
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "dependency not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "dependency deleted"})
+}
+
+// ImportDependencies loads a DSPPGMREF (?format=dsppgmref) or DSPDBR
+// (?format=dspdbr) outfile sent as CSV in the request body. Dependencies that
+// are already known are left as they are; rows that cannot be read are
+// reported and skipped.
+func (cfg *apiConfig) ImportDependencies(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permDependencyImport)
+	if !ok {
+		return
+	}
+
+	var (
+		parse  func(r io.Reader) ([]ibmi.Reference, []ibmi.RowError, error)
+		source database.DepSource
+	)
+	format := c.Query("format")
+	switch format {
+	case "dsppgmref":
+		parse, source = ibmi.ParsePgmRef, database.DepSourceDsppgmref
+	case "dspdbr":
+		parse, source = ibmi.ParseDBRelation, database.DepSourceDspdbr
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be dsppgmref or dspdbr"})
+		return
+	}
+
+	refs, rowErrs, err := parse(http.MaxBytesReader(c.Writer, c.Request.Body, maxDependencyImportBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "outfile is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid outfile: " + err.Error()})
+		return
+	}
+
+	access, ok := cfg.libAccessFor(c, user)
+	if !ok {
+		return
+	}
+
+	result := DependencyImportResult{
+		Format:        format,
+		References:    len(refs),
+		ForbiddenLibs: []string{},
+		Skipped:       []importRowError{},
+	}
+	for _, rowErr := range rowErrs {
+		result.Skipped = append(result.Skipped, importRowError{Line: rowErr.Line, Error: rowErr.Err.Error()})
+	}
+
+	ctx := c.Request.Context()
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		for _, ref := range refs {
+			if ref.Lib == ref.RefLib && ref.Obj == ref.RefObj && ref.Type == ref.RefType {
+				continue
+			}
+			lib := storedName(ref.Lib)
+			if !access.can(lib, database.LibPermissionRequest) {
+				if !slices.Contains(result.ForbiddenLibs, lib) {
+					result.ForbiddenLibs = append(result.ForbiddenLibs, lib)
+				}
+				result.Forbidden++
+				continue
+			}
+			n, err := q.ImportObjDependency(ctx, database.ImportObjDependencyParams{
+				Lib:       lib,
+				Obj:       storedName(ref.Obj),
+				ObjType:   ref.Type,
+				DepLib:    storedName(ref.RefLib),
+				DepObj:    storedName(ref.RefObj),
+				DepType:   ref.RefType,
+				Source:    source,
+				CreatedBy: user.Username,
+			})
+			if err != nil {
+				return err
+			}
+			result.Imported += n
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("error importing dependencies: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not import dependencies"})
+		return
+	}
+	result.Duplicates = int64(len(refs)) - result.Imported - result.Forbidden
+	slices.Sort(result.ForbiddenLibs)
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,423 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/paul39-33/imimix/internal/database"
+	"github.com/paul39-33/imimix/internal/webhook"
+)
+
+// maxDeploymentEventBytes bounds a webhook payload.
+const maxDeploymentEventBytes = 1 << 20
+
+// deploymentActor is recorded in the promotion history for deployments the
+// change-management tool reported.
+const deploymentActor = "deployment webhook"
+
+var (
+	errEventReviewed = errors.New("deployment event was already reviewed")
+	errReqNotPending = errors.New("obj request is not pending")
+	errEventNotFound = errors.New("deployment event not found")
+	errReqNotFound   = errors.New("obj request not found")
+	errReqForbidden  = errors.New("forbidden: manage permission on the lib required")
+)
+
+// DeploymentEventInput is the payload the change-management tool sends when
+// it deploys an object. EventID identifies the delivery so retries are only
+// applied once.
+type DeploymentEventInput struct {
+	EventID   string    `json:"event_id"`
+	ObjKind   string    `json:"obj_kind"`
+	Lib       string    `json:"lib"`
+	Object    string    `json:"object"`
+	Type      string    `json:"type"`
+	Version   string    `json:"version"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type DeploymentEvent struct {
+	ID         uuid.UUID  `json:"id"`
+	EventID    string     `json:"event_id"`
+	ObjKind    string     `json:"obj_kind"`
+	Lib        string     `json:"lib"`
+	Obj        string     `json:"obj"`
+	ObjType    string     `json:"obj_type"`
+	ObjVer     string     `json:"obj_ver"`
+	DeployedAt time.Time  `json:"deployed_at"`
+	Status     string     `json:"status"`
+	Reason     string     `json:"reason,omitempty"`
+	ObjReqID   *uuid.UUID `json:"obj_req_id,omitempty"`
+	ReceivedAt time.Time  `json:"received_at"`
+	ResolvedBy string     `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+type ResolveDeploymentInput struct {
+	ObjReqID uuid.UUID `json:"obj_req_id" binding:"required"`
+}
+
+func toDeploymentEvent(e database.DeploymentEvent) DeploymentEvent {
+	return DeploymentEvent{
+		ID:         e.ID,
+		EventID:    e.EventID,
+		ObjKind:    string(e.ObjKind),
+		Lib:        e.Lib,
+		Obj:        e.Obj,
+		ObjType:    e.ObjType,
+		ObjVer:     e.ObjVer,
+		DeployedAt: e.DeployedAt,
+		Status:     string(e.Status),
+		Reason:     e.Reason,
+		ObjReqID:   NullUUIDToPtr(e.ObjReqID),
+		ReceivedAt: e.ReceivedAt,
+		ResolvedBy: NullStringToString(e.ResolvedBy),
+		ResolvedAt: NullTimeToPtr(e.ResolvedAt),
+	}
+}
+
+// applyDeployment marks a request deployed at objVer and moves it to the
+// production stage, recording the move in the promotion history. The source
+// obj, if any, is flagged for MIMIX registration.
+func applyDeployment(ctx context.Context, q *database.Queries, req database.MimixObjReq, objVer, actor, note string) (database.MimixObjReq, error) {
+	stageID := req.StageID
+	prod, err := q.GetProductionStage(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return req, err
+	}
+	hasProd := err == nil
+	if hasProd {
+		stageID = uuid.NullUUID{UUID: prod.ID, Valid: true}
+	}
+
+	updated, err := q.ApplyObjReqDeployment(ctx, database.ApplyObjReqDeploymentParams{
+		ID:      req.ID,
+		ObjVer:  objVer,
+		StageID: stageID,
+	})
+	if err != nil {
+		return req, err
+	}
+
+	if hasProd && req.StageID != stageID {
+		if _, err := q.CreatePromotion(ctx, database.CreatePromotionParams{
+			ObjReqID:    uuid.NullUUID{UUID: req.ID, Valid: true},
+			FromStageID: req.StageID,
+			ToStageID:   prod.ID,
+			Actor:       actor,
+			Note:        note,
+		}); err != nil {
+			return req, err
+		}
+	}
+
+	if req.SourceObjID.Valid {
+		if _, err := flagForRegistration(ctx, q, req.SourceObjID.UUID); err != nil {
+			return req, err
+		}
+	}
+	return updated, nil
+}
+
+// normalizeDeploymentEvent checks the required fields and puts the object in
+// stored form.
+func normalizeDeploymentEvent(in DeploymentEventInput) (objIdentity, error) {
+	switch {
+	case strings.TrimSpace(in.EventID) == "":
+		return objIdentity{}, errors.New("event_id is required")
+	case strings.TrimSpace(in.Version) == "":
+		return objIdentity{}, errors.New("version is required")
+	case in.Timestamp.IsZero():
+		return objIdentity{}, errors.New("timestamp is required")
+	}
+
+	kind, err := parseObjKind(in.ObjKind)
+	if err != nil {
+		return objIdentity{}, err
+	}
+	id := objIdentity{Kind: kind, Name: in.Object, Lib: in.Lib, ObjType: in.Type}
+	if err := id.normalize("object"); err != nil {
+		return objIdentity{}, err
+	}
+	return id, nil
+}
+
+// ReceiveDeploymentEvent is the inbound webhook of the change-management
+// tool. The body must be signed with DEPLOY_WEBHOOK_SECRET. An event that
+// matches exactly one pending obj request marks it deployed; anything else is
+// queued for review.
+func (cfg *apiConfig) ReceiveDeploymentEvent(c *gin.Context) {
+	if cfg.webhookSecret == "" {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "deployment webhook is not configured"})
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxDeploymentEventBytes))
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "payload is too large"})
+		return
+	}
+	if err := webhook.Verify([]byte(cfg.webhookSecret), body, c.GetHeader(webhook.SignatureHeader)); err != nil {
+		log.Printf("rejected deployment event: %v", err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var input DeploymentEventInput
+	if err := json.Unmarshal(body, &input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	id, err := normalizeDeploymentEvent(input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.EventID = strings.TrimSpace(input.EventID)
+	input.Version = strings.TrimSpace(input.Version)
+
+	ctx := c.Request.Context()
+	existing, err := cfg.dbQueries.GetDeploymentEventByEventID(ctx, input.EventID)
+	if err == nil {
+		c.JSON(http.StatusOK, gin.H{"status": "duplicate", "event": toDeploymentEvent(existing)})
+		return
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("error getting deployment event: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not record deployment event"})
+		return
+	}
+
+	var event database.DeploymentEvent
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		params := database.CreateDeploymentEventParams{
+			EventID:    input.EventID,
+			ObjKind:    id.Kind,
+			Lib:        id.Lib,
+			Obj:        id.Name,
+			ObjType:    id.ObjType,
+			ObjVer:     input.Version,
+			DeployedAt: input.Timestamp.UTC(),
+			Payload:    string(body),
+		}
+
+		reqs, err := q.ListPendingObjReqForDeployment(ctx, database.ListPendingObjReqForDeploymentParams{
+			ObjKind: id.Kind,
+			ObjName: id.Name,
+			Lib:     id.Lib,
+			ObjType: id.ObjType,
+		})
+		if err != nil {
+			return err
+		}
+
+		switch len(reqs) {
+		case 0:
+			params.Status = database.DeploymentEventStatusUnmatched
+			params.Reason = "no pending obj request for this object"
+		case 1:
+			if _, err := applyDeployment(ctx, q, reqs[0], input.Version, deploymentActor, "deployment event "+input.EventID); err != nil {
+				return err
+			}
+			params.Status = database.DeploymentEventStatusMatched
+			params.ObjReqID = uuid.NullUUID{UUID: reqs[0].ID, Valid: true}
+		default:
+			params.Status = database.DeploymentEventStatusAmbiguous
+			params.Reason = "more than one pending obj request for this object"
+		}
+
+		event, err = q.CreateDeploymentEvent(ctx, params)
+		return err
+	})
+	if err != nil {
+		log.Printf("error recording deployment event: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not record deployment event"})
+		return
+	}
+
+	if event.Status != database.DeploymentEventStatusMatched {
+		c.JSON(http.StatusAccepted, gin.H{"status": "queued_for_review", "event": toDeploymentEvent(event)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "matched", "event": toDeploymentEvent(event)})
+}
+
+// ListDeploymentReview returns the deployment events that matched no single
+// pending request, oldest first.
+func (cfg *apiConfig) ListDeploymentReview(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permDeploymentReview)
+	if !ok {
+		return
+	}
+	access, ok := cfg.libAccessFor(c, user)
+	if !ok {
+		return
+	}
+
+	rows, err := cfg.dbQueries.ListDeploymentEventsForReview(c.Request.Context())
+	if err != nil {
+		log.Printf("error listing deployment events: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get deployment events"})
+		return
+	}
+
+	events := make([]DeploymentEvent, 0, len(rows))
+	for _, row := range rows {
+		if access.can(row.Lib, database.LibPermissionRead) {
+			events = append(events, toDeploymentEvent(row))
+		}
+	}
+	c.JSON(http.StatusOK, events)
+}
+
+// getReviewEvent loads a deployment event that is still waiting for review.
+// Events for libraries access cannot read are not found.
+func getReviewEvent(ctx context.Context, q *database.Queries, access libAccess, id uuid.UUID) (database.DeploymentEvent, error) {
+	event, err := q.GetDeploymentEventByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) || err == nil && !access.can(event.Lib, database.LibPermissionRead) {
+		return event, errEventNotFound
+	}
+	if err != nil {
+		return event, err
+	}
+	if event.Status != database.DeploymentEventStatusUnmatched && event.Status != database.DeploymentEventStatusAmbiguous {
+		return event, errEventReviewed
+	}
+	return event, nil
+}
+
+func respondReviewError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errEventNotFound), errors.Is(err, errReqNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, errEventReviewed), errors.Is(err, errReqNotPending):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, errReqForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		log.Printf("error reviewing deployment event: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not review deployment event"})
+	}
+}
+
+// ResolveDeploymentEvent applies a queued event to the obj request a
+// reviewer picked.
+func (cfg *apiConfig) ResolveDeploymentEvent(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permDeploymentReview)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event id"})
+		return
+	}
+
+	var input ResolveDeploymentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		log.Printf("error binding json: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid parameters"})
+		return
+	}
+
+	access, ok := cfg.libAccessFor(c, user)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	var (
+		event database.DeploymentEvent
+		req   database.MimixObjReq
+	)
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		queued, err := getReviewEvent(ctx, q, access, id)
+		if err != nil {
+			return err
+		}
+
+		current, err := q.GetMimixObjReqByID(ctx, input.ObjReqID)
+		if errors.Is(err, sql.ErrNoRows) || err == nil && !access.can(current.Lib, database.LibPermissionRead) {
+			return errReqNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if !access.can(current.Lib, database.LibPermissionManage) {
+			return errReqForbidden
+		}
+		if current.ReqStatus != database.ReqStatusPending {
+			return errReqNotPending
+		}
+
+		if req, err = applyDeployment(ctx, q, current, queued.ObjVer, user.Username, "deployment event "+queued.EventID); err != nil {
+			return err
+		}
+		event, err = q.ResolveDeploymentEvent(ctx, database.ResolveDeploymentEventParams{
+			ID:         id,
+			Status:     database.DeploymentEventStatusResolved,
+			ObjReqID:   uuid.NullUUID{UUID: req.ID, Valid: true},
+			ResolvedBy: sql.NullString{String: user.Username, Valid: true},
+		})
+		return err
+	})
+	if err != nil {
+		respondReviewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"event":   toDeploymentEvent(event),
+		"obj_req": toMimixObjReq(req),
+	})
+}
+
+// DismissDeploymentEvent removes a queued event from review without applying
+// it, e.g. for deployments the registry does not track.
+func (cfg *apiConfig) DismissDeploymentEvent(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permDeploymentReview)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event id"})
+		return
+	}
+
+	access, ok := cfg.libAccessFor(c, user)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	var event database.DeploymentEvent
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		if _, err := getReviewEvent(ctx, q, access, id); err != nil {
+			return err
+		}
+		event, err = q.ResolveDeploymentEvent(ctx, database.ResolveDeploymentEventParams{
+			ID:         id,
+			Status:     database.DeploymentEventStatusDismissed,
+			ResolvedBy: sql.NullString{String: user.Username, Valid: true},
+		})
+		return err
+	})
+	if err != nil {
+		respondReviewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toDeploymentEvent(event))
+}
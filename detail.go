@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/paul39-33/imimix/internal/database"
+)
+
+// conversion outcomes of a pending request
+const (
+	conversionUpdates  = "updates_existing"
+	conversionCreates  = "creates_new"
+	conversionConflict = "conflict"
+)
+
+// ObjDetail is an obj with every request that references it, open and
+// closed: the ones raised from it and the ones converted into it.
+type ObjDetail struct {
+	MimixObj
+	Requests []MimixObjReq `json:"requests"`
+}
+
+// ObjReqDetail is an obj request with, while it is pending, a preview of
+// what converting it would do.
+type ObjReqDetail struct {
+	MimixObjReq
+	Conversion *ConversionPreview `json:"conversion,omitempty"`
+}
+
+// ConversionPreview compares a pending request with the obj of the same lib
+// and name. Outcome mirrors convertObjReq: a request raised from an obj
+// updates it, any other request creates a new obj unless one of the same
+// name already exists.
+type ConversionPreview struct {
+	Outcome     string        `json:"outcome"`
+	ExistingObj *MimixObj     `json:"existing_obj"`
+	Changes     []FieldChange `json:"changes"`
+}
+
+// objReqChanges lists the fields a request would change on obj.
+func objReqChanges(obj MimixObj, req MimixObjReq) []FieldChange {
+	changes := []FieldChange{}
+	for _, f := range []struct {
+		field    string
+		from, to string
+	}{
+		{"obj", obj.Obj, req.ObjName},
+		{"lib", obj.Lib, req.Lib},
+		{"obj_type", obj.ObjType, req.ObjType},
+		{"obj_ver", obj.ObjVer, req.ObjVer},
+		{"developer", obj.Developer, req.Developer},
+		{"obj_kind", obj.ObjKind, req.ObjKind},
+		{"prc_type", obj.PrcType, req.PrcType},
+	} {
+		if f.from != f.to {
+			changes = append(changes, FieldChange{Field: f.field, From: f.from, To: f.to})
+		}
+	}
+	if !obj.PromoteDate.Equal(req.PromoteDate) {
+		changes = append(changes, FieldChange{Field: "promote_date", From: obj.PromoteDate, To: req.PromoteDate})
+	}
+	if obj.Subtree != req.Subtree {
+		changes = append(changes, FieldChange{Field: "subtree", From: obj.Subtree, To: req.Subtree})
+	}
+	return changes
+}
+
+// objDetail loads the requests linked to obj, leaving out those in
+// libraries access cannot read: a request may have moved to another library
+// since it was raised from obj.
+func objDetail(ctx context.Context, q *database.Queries, access libAccess, obj database.MimixObj) (ObjDetail, error) {
+	rows, err := q.ListObjLinkedRequests(ctx, obj.ID)
+	if err != nil {
+		return ObjDetail{}, err
+	}
+	rows = access.readableObjReqs(rows)
+
+	detail := ObjDetail{MimixObj: toMimixObj(obj), Requests: make([]MimixObjReq, 0, len(rows))}
+	for _, row := range rows {
+		detail.Requests = append(detail.Requests, toMimixObjReq(row))
+	}
+	return detail, nil
+}
+
+// previewConversion finds the obj a pending request would be converted
+// against: its source obj when it still exists, otherwise the obj with the
+// same lib and name.
+func previewConversion(ctx context.Context, q *database.Queries, req database.MimixObjReq) (*ConversionPreview, error) {
+	if req.SourceObjID.Valid {
+		obj, err := q.GetObjByID(ctx, req.SourceObjID.UUID)
+		if err == nil {
+			existing := toMimixObj(obj)
+			return &ConversionPreview{
+				Outcome:     conversionUpdates,
+				ExistingObj: &existing,
+				Changes:     objReqChanges(existing, toMimixObjReq(req)),
+			}, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+	}
+
+	// the new obj is stored in normalised form; a request that does not
+	// normalise fails on conversion anyway, so compare it as it is
+	identity := objIdentity{Kind: req.ObjKind, Name: req.ObjName, Lib: req.Lib, ObjType: req.ObjType, Subtree: req.Subtree}
+	if err := identity.normalize("obj_name"); err == nil {
+		req.ObjName, req.Lib, req.ObjType = identity.Name, identity.Lib, identity.ObjType
+	}
+
+	obj, err := q.GetObjByNameAndLib(ctx, database.GetObjByNameAndLibParams{
+		Obj: req.ObjName,
+		Lib: req.Lib,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return &ConversionPreview{Outcome: conversionCreates, Changes: []FieldChange{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	existing := toMimixObj(obj)
+	return &ConversionPreview{
+		Outcome:     conversionConflict,
+		ExistingObj: &existing,
+		Changes:     objReqChanges(existing, toMimixObjReq(req)),
+	}, nil
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/paul39-33/imimix/internal/database"
+)
+
+const notifyDeveloperReassigned = "developer_reassigned"
+
+var errUnknownDeveloper = errors.New("developer must be an existing user")
+
+// resolveDeveloper checks a developer given as input and returns the name to
+// store, the lowercase username of the account. A blank name, or the one the
+// row already holds, passes unchecked so rows still carrying an unmatched
+// legacy name stay editable.
+func resolveDeveloper(ctx context.Context, q *database.Queries, name, current string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || name == strings.ToLower(strings.TrimSpace(current)) {
+		return name, nil
+	}
+	user, err := q.GetUserByUsername(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", errUnknownDeveloper
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.ToLower(user.Username), nil
+}
+
+// developerError writes the response for a failed resolveDeveloper.
+func developerError(c *gin.Context, err error) {
+	if errors.Is(err, errUnknownDeveloper) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	log.Printf("error getting developer: %v", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get developer"})
+}
+
+// DeveloperMatch is one entry of the report of how the free-text developers
+// found by the migration were matched to accounts.
+type DeveloperMatch struct {
+	Developer  string     `json:"developer"`
+	UserID     *uuid.UUID `json:"user_id,omitempty"`
+	Method     string     `json:"method"`
+	Distance   *int32     `json:"distance,omitempty"`
+	ObjCount   int32      `json:"obj_count"`
+	ReqCount   int32      `json:"req_count"`
+	ResolvedBy string     `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+var allowedDeveloperMatchMethod = map[string]database.DeveloperMatchMethod{
+	"exact":      database.DeveloperMatchMethodExact,
+	"normalized": database.DeveloperMatchMethodNormalized,
+	"fuzzy":      database.DeveloperMatchMethodFuzzy,
+	"manual":     database.DeveloperMatchMethodManual,
+	"unmatched":  database.DeveloperMatchMethodUnmatched,
+}
+
+func toDeveloperMatch(row database.DeveloperMatch) DeveloperMatch {
+	m := DeveloperMatch{
+		Developer:  row.Developer,
+		UserID:     NullUUIDToPtr(row.UserID),
+		Method:     string(row.Method),
+		ObjCount:   row.ObjCount,
+		ReqCount:   row.ReqCount,
+		ResolvedBy: NullStringToString(row.ResolvedBy),
+		ResolvedAt: NullTimeToPtr(row.ResolvedAt),
+	}
+	if row.Distance.Valid {
+		m.Distance = &row.Distance.Int32
+	}
+	return m
+}
+
+// ListDeveloperMatches returns the developer match report. ?method=unmatched
+// narrows it to the names still waiting for review.
+func (cfg *apiConfig) ListDeveloperMatches(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c, permAdminView); !ok {
+		return
+	}
+
+	method := strings.ToLower(strings.TrimSpace(c.Query("method")))
+	if _, ok := allowedDeveloperMatchMethod[method]; method != "" && !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid method"})
+		return
+	}
+
+	rows, err := cfg.dbQueries.ListDeveloperMatches(c.Request.Context(), method)
+	if err != nil {
+		log.Printf("error listing developer matches: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get developer matches"})
+		return
+	}
+
+	matches := make([]DeveloperMatch, 0, len(rows))
+	for _, row := range rows {
+		matches = append(matches, toDeveloperMatch(row))
+	}
+	c.JSON(http.StatusOK, matches)
+}
+
+// ResolveDeveloperMatch links an unmatched legacy developer name to an
+// account, along with every obj and request still carrying it.
+func (cfg *apiConfig) ResolveDeveloperMatch(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permUsersManage)
+	if !ok {
+		return
+	}
+
+	type parameters struct {
+		Developer string `json:"developer"`
+		Username  string `json:"username"`
+	}
+
+	var params parameters
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	params.Developer = strings.ToLower(strings.TrimSpace(params.Developer))
+
+	ctx := c.Request.Context()
+	match, err := cfg.dbQueries.GetDeveloperMatch(ctx, params.Developer)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "developer not found in the match report"})
+		return
+	}
+	if err != nil {
+		log.Printf("error getting developer match: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get developer match"})
+		return
+	}
+	if match.Method != database.DeveloperMatchMethodUnmatched {
+		c.JSON(http.StatusConflict, gin.H{"error": "developer is already matched; reassign its objs instead"})
+		return
+	}
+
+	target, err := cfg.dbQueries.GetUserByUsername(ctx, params.Username)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("error getting user by username: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get user"})
+		return
+	}
+	targetID := uuid.NullUUID{UUID: target.ID, Valid: true}
+
+	var objs, reqs int64
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		rows, err := q.LinkObjDeveloper(ctx, database.LinkObjDeveloperParams{UserID: targetID, Developer: params.Developer})
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if err := recordObjVersion(ctx, q, database.CreateObjectVersionParams{
+				ObjID:       row.ID,
+				ObjVer:      row.ObjVer,
+				PromoteDate: row.PromoteDate,
+				Developer:   row.Developer,
+				Source:      database.ObjectVersionSourceUpdated,
+			}, user.Username); err != nil {
+				return err
+			}
+		}
+		objs = int64(len(rows))
+
+		if reqs, err = q.LinkObjReqDeveloper(ctx, database.LinkObjReqDeveloperParams{UserID: targetID, Developer: params.Developer}); err != nil {
+			return err
+		}
+		return q.ResolveDeveloperMatch(ctx, database.ResolveDeveloperMatchParams{
+			Developer:  params.Developer,
+			UserID:     targetID,
+			ResolvedBy: sql.NullString{String: user.Username, Valid: true},
+		})
+	})
+	if err != nil {
+		log.Printf("error resolving developer match: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not resolve developer match"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "developer matched",
+		"developer": params.Developer,
+		"username":  strings.ToLower(target.Username),
+		"objs":      objs,
+		"requests":  reqs,
+	})
+}
+
+// ReassignDeveloper moves every obj, and every pending request, of a
+// departing developer to another account in one go.
+func (cfg *apiConfig) ReassignDeveloper(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permUsersManage)
+	if !ok {
+		return
+	}
+
+	type parameters struct {
+		To string `json:"to"`
+	}
+
+	var params parameters
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	from, err := cfg.dbQueries.GetUserByUsername(ctx, c.Param("username"))
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("error getting user by username: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get user"})
+		return
+	}
+	to, err := cfg.dbQueries.GetUserByUsername(ctx, params.To)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to: user not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("error getting user by username: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get user"})
+		return
+	}
+	if from.ID == to.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot reassign a developer to themselves"})
+		return
+	}
+
+	var objs, reqs int64
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		rows, err := q.ReassignObjDeveloper(ctx, database.ReassignObjDeveloperParams{
+			ToID:   uuid.NullUUID{UUID: to.ID, Valid: true},
+			FromID: uuid.NullUUID{UUID: from.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if err := recordObjVersion(ctx, q, database.CreateObjectVersionParams{
+				ObjID:       row.ID,
+				ObjVer:      row.ObjVer,
+				PromoteDate: row.PromoteDate,
+				Developer:   row.Developer,
+				Source:      database.ObjectVersionSourceUpdated,
+			}, user.Username); err != nil {
+				return err
+			}
+		}
+		objs = int64(len(rows))
+
+		reqs, err = q.ReassignObjReqDeveloper(ctx, database.ReassignObjReqDeveloperParams{
+			ToID:   uuid.NullUUID{UUID: to.ID, Valid: true},
+			FromID: uuid.NullUUID{UUID: from.ID, Valid: true},
+		})
+		if err != nil || objs+reqs == 0 {
+			return err
+		}
+		_, err = notify(ctx, q, notice{
+			recipient: to.Username,
+			kind:      notifyDeveloperReassigned,
+			message:   fmt.Sprintf("%s reassigned %d objs and %d pending requests from %s to you", user.Username, objs, reqs, from.Username),
+		})
+		return err
+	})
+	if err != nil {
+		log.Printf("error reassigning developer: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not reassign developer"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "developer reassigned",
+		"from":     strings.ToLower(from.Username),
+		"to":       strings.ToLower(to.Username),
+		"objs":     objs,
+		"requests": reqs,
+	})
+}
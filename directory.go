@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/paul39-33/imimix/internal/auth"
+	"github.com/paul39-33/imimix/internal/database"
+)
+
+const defaultLDAPUserFilter = "(&(objectClass=user)(sAMAccountName=%s))"
+
+// errNoMappedRole is returned for a directory user none of whose groups
+// maps to a role.
+var errNoMappedRole = errors.New("no directory group of the user maps to a role")
+
+// errLocalAccount is returned for a directory login matching a local account
+// that has not been linked to the directory.
+var errLocalAccount = errors.New("account is not linked to the directory")
+
+// authenticatorFromEnv picks the login backend from AUTH_BACKEND: "local"
+// (the default) checks the passwords in the users table, "ldap" binds to
+// the directory at LDAP_URL. A plain ldap:// url is upgraded with StartTLS
+// unless LDAP_INSECURE is true. With ldap, accounts that still have a local
+// password, such as the bootstrap lead, keep logging in with it unless
+// LDAP_LOCAL_LOGIN is false; directory users have none.
+func authenticatorFromEnv(q *database.Queries) (auth.Authenticator, error) {
+	local := auth.PasswordStore{HashedPassword: func(ctx context.Context, username string) (string, error) {
+		user, err := q.GetUserByUsername(ctx, username)
+		return user.HashedPassword, err
+	}}
+
+	switch backend := strings.ToLower(strings.TrimSpace(os.Getenv("AUTH_BACKEND"))); backend {
+	case "", "local":
+		return local, nil
+	case "ldap":
+		// anything but a clear yes keeps StartTLS on
+		ldapInsecure, _ := strconv.ParseBool(os.Getenv("LDAP_INSECURE"))
+		filter := os.Getenv("LDAP_USER_FILTER")
+		if filter == "" {
+			filter = defaultLDAPUserFilter
+		}
+		directory, err := auth.NewLDAP(auth.LDAPConfig{
+			URL:            os.Getenv("LDAP_URL"),
+			BindDN:         os.Getenv("LDAP_BIND_DN"),
+			BindPassword:   os.Getenv("LDAP_BIND_PASSWORD"),
+			BaseDN:         os.Getenv("LDAP_BASE_DN"),
+			UserFilter:     filter,
+			GroupAttribute: os.Getenv("LDAP_GROUP_ATTRIBUTE"),
+			Insecure:       ldapInsecure,
+		})
+		if err != nil {
+			return nil, err
+		}
+		// anything but a clear no keeps local logins on
+		if localLogin, err := strconv.ParseBool(os.Getenv("LDAP_LOCAL_LOGIN")); err == nil && !localLogin {
+			return directory, nil
+		}
+		// local passwords are checked first so they work while the
+		// directory is down
+		return auth.Chain{local, directory}, nil
+	default:
+		return nil, fmt.Errorf("unknown AUTH_BACKEND %q", backend)
+	}
+}
+
+// groupRolesFromEnv reads LDAP_GROUP_ROLES, comma separated group=role
+// pairs such as IBMi-DC=dc,IBMi-Audit=auditor. Groups are matched by the
+// common name of their DN, case-insensitively.
+func groupRolesFromEnv() (map[string][]string, error) {
+	mapping := map[string][]string{}
+	for _, pair := range strings.Split(os.Getenv("LDAP_GROUP_ROLES"), ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		group, role, ok := strings.Cut(pair, "=")
+		group = strings.ToLower(strings.TrimSpace(group))
+		role = strings.ToLower(strings.TrimSpace(role))
+		if !ok || group == "" || role == "" {
+			return nil, fmt.Errorf("invalid LDAP_GROUP_ROLES entry %q", pair)
+		}
+		mapping[group] = append(mapping[group], role)
+	}
+	return mapping, nil
+}
+
+// usesLocalPasswords reports whether logins are checked against the users
+// table, which is then where new users sign up.
+func (cfg *apiConfig) usesLocalPasswords() bool {
+	_, ok := cfg.authenticator.(auth.PasswordStore)
+	return ok
+}
+
+// directoryRoles maps the groups of a directory user to roles. Mapped
+// roles that do not exist are skipped.
+func (cfg *apiConfig) directoryRoles(ctx context.Context, groups []string) ([]database.Role, error) {
+	var roles []database.Role
+	seen := map[string]bool{}
+	for _, group := range groups {
+		for _, name := range cfg.groupRoles[strings.ToLower(auth.GroupCN(group))] {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			role, err := cfg.dbQueries.GetRoleByName(ctx, name)
+			if errors.Is(err, sql.ErrNoRows) {
+				log.Printf("LDAP_GROUP_ROLES maps %s to unknown role %q", group, name)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			roles = append(roles, role)
+		}
+	}
+	if len(roles) == 0 {
+		return nil, errNoMappedRole
+	}
+	return roles, nil
+}
+
+// syncDirectoryUser provisions a directory user on first login and, on
+// every login, sets their roles from their groups, keeping the lead role of
+// a lead. The directory holds the password, so none is kept locally. A
+// local account of the same name is only signed in to once an admin has
+// linked it with SetUserDirectory.
+func (cfg *apiConfig) syncDirectoryUser(ctx context.Context, identity auth.Identity) error {
+	roles, err := cfg.directoryRoles(ctx, identity.Groups)
+	if err != nil {
+		return err
+	}
+	job := primaryJob(roles)
+
+	var user database.GetUserByUsernameRow
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		var err error
+		user, err = q.GetUserByUsername(ctx, identity.Username)
+		if errors.Is(err, sql.ErrNoRows) {
+			if _, err := q.CreateUser(ctx, database.CreateUserParams{
+				Username:       identity.Username,
+				HashedPassword: "",
+				Job:            job,
+			}); err != nil {
+				return err
+			}
+			if user, err = q.GetUserByUsername(ctx, identity.Username); err != nil {
+				return err
+			}
+			if err := q.SetUserDirectory(ctx, database.SetUserDirectoryParams{ID: user.ID, Directory: true}); err != nil {
+				return err
+			}
+			log.Printf("provisioned directory user %s", identity.Username)
+			user.Directory = true
+		}
+		if err != nil {
+			return err
+		}
+		if !user.Directory {
+			return errLocalAccount
+		}
+		// leads are appointed in the app, so their groups do not take
+		// the lead role away
+		held, err := q.ListUserRoleNames(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if slices.Contains(held, leadRole) {
+			lead, err := q.GetRoleByName(ctx, leadRole)
+			if err != nil {
+				return err
+			}
+			roles = append(roles, lead)
+		}
+		return assignRoles(ctx, q, user.ID, job, roles)
+	})
+	if err != nil {
+		return err
+	}
+	cfg.permissions.invalidate(user.ID)
+	return nil
+}
+
+// SetUserDirectory links a user to the directory, so a directory login of
+// the same name signs in to it, or unlinks them. Linking drops the local
+// password; an unlinked user needs a new one to log in locally.
+func (cfg *apiConfig) SetUserDirectory(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c, permUsersManage); !ok {
+		return
+	}
+
+	type parameters struct {
+		Directory *bool `json:"directory" binding:"required"`
+	}
+
+	var params parameters
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	target, ok := cfg.getUserParam(c)
+	if !ok {
+		return
+	}
+
+	if err := cfg.dbQueries.SetUserDirectory(c.Request.Context(), database.SetUserDirectoryParams{
+		ID:        target.ID,
+		Directory: *params.Directory,
+	}); err != nil {
+		log.Printf("error setting user directory: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not update user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user updated", "username": target.Username, "directory": *params.Directory})
+}
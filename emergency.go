@@ -0,0 +1,381 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/paul39-33/imimix/internal/database"
+)
+
+// notification kinds
+const (
+	notifyEmergencyJustify = "emergency_justify"
+	notifyEmergencyReview  = "emergency_review"
+	notifyEmergencyOutcome = "emergency_outcome"
+)
+
+// EmergencyReview is the follow-up of an emergency request promoted past
+// stages: the actor justifies the shortcut and someone else reviews it.
+type EmergencyReview struct {
+	ID            uuid.UUID  `json:"id"`
+	ObjReqID      uuid.UUID  `json:"obj_req_id"`
+	PromotionID   uuid.UUID  `json:"promotion_id"`
+	SkippedStages []string   `json:"skipped_stages"`
+	Actor         string     `json:"actor"`
+	Status        string     `json:"status"`
+	Justification string     `json:"justification,omitempty"`
+	JustifiedAt   *time.Time `json:"justified_at,omitempty"`
+	Reviewer      string     `json:"reviewer,omitempty"`
+	ReviewNote    string     `json:"review_note,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+var allowedEmergencyReviewStatus = map[string]database.EmergencyReviewStatus{
+	"awaiting_justification": database.EmergencyReviewStatusAwaitingJustification,
+	"awaiting_review":        database.EmergencyReviewStatusAwaitingReview,
+	"accepted":               database.EmergencyReviewStatusAccepted,
+	"rejected":               database.EmergencyReviewStatusRejected,
+}
+
+// parseReqPriority reads a request priority; empty means normal.
+func parseReqPriority(priority string) (database.ReqPriority, error) {
+	priority = strings.ToLower(strings.TrimSpace(priority))
+	if priority == "" {
+		return database.ReqPriorityNormal, nil
+	}
+	val, ok := allowedReqPriority[priority]
+	if !ok {
+		return "", fieldError{field: "priority", err: errors.New("must be low, normal, high or emergency")}
+	}
+	return val, nil
+}
+
+func toEmergencyReview(r database.EmergencyReview) EmergencyReview {
+	return EmergencyReview{
+		ID:            r.ID,
+		ObjReqID:      r.ObjReqID,
+		PromotionID:   r.PromotionID,
+		SkippedStages: strings.Split(r.SkippedStages, ","),
+		Actor:         r.Actor,
+		Status:        string(r.Status),
+		Justification: r.Justification,
+		JustifiedAt:   NullTimeToPtr(r.JustifiedAt),
+		Reviewer:      NullStringToString(r.Reviewer),
+		ReviewNote:    r.ReviewNote,
+		ReviewedAt:    NullTimeToPtr(r.ReviewedAt),
+		CreatedAt:     r.CreatedAt,
+	}
+}
+
+// skippedStages returns the stages a forward move jumps over.
+func skippedStages(stages []database.PromotionStage, from uuid.NullUUID, to database.PromotionStage) []string {
+	fromIdx := stageIndex(stages, from)
+	toIdx := stageIndex(stages, uuid.NullUUID{UUID: to.ID, Valid: true})
+
+	var skipped []string
+	for i := fromIdx + 1; i < toIdx; i++ {
+		skipped = append(skipped, stages[i].Name)
+	}
+	return skipped
+}
+
+// checkEmergencyMove allows an emergency request to skip stages when the
+// caller asks for the emergency path, as long as the caller has justified
+// every earlier shortcut. Other moves follow checkStageMove. It returns the
+// stages skipped.
+func checkEmergencyMove(ctx context.Context, q *database.Queries, actor string, req database.MimixObjReq, stages []database.PromotionStage, to database.PromotionStage, emergency bool) ([]string, error) {
+	skipped := skippedStages(stages, req.StageID, to)
+	if !emergency || len(skipped) == 0 {
+		return nil, checkStageMove(stages, req.StageID, to)
+	}
+	if req.Priority != database.ReqPriorityEmergency {
+		return nil, stageMoveError{msg: "only emergency requests can skip stages"}
+	}
+
+	unjustified, err := q.CheckUnjustifiedEmergency(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+	if unjustified {
+		return nil, stageMoveError{msg: "justify your earlier emergency promotion before skipping stages again"}
+	}
+	return skipped, nil
+}
+
+// getEmergencyReviewParam loads the review named by the :id path param,
+// writing the error response itself when it cannot.
+func (cfg *apiConfig) getEmergencyReviewParam(c *gin.Context) (database.EmergencyReview, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid review id"})
+		return database.EmergencyReview{}, false
+	}
+
+	review, err := cfg.dbQueries.GetEmergencyReviewByID(c.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "emergency review not found"})
+		return review, false
+	}
+	if err != nil {
+		log.Printf("error getting emergency review: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get emergency review"})
+		return review, false
+	}
+	if !cfg.requireObjReqLib(c, contextUser(c), review.ObjReqID, database.LibPermissionRead, "emergency review not found") {
+		return review, false
+	}
+	return review, true
+}
+
+// ListEmergencyReviews returns emergency reviews, oldest first. ?status=
+// narrows them to one status.
+func (cfg *apiConfig) ListEmergencyReviews(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c)
+	if !ok {
+		return
+	}
+
+	var status database.NullEmergencyReviewStatus
+	if s := strings.ToLower(strings.TrimSpace(c.Query("status"))); s != "" {
+		val, ok := allowedEmergencyReviewStatus[s]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+			return
+		}
+		status = database.NullEmergencyReviewStatus{EmergencyReviewStatus: val, Valid: true}
+	}
+
+	rows, err := cfg.dbQueries.ListEmergencyReviews(c.Request.Context(), status)
+	if err != nil {
+		log.Printf("error listing emergency reviews: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get emergency reviews"})
+		return
+	}
+
+	// reviews of requests in libraries the caller cannot read are left out
+	access, ok := cfg.libAccessFor(c, user)
+	if !ok {
+		return
+	}
+	libRows, err := cfg.dbQueries.ListEmergencyReviewLibs(c.Request.Context())
+	if err != nil {
+		log.Printf("error listing emergency review libs: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get emergency reviews"})
+		return
+	}
+	libs := make(map[uuid.UUID]string, len(libRows))
+	for _, row := range libRows {
+		libs[row.ID] = row.Lib
+	}
+
+	reviews := make([]EmergencyReview, 0, len(rows))
+	for _, row := range rows {
+		if access.can(libs[row.ID], database.LibPermissionRead) {
+			reviews = append(reviews, toEmergencyReview(row))
+		}
+	}
+	c.JSON(http.StatusOK, reviews)
+}
+
+// ListObjReqEmergencyReviews returns the emergency reviews of one obj
+// request, oldest first.
+func (cfg *apiConfig) ListObjReqEmergencyReviews(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid obj req id"})
+		return
+	}
+	if !cfg.requireObjReqLib(c, user, id, database.LibPermissionRead, "obj request not found") {
+		return
+	}
+
+	rows, err := cfg.dbQueries.ListObjReqEmergencyReviews(c.Request.Context(), id)
+	if err != nil {
+		log.Printf("error listing emergency reviews: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get emergency reviews"})
+		return
+	}
+
+	reviews := make([]EmergencyReview, 0, len(rows))
+	for _, row := range rows {
+		reviews = append(reviews, toEmergencyReview(row))
+	}
+	c.JSON(http.StatusOK, reviews)
+}
+
+// JustifyEmergencyReview records why stages were skipped. Only the user who
+// promoted the request can justify it.
+func (cfg *apiConfig) JustifyEmergencyReview(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c)
+	if !ok || !cfg.requirePermission(c, permComment) {
+		return
+	}
+
+	type parameters struct {
+		Justification string `json:"justification" binding:"required"`
+	}
+
+	var params parameters
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	params.Justification = strings.TrimSpace(params.Justification)
+	if params.Justification == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "justification cannot be empty"})
+		return
+	}
+
+	review, ok := cfg.getEmergencyReviewParam(c)
+	if !ok {
+		return
+	}
+	if !strings.EqualFold(review.Actor, user.Username) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only " + review.Actor + " can justify this emergency promotion"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	justified, err := cfg.dbQueries.JustifyEmergencyReview(ctx, database.JustifyEmergencyReviewParams{
+		ID:            review.ID,
+		Justification: params.Justification,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusConflict, gin.H{"error": "emergency promotion is already justified"})
+		return
+	}
+	if err != nil {
+		log.Printf("error justifying emergency review: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not justify emergency promotion"})
+		return
+	}
+
+	// the emergency reviewers check the justification
+	reviewers, err := cfg.dbQueries.ListUsernamesWithPermission(ctx, permEmergencyReview)
+	if err != nil {
+		log.Printf("error listing reviewers: %v", err)
+	}
+	for _, reviewer := range reviewers {
+		if strings.EqualFold(reviewer, user.Username) {
+			continue
+		}
+		if _, err := notify(ctx, cfg.dbQueries, notice{
+			recipient: reviewer,
+			kind:      notifyEmergencyReview,
+			message:   fmt.Sprintf("%s justified an emergency promotion that skipped %s", user.Username, justified.SkippedStages),
+			objReqID:  justified.ObjReqID,
+			dedupeKey: fmt.Sprintf("%s:%s:%s", notifyEmergencyReview, justified.ID, strings.ToLower(reviewer)),
+		}); err != nil {
+			log.Printf("error notifying reviewer: %v", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, toEmergencyReview(justified))
+}
+
+// ReviewEmergencyReview accepts or rejects a justification. Reviewers are
+// change management or DC, and never the actor.
+func (cfg *apiConfig) ReviewEmergencyReview(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permEmergencyReview)
+	if !ok {
+		return
+	}
+
+	type parameters struct {
+		Accepted *bool  `json:"accepted" binding:"required"`
+		Note     string `json:"note"`
+	}
+
+	var params parameters
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	review, ok := cfg.getEmergencyReviewParam(c)
+	if !ok {
+		return
+	}
+	if strings.EqualFold(review.Actor, user.Username) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "an emergency promotion cannot be reviewed by its actor"})
+		return
+	}
+
+	status := database.EmergencyReviewStatusRejected
+	if *params.Accepted {
+		status = database.EmergencyReviewStatusAccepted
+	}
+
+	ctx := c.Request.Context()
+	reviewed, err := cfg.dbQueries.ReviewEmergencyReview(ctx, database.ReviewEmergencyReviewParams{
+		ID:         review.ID,
+		Status:     status,
+		Reviewer:   sql.NullString{String: user.Username, Valid: true},
+		ReviewNote: strings.TrimSpace(params.Note),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusConflict, gin.H{"error": "emergency promotion is not awaiting review"})
+		return
+	}
+	if err != nil {
+		log.Printf("error reviewing emergency promotion: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not review emergency promotion"})
+		return
+	}
+
+	if _, err := notify(ctx, cfg.dbQueries, notice{
+		recipient: reviewed.Actor,
+		kind:      notifyEmergencyOutcome,
+		message:   fmt.Sprintf("%s %s your emergency promotion justification", user.Username, reviewed.Status),
+		objReqID:  reviewed.ObjReqID,
+	}); err != nil {
+		log.Printf("error notifying actor: %v", err)
+	}
+
+	c.JSON(http.StatusOK, toEmergencyReview(reviewed))
+}
+
+// remindEmergencyJustification reminds actors, once a day, of emergency
+// promotions they have not justified yet.
+func (cfg *apiConfig) remindEmergencyJustification(ctx context.Context) (string, error) {
+	reviews, err := cfg.dbQueries.ListEmergencyReviews(ctx, database.NullEmergencyReviewStatus{
+		EmergencyReviewStatus: database.EmergencyReviewStatusAwaitingJustification,
+		Valid:                 true,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	today := time.Now().Format(time.DateOnly)
+	sent := 0
+	for _, r := range reviews {
+		isNew, err := notify(ctx, cfg.dbQueries, notice{
+			recipient: r.Actor,
+			kind:      notifyEmergencyJustify,
+			message:   fmt.Sprintf("justify your emergency promotion that skipped %s", r.SkippedStages),
+			objReqID:  r.ObjReqID,
+			dedupeKey: fmt.Sprintf("%s:%s:%s", notifyEmergencyJustify, r.ID, today),
+		})
+		if err != nil {
+			return "", err
+		}
+		if isNew {
+			sent++
+		}
+	}
+	return fmt.Sprintf("%d unjustified, %d reminders sent", len(reviews), sent), nil
+}
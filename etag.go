@@ -0,0 +1,201 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/paul39-33/imimix/internal/database"
+)
+
+// etag formats a row version as a strong entity tag.
+func etag(version int32) string {
+	return `"` + strconv.FormatInt(int64(version), 10) + `"`
+}
+
+// parseETag reads a single entity tag produced by etag. Weak tags are accepted
+// because proxies may weaken them on the way back.
+func parseETag(tag string) (int32, error) {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, errors.New("malformed entity tag")
+	}
+	version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 32)
+	if err != nil {
+		return 0, errors.New("malformed entity tag")
+	}
+	return int32(version), nil
+}
+
+// requireIfMatch reads the If-Match header of a write request. It returns the
+// version the client expects, or anyVersion for "If-Match: *". A missing
+// header is answered with 428 Precondition Required.
+func requireIfMatch(c *gin.Context) (version int32, anyVersion bool, ok bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		c.JSON(http.StatusPreconditionRequired, gin.H{
+			"error": "If-Match header is required",
+		})
+		return 0, false, false
+	}
+	if header == "*" {
+		return 0, true, true
+	}
+
+	version, err := parseETag(header)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid If-Match header",
+		})
+		return 0, false, false
+	}
+	return version, false, true
+}
+
+// objPreconditionFailed answers a failed conditional obj update: 404 when the
+// obj no longer exists, otherwise 412 with the current server copy so the
+// client can merge and retry.
+func (cfg *apiConfig) objPreconditionFailed(c *gin.Context, id uuid.UUID) {
+	current, err := cfg.dbQueries.GetObjByID(c.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no matching obj found"})
+		return
+	}
+	if err != nil {
+		log.Printf("error getting mimix object: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get mimix object"})
+		return
+	}
+
+	c.Header("ETag", etag(current.Version))
+	c.JSON(http.StatusPreconditionFailed, gin.H{
+		"error":   "obj was modified by someone else",
+		"current": toMimixObj(current),
+	})
+}
+
+// objReqPreconditionFailed is objPreconditionFailed for obj requests.
+func (cfg *apiConfig) objReqPreconditionFailed(c *gin.Context, id uuid.UUID) {
+	current, err := cfg.dbQueries.GetMimixObjReqByID(c.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "obj request not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("error getting mimix object request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get mimix object request"})
+		return
+	}
+
+	c.Header("ETag", etag(current.Version))
+	c.JSON(http.StatusPreconditionFailed, gin.H{
+		"error":   "obj request was modified by someone else",
+		"current": toMimixObjReq(current),
+	})
+}
+
+// GetObj returns an obj in full, with the requests that reference it. The
+// ETag is the obj's version, for If-Match on updates; it does not cover the
+// linked requests or comments, so conditional GETs are not answered with 304.
+func (cfg *apiConfig) GetObj(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c)
+	if !ok {
+		return
+	}
+
+	objUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Printf("error parsing obj id: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid obj id"})
+		return
+	}
+
+	obj, err := cfg.dbQueries.GetObjByID(c.Request.Context(), objUUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no matching obj found"})
+		return
+	}
+	if err != nil {
+		log.Printf("error getting mimix object: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get mimix object"})
+		return
+	}
+	if !cfg.requireLib(c, user, obj.Lib, database.LibPermissionRead, "no matching obj found") {
+		return
+	}
+
+	access, ok := cfg.libAccessFor(c, user)
+	if !ok {
+		return
+	}
+	resp, err := objDetail(c.Request.Context(), cfg.dbQueries, access, obj)
+	if err != nil {
+		log.Printf("error getting obj requests: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get mimix object"})
+		return
+	}
+
+	comments, err := cfg.dbQueries.CountObjComments(c.Request.Context(), uuid.NullUUID{UUID: obj.ID, Valid: true})
+	if err != nil {
+		log.Printf("error counting comments: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get mimix object"})
+		return
+	}
+	resp.CommentCount = &comments
+	c.Header("ETag", etag(obj.Version))
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetObjReq returns an obj request in full; a pending one comes with a
+// preview of its conversion. Like GetObj it always answers in full.
+func (cfg *apiConfig) GetObjReq(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c)
+	if !ok {
+		return
+	}
+
+	objReqUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Printf("error parsing obj req id: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid obj req id"})
+		return
+	}
+
+	objReq, err := cfg.dbQueries.GetMimixObjReqByID(c.Request.Context(), objReqUUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "obj request not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("error getting mimix object request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get mimix object request"})
+		return
+	}
+	if !cfg.requireLib(c, user, objReq.Lib, database.LibPermissionRead, "obj request not found") {
+		return
+	}
+
+	comments, err := cfg.dbQueries.CountObjReqComments(c.Request.Context(), uuid.NullUUID{UUID: objReq.ID, Valid: true})
+	if err != nil {
+		log.Printf("error counting comments: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get mimix object request"})
+		return
+	}
+
+	resp := ObjReqDetail{MimixObjReq: toMimixObjReq(objReq)}
+	resp.CommentCount = &comments
+	if objReq.ReqStatus == database.ReqStatusPending {
+		if resp.Conversion, err = previewConversion(c.Request.Context(), cfg.dbQueries, objReq); err != nil {
+			log.Printf("error previewing conversion: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get mimix object request"})
+			return
+		}
+	}
+	c.Header("ETag", etag(objReq.Version))
+	c.JSON(http.StatusOK, resp)
+}
@@ -1,6 +1,9 @@
 package auth
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"testing"
 	"time"
 
@@ -107,3 +110,84 @@ func TestMakeRefreshToken(t *testing.T) {
 		t.Error("MakeRefreshToken generated duplicate tokens")
 	}
 }
+
+func TestPasswordStore(t *testing.T) {
+	hashed, _ := HashPassword("testPassword123")
+	store := PasswordStore{HashedPassword: func(ctx context.Context, username string) (string, error) {
+		switch username {
+		case "alice":
+			return hashed, nil
+		case "ldapuser":
+			return "", nil
+		}
+		return "", sql.ErrNoRows
+	}}
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wantErr  bool
+	}{
+		{"correct password", "Alice", "testPassword123", false},
+		{"wrong password", "alice", "wrong", true},
+		{"unknown user", "bob", "testPassword123", true},
+		{"no local password", "ldapuser", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := store.Authenticate(context.Background(), tt.username, tt.password)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidCredentials) {
+					t.Errorf("Authenticate() error = %v, want ErrInvalidCredentials", err)
+				}
+				return
+			}
+			if err != nil || id.Username != "alice" || id.External {
+				t.Errorf("Authenticate() = %+v, %v", id, err)
+			}
+		})
+	}
+}
+
+// stubAuth answers every login the same way.
+type stubAuth struct {
+	id  Identity
+	err error
+}
+
+func (s stubAuth) Authenticate(ctx context.Context, username, password string) (Identity, error) {
+	return s.id, s.err
+}
+
+func TestChain(t *testing.T) {
+	local := Identity{Username: "admin"}
+	external := Identity{Username: "alice", External: true}
+	outage := errors.New("directory unreachable")
+
+	tests := []struct {
+		name    string
+		chain   Chain
+		want    Identity
+		wantErr error
+	}{
+		{"first accepts", Chain{stubAuth{id: local}, stubAuth{err: outage}}, local, nil},
+		{"falls through on invalid credentials", Chain{stubAuth{err: ErrInvalidCredentials}, stubAuth{id: external}}, external, nil},
+		{"stops on other errors", Chain{stubAuth{err: outage}, stubAuth{id: external}}, Identity{}, outage},
+		{"none accepts", Chain{stubAuth{err: ErrInvalidCredentials}, stubAuth{err: ErrInvalidCredentials}}, Identity{}, ErrInvalidCredentials},
+		{"empty", Chain{}, Identity{}, ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := tt.chain.Authenticate(context.Background(), "user", "pw")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+			if id.Username != tt.want.Username || id.External != tt.want.External {
+				t.Errorf("Authenticate() = %+v, want %+v", id, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidCredentials is returned for an unknown user or a wrong
+// password; authenticators do not tell the two apart.
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
+// Identity is a user an Authenticator verified.
+type Identity struct {
+	// Username is lower-cased, as usernames are stored.
+	Username string
+	// Groups are the directory groups the user is a member of, as DNs.
+	Groups []string
+	// External is set when a directory rather than the users table
+	// verified the password; such users are provisioned on first login.
+	External bool
+}
+
+// Authenticator verifies a username and password.
+type Authenticator interface {
+	Authenticate(ctx context.Context, username, password string) (Identity, error)
+}
+
+// PasswordStore authenticates against the bcrypt hashes kept in the users
+// table.
+type PasswordStore struct {
+	// HashedPassword returns the stored hash of username, or sql.ErrNoRows
+	// when there is no such user.
+	HashedPassword func(ctx context.Context, username string) (string, error)
+}
+
+func (s PasswordStore) Authenticate(ctx context.Context, username, password string) (Identity, error) {
+	username = strings.ToLower(strings.TrimSpace(username))
+	hash, err := s.HashedPassword(ctx, username)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Identity{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return Identity{}, err
+	}
+	// users provisioned from a directory have no local password
+	if hash == "" || !CheckPasswordHash(password, hash) {
+		return Identity{}, ErrInvalidCredentials
+	}
+	return Identity{Username: username}, nil
+}
+
+// Chain tries each authenticator in turn. Only ErrInvalidCredentials moves
+// on to the next one; any other error ends the login, so an outage is not
+// mistaken for a wrong password.
+type Chain []Authenticator
+
+func (c Chain) Authenticate(ctx context.Context, username, password string) (Identity, error) {
+	for _, a := range c {
+		id, err := a.Authenticate(ctx, username, password)
+		if !errors.Is(err, ErrInvalidCredentials) {
+			return id, err
+		}
+	}
+	return Identity{}, ErrInvalidCredentials
+}
+
+// GroupCN returns the common name of a group DN, such as IBMi-DC for
+// CN=IBMi-DC,OU=Groups,DC=corp,DC=example. A DN not starting with a CN is
+// returned unchanged.
+func GroupCN(dn string) string {
+	rdn := dn
+	for i := 0; i < len(dn); i++ {
+		if dn[i] == '\\' {
+			i++
+			continue
+		}
+		if dn[i] == ',' {
+			rdn = dn[:i]
+			break
+		}
+	}
+	attr, value, ok := strings.Cut(rdn, "=")
+	if !ok || !strings.EqualFold(strings.TrimSpace(attr), "cn") {
+		return dn
+	}
+	return strings.TrimSpace(value)
+}
@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+const (
+	defaultLDAPTimeout    = 10 * time.Second
+	defaultGroupAttribute = "memberOf"
+	// two entries are enough to tell a name is ambiguous
+	ldapSearchSizeLimit = 2
+)
+
+// LDAPConfig points an LDAP authenticator at a directory such as Active
+// Directory. URL is ldap://host[:389] or ldaps://host[:636]. Users are
+// found by searching BaseDN with UserFilter, in which %s stands for the
+// escaped username, bound as BindDN when it is set. An ldap:// connection
+// is upgraded with StartTLS before any password is sent, unless Insecure
+// is set.
+type LDAPConfig struct {
+	URL            string
+	BindDN         string
+	BindPassword   string
+	BaseDN         string
+	UserFilter     string
+	GroupAttribute string
+	Timeout        time.Duration
+	TLSConfig      *tls.Config
+	Insecure       bool
+}
+
+// LDAP authenticates with a search for the user's entry followed by a bind
+// as that entry with their password. The protocol is spoken by go-ldap.
+type LDAP struct {
+	cfg      LDAPConfig
+	url      string
+	host     string
+	startTLS bool
+}
+
+// NewLDAP checks cfg and fills in its defaults.
+func NewLDAP(cfg LDAPConfig) (*LDAP, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ldap url: %w", err)
+	}
+	l := &LDAP{host: u.Hostname()}
+	switch u.Scheme {
+	case "ldap":
+		l.startTLS = !cfg.Insecure
+		if u.Port() == "" {
+			u.Host = net.JoinHostPort(u.Hostname(), "389")
+		}
+	case "ldaps":
+		if u.Port() == "" {
+			u.Host = net.JoinHostPort(u.Hostname(), "636")
+		}
+	default:
+		return nil, fmt.Errorf("invalid ldap url scheme %q", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, errors.New("ldap url has no host")
+	}
+	l.url = u.Scheme + "://" + u.Host
+	if cfg.BaseDN == "" {
+		return nil, errors.New("ldap base dn is required")
+	}
+	if strings.Count(cfg.UserFilter, "%s") != 1 {
+		return nil, errors.New("ldap user filter must contain %s once")
+	}
+	if _, err := ldap.CompileFilter(fmt.Sprintf(cfg.UserFilter, "x")); err != nil {
+		return nil, fmt.Errorf("invalid ldap user filter: %w", err)
+	}
+	if cfg.GroupAttribute == "" {
+		cfg.GroupAttribute = defaultGroupAttribute
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultLDAPTimeout
+	}
+	l.cfg = cfg
+	return l, nil
+}
+
+func (l *LDAP) Authenticate(ctx context.Context, username, password string) (Identity, error) {
+	username = strings.ToLower(strings.TrimSpace(username))
+	// a simple bind with an empty password is an anonymous bind, which
+	// servers accept for any name
+	if username == "" || password == "" {
+		return Identity{}, ErrInvalidCredentials
+	}
+
+	conn, err := l.dial(ctx)
+	if err != nil {
+		return Identity{}, err
+	}
+	defer conn.Close()
+
+	if l.cfg.BindDN != "" {
+		if err := conn.Bind(l.cfg.BindDN, l.cfg.BindPassword); err != nil {
+			return Identity{}, fmt.Errorf("ldap service bind: %w", err)
+		}
+	}
+
+	res, err := conn.Search(ldap.NewSearchRequest(
+		l.cfg.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, ldapSearchSizeLimit, 0, false,
+		fmt.Sprintf(l.cfg.UserFilter, ldap.EscapeFilter(username)),
+		[]string{l.cfg.GroupAttribute}, nil,
+	))
+	// an unknown or ambiguous name is refused like a wrong password
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return Identity{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return Identity{}, fmt.Errorf("ldap search: %w", err)
+	}
+	if len(res.Entries) != 1 {
+		return Identity{}, ErrInvalidCredentials
+	}
+	entry := res.Entries[0]
+
+	err = conn.Bind(entry.DN, password)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+		return Identity{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return Identity{}, fmt.Errorf("ldap bind: %w", err)
+	}
+
+	return Identity{
+		Username: username,
+		Groups:   entry.GetEqualFoldAttributeValues(l.cfg.GroupAttribute),
+		External: true,
+	}, nil
+}
+
+// dial connects to the server within the timeout, upgrading a plain
+// connection with StartTLS unless the config says otherwise.
+func (l *LDAP) dial(ctx context.Context) (*ldap.Conn, error) {
+	d := &net.Dialer{Timeout: l.cfg.Timeout}
+	if deadline, ok := ctx.Deadline(); ok {
+		d.Deadline = deadline
+	}
+	conn, err := ldap.DialURL(l.url, ldap.DialWithDialer(d), ldap.DialWithTLSConfig(l.tlsConfig()))
+	if err != nil {
+		return nil, fmt.Errorf("ldap dial: %w", err)
+	}
+	conn.SetTimeout(l.cfg.Timeout)
+
+	if l.startTLS {
+		if err := conn.StartTLS(l.tlsConfig()); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("ldap starttls: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// tlsConfig is the configured TLS config, verifying the url's host unless
+// it names another server.
+func (l *LDAP) tlsConfig() *tls.Config {
+	cfg := &tls.Config{}
+	if l.cfg.TLSConfig != nil {
+		cfg = l.cfg.TLSConfig.Clone()
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = l.host
+	}
+	return cfg
+}
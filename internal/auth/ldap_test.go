@@ -0,0 +1,414 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"math/big"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// testEntry is an entry of the in-process directory.
+type testEntry struct {
+	password string
+	attrs    map[string][]string
+}
+
+// startDirectory serves entries over LDAP on a loopback port until the
+// test ends, returning its url. StartTLS is refused when tlsCfg is nil.
+func startDirectory(t *testing.T, entries map[string]testEntry, tlsCfg *tls.Config) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveLDAP(conn, entries, tlsCfg)
+		}
+	}()
+	return "ldap://" + ln.Addr().String()
+}
+
+// LDAP protocol operations and filter choices the test directory handles
+// (RFC 4511).
+const (
+	opBind             ber.Tag = 0
+	opBindResponse     ber.Tag = 1
+	opUnbind           ber.Tag = 2
+	opSearch           ber.Tag = 3
+	opSearchEntry      ber.Tag = 4
+	opSearchDone       ber.Tag = 5
+	opExtended         ber.Tag = 23
+	opExtendedResponse ber.Tag = 24
+
+	filterAnd      ber.Tag = 0
+	filterOr       ber.Tag = 1
+	filterNot      ber.Tag = 2
+	filterEquality ber.Tag = 3
+	filterPresent  ber.Tag = 7
+)
+
+const startTLSOID = "1.3.6.1.4.1.1466.20037"
+
+func serveLDAP(conn net.Conn, entries map[string]testEntry, tlsCfg *tls.Config) {
+	defer func() { conn.Close() }()
+	reply := func(id int64, op *ber.Packet) {
+		msg := ber.NewSequence("")
+		msg.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, ""))
+		msg.AppendChild(op)
+		conn.Write(msg.Bytes())
+	}
+	result := func(tag ber.Tag, code uint16) *ber.Packet {
+		op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "")
+		op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), ""))
+		op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+		op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+		return op
+	}
+
+	for {
+		msg, err := ber.ReadPacket(conn)
+		if err != nil || len(msg.Children) < 2 {
+			return
+		}
+		id, _ := msg.Children[0].Value.(int64)
+		op := msg.Children[1]
+		fields := op.Children
+
+		switch op.Tag {
+		case opBind:
+			entry, ok := entries[fields[1].Value.(string)]
+			password := fields[2].Data.String()
+			code := uint16(ldap.LDAPResultInvalidCredentials)
+			if ok && password != "" && password == entry.password {
+				code = ldap.LDAPResultSuccess
+			}
+			reply(id, result(opBindResponse, code))
+		case opSearch:
+			base := strings.ToLower(fields[0].Value.(string))
+			limit, _ := fields[3].Value.(int64)
+			sent, code := int64(0), uint16(ldap.LDAPResultSuccess)
+			for dn, entry := range entries {
+				if !strings.HasSuffix(strings.ToLower(dn), base) || !matchFilter(fields[6], entry) {
+					continue
+				}
+				if limit > 0 && sent == limit {
+					code = ldap.LDAPResultSizeLimitExceeded
+					break
+				}
+				attrs := ber.NewSequence("")
+				for _, w := range fields[7].Children {
+					name := w.Value.(string)
+					vals := entryAttribute(entry, name)
+					if vals == nil {
+						continue
+					}
+					set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "")
+					for _, v := range vals {
+						set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, v, ""))
+					}
+					attr := ber.NewSequence("")
+					attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, ""))
+					attr.AppendChild(set)
+					attrs.AppendChild(attr)
+				}
+				found := ber.Encode(ber.ClassApplication, ber.TypeConstructed, opSearchEntry, nil, "")
+				found.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, dn, ""))
+				found.AppendChild(attrs)
+				reply(id, found)
+				sent++
+			}
+			reply(id, result(opSearchDone, code))
+		case opExtended:
+			if tlsCfg == nil || fields[0].Data.String() != startTLSOID {
+				reply(id, result(opExtendedResponse, ldap.LDAPResultProtocolError))
+				continue
+			}
+			reply(id, result(opExtendedResponse, ldap.LDAPResultSuccess))
+			conn = tls.Server(conn, tlsCfg)
+		case opUnbind:
+			return
+		}
+	}
+}
+
+// entryAttribute returns the values of name, matched case-insensitively as
+// attribute descriptions are.
+func entryAttribute(entry testEntry, name string) []string {
+	for k, v := range entry.attrs {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return nil
+}
+
+func matchFilter(f *ber.Packet, entry testEntry) bool {
+	switch f.Tag {
+	case filterAnd:
+		for _, s := range f.Children {
+			if !matchFilter(s, entry) {
+				return false
+			}
+		}
+		return true
+	case filterOr:
+		for _, s := range f.Children {
+			if matchFilter(s, entry) {
+				return true
+			}
+		}
+		return false
+	case filterNot:
+		return !matchFilter(f.Children[0], entry)
+	case filterEquality:
+		want := f.Children[1].Value.(string)
+		return slices.ContainsFunc(entryAttribute(entry, f.Children[0].Value.(string)), func(v string) bool {
+			return strings.EqualFold(v, want)
+		})
+	case filterPresent:
+		return entryAttribute(entry, f.Data.String()) != nil
+	}
+	return false
+}
+
+var testEntries = map[string]testEntry{
+	"CN=svc-imimix,OU=Service,DC=corp,DC=test": {password: "svc-secret"},
+	"CN=Alice Smith,OU=Staff,DC=corp,DC=test": {
+		password: "alice-pw",
+		attrs: map[string][]string{
+			"objectClass":    {"user"},
+			"sAMAccountName": {"alice"},
+			"memberOf":       {"CN=IBMi-DC,OU=Groups,DC=corp,DC=test", "CN=Staff,OU=Groups,DC=corp,DC=test"},
+		},
+	},
+	"CN=Bob One,OU=Staff,DC=corp,DC=test": {
+		password: "bob-pw",
+		attrs:    map[string][]string{"objectClass": {"user"}, "sAMAccountName": {"bob"}},
+	},
+	"CN=Bob Two,OU=Staff,DC=corp,DC=test": {
+		password: "bob-pw",
+		attrs:    map[string][]string{"objectClass": {"user"}, "sAMAccountName": {"bob"}},
+	},
+	"CN=Carol,OU=Staff,DC=corp,DC=test": {
+		password: "carol-pw",
+		attrs:    map[string][]string{"objectClass": {"contact"}, "sAMAccountName": {"carol"}},
+	},
+}
+
+func newTestLDAP(t *testing.T, bindPassword string) *LDAP {
+	t.Helper()
+	l, err := NewLDAP(LDAPConfig{
+		URL:          startDirectory(t, testEntries, nil),
+		BindDN:       "CN=svc-imimix,OU=Service,DC=corp,DC=test",
+		BindPassword: bindPassword,
+		BaseDN:       "OU=Staff,DC=corp,DC=test",
+		UserFilter:   "(&(objectClass=user)(sAMAccountName=%s))",
+		Insecure:     true,
+	})
+	if err != nil {
+		t.Fatalf("NewLDAP failed: %v", err)
+	}
+	return l
+}
+
+func TestLDAPAuthenticate(t *testing.T) {
+	l := newTestLDAP(t, "svc-secret")
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wantErr  error
+	}{
+		{"valid credentials", "alice", "alice-pw", nil},
+		{"username is case insensitive", " Alice ", "alice-pw", nil},
+		{"wrong password", "alice", "nope", ErrInvalidCredentials},
+		{"empty password", "alice", "", ErrInvalidCredentials},
+		{"unknown user", "dave", "dave-pw", ErrInvalidCredentials},
+		{"ambiguous user", "bob", "bob-pw", ErrInvalidCredentials},
+		{"filtered out", "carol", "carol-pw", ErrInvalidCredentials},
+		{"wildcard username", "*", "alice-pw", ErrInvalidCredentials},
+		{"filter injection", "x)(sAMAccountName=alice", "alice-pw", ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := l.Authenticate(context.Background(), tt.username, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if id.Username != "alice" || !id.External {
+				t.Errorf("Authenticate() = %+v, want external alice", id)
+			}
+			want := []string{"CN=IBMi-DC,OU=Groups,DC=corp,DC=test", "CN=Staff,OU=Groups,DC=corp,DC=test"}
+			if !slices.Equal(id.Groups, want) {
+				t.Errorf("Groups = %v, want %v", id.Groups, want)
+			}
+		})
+	}
+}
+
+func TestLDAPServiceBindFails(t *testing.T) {
+	l := newTestLDAP(t, "wrong")
+
+	_, err := l.Authenticate(context.Background(), "alice", "alice-pw")
+	if err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Authenticate() error = %v, want a service bind error", err)
+	}
+}
+
+// testCertificate returns a server config with a self-signed certificate
+// for 127.0.0.1 and a pool trusting it.
+func testCertificate(t *testing.T) (*tls.Config, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}, roots
+}
+
+func TestLDAPStartTLS(t *testing.T) {
+	serverTLS, roots := testCertificate(t)
+	_, untrusted := testCertificate(t)
+
+	tests := []struct {
+		name      string
+		serverTLS *tls.Config
+		roots     *x509.CertPool
+		wantErr   bool
+	}{
+		{"upgraded", serverTLS, roots, false},
+		{"refused by the server", nil, roots, true},
+		{"untrusted certificate", serverTLS, untrusted, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := NewLDAP(LDAPConfig{
+				URL:          startDirectory(t, testEntries, tt.serverTLS),
+				BindDN:       "CN=svc-imimix,OU=Service,DC=corp,DC=test",
+				BindPassword: "svc-secret",
+				BaseDN:       "OU=Staff,DC=corp,DC=test",
+				UserFilter:   "(sAMAccountName=%s)",
+				TLSConfig:    &tls.Config{RootCAs: tt.roots},
+			})
+			if err != nil {
+				t.Fatalf("NewLDAP failed: %v", err)
+			}
+			id, err := l.Authenticate(context.Background(), "alice", "alice-pw")
+			if tt.wantErr {
+				if err == nil || errors.Is(err, ErrInvalidCredentials) {
+					t.Fatalf("Authenticate() error = %v, want a starttls error", err)
+				}
+				return
+			}
+			if err != nil || id.Username != "alice" {
+				t.Fatalf("Authenticate() = %+v, %v, want alice", id, err)
+			}
+		})
+	}
+}
+
+func TestLDAPUnreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	l, err := NewLDAP(LDAPConfig{URL: "ldap://" + addr, BaseDN: "DC=corp,DC=test", UserFilter: "(uid=%s)"})
+	if err != nil {
+		t.Fatalf("NewLDAP failed: %v", err)
+	}
+	if _, err := l.Authenticate(context.Background(), "alice", "alice-pw"); err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Authenticate() error = %v, want a dial error", err)
+	}
+}
+
+func TestNewLDAP(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     LDAPConfig
+		wantErr bool
+	}{
+		{"valid", LDAPConfig{URL: "ldaps://dc1.corp.test", BaseDN: "DC=corp,DC=test", UserFilter: "(sAMAccountName=%s)"}, false},
+		{"bad scheme", LDAPConfig{URL: "http://dc1.corp.test", BaseDN: "DC=corp,DC=test", UserFilter: "(uid=%s)"}, true},
+		{"no host", LDAPConfig{URL: "ldap://", BaseDN: "DC=corp,DC=test", UserFilter: "(uid=%s)"}, true},
+		{"no base dn", LDAPConfig{URL: "ldap://dc1.corp.test", UserFilter: "(uid=%s)"}, true},
+		{"no placeholder", LDAPConfig{URL: "ldap://dc1.corp.test", BaseDN: "DC=corp,DC=test", UserFilter: "(uid=alice)"}, true},
+		{"unbalanced filter", LDAPConfig{URL: "ldap://dc1.corp.test", BaseDN: "DC=corp,DC=test", UserFilter: "(&(uid=%s)"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := NewLDAP(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewLDAP() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && l.url != "ldaps://dc1.corp.test:636" {
+				t.Errorf("url = %q, want the default ldaps port", l.url)
+			}
+		})
+	}
+}
+
+func TestGroupCN(t *testing.T) {
+	tests := []struct {
+		dn   string
+		want string
+	}{
+		{"CN=IBMi-DC,OU=Groups,DC=corp,DC=test", "IBMi-DC"},
+		{"cn=auditors,dc=corp", "auditors"},
+		{`CN=Smith\, J,OU=Groups`, `Smith\, J`},
+		{"OU=Groups,DC=corp", "OU=Groups,DC=corp"},
+		{"IBMi-DC", "IBMi-DC"},
+	}
+
+	for _, tt := range tests {
+		if got := GroupCN(tt.dn); got != tt.want {
+			t.Errorf("GroupCN(%q) = %q, want %q", tt.dn, got, tt.want)
+		}
+	}
+}
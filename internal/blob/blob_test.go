@@ -0,0 +1,165 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// roundTrip exercises the behaviour every Store must share.
+func roundTrip(t *testing.T, s Store) {
+	t.Helper()
+	ctx := context.Background()
+	key := "obj/1234/printout (1).txt"
+	data := []byte("DSPOBJD OBJ(PAYLIB/PAYCALC) OBJTYPE(*PGM)")
+
+	if err := s.Put(ctx, key, bytes.NewReader(data), int64(len(data))); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	r, err := s.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	r.Close()
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("Get = %q, %v; want %q", got, err, data)
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get after Delete = %v, want ErrNotFound", err)
+	}
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete of missing key = %v, want nil", err)
+	}
+
+	for _, bad := range []string{"", "/abs", "a/../b", "a//b", "./a", `a\b`} {
+		if err := s.Put(ctx, bad, strings.NewReader("x"), 1); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q) = %v, want ErrInvalidKey", bad, err)
+		}
+	}
+}
+
+func TestFS(t *testing.T) {
+	s, err := NewFS(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundTrip(t, s)
+
+	// a short body is rejected and leaves nothing behind
+	ctx := context.Background()
+	if err := s.Put(ctx, "short", strings.NewReader("abc"), 10); err == nil {
+		t.Fatal("Put with short body succeeded")
+	}
+	if _, err := s.Get(ctx, "short"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get after failed Put = %v, want ErrNotFound", err)
+	}
+}
+
+// fakeS3 is a minimal in-memory stand-in for an S3 bucket that rejects
+// requests signed for any other access key.
+type fakeS3 struct {
+	bucket    string
+	accessKey string
+
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+// s3Error writes an error in the XML form S3 uses.
+func s3Error(w http.ResponseWriter, code string, status int) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, code)
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential="+f.accessKey+"/") {
+		s3Error(w, "InvalidAccessKeyId", http.StatusForbidden)
+		return
+	}
+	key, ok := strings.CutPrefix(r.URL.Path, "/"+f.bucket+"/")
+	if !ok {
+		s3Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		f.objects[key] = data
+	case http.MethodGet, http.MethodHead:
+		data, ok := f.objects[key]
+		if !ok {
+			s3Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Write(data)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestS3(t *testing.T) {
+	fake := &fakeS3{bucket: "imimix", accessKey: "minio", objects: map[string][]byte{}}
+	srv := httptest.NewTLSServer(fake)
+	defer srv.Close()
+
+	s, err := NewS3(S3Config{
+		Endpoint:  srv.URL,
+		Bucket:    "imimix",
+		AccessKey: "minio",
+		SecretKey: "minio-secret",
+	}, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundTrip(t, s)
+
+	bad, err := NewS3(S3Config{
+		Endpoint:  srv.URL,
+		Bucket:    "imimix",
+		AccessKey: "someone-else",
+		SecretKey: "minio-secret",
+	}, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = bad.Put(context.Background(), "k", strings.NewReader("x"), 1)
+	if err == nil || !strings.Contains(err.Error(), "InvalidAccessKeyId") {
+		t.Fatalf("Put with unknown access key = %v, want InvalidAccessKeyId", err)
+	}
+}
+
+func TestNewS3(t *testing.T) {
+	if _, err := NewS3(S3Config{Endpoint: "http://localhost:9000", Bucket: "b"}, nil); err == nil {
+		t.Error("NewS3 without credentials succeeded")
+	}
+	if _, err := NewS3(S3Config{Endpoint: "not a url", Bucket: "b", AccessKey: "a", SecretKey: "s"}, nil); err == nil {
+		t.Error("NewS3 with invalid endpoint succeeded")
+	}
+	if _, err := NewS3(S3Config{Endpoint: "http://localhost:9000/s3", Bucket: "b", AccessKey: "a", SecretKey: "s"}, nil); err == nil {
+		t.Error("NewS3 with an endpoint path succeeded")
+	}
+}
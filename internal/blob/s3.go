@@ -0,0 +1,114 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config points an S3 store at a bucket. Endpoint is the service base URL,
+// such as https://s3.eu-west-1.amazonaws.com or a MinIO server; buckets are
+// always addressed path-style.
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+}
+
+// S3 stores blobs as objects in an S3-compatible bucket through the MinIO
+// client, which does the request signing.
+type S3 struct {
+	bucket string
+	client *minio.Client
+}
+
+// NewS3 returns a store for cfg. A nil client uses http.DefaultTransport.
+func NewS3(cfg S3Config, client *http.Client) (*S3, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, errors.New("s3 endpoint, bucket, access key and secret key are required")
+	}
+	base, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || base.Host == "" || (base.Scheme != "http" && base.Scheme != "https") {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", cfg.Endpoint)
+	}
+	if base.Path != "" {
+		return nil, fmt.Errorf("s3 endpoint %q cannot have a path", cfg.Endpoint)
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+
+	opts := &minio.Options{
+		Creds:        credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure:       base.Scheme == "https",
+		Region:       cfg.Region,
+		BucketLookup: minio.BucketLookupPath,
+	}
+	if client != nil {
+		opts.Transport = client.Transport
+	}
+	mc, err := minio.New(base.Host, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &S3{bucket: cfg.Bucket, client: mc}, nil
+}
+
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{})
+	return s.wrap(http.MethodPut, key, err)
+}
+
+// Get checks that the object exists before returning, so a missing key is
+// reported here rather than on the first read.
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, s.wrap(http.MethodGet, key, err)
+	}
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrNotFound
+		}
+		return nil, s.wrap(http.MethodGet, key, err)
+	}
+	return obj, nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return nil
+	}
+	return s.wrap(http.MethodDelete, key, err)
+}
+
+// wrap names the failed operation and the error code S3 sent back.
+func (s *S3) wrap(method, key string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if code := minio.ToErrorResponse(err).Code; code != "" {
+		return fmt.Errorf("s3 %s /%s/%s: %s: %w", method, s.bucket, key, code, err)
+	}
+	return fmt.Errorf("s3 %s /%s/%s: %w", method, s.bucket, key, err)
+}
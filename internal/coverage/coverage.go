@@ -0,0 +1,119 @@
+// Package coverage decides which generic data group rule covers an object.
+//
+// Rules use MIMIX style generic names: an exact name, a prefix ending in '*'
+// (PAY*) or *ALL. When several rules match an object the most specific one
+// wins, comparing the library first, then the object and then the type. An
+// exclude rule beats an include rule that is just as specific.
+package coverage
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// All matches any library, object or type.
+const All = "*ALL"
+
+// Rule is one generic data group entry. Lib is ignored for objects that do
+// not live in a library (IFS and DLO), where Obj is matched against the path.
+type Rule struct {
+	ID      uuid.UUID
+	Kind    string
+	Lib     string
+	Obj     string
+	ObjType string
+	Exclude bool
+	Status  string
+}
+
+// Object is what a rule is matched against.
+type Object struct {
+	Kind    string
+	Lib     string
+	Obj     string
+	ObjType string
+}
+
+// specificity of a single pattern: *ALL < generic (longer prefix wins) < exact.
+func specificity(pattern string) int {
+	switch {
+	case isAll(pattern):
+		return 0
+	case strings.HasSuffix(pattern, "*"):
+		return 1 + len(pattern) - 1
+	default:
+		// longer than any prefix of a name of the same length
+		return 1 << 16
+	}
+}
+
+func isAll(pattern string) bool {
+	return pattern == "" || strings.EqualFold(pattern, All)
+}
+
+// matchPattern reports whether value matches pattern, ignoring case.
+func matchPattern(pattern, value string) bool {
+	if isAll(pattern) {
+		return true
+	}
+	pattern, value = strings.ToLower(pattern), strings.ToLower(value)
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(value, prefix)
+	}
+	return pattern == value
+}
+
+// IsGeneric reports whether pattern matches more than one name.
+func IsGeneric(pattern string) bool {
+	return isAll(pattern) || strings.HasSuffix(pattern, "*")
+}
+
+// Matches reports whether the rule applies to the object.
+func (r Rule) Matches(o Object) bool {
+	if r.Kind != o.Kind {
+		return false
+	}
+	if r.Kind == "lib" && !matchPattern(r.Lib, o.Lib) {
+		return false
+	}
+	return matchPattern(r.Obj, o.Obj) && matchPattern(r.ObjType, o.ObjType)
+}
+
+// moreSpecific reports whether a should win over b.
+func moreSpecific(a, b Rule) bool {
+	if sa, sb := specificity(a.Lib), specificity(b.Lib); sa != sb {
+		return sa > sb
+	}
+	if sa, sb := specificity(a.Obj), specificity(b.Obj); sa != sb {
+		return sa > sb
+	}
+	if sa, sb := specificity(a.ObjType), specificity(b.ObjType); sa != sb {
+		return sa > sb
+	}
+	return a.Exclude && !b.Exclude
+}
+
+// Evaluate returns every rule that matches the object, most specific first.
+// The first entry, if any, decides the object's status. Rules that tie keep
+// their input order.
+func Evaluate(rules []Rule, o Object) []Rule {
+	var matched []Rule
+	for _, r := range rules {
+		if r.Matches(o) {
+			matched = append(matched, r)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool { return moreSpecific(matched[i], matched[j]) })
+	return matched
+}
+
+// Winner returns the rule that decides the object's status.
+func Winner(rules []Rule, o Object) (Rule, bool) {
+	matched := Evaluate(rules, o)
+	if len(matched) == 0 {
+		return Rule{}, false
+	}
+	return matched[0], true
+}
@@ -0,0 +1,93 @@
+package coverage
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func rule(lib, obj, objType string, exclude bool, status string) Rule {
+	return Rule{ID: uuid.New(), Kind: "lib", Lib: lib, Obj: obj, ObjType: objType, Exclude: exclude, Status: status}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		obj  Object
+		want bool
+	}{
+		{"all", rule("*ALL", "*ALL", "*ALL", false, ""), Object{"lib", "paylib", "pay01", "*PGM"}, true},
+		{"generic obj", rule("paylib", "pay*", "*ALL", false, ""), Object{"lib", "paylib", "PAY01", "*PGM"}, true},
+		{"generic obj miss", rule("paylib", "pay*", "*ALL", false, ""), Object{"lib", "paylib", "ord01", "*PGM"}, false},
+		{"exact lib miss", rule("paylib", "*ALL", "*ALL", false, ""), Object{"lib", "ordlib", "pay01", "*PGM"}, false},
+		{"type", rule("*ALL", "*ALL", "*FILE", false, ""), Object{"lib", "paylib", "pay01", "*PGM"}, false},
+		{"kind", rule("*ALL", "*ALL", "*ALL", false, ""), Object{"ifs", "*ifs", "/home/a", "*STMF"}, false},
+		{"ifs path", Rule{Kind: "ifs", Obj: "/home/app/*", ObjType: "*ALL"}, Object{"ifs", "*ifs", "/home/app/cfg/x.json", "*STMF"}, true},
+		{"ifs ignores lib", Rule{Kind: "ifs", Lib: "paylib", Obj: "/home/*", ObjType: "*ALL"}, Object{"ifs", "*ifs", "/home/a", "*STMF"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Matches(tt.obj); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWinner(t *testing.T) {
+	allInclude := rule("paylib", "*ALL", "*ALL", false, "done")
+	payExclude := rule("paylib", "pay*", "*ALL", true, "tidak perlu daftar")
+	payrExclude := rule("paylib", "payr*", "*ALL", true, "tidak perlu daftar")
+	exact := rule("paylib", "payroll", "*PGM", false, "done")
+	qtemp := rule("qtemp", "*ALL", "*ALL", true, "tidak perlu daftar")
+	rules := []Rule{allInclude, payExclude, payrExclude, exact, qtemp}
+
+	tests := []struct {
+		name string
+		obj  Object
+		want Rule
+		ok   bool
+	}{
+		{"library wide include", Object{"lib", "paylib", "ordhdr", "*FILE"}, allInclude, true},
+		{"generic beats all", Object{"lib", "paylib", "pay01", "*PGM"}, payExclude, true},
+		{"longer prefix wins", Object{"lib", "paylib", "payrate", "*PGM"}, payrExclude, true},
+		{"exact beats generic", Object{"lib", "paylib", "payroll", "*PGM"}, exact, true},
+		{"exact name other type", Object{"lib", "paylib", "payroll", "*FILE"}, payrExclude, true},
+		{"excluded library", Object{"lib", "qtemp", "x", "*FILE"}, qtemp, true},
+		{"no rule", Object{"lib", "ordlib", "x", "*FILE"}, Rule{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Winner(rules, tt.obj)
+			if ok != tt.ok || got.ID != tt.want.ID {
+				t.Errorf("Winner() = %+v, %v; want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestExcludeWinsTie(t *testing.T) {
+	include := rule("paylib", "*ALL", "*ALL", false, "done")
+	exclude := rule("paylib", "*ALL", "*ALL", true, "tidak perlu daftar")
+
+	for _, rules := range [][]Rule{{include, exclude}, {exclude, include}} {
+		got, _ := Winner(rules, Object{"lib", "paylib", "x", "*PGM"})
+		if got.ID != exclude.ID {
+			t.Errorf("Winner() = %+v, want the exclude rule", got)
+		}
+	}
+}
+
+func TestEvaluateOrder(t *testing.T) {
+	a := rule("*ALL", "*ALL", "*ALL", false, "")
+	b := rule("paylib", "*ALL", "*ALL", false, "")
+	c := rule("paylib", "pay*", "*ALL", false, "")
+
+	matched := Evaluate([]Rule{a, b, c}, Object{"lib", "paylib", "pay01", "*PGM"})
+	if len(matched) != 3 || matched[0].ID != c.ID || matched[1].ID != b.ID || matched[2].ID != a.ID {
+		t.Errorf("Evaluate() order wrong: %+v", matched)
+	}
+}
@@ -17,9 +17,14 @@ VALUES ($1)
 RETURNING id, lib
 `
 
-func (q *Queries) CreateMimixLib(ctx context.Context, lib string) (MimixLib, error) {
+type CreateMimixLibRow struct {
+	ID  uuid.UUID
+	Lib string
+}
+
+func (q *Queries) CreateMimixLib(ctx context.Context, lib string) (CreateMimixLibRow, error) {
 	row := q.db.QueryRowContext(ctx, createMimixLib, lib)
-	var i MimixLib
+	var i CreateMimixLibRow
 	err := row.Scan(&i.ID, &i.Lib)
 	return i, err
 }
@@ -30,25 +35,14 @@ FROM mimix_lib
 WHERE lib = $1
 `
 
-func (q *Queries) GetMimixLibByName(ctx context.Context, lib string) (MimixLib, error) {
+type GetMimixLibByNameRow struct {
+	ID  uuid.UUID
+	Lib string
+}
+
+func (q *Queries) GetMimixLibByName(ctx context.Context, lib string) (GetMimixLibByNameRow, error) {
 	row := q.db.QueryRowContext(ctx, getMimixLibByName, lib)
-	var i MimixLib
+	var i GetMimixLibByNameRow
 	err := row.Scan(&i.ID, &i.Lib)
 	return i, err
 }
-
-const updateObjLibID = `-- name: UpdateObjLibID :exec
-UPDATE mimix_obj
-SET lib_id = $2
-WHERE id = $1
-`
-
-type UpdateObjLibIDParams struct {
-	ID    uuid.UUID
-	LibID uuid.UUID
-}
-
-func (q *Queries) UpdateObjLibID(ctx context.Context, arg UpdateObjLibIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateObjLibID, arg.ID, arg.LibID)
-	return err
-}
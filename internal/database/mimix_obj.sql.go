@@ -14,9 +14,9 @@ import (
 )
 
 const addObj = `-- name: AddObj :one
-INSERT INTO mimix_obj (obj, obj_type, promote_date, obj_ver, lib, lib_id, mimix_status, developer, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
-RETURNING id, obj, obj_type, promote_date, obj_ver, lib, lib_id, mimix_status, developer, updated_at
+INSERT INTO mimix_obj (obj, obj_type, promote_date, obj_ver, lib, lib_id, mimix_status, developer, developer_id, obj_kind, prc_type, subtree, rule_id, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, (SELECT id FROM users WHERE LOWER(username) = LOWER($8)), $9, $10, $11, $12, NOW())
+RETURNING id, obj, obj_type, promote_date, obj_ver, lib, lib_id, mimix_status, developer, updated_at, version, obj_kind, prc_type, subtree, rule_id
 `
 
 type AddObjParams struct {
@@ -25,9 +25,13 @@ type AddObjParams struct {
 	PromoteDate sql.NullTime
 	ObjVer      string
 	Lib         string
-	LibID       uuid.UUID
+	LibID       uuid.NullUUID
 	MimixStatus MimixStatus
 	Developer   string
+	ObjKind     ObjKind
+	PrcType     PrcType
+	Subtree     bool
+	RuleID      uuid.NullUUID
 }
 
 type AddObjRow struct {
@@ -37,10 +41,15 @@ type AddObjRow struct {
 	PromoteDate sql.NullTime
 	ObjVer      string
 	Lib         string
-	LibID       uuid.UUID
+	LibID       uuid.NullUUID
 	MimixStatus MimixStatus
 	Developer   string
 	UpdatedAt   time.Time
+	Version     int32
+	ObjKind     ObjKind
+	PrcType     PrcType
+	Subtree     bool
+	RuleID      uuid.NullUUID
 }
 
 func (q *Queries) AddObj(ctx context.Context, arg AddObjParams) (AddObjRow, error) {
@@ -53,6 +62,10 @@ func (q *Queries) AddObj(ctx context.Context, arg AddObjParams) (AddObjRow, erro
 		arg.LibID,
 		arg.MimixStatus,
 		arg.Developer,
+		arg.ObjKind,
+		arg.PrcType,
+		arg.Subtree,
+		arg.RuleID,
 	)
 	var i AddObjRow
 	err := row.Scan(
@@ -66,6 +79,11 @@ func (q *Queries) AddObj(ctx context.Context, arg AddObjParams) (AddObjRow, erro
 		&i.MimixStatus,
 		&i.Developer,
 		&i.UpdatedAt,
+		&i.Version,
+		&i.ObjKind,
+		&i.PrcType,
+		&i.Subtree,
+		&i.RuleID,
 	)
 	return i, err
 }
@@ -82,7 +100,11 @@ INSERT INTO mimix_obj_req (
     obj_type,
     promote_date,
     developer,
-    source_obj_id
+    developer_id,
+    source_obj_id,
+    obj_kind,
+    prc_type,
+    subtree
 )
 SELECT
     o.obj,
@@ -95,9 +117,13 @@ SELECT
     o.obj_type,
     o.promote_date,
     o.developer,
-    o.id          -- source obj id
+    o.developer_id,
+    o.id,         -- source obj id
+    o.obj_kind,
+    o.prc_type,
+    o.subtree
 FROM mimix_obj AS o
-WHERE o.id = $1
+WHERE o.id = $1 AND o.deleted_at IS NULL
 `
 
 type AddObjToObjReqParams struct {
@@ -113,8 +139,8 @@ func (q *Queries) AddObjToObjReq(ctx context.Context, arg AddObjToObjReqParams)
 
 const completeObjMimixStatus = `-- name: CompleteObjMimixStatus :exec
 UPDATE mimix_obj
-SET mimix_status = 'done', updated_at = NOW()
-WHERE id = $1
+SET mimix_status = 'done', rule_id = NULL, updated_at = NOW(), version = version + 1
+WHERE id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) CompleteObjMimixStatus(ctx context.Context, id uuid.UUID) error {
@@ -122,10 +148,135 @@ func (q *Queries) CompleteObjMimixStatus(ctx context.Context, id uuid.UUID) erro
 	return err
 }
 
+const convertObjVersion = `-- name: ConvertObjVersion :one
+UPDATE mimix_obj
+SET
+    obj_ver       = $2,
+    promote_date  = $3,
+    -- a request without a developer keeps the obj's
+    developer     = COALESCE(NULLIF($4, ''), developer),
+    developer_id  = CASE WHEN $4 = '' THEN developer_id ELSE (SELECT id FROM users WHERE LOWER(username) = LOWER($4)) END,
+    mimix_status  = 'done',
+    rule_id       = NULL,
+    updated_at    = NOW(),
+    version       = version + 1
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, obj, obj_type, promote_date, lib, lib_id, obj_ver, mimix_status, developer, keterangan, updated_at, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, rule_id, stage_id, developer_id
+`
+
+type ConvertObjVersionParams struct {
+	ID          uuid.UUID
+	ObjVer      string
+	PromoteDate sql.NullTime
+	Developer   string
+}
+
+func (q *Queries) ConvertObjVersion(ctx context.Context, arg ConvertObjVersionParams) (MimixObj, error) {
+	row := q.db.QueryRowContext(ctx, convertObjVersion,
+		arg.ID,
+		arg.ObjVer,
+		arg.PromoteDate,
+		arg.Developer,
+	)
+	var i MimixObj
+	err := row.Scan(
+		&i.ID,
+		&i.Obj,
+		&i.ObjType,
+		&i.PromoteDate,
+		&i.Lib,
+		&i.LibID,
+		&i.ObjVer,
+		&i.MimixStatus,
+		&i.Developer,
+		&i.Keterangan,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.ObjKind,
+		&i.PrcType,
+		&i.Subtree,
+		&i.RuleID,
+		&i.StageID,
+		&i.DeveloperID,
+	)
+	return i, err
+}
+
+const countObjByDeveloper = `-- name: CountObjByDeveloper :many
+SELECT mimix_status, COUNT(*) AS count
+FROM mimix_obj
+WHERE developer_id = $1 AND deleted_at IS NULL
+GROUP BY mimix_status
+`
+
+type CountObjByDeveloperRow struct {
+	MimixStatus MimixStatus
+	Count       int64
+}
+
+func (q *Queries) CountObjByDeveloper(ctx context.Context, developerID uuid.NullUUID) ([]CountObjByDeveloperRow, error) {
+	rows, err := q.db.QueryContext(ctx, countObjByDeveloper, developerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountObjByDeveloperRow
+	for rows.Next() {
+		var i CountObjByDeveloperRow
+		if err := rows.Scan(&i.MimixStatus, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDeletedObjByID = `-- name: GetDeletedObjByID :one
+SELECT id, obj, obj_type, promote_date, lib, lib_id, obj_ver, mimix_status, developer, keterangan, updated_at, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, rule_id, stage_id, developer_id
+FROM mimix_obj
+WHERE id = $1 AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) GetDeletedObjByID(ctx context.Context, id uuid.UUID) (MimixObj, error) {
+	row := q.db.QueryRowContext(ctx, getDeletedObjByID, id)
+	var i MimixObj
+	err := row.Scan(
+		&i.ID,
+		&i.Obj,
+		&i.ObjType,
+		&i.PromoteDate,
+		&i.Lib,
+		&i.LibID,
+		&i.ObjVer,
+		&i.MimixStatus,
+		&i.Developer,
+		&i.Keterangan,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.ObjKind,
+		&i.PrcType,
+		&i.Subtree,
+		&i.RuleID,
+		&i.StageID,
+		&i.DeveloperID,
+	)
+	return i, err
+}
+
 const getMimixStatusByID = `-- name: GetMimixStatusByID :one
 SELECT mimix_status
 FROM mimix_obj
-WHERE id = $1
+WHERE id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) GetMimixStatusByID(ctx context.Context, id uuid.UUID) (MimixStatus, error) {
@@ -136,9 +287,9 @@ func (q *Queries) GetMimixStatusByID(ctx context.Context, id uuid.UUID) (MimixSt
 }
 
 const getObjByID = `-- name: GetObjByID :one
-SELECT id, obj, obj_type, promote_date, lib, lib_id, obj_ver, mimix_status, developer, keterangan, updated_at
+SELECT id, obj, obj_type, promote_date, lib, lib_id, obj_ver, mimix_status, developer, keterangan, updated_at, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, rule_id, stage_id, developer_id
 FROM mimix_obj
-WHERE id = $1
+WHERE id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) GetObjByID(ctx context.Context, id uuid.UUID) (MimixObj, error) {
@@ -156,14 +307,23 @@ func (q *Queries) GetObjByID(ctx context.Context, id uuid.UUID) (MimixObj, error
 		&i.Developer,
 		&i.Keterangan,
 		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.ObjKind,
+		&i.PrcType,
+		&i.Subtree,
+		&i.RuleID,
+		&i.StageID,
+		&i.DeveloperID,
 	)
 	return i, err
 }
 
 const getObjByNameAndLib = `-- name: GetObjByNameAndLib :one
-SELECT id, obj, obj_type, promote_date, lib, lib_id, obj_ver, mimix_status, developer, keterangan, updated_at
+SELECT id, obj, obj_type, promote_date, lib, lib_id, obj_ver, mimix_status, developer, keterangan, updated_at, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, rule_id, stage_id, developer_id
 FROM mimix_obj
-WHERE obj = $1 AND lib = $2
+WHERE obj = $1 AND lib = $2 AND deleted_at IS NULL
 `
 
 type GetObjByNameAndLibParams struct {
@@ -186,28 +346,405 @@ func (q *Queries) GetObjByNameAndLib(ctx context.Context, arg GetObjByNameAndLib
 		&i.Developer,
 		&i.Keterangan,
 		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.ObjKind,
+		&i.PrcType,
+		&i.Subtree,
+		&i.RuleID,
+		&i.StageID,
+		&i.DeveloperID,
 	)
 	return i, err
 }
 
-const removeObjByID = `-- name: RemoveObjByID :exec
+const linkObjDeveloper = `-- name: LinkObjDeveloper :many
+UPDATE mimix_obj
+SET developer_id = $1,
+    developer = (SELECT LOWER(username) FROM users WHERE id = $1),
+    updated_at = NOW(),
+    version = version + 1
+WHERE developer_id IS NULL AND LOWER(TRIM(developer)) = $2::TEXT
+RETURNING id, obj_ver, promote_date, developer
+`
+
+type LinkObjDeveloperParams struct {
+	UserID    uuid.NullUUID
+	Developer string
+}
+
+type LinkObjDeveloperRow struct {
+	ID          uuid.UUID
+	ObjVer      string
+	PromoteDate sql.NullTime
+	Developer   string
+}
+
+func (q *Queries) LinkObjDeveloper(ctx context.Context, arg LinkObjDeveloperParams) ([]LinkObjDeveloperRow, error) {
+	rows, err := q.db.QueryContext(ctx, linkObjDeveloper, arg.UserID, arg.Developer)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LinkObjDeveloperRow
+	for rows.Next() {
+		var i LinkObjDeveloperRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ObjVer,
+			&i.PromoteDate,
+			&i.Developer,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeletedObj = `-- name: ListDeletedObj :many
+SELECT id, obj, obj_type, promote_date, lib, lib_id, obj_ver, mimix_status, developer, keterangan, updated_at, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, rule_id, stage_id, developer_id
+FROM mimix_obj
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+`
+
+func (q *Queries) ListDeletedObj(ctx context.Context) ([]MimixObj, error) {
+	rows, err := q.db.QueryContext(ctx, listDeletedObj)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MimixObj
+	for rows.Next() {
+		var i MimixObj
+		if err := rows.Scan(
+			&i.ID,
+			&i.Obj,
+			&i.ObjType,
+			&i.PromoteDate,
+			&i.Lib,
+			&i.LibID,
+			&i.ObjVer,
+			&i.MimixStatus,
+			&i.Developer,
+			&i.Keterangan,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.ObjKind,
+			&i.PrcType,
+			&i.Subtree,
+			&i.RuleID,
+			&i.StageID,
+			&i.DeveloperID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listObjByDeveloper = `-- name: ListObjByDeveloper :many
+SELECT id, obj, obj_type, promote_date, lib, lib_id, obj_ver, mimix_status, developer, keterangan, updated_at, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, rule_id, stage_id, developer_id
+FROM mimix_obj
+WHERE developer_id = $1 AND deleted_at IS NULL
+ORDER BY updated_at DESC
+`
+
+func (q *Queries) ListObjByDeveloper(ctx context.Context, developerID uuid.NullUUID) ([]MimixObj, error) {
+	rows, err := q.db.QueryContext(ctx, listObjByDeveloper, developerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MimixObj
+	for rows.Next() {
+		var i MimixObj
+		if err := rows.Scan(
+			&i.ID,
+			&i.Obj,
+			&i.ObjType,
+			&i.PromoteDate,
+			&i.Lib,
+			&i.LibID,
+			&i.ObjVer,
+			&i.MimixStatus,
+			&i.Developer,
+			&i.Keterangan,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.ObjKind,
+			&i.PrcType,
+			&i.Subtree,
+			&i.RuleID,
+			&i.StageID,
+			&i.DeveloperID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listObjLibsByName = `-- name: ListObjLibsByName :many
+SELECT DISTINCT lib
+FROM mimix_obj
+WHERE obj = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) ListObjLibsByName(ctx context.Context, obj string) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listObjLibsByName, obj)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var lib string
+		if err := rows.Scan(&lib); err != nil {
+			return nil, err
+		}
+		items = append(items, lib)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStaleDaftarkanObj = `-- name: ListStaleDaftarkanObj :many
+SELECT id, obj, obj_type, promote_date, lib, lib_id, obj_ver, mimix_status, developer, keterangan, updated_at, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, rule_id, stage_id, developer_id
+FROM mimix_obj
+WHERE mimix_status = 'daftarkan' AND deleted_at IS NULL
+  AND promote_date < NOW() - make_interval(days => $1::int)
+ORDER BY promote_date
+`
+
+func (q *Queries) ListStaleDaftarkanObj(ctx context.Context, days int32) ([]MimixObj, error) {
+	rows, err := q.db.QueryContext(ctx, listStaleDaftarkanObj, days)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MimixObj
+	for rows.Next() {
+		var i MimixObj
+		if err := rows.Scan(
+			&i.ID,
+			&i.Obj,
+			&i.ObjType,
+			&i.PromoteDate,
+			&i.Lib,
+			&i.LibID,
+			&i.ObjVer,
+			&i.MimixStatus,
+			&i.Developer,
+			&i.Keterangan,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.ObjKind,
+			&i.PrcType,
+			&i.Subtree,
+			&i.RuleID,
+			&i.StageID,
+			&i.DeveloperID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeExpiredObj = `-- name: PurgeExpiredObj :execrows
+DELETE FROM mimix_obj
+WHERE deleted_at < NOW() - make_interval(days => $1::int)
+`
+
+func (q *Queries) PurgeExpiredObj(ctx context.Context, retentionDays int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeExpiredObj, retentionDays)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const purgeObjByID = `-- name: PurgeObjByID :execrows
 DELETE FROM mimix_obj
-WHERE id = $1
+WHERE id = $1 AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) PurgeObjByID(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeObjByID, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const reassignObjDeveloper = `-- name: ReassignObjDeveloper :many
+UPDATE mimix_obj
+SET developer_id = $1,
+    developer = (SELECT LOWER(username) FROM users WHERE id = $1),
+    updated_at = NOW(),
+    version = version + 1
+WHERE developer_id = $2 AND deleted_at IS NULL
+RETURNING id, obj_ver, promote_date, developer
+`
+
+type ReassignObjDeveloperParams struct {
+	ToID   uuid.NullUUID
+	FromID uuid.NullUUID
+}
+
+type ReassignObjDeveloperRow struct {
+	ID          uuid.UUID
+	ObjVer      string
+	PromoteDate sql.NullTime
+	Developer   string
+}
+
+func (q *Queries) ReassignObjDeveloper(ctx context.Context, arg ReassignObjDeveloperParams) ([]ReassignObjDeveloperRow, error) {
+	rows, err := q.db.QueryContext(ctx, reassignObjDeveloper, arg.ToID, arg.FromID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ReassignObjDeveloperRow
+	for rows.Next() {
+		var i ReassignObjDeveloperRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ObjVer,
+			&i.PromoteDate,
+			&i.Developer,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removeObjByID = `-- name: RemoveObjByID :execrows
+UPDATE mimix_obj
+SET deleted_at = NOW(), deleted_by = $2, updated_at = NOW(), version = version + 1
+WHERE id = $1 AND deleted_at IS NULL
 `
 
-func (q *Queries) RemoveObjByID(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, removeObjByID, id)
+type RemoveObjByIDParams struct {
+	ID        uuid.UUID
+	DeletedBy sql.NullString
+}
+
+func (q *Queries) RemoveObjByID(ctx context.Context, arg RemoveObjByIDParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, removeObjByID, arg.ID, arg.DeletedBy)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const resetObjCoverageByRule = `-- name: ResetObjCoverageByRule :exec
+UPDATE mimix_obj
+SET mimix_status = 'unset', rule_id = NULL, updated_at = NOW(), version = version + 1
+WHERE rule_id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) ResetObjCoverageByRule(ctx context.Context, ruleID uuid.NullUUID) error {
+	_, err := q.db.ExecContext(ctx, resetObjCoverageByRule, ruleID)
 	return err
 }
 
+const restoreObjByID = `-- name: RestoreObjByID :one
+UPDATE mimix_obj
+SET deleted_at = NULL, deleted_by = NULL, updated_at = NOW(), version = version + 1
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING id, obj, obj_type, promote_date, lib, lib_id, obj_ver, mimix_status, developer, keterangan, updated_at, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, rule_id, stage_id, developer_id
+`
+
+func (q *Queries) RestoreObjByID(ctx context.Context, id uuid.UUID) (MimixObj, error) {
+	row := q.db.QueryRowContext(ctx, restoreObjByID, id)
+	var i MimixObj
+	err := row.Scan(
+		&i.ID,
+		&i.Obj,
+		&i.ObjType,
+		&i.PromoteDate,
+		&i.Lib,
+		&i.LibID,
+		&i.ObjVer,
+		&i.MimixStatus,
+		&i.Developer,
+		&i.Keterangan,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.ObjKind,
+		&i.PrcType,
+		&i.Subtree,
+		&i.RuleID,
+		&i.StageID,
+		&i.DeveloperID,
+	)
+	return i, err
+}
+
 const searchMimixObj = `-- name: SearchMimixObj :many
-SELECT id, obj, obj_type, promote_date, lib, lib_id, obj_ver, mimix_status, developer, keterangan, updated_at
-FROM mimix_obj
+SELECT o.id, o.obj, o.obj_type, o.promote_date, o.lib, o.lib_id, o.obj_ver, o.mimix_status, o.developer, o.keterangan, o.updated_at, o.version, o.deleted_at, o.deleted_by, o.obj_kind, o.prc_type, o.subtree, o.rule_id, o.stage_id, o.developer_id
+FROM mimix_obj o
+LEFT JOIN users d ON d.id = o.developer_id
 WHERE
-    obj ILIKE '%' || $1 || '%'
- OR lib ILIKE '%' || $1 || '%'
- OR developer ILIKE '%' || $1 || '%'
-ORDER BY updated_at DESC
+    o.deleted_at IS NULL
+AND (
+    o.obj ILIKE '%' || $1 || '%'
+ OR o.lib ILIKE '%' || $1 || '%'
+ OR COALESCE(d.username, o.developer) ILIKE '%' || $1 || '%'
+)
+ORDER BY o.updated_at DESC
 `
 
 func (q *Queries) SearchMimixObj(ctx context.Context, dollar_1 sql.NullString) ([]MimixObj, error) {
@@ -231,6 +768,15 @@ func (q *Queries) SearchMimixObj(ctx context.Context, dollar_1 sql.NullString) (
 			&i.Developer,
 			&i.Keterangan,
 			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.ObjKind,
+			&i.PrcType,
+			&i.Subtree,
+			&i.RuleID,
+			&i.StageID,
+			&i.DeveloperID,
 		); err != nil {
 			return nil, err
 		}
@@ -245,10 +791,27 @@ func (q *Queries) SearchMimixObj(ctx context.Context, dollar_1 sql.NullString) (
 	return items, nil
 }
 
+const setObjCoverage = `-- name: SetObjCoverage :exec
+UPDATE mimix_obj
+SET mimix_status = $2, rule_id = $3, updated_at = NOW(), version = version + 1
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+type SetObjCoverageParams struct {
+	ID          uuid.UUID
+	MimixStatus MimixStatus
+	RuleID      uuid.NullUUID
+}
+
+func (q *Queries) SetObjCoverage(ctx context.Context, arg SetObjCoverageParams) error {
+	_, err := q.db.ExecContext(ctx, setObjCoverage, arg.ID, arg.MimixStatus, arg.RuleID)
+	return err
+}
+
 const updateMimixStatus = `-- name: UpdateMimixStatus :exec
 UPDATE mimix_obj
-SET mimix_status = $2, updated_at = NOW()
-WHERE id = $1
+SET mimix_status = $2, rule_id = NULL, updated_at = NOW(), version = version + 1
+WHERE id = $1 AND deleted_at IS NULL
 `
 
 type UpdateMimixStatusParams struct {
@@ -271,10 +834,17 @@ SET
     promote_date  = $6,
     mimix_status  = $7,
     developer     = $8,
+    developer_id  = (SELECT id FROM users WHERE LOWER(username) = LOWER($8)),
     keterangan    = $9,
-    updated_at    = NOW()
-WHERE id = $1
-RETURNING id, obj, obj_type, promote_date, lib, lib_id, obj_ver, mimix_status, developer, keterangan, updated_at
+    obj_kind      = $11,
+    prc_type      = $12,
+    subtree       = $13,
+    -- a status set by hand is no longer explained by a coverage rule
+    rule_id       = CASE WHEN mimix_status = $7 THEN rule_id END,
+    updated_at    = NOW(),
+    version       = version + 1
+WHERE id = $1 AND version = $10 AND deleted_at IS NULL
+RETURNING id, obj, obj_type, promote_date, lib, lib_id, obj_ver, mimix_status, developer, keterangan, updated_at, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, rule_id, stage_id, developer_id
 `
 
 type UpdateObjInfoParams struct {
@@ -287,6 +857,10 @@ type UpdateObjInfoParams struct {
 	MimixStatus MimixStatus
 	Developer   string
 	Keterangan  sql.NullString
+	Version     int32
+	ObjKind     ObjKind
+	PrcType     PrcType
+	Subtree     bool
 }
 
 func (q *Queries) UpdateObjInfo(ctx context.Context, arg UpdateObjInfoParams) (MimixObj, error) {
@@ -300,6 +874,10 @@ func (q *Queries) UpdateObjInfo(ctx context.Context, arg UpdateObjInfoParams) (M
 		arg.MimixStatus,
 		arg.Developer,
 		arg.Keterangan,
+		arg.Version,
+		arg.ObjKind,
+		arg.PrcType,
+		arg.Subtree,
 	)
 	var i MimixObj
 	err := row.Scan(
@@ -314,14 +892,23 @@ func (q *Queries) UpdateObjInfo(ctx context.Context, arg UpdateObjInfoParams) (M
 		&i.Developer,
 		&i.Keterangan,
 		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.ObjKind,
+		&i.PrcType,
+		&i.Subtree,
+		&i.RuleID,
+		&i.StageID,
+		&i.DeveloperID,
 	)
 	return i, err
 }
 
 const updateObjStatus = `-- name: UpdateObjStatus :exec
 UPDATE mimix_obj
-SET mimix_status = $2, updated_at = NOW()
-WHERE obj = $1
+SET mimix_status = $2, rule_id = NULL, updated_at = NOW(), version = version + 1
+WHERE obj = $1 AND deleted_at IS NULL
 RETURNING obj, mimix_status
 `
 
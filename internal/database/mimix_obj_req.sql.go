@@ -13,10 +13,204 @@ import (
 	"github.com/google/uuid"
 )
 
+const applyObjReqDeployment = `-- name: ApplyObjReqDeployment :one
+UPDATE mimix_obj_req
+SET promote_status = 'deployed', obj_ver = $2, stage_id = $3, updated_at = NOW(), version = version + 1
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, obj_name, requester, created_at, updated_at, lib, obj_ver, obj_type, promote_date, developer, promote_status, source_obj_id, req_status, ticket_id, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, stage_id, sla_policy_id, due_at, resolved_at, priority, assignee, assigned_by, assigned_at, developer_id, team_id, team_approval, team_approval_by, team_approval_at, team_approval_note
+`
+
+type ApplyObjReqDeploymentParams struct {
+	ID      uuid.UUID
+	ObjVer  string
+	StageID uuid.NullUUID
+}
+
+func (q *Queries) ApplyObjReqDeployment(ctx context.Context, arg ApplyObjReqDeploymentParams) (MimixObjReq, error) {
+	row := q.db.QueryRowContext(ctx, applyObjReqDeployment, arg.ID, arg.ObjVer, arg.StageID)
+	var i MimixObjReq
+	err := row.Scan(
+		&i.ID,
+		&i.ObjName,
+		&i.Requester,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Lib,
+		&i.ObjVer,
+		&i.ObjType,
+		&i.PromoteDate,
+		&i.Developer,
+		&i.PromoteStatus,
+		&i.SourceObjID,
+		&i.ReqStatus,
+		&i.TicketID,
+		&i.Version,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.ObjKind,
+		&i.PrcType,
+		&i.Subtree,
+		&i.StageID,
+		&i.SlaPolicyID,
+		&i.DueAt,
+		&i.ResolvedAt,
+		&i.Priority,
+		&i.Assignee,
+		&i.AssignedBy,
+		&i.AssignedAt,
+		&i.DeveloperID,
+		&i.TeamID,
+		&i.TeamApproval,
+		&i.TeamApprovalBy,
+		&i.TeamApprovalAt,
+		&i.TeamApprovalNote,
+	)
+	return i, err
+}
+
+const assignObjReq = `-- name: AssignObjReq :one
+UPDATE mimix_obj_req
+SET assignee = $1,
+    assigned_by = $2,
+    assigned_at = CASE WHEN $1::text IS NULL THEN NULL ELSE NOW() END,
+    updated_at = NOW(),
+    version = version + 1
+WHERE id = $3 AND req_status = 'pending' AND deleted_at IS NULL
+RETURNING id, obj_name, requester, created_at, updated_at, lib, obj_ver, obj_type, promote_date, developer, promote_status, source_obj_id, req_status, ticket_id, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, stage_id, sla_policy_id, due_at, resolved_at, priority, assignee, assigned_by, assigned_at, developer_id, team_id, team_approval, team_approval_by, team_approval_at, team_approval_note
+`
+
+type AssignObjReqParams struct {
+	Assignee   sql.NullString
+	AssignedBy sql.NullString
+	ID         uuid.UUID
+}
+
+func (q *Queries) AssignObjReq(ctx context.Context, arg AssignObjReqParams) (MimixObjReq, error) {
+	row := q.db.QueryRowContext(ctx, assignObjReq, arg.Assignee, arg.AssignedBy, arg.ID)
+	var i MimixObjReq
+	err := row.Scan(
+		&i.ID,
+		&i.ObjName,
+		&i.Requester,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Lib,
+		&i.ObjVer,
+		&i.ObjType,
+		&i.PromoteDate,
+		&i.Developer,
+		&i.PromoteStatus,
+		&i.SourceObjID,
+		&i.ReqStatus,
+		&i.TicketID,
+		&i.Version,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.ObjKind,
+		&i.PrcType,
+		&i.Subtree,
+		&i.StageID,
+		&i.SlaPolicyID,
+		&i.DueAt,
+		&i.ResolvedAt,
+		&i.Priority,
+		&i.Assignee,
+		&i.AssignedBy,
+		&i.AssignedAt,
+		&i.DeveloperID,
+		&i.TeamID,
+		&i.TeamApproval,
+		&i.TeamApprovalBy,
+		&i.TeamApprovalAt,
+		&i.TeamApprovalNote,
+	)
+	return i, err
+}
+
+const claimObjReq = `-- name: ClaimObjReq :one
+UPDATE mimix_obj_req
+SET assignee = $2, assigned_by = $2, assigned_at = NOW(), updated_at = NOW(), version = version + 1
+WHERE id = $1 AND req_status = 'pending' AND deleted_at IS NULL
+  AND (assignee IS NULL OR assignee = $2)
+RETURNING id, obj_name, requester, created_at, updated_at, lib, obj_ver, obj_type, promote_date, developer, promote_status, source_obj_id, req_status, ticket_id, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, stage_id, sla_policy_id, due_at, resolved_at, priority, assignee, assigned_by, assigned_at, developer_id, team_id, team_approval, team_approval_by, team_approval_at, team_approval_note
+`
+
+type ClaimObjReqParams struct {
+	ID       uuid.UUID
+	Assignee sql.NullString
+}
+
+func (q *Queries) ClaimObjReq(ctx context.Context, arg ClaimObjReqParams) (MimixObjReq, error) {
+	row := q.db.QueryRowContext(ctx, claimObjReq, arg.ID, arg.Assignee)
+	var i MimixObjReq
+	err := row.Scan(
+		&i.ID,
+		&i.ObjName,
+		&i.Requester,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Lib,
+		&i.ObjVer,
+		&i.ObjType,
+		&i.PromoteDate,
+		&i.Developer,
+		&i.PromoteStatus,
+		&i.SourceObjID,
+		&i.ReqStatus,
+		&i.TicketID,
+		&i.Version,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.ObjKind,
+		&i.PrcType,
+		&i.Subtree,
+		&i.StageID,
+		&i.SlaPolicyID,
+		&i.DueAt,
+		&i.ResolvedAt,
+		&i.Priority,
+		&i.Assignee,
+		&i.AssignedBy,
+		&i.AssignedAt,
+		&i.DeveloperID,
+		&i.TeamID,
+		&i.TeamApproval,
+		&i.TeamApprovalBy,
+		&i.TeamApprovalAt,
+		&i.TeamApprovalNote,
+	)
+	return i, err
+}
+
+const clearExpiredObjReqSources = `-- name: ClearExpiredObjReqSources :exec
+UPDATE mimix_obj_req
+SET source_obj_id = NULL
+WHERE source_obj_id IN (
+    SELECT id FROM mimix_obj
+    WHERE deleted_at < NOW() - make_interval(days => $1::int)
+)
+`
+
+func (q *Queries) ClearExpiredObjReqSources(ctx context.Context, retentionDays int32) error {
+	_, err := q.db.ExecContext(ctx, clearExpiredObjReqSources, retentionDays)
+	return err
+}
+
+const clearObjReqSource = `-- name: ClearObjReqSource :exec
+UPDATE mimix_obj_req
+SET source_obj_id = NULL
+WHERE source_obj_id = $1
+`
+
+func (q *Queries) ClearObjReqSource(ctx context.Context, sourceObjID uuid.NullUUID) error {
+	_, err := q.db.ExecContext(ctx, clearObjReqSource, sourceObjID)
+	return err
+}
+
 const completeMimixObjReq = `-- name: CompleteMimixObjReq :exec
 UPDATE mimix_obj_req
-SET req_status = 'completed', updated_at = NOW()
-WHERE id = $1
+SET req_status = 'completed', resolved_at = NOW(), updated_at = NOW(), version = version + 1
+WHERE id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) CompleteMimixObjReq(ctx context.Context, id uuid.UUID) error {
@@ -24,6 +218,41 @@ func (q *Queries) CompleteMimixObjReq(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const countObjReqByRequester = `-- name: CountObjReqByRequester :many
+SELECT req_status, COUNT(*) AS count
+FROM mimix_obj_req
+WHERE requester = $1 AND deleted_at IS NULL
+GROUP BY req_status
+`
+
+type CountObjReqByRequesterRow struct {
+	ReqStatus ReqStatus
+	Count     int64
+}
+
+func (q *Queries) CountObjReqByRequester(ctx context.Context, requester string) ([]CountObjReqByRequesterRow, error) {
+	rows, err := q.db.QueryContext(ctx, countObjReqByRequester, requester)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountObjReqByRequesterRow
+	for rows.Next() {
+		var i CountObjReqByRequesterRow
+		if err := rows.Scan(&i.ReqStatus, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createMimixObjReq = `-- name: CreateMimixObjReq :one
 INSERT INTO mimix_obj_req (
     obj_name,
@@ -33,13 +262,21 @@ INSERT INTO mimix_obj_req (
     obj_ver,
     obj_type,
     promote_date,
-    developer
+    developer,
+    ticket_id,
+    obj_kind,
+    prc_type,
+    subtree,
+    priority,
+    developer_id
 )
 VALUES (
     $1, $2, $3, $4,
-    $5, $6, $7, $8
+    $5, $6, $7, $8,
+    $9, $10, $11, $12,
+    $13, (SELECT id FROM users WHERE LOWER(username) = LOWER($8))
 )
-RETURNING id, obj_name, requester, req_status, lib, obj_ver, obj_type, promote_date, developer, created_at, updated_at
+RETURNING id, obj_name, requester, req_status, lib, obj_ver, obj_type, promote_date, developer, created_at, updated_at, ticket_id, version, obj_kind, prc_type, subtree, priority
 `
 
 type CreateMimixObjReqParams struct {
@@ -51,6 +288,11 @@ type CreateMimixObjReqParams struct {
 	ObjType     string
 	PromoteDate time.Time
 	Developer   sql.NullString
+	TicketID    uuid.NullUUID
+	ObjKind     ObjKind
+	PrcType     PrcType
+	Subtree     bool
+	Priority    ReqPriority
 }
 
 type CreateMimixObjReqRow struct {
@@ -65,6 +307,12 @@ type CreateMimixObjReqRow struct {
 	Developer   sql.NullString
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+	TicketID    uuid.NullUUID
+	Version     int32
+	ObjKind     ObjKind
+	PrcType     PrcType
+	Subtree     bool
+	Priority    ReqPriority
 }
 
 func (q *Queries) CreateMimixObjReq(ctx context.Context, arg CreateMimixObjReqParams) (CreateMimixObjReqRow, error) {
@@ -77,6 +325,11 @@ func (q *Queries) CreateMimixObjReq(ctx context.Context, arg CreateMimixObjReqPa
 		arg.ObjType,
 		arg.PromoteDate,
 		arg.Developer,
+		arg.TicketID,
+		arg.ObjKind,
+		arg.PrcType,
+		arg.Subtree,
+		arg.Priority,
 	)
 	var i CreateMimixObjReqRow
 	err := row.Scan(
@@ -91,13 +344,83 @@ func (q *Queries) CreateMimixObjReq(ctx context.Context, arg CreateMimixObjReqPa
 		&i.Developer,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TicketID,
+		&i.Version,
+		&i.ObjKind,
+		&i.PrcType,
+		&i.Subtree,
+		&i.Priority,
+	)
+	return i, err
+}
+
+const getDeletedMimixObjReqByID = `-- name: GetDeletedMimixObjReqByID :one
+SELECT id, obj_name, requester, created_at, updated_at, lib, obj_ver, obj_type, promote_date, developer, promote_status, source_obj_id, req_status, ticket_id, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, stage_id, sla_policy_id, due_at, resolved_at, priority, assignee, assigned_by, assigned_at, developer_id, team_id, team_approval, team_approval_by, team_approval_at, team_approval_note
+FROM mimix_obj_req
+WHERE id = $1 AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) GetDeletedMimixObjReqByID(ctx context.Context, id uuid.UUID) (MimixObjReq, error) {
+	row := q.db.QueryRowContext(ctx, getDeletedMimixObjReqByID, id)
+	var i MimixObjReq
+	err := row.Scan(
+		&i.ID,
+		&i.ObjName,
+		&i.Requester,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Lib,
+		&i.ObjVer,
+		&i.ObjType,
+		&i.PromoteDate,
+		&i.Developer,
+		&i.PromoteStatus,
+		&i.SourceObjID,
+		&i.ReqStatus,
+		&i.TicketID,
+		&i.Version,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.ObjKind,
+		&i.PrcType,
+		&i.Subtree,
+		&i.StageID,
+		&i.SlaPolicyID,
+		&i.DueAt,
+		&i.ResolvedAt,
+		&i.Priority,
+		&i.Assignee,
+		&i.AssignedBy,
+		&i.AssignedAt,
+		&i.DeveloperID,
+		&i.TeamID,
+		&i.TeamApproval,
+		&i.TeamApprovalBy,
+		&i.TeamApprovalAt,
+		&i.TeamApprovalNote,
 	)
 	return i, err
 }
 
+const getLastAutoAssignee = `-- name: GetLastAutoAssignee :one
+SELECT assignee
+FROM mimix_obj_req
+WHERE assigned_by = 'auto' AND assignee IS NOT NULL
+ORDER BY assigned_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLastAutoAssignee(ctx context.Context) (sql.NullString, error) {
+	row := q.db.QueryRowContext(ctx, getLastAutoAssignee)
+	var assignee sql.NullString
+	err := row.Scan(&assignee)
+	return assignee, err
+}
+
 const getMimixObjReq = `-- name: GetMimixObjReq :many
-SELECT id, obj_name, requester, created_at, updated_at, lib, obj_ver, obj_type, promote_date, developer, promote_status, source_obj_id, req_status
+SELECT id, obj_name, requester, created_at, updated_at, lib, obj_ver, obj_type, promote_date, developer, promote_status, source_obj_id, req_status, ticket_id, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, stage_id, sla_policy_id, due_at, resolved_at, priority, assignee, assigned_by, assigned_at, developer_id, team_id, team_approval, team_approval_by, team_approval_at, team_approval_note
 FROM mimix_obj_req
+WHERE deleted_at IS NULL
 `
 
 func (q *Queries) GetMimixObjReq(ctx context.Context) ([]MimixObjReq, error) {
@@ -123,6 +446,27 @@ func (q *Queries) GetMimixObjReq(ctx context.Context) ([]MimixObjReq, error) {
 			&i.PromoteStatus,
 			&i.SourceObjID,
 			&i.ReqStatus,
+			&i.TicketID,
+			&i.Version,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.ObjKind,
+			&i.PrcType,
+			&i.Subtree,
+			&i.StageID,
+			&i.SlaPolicyID,
+			&i.DueAt,
+			&i.ResolvedAt,
+			&i.Priority,
+			&i.Assignee,
+			&i.AssignedBy,
+			&i.AssignedAt,
+			&i.DeveloperID,
+			&i.TeamID,
+			&i.TeamApproval,
+			&i.TeamApprovalBy,
+			&i.TeamApprovalAt,
+			&i.TeamApprovalNote,
 		); err != nil {
 			return nil, err
 		}
@@ -138,9 +482,9 @@ func (q *Queries) GetMimixObjReq(ctx context.Context) ([]MimixObjReq, error) {
 }
 
 const getMimixObjReqByID = `-- name: GetMimixObjReqByID :one
-SELECT id, obj_name, requester, created_at, updated_at, lib, obj_ver, obj_type, promote_date, developer, promote_status, source_obj_id, req_status
+SELECT id, obj_name, requester, created_at, updated_at, lib, obj_ver, obj_type, promote_date, developer, promote_status, source_obj_id, req_status, ticket_id, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, stage_id, sla_policy_id, due_at, resolved_at, priority, assignee, assigned_by, assigned_at, developer_id, team_id, team_approval, team_approval_by, team_approval_at, team_approval_note
 FROM mimix_obj_req
-WHERE id = $1
+WHERE id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) GetMimixObjReqByID(ctx context.Context, id uuid.UUID) (MimixObjReq, error) {
@@ -160,14 +504,36 @@ func (q *Queries) GetMimixObjReqByID(ctx context.Context, id uuid.UUID) (MimixOb
 		&i.PromoteStatus,
 		&i.SourceObjID,
 		&i.ReqStatus,
+		&i.TicketID,
+		&i.Version,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.ObjKind,
+		&i.PrcType,
+		&i.Subtree,
+		&i.StageID,
+		&i.SlaPolicyID,
+		&i.DueAt,
+		&i.ResolvedAt,
+		&i.Priority,
+		&i.Assignee,
+		&i.AssignedBy,
+		&i.AssignedAt,
+		&i.DeveloperID,
+		&i.TeamID,
+		&i.TeamApproval,
+		&i.TeamApprovalBy,
+		&i.TeamApprovalAt,
+		&i.TeamApprovalNote,
 	)
 	return i, err
 }
 
 const getMimixObjReqByRequester = `-- name: GetMimixObjReqByRequester :many
-SELECT id, obj_name, requester, created_at, updated_at, lib, obj_ver, obj_type, promote_date, developer, promote_status, source_obj_id, req_status
+SELECT id, obj_name, requester, created_at, updated_at, lib, obj_ver, obj_type, promote_date, developer, promote_status, source_obj_id, req_status, ticket_id, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, stage_id, sla_policy_id, due_at, resolved_at, priority, assignee, assigned_by, assigned_at, developer_id, team_id, team_approval, team_approval_by, team_approval_at, team_approval_note
 FROM mimix_obj_req
-WHERE requester = $1
+WHERE requester = $1 AND deleted_at IS NULL
+ORDER BY priority DESC, created_at
 `
 
 func (q *Queries) GetMimixObjReqByRequester(ctx context.Context, requester string) ([]MimixObjReq, error) {
@@ -193,6 +559,92 @@ func (q *Queries) GetMimixObjReqByRequester(ctx context.Context, requester strin
 			&i.PromoteStatus,
 			&i.SourceObjID,
 			&i.ReqStatus,
+			&i.TicketID,
+			&i.Version,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.ObjKind,
+			&i.PrcType,
+			&i.Subtree,
+			&i.StageID,
+			&i.SlaPolicyID,
+			&i.DueAt,
+			&i.ResolvedAt,
+			&i.Priority,
+			&i.Assignee,
+			&i.AssignedBy,
+			&i.AssignedAt,
+			&i.DeveloperID,
+			&i.TeamID,
+			&i.TeamApproval,
+			&i.TeamApprovalBy,
+			&i.TeamApprovalAt,
+			&i.TeamApprovalNote,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMimixObjReqByTicketID = `-- name: GetMimixObjReqByTicketID :many
+SELECT id, obj_name, requester, created_at, updated_at, lib, obj_ver, obj_type, promote_date, developer, promote_status, source_obj_id, req_status, ticket_id, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, stage_id, sla_policy_id, due_at, resolved_at, priority, assignee, assigned_by, assigned_at, developer_id, team_id, team_approval, team_approval_by, team_approval_at, team_approval_note
+FROM mimix_obj_req
+WHERE ticket_id = $1 AND deleted_at IS NULL
+ORDER BY created_at
+`
+
+func (q *Queries) GetMimixObjReqByTicketID(ctx context.Context, ticketID uuid.NullUUID) ([]MimixObjReq, error) {
+	rows, err := q.db.QueryContext(ctx, getMimixObjReqByTicketID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MimixObjReq
+	for rows.Next() {
+		var i MimixObjReq
+		if err := rows.Scan(
+			&i.ID,
+			&i.ObjName,
+			&i.Requester,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Lib,
+			&i.ObjVer,
+			&i.ObjType,
+			&i.PromoteDate,
+			&i.Developer,
+			&i.PromoteStatus,
+			&i.SourceObjID,
+			&i.ReqStatus,
+			&i.TicketID,
+			&i.Version,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.ObjKind,
+			&i.PrcType,
+			&i.Subtree,
+			&i.StageID,
+			&i.SlaPolicyID,
+			&i.DueAt,
+			&i.ResolvedAt,
+			&i.Priority,
+			&i.Assignee,
+			&i.AssignedBy,
+			&i.AssignedAt,
+			&i.DeveloperID,
+			&i.TeamID,
+			&i.TeamApproval,
+			&i.TeamApprovalBy,
+			&i.TeamApprovalAt,
+			&i.TeamApprovalNote,
 		); err != nil {
 			return nil, err
 		}
@@ -208,9 +660,9 @@ func (q *Queries) GetMimixObjReqByRequester(ctx context.Context, requester strin
 }
 
 const getPendingObjReqByNameAndLib = `-- name: GetPendingObjReqByNameAndLib :one
-SELECT id, obj_name, requester, created_at, updated_at, lib, obj_ver, obj_type, promote_date, developer, promote_status, source_obj_id, req_status
+SELECT id, obj_name, requester, created_at, updated_at, lib, obj_ver, obj_type, promote_date, developer, promote_status, source_obj_id, req_status, ticket_id, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, stage_id, sla_policy_id, due_at, resolved_at, priority, assignee, assigned_by, assigned_at, developer_id, team_id, team_approval, team_approval_by, team_approval_at, team_approval_note
 FROM mimix_obj_req
-WHERE obj_name = $1 AND lib = $2 AND req_status = 'pending'
+WHERE obj_name = $1 AND lib = $2 AND req_status = 'pending' AND deleted_at IS NULL
 `
 
 type GetPendingObjReqByNameAndLibParams struct {
@@ -235,33 +687,63 @@ func (q *Queries) GetPendingObjReqByNameAndLib(ctx context.Context, arg GetPendi
 		&i.PromoteStatus,
 		&i.SourceObjID,
 		&i.ReqStatus,
+		&i.TicketID,
+		&i.Version,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.ObjKind,
+		&i.PrcType,
+		&i.Subtree,
+		&i.StageID,
+		&i.SlaPolicyID,
+		&i.DueAt,
+		&i.ResolvedAt,
+		&i.Priority,
+		&i.Assignee,
+		&i.AssignedBy,
+		&i.AssignedAt,
+		&i.DeveloperID,
+		&i.TeamID,
+		&i.TeamApproval,
+		&i.TeamApprovalBy,
+		&i.TeamApprovalAt,
+		&i.TeamApprovalNote,
 	)
 	return i, err
 }
 
-const removeMimixObjReq = `-- name: RemoveMimixObjReq :exec
-DELETE FROM mimix_obj_req
-WHERE id = $1
-RETURNING obj_name
+const linkObjReqDeveloper = `-- name: LinkObjReqDeveloper :execrows
+UPDATE mimix_obj_req
+SET developer_id = $1,
+    developer = (SELECT LOWER(username) FROM users WHERE id = $1),
+    updated_at = NOW(),
+    version = version + 1
+WHERE developer_id IS NULL AND LOWER(TRIM(developer)) = $2::TEXT
 `
 
-func (q *Queries) RemoveMimixObjReq(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, removeMimixObjReq, id)
-	return err
+type LinkObjReqDeveloperParams struct {
+	UserID    uuid.NullUUID
+	Developer string
 }
 
-const searchMimixObjReq = `-- name: SearchMimixObjReq :many
-SELECT id, obj_name, requester, created_at, updated_at, lib, obj_ver, obj_type, promote_date, developer, promote_status, source_obj_id, req_status FROM mimix_obj_req
-WHERE
-    obj_name ILIKE '%' || $1 || '%'
- OR requester ILIKE '%' || $1 || '%'
- OR developer ILIKE '%' || $1 || '%'
- OR lib ILIKE '%' || $1 || '%'
-ORDER BY updated_at DESC
+func (q *Queries) LinkObjReqDeveloper(ctx context.Context, arg LinkObjReqDeveloperParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, linkObjReqDeveloper, arg.UserID, arg.Developer)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const listAwaitingTeamApproval = `-- name: ListAwaitingTeamApproval :many
+SELECT r.id, r.obj_name, r.requester, r.created_at, r.updated_at, r.lib, r.obj_ver, r.obj_type, r.promote_date, r.developer, r.promote_status, r.source_obj_id, r.req_status, r.ticket_id, r.version, r.deleted_at, r.deleted_by, r.obj_kind, r.prc_type, r.subtree, r.stage_id, r.sla_policy_id, r.due_at, r.resolved_at, r.priority, r.assignee, r.assigned_by, r.assigned_at, r.developer_id, r.team_id, r.team_approval, r.team_approval_by, r.team_approval_at, r.team_approval_note
+FROM mimix_obj_req r
+JOIN team_member m ON m.team_id = r.team_id
+WHERE m.user_id = $1 AND m.is_lead AND r.team_approval = 'awaiting' AND r.deleted_at IS NULL
+ORDER BY r.priority DESC, r.created_at
 `
 
-func (q *Queries) SearchMimixObjReq(ctx context.Context, dollar_1 sql.NullString) ([]MimixObjReq, error) {
-	rows, err := q.db.QueryContext(ctx, searchMimixObjReq, dollar_1)
+func (q *Queries) ListAwaitingTeamApproval(ctx context.Context, userID uuid.UUID) ([]MimixObjReq, error) {
+	rows, err := q.db.QueryContext(ctx, listAwaitingTeamApproval, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -283,6 +765,27 @@ func (q *Queries) SearchMimixObjReq(ctx context.Context, dollar_1 sql.NullString
 			&i.PromoteStatus,
 			&i.SourceObjID,
 			&i.ReqStatus,
+			&i.TicketID,
+			&i.Version,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.ObjKind,
+			&i.PrcType,
+			&i.Subtree,
+			&i.StageID,
+			&i.SlaPolicyID,
+			&i.DueAt,
+			&i.ResolvedAt,
+			&i.Priority,
+			&i.Assignee,
+			&i.AssignedBy,
+			&i.AssignedAt,
+			&i.DeveloperID,
+			&i.TeamID,
+			&i.TeamApproval,
+			&i.TeamApprovalBy,
+			&i.TeamApprovalAt,
+			&i.TeamApprovalNote,
 		); err != nil {
 			return nil, err
 		}
@@ -297,49 +800,947 @@ func (q *Queries) SearchMimixObjReq(ctx context.Context, dollar_1 sql.NullString
 	return items, nil
 }
 
-const updateMimixObjReqInfo = `-- name: UpdateMimixObjReqInfo :one
-UPDATE mimix_obj_req
-SET obj_name = $2,
-    lib = $3,
-    obj_ver = $4,
-    obj_type = $5,
-    promote_date = $6,
-    developer = $7,
-    updated_at = NOW(),
-    promote_status = $8,
-    req_status = $9
-WHERE id = $1
-RETURNING id, obj_name, requester, req_status, lib, obj_ver, obj_type, promote_date, developer, created_at, updated_at, promote_status
+const listDeletedMimixObjReq = `-- name: ListDeletedMimixObjReq :many
+SELECT id, obj_name, requester, created_at, updated_at, lib, obj_ver, obj_type, promote_date, developer, promote_status, source_obj_id, req_status, ticket_id, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, stage_id, sla_policy_id, due_at, resolved_at, priority, assignee, assigned_by, assigned_at, developer_id, team_id, team_approval, team_approval_by, team_approval_at, team_approval_note
+FROM mimix_obj_req
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
 `
 
-type UpdateMimixObjReqInfoParams struct {
-	ID            uuid.UUID
-	ObjName       string
-	Lib           string
-	ObjVer        string
-	ObjType       string
-	PromoteDate   time.Time
-	Developer     sql.NullString
-	PromoteStatus NullPromoteStatus
-	ReqStatus     ReqStatus
-}
-
-type UpdateMimixObjReqInfoRow struct {
-	ID            uuid.UUID
-	ObjName       string
-	Requester     string
-	ReqStatus     ReqStatus
-	Lib           string
-	ObjVer        string
-	ObjType       string
-	PromoteDate   time.Time
-	Developer     sql.NullString
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	PromoteStatus NullPromoteStatus
+func (q *Queries) ListDeletedMimixObjReq(ctx context.Context) ([]MimixObjReq, error) {
+	rows, err := q.db.QueryContext(ctx, listDeletedMimixObjReq)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MimixObjReq
+	for rows.Next() {
+		var i MimixObjReq
+		if err := rows.Scan(
+			&i.ID,
+			&i.ObjName,
+			&i.Requester,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Lib,
+			&i.ObjVer,
+			&i.ObjType,
+			&i.PromoteDate,
+			&i.Developer,
+			&i.PromoteStatus,
+			&i.SourceObjID,
+			&i.ReqStatus,
+			&i.TicketID,
+			&i.Version,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.ObjKind,
+			&i.PrcType,
+			&i.Subtree,
+			&i.StageID,
+			&i.SlaPolicyID,
+			&i.DueAt,
+			&i.ResolvedAt,
+			&i.Priority,
+			&i.Assignee,
+			&i.AssignedBy,
+			&i.AssignedAt,
+			&i.DeveloperID,
+			&i.TeamID,
+			&i.TeamApproval,
+			&i.TeamApprovalBy,
+			&i.TeamApprovalAt,
+			&i.TeamApprovalNote,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-func (q *Queries) UpdateMimixObjReqInfo(ctx context.Context, arg UpdateMimixObjReqInfoParams) (UpdateMimixObjReqInfoRow, error) {
+const listObjLinkedRequests = `-- name: ListObjLinkedRequests :many
+SELECT id, obj_name, requester, created_at, updated_at, lib, obj_ver, obj_type, promote_date, developer, promote_status, source_obj_id, req_status, ticket_id, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, stage_id, sla_policy_id, due_at, resolved_at, priority, assignee, assigned_by, assigned_at, developer_id, team_id, team_approval, team_approval_by, team_approval_at, team_approval_note
+FROM mimix_obj_req
+WHERE deleted_at IS NULL
+  AND (
+    source_obj_id = $1::uuid
+    OR id IN (SELECT obj_req_id FROM object_versions WHERE obj_id = $1::uuid)
+  )
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListObjLinkedRequests(ctx context.Context, objID uuid.UUID) ([]MimixObjReq, error) {
+	rows, err := q.db.QueryContext(ctx, listObjLinkedRequests, objID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MimixObjReq
+	for rows.Next() {
+		var i MimixObjReq
+		if err := rows.Scan(
+			&i.ID,
+			&i.ObjName,
+			&i.Requester,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Lib,
+			&i.ObjVer,
+			&i.ObjType,
+			&i.PromoteDate,
+			&i.Developer,
+			&i.PromoteStatus,
+			&i.SourceObjID,
+			&i.ReqStatus,
+			&i.TicketID,
+			&i.Version,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.ObjKind,
+			&i.PrcType,
+			&i.Subtree,
+			&i.StageID,
+			&i.SlaPolicyID,
+			&i.DueAt,
+			&i.ResolvedAt,
+			&i.Priority,
+			&i.Assignee,
+			&i.AssignedBy,
+			&i.AssignedAt,
+			&i.DeveloperID,
+			&i.TeamID,
+			&i.TeamApproval,
+			&i.TeamApprovalBy,
+			&i.TeamApprovalAt,
+			&i.TeamApprovalNote,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listObjReqByAssignee = `-- name: ListObjReqByAssignee :many
+SELECT id, obj_name, requester, created_at, updated_at, lib, obj_ver, obj_type, promote_date, developer, promote_status, source_obj_id, req_status, ticket_id, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, stage_id, sla_policy_id, due_at, resolved_at, priority, assignee, assigned_by, assigned_at, developer_id, team_id, team_approval, team_approval_by, team_approval_at, team_approval_note
+FROM mimix_obj_req
+WHERE assignee = $1 AND req_status = 'pending' AND deleted_at IS NULL
+ORDER BY priority DESC, due_at NULLS LAST, created_at
+`
+
+func (q *Queries) ListObjReqByAssignee(ctx context.Context, assignee sql.NullString) ([]MimixObjReq, error) {
+	rows, err := q.db.QueryContext(ctx, listObjReqByAssignee, assignee)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MimixObjReq
+	for rows.Next() {
+		var i MimixObjReq
+		if err := rows.Scan(
+			&i.ID,
+			&i.ObjName,
+			&i.Requester,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Lib,
+			&i.ObjVer,
+			&i.ObjType,
+			&i.PromoteDate,
+			&i.Developer,
+			&i.PromoteStatus,
+			&i.SourceObjID,
+			&i.ReqStatus,
+			&i.TicketID,
+			&i.Version,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.ObjKind,
+			&i.PrcType,
+			&i.Subtree,
+			&i.StageID,
+			&i.SlaPolicyID,
+			&i.DueAt,
+			&i.ResolvedAt,
+			&i.Priority,
+			&i.Assignee,
+			&i.AssignedBy,
+			&i.AssignedAt,
+			&i.DeveloperID,
+			&i.TeamID,
+			&i.TeamApproval,
+			&i.TeamApprovalBy,
+			&i.TeamApprovalAt,
+			&i.TeamApprovalNote,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listObjReqDueForReminder = `-- name: ListObjReqDueForReminder :many
+SELECT id, obj_name, requester, created_at, updated_at, lib, obj_ver, obj_type, promote_date, developer, promote_status, source_obj_id, req_status, ticket_id, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, stage_id, sla_policy_id, due_at, resolved_at, priority, assignee, assigned_by, assigned_at, developer_id, team_id, team_approval, team_approval_by, team_approval_at, team_approval_note
+FROM mimix_obj_req
+WHERE req_status = 'pending' AND deleted_at IS NULL
+  AND promote_date >= NOW()
+  AND promote_date < NOW() + make_interval(days => $1::int)
+ORDER BY promote_date
+`
+
+func (q *Queries) ListObjReqDueForReminder(ctx context.Context, days int32) ([]MimixObjReq, error) {
+	rows, err := q.db.QueryContext(ctx, listObjReqDueForReminder, days)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MimixObjReq
+	for rows.Next() {
+		var i MimixObjReq
+		if err := rows.Scan(
+			&i.ID,
+			&i.ObjName,
+			&i.Requester,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Lib,
+			&i.ObjVer,
+			&i.ObjType,
+			&i.PromoteDate,
+			&i.Developer,
+			&i.PromoteStatus,
+			&i.SourceObjID,
+			&i.ReqStatus,
+			&i.TicketID,
+			&i.Version,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.ObjKind,
+			&i.PrcType,
+			&i.Subtree,
+			&i.StageID,
+			&i.SlaPolicyID,
+			&i.DueAt,
+			&i.ResolvedAt,
+			&i.Priority,
+			&i.Assignee,
+			&i.AssignedBy,
+			&i.AssignedAt,
+			&i.DeveloperID,
+			&i.TeamID,
+			&i.TeamApproval,
+			&i.TeamApprovalBy,
+			&i.TeamApprovalAt,
+			&i.TeamApprovalNote,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listObjReqPastPromoteDate = `-- name: ListObjReqPastPromoteDate :many
+SELECT id, obj_name, requester, created_at, updated_at, lib, obj_ver, obj_type, promote_date, developer, promote_status, source_obj_id, req_status, ticket_id, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, stage_id, sla_policy_id, due_at, resolved_at, priority, assignee, assigned_by, assigned_at, developer_id, team_id, team_approval, team_approval_by, team_approval_at, team_approval_note
+FROM mimix_obj_req
+WHERE req_status = 'pending' AND deleted_at IS NULL AND promote_date < NOW()
+ORDER BY promote_date
+`
+
+func (q *Queries) ListObjReqPastPromoteDate(ctx context.Context) ([]MimixObjReq, error) {
+	rows, err := q.db.QueryContext(ctx, listObjReqPastPromoteDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MimixObjReq
+	for rows.Next() {
+		var i MimixObjReq
+		if err := rows.Scan(
+			&i.ID,
+			&i.ObjName,
+			&i.Requester,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Lib,
+			&i.ObjVer,
+			&i.ObjType,
+			&i.PromoteDate,
+			&i.Developer,
+			&i.PromoteStatus,
+			&i.SourceObjID,
+			&i.ReqStatus,
+			&i.TicketID,
+			&i.Version,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.ObjKind,
+			&i.PrcType,
+			&i.Subtree,
+			&i.StageID,
+			&i.SlaPolicyID,
+			&i.DueAt,
+			&i.ResolvedAt,
+			&i.Priority,
+			&i.Assignee,
+			&i.AssignedBy,
+			&i.AssignedAt,
+			&i.DeveloperID,
+			&i.TeamID,
+			&i.TeamApproval,
+			&i.TeamApprovalBy,
+			&i.TeamApprovalAt,
+			&i.TeamApprovalNote,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPendingObjReq = `-- name: ListPendingObjReq :many
+SELECT id, obj_name, requester, created_at, updated_at, lib, obj_ver, obj_type, promote_date, developer, promote_status, source_obj_id, req_status, ticket_id, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, stage_id, sla_policy_id, due_at, resolved_at, priority, assignee, assigned_by, assigned_at, developer_id, team_id, team_approval, team_approval_by, team_approval_at, team_approval_note
+FROM mimix_obj_req
+WHERE req_status = 'pending' AND deleted_at IS NULL
+ORDER BY created_at
+`
+
+func (q *Queries) ListPendingObjReq(ctx context.Context) ([]MimixObjReq, error) {
+	rows, err := q.db.QueryContext(ctx, listPendingObjReq)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MimixObjReq
+	for rows.Next() {
+		var i MimixObjReq
+		if err := rows.Scan(
+			&i.ID,
+			&i.ObjName,
+			&i.Requester,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Lib,
+			&i.ObjVer,
+			&i.ObjType,
+			&i.PromoteDate,
+			&i.Developer,
+			&i.PromoteStatus,
+			&i.SourceObjID,
+			&i.ReqStatus,
+			&i.TicketID,
+			&i.Version,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.ObjKind,
+			&i.PrcType,
+			&i.Subtree,
+			&i.StageID,
+			&i.SlaPolicyID,
+			&i.DueAt,
+			&i.ResolvedAt,
+			&i.Priority,
+			&i.Assignee,
+			&i.AssignedBy,
+			&i.AssignedAt,
+			&i.DeveloperID,
+			&i.TeamID,
+			&i.TeamApproval,
+			&i.TeamApprovalBy,
+			&i.TeamApprovalAt,
+			&i.TeamApprovalNote,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPendingObjReqForDeployment = `-- name: ListPendingObjReqForDeployment :many
+SELECT id, obj_name, requester, created_at, updated_at, lib, obj_ver, obj_type, promote_date, developer, promote_status, source_obj_id, req_status, ticket_id, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, stage_id, sla_policy_id, due_at, resolved_at, priority, assignee, assigned_by, assigned_at, developer_id, team_id, team_approval, team_approval_by, team_approval_at, team_approval_note
+FROM mimix_obj_req
+WHERE obj_kind = $1 AND obj_name = $2 AND lib = $3 AND obj_type = $4
+  AND req_status = 'pending' AND deleted_at IS NULL
+ORDER BY created_at
+`
+
+type ListPendingObjReqForDeploymentParams struct {
+	ObjKind ObjKind
+	ObjName string
+	Lib     string
+	ObjType string
+}
+
+func (q *Queries) ListPendingObjReqForDeployment(ctx context.Context, arg ListPendingObjReqForDeploymentParams) ([]MimixObjReq, error) {
+	rows, err := q.db.QueryContext(ctx, listPendingObjReqForDeployment,
+		arg.ObjKind,
+		arg.ObjName,
+		arg.Lib,
+		arg.ObjType,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MimixObjReq
+	for rows.Next() {
+		var i MimixObjReq
+		if err := rows.Scan(
+			&i.ID,
+			&i.ObjName,
+			&i.Requester,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Lib,
+			&i.ObjVer,
+			&i.ObjType,
+			&i.PromoteDate,
+			&i.Developer,
+			&i.PromoteStatus,
+			&i.SourceObjID,
+			&i.ReqStatus,
+			&i.TicketID,
+			&i.Version,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.ObjKind,
+			&i.PrcType,
+			&i.Subtree,
+			&i.StageID,
+			&i.SlaPolicyID,
+			&i.DueAt,
+			&i.ResolvedAt,
+			&i.Priority,
+			&i.Assignee,
+			&i.AssignedBy,
+			&i.AssignedAt,
+			&i.DeveloperID,
+			&i.TeamID,
+			&i.TeamApproval,
+			&i.TeamApprovalBy,
+			&i.TeamApprovalAt,
+			&i.TeamApprovalNote,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnassignedObjReq = `-- name: ListUnassignedObjReq :many
+SELECT id, obj_name, requester, created_at, updated_at, lib, obj_ver, obj_type, promote_date, developer, promote_status, source_obj_id, req_status, ticket_id, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, stage_id, sla_policy_id, due_at, resolved_at, priority, assignee, assigned_by, assigned_at, developer_id, team_id, team_approval, team_approval_by, team_approval_at, team_approval_note
+FROM mimix_obj_req
+WHERE assignee IS NULL AND req_status = 'pending' AND deleted_at IS NULL
+ORDER BY priority DESC, due_at NULLS LAST, created_at
+`
+
+func (q *Queries) ListUnassignedObjReq(ctx context.Context) ([]MimixObjReq, error) {
+	rows, err := q.db.QueryContext(ctx, listUnassignedObjReq)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MimixObjReq
+	for rows.Next() {
+		var i MimixObjReq
+		if err := rows.Scan(
+			&i.ID,
+			&i.ObjName,
+			&i.Requester,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Lib,
+			&i.ObjVer,
+			&i.ObjType,
+			&i.PromoteDate,
+			&i.Developer,
+			&i.PromoteStatus,
+			&i.SourceObjID,
+			&i.ReqStatus,
+			&i.TicketID,
+			&i.Version,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.ObjKind,
+			&i.PrcType,
+			&i.Subtree,
+			&i.StageID,
+			&i.SlaPolicyID,
+			&i.DueAt,
+			&i.ResolvedAt,
+			&i.Priority,
+			&i.Assignee,
+			&i.AssignedBy,
+			&i.AssignedAt,
+			&i.DeveloperID,
+			&i.TeamID,
+			&i.TeamApproval,
+			&i.TeamApprovalBy,
+			&i.TeamApprovalAt,
+			&i.TeamApprovalNote,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeExpiredMimixObjReq = `-- name: PurgeExpiredMimixObjReq :execrows
+DELETE FROM mimix_obj_req
+WHERE deleted_at < NOW() - make_interval(days => $1::int)
+`
+
+func (q *Queries) PurgeExpiredMimixObjReq(ctx context.Context, retentionDays int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeExpiredMimixObjReq, retentionDays)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const purgeMimixObjReq = `-- name: PurgeMimixObjReq :execrows
+DELETE FROM mimix_obj_req
+WHERE id = $1 AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) PurgeMimixObjReq(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeMimixObjReq, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const reassignObjReqDeveloper = `-- name: ReassignObjReqDeveloper :execrows
+UPDATE mimix_obj_req
+SET developer_id = $1,
+    developer = (SELECT LOWER(username) FROM users WHERE id = $1),
+    updated_at = NOW(),
+    version = version + 1
+WHERE developer_id = $2 AND req_status = 'pending' AND deleted_at IS NULL
+`
+
+type ReassignObjReqDeveloperParams struct {
+	ToID   uuid.NullUUID
+	FromID uuid.NullUUID
+}
+
+func (q *Queries) ReassignObjReqDeveloper(ctx context.Context, arg ReassignObjReqDeveloperParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, reassignObjReqDeveloper, arg.ToID, arg.FromID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const rejectMimixObjReq = `-- name: RejectMimixObjReq :exec
+UPDATE mimix_obj_req
+SET req_status = 'rejected', resolved_at = NOW(), updated_at = NOW(), version = version + 1
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) RejectMimixObjReq(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, rejectMimixObjReq, id)
+	return err
+}
+
+const removeMimixObjReq = `-- name: RemoveMimixObjReq :execrows
+UPDATE mimix_obj_req
+SET deleted_at = NOW(), deleted_by = $2, updated_at = NOW(), version = version + 1
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+type RemoveMimixObjReqParams struct {
+	ID        uuid.UUID
+	DeletedBy sql.NullString
+}
+
+func (q *Queries) RemoveMimixObjReq(ctx context.Context, arg RemoveMimixObjReqParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, removeMimixObjReq, arg.ID, arg.DeletedBy)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreMimixObjReq = `-- name: RestoreMimixObjReq :one
+UPDATE mimix_obj_req
+SET deleted_at = NULL, deleted_by = NULL, updated_at = NOW(), version = version + 1
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING id, obj_name, requester, created_at, updated_at, lib, obj_ver, obj_type, promote_date, developer, promote_status, source_obj_id, req_status, ticket_id, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, stage_id, sla_policy_id, due_at, resolved_at, priority, assignee, assigned_by, assigned_at, developer_id, team_id, team_approval, team_approval_by, team_approval_at, team_approval_note
+`
+
+func (q *Queries) RestoreMimixObjReq(ctx context.Context, id uuid.UUID) (MimixObjReq, error) {
+	row := q.db.QueryRowContext(ctx, restoreMimixObjReq, id)
+	var i MimixObjReq
+	err := row.Scan(
+		&i.ID,
+		&i.ObjName,
+		&i.Requester,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Lib,
+		&i.ObjVer,
+		&i.ObjType,
+		&i.PromoteDate,
+		&i.Developer,
+		&i.PromoteStatus,
+		&i.SourceObjID,
+		&i.ReqStatus,
+		&i.TicketID,
+		&i.Version,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.ObjKind,
+		&i.PrcType,
+		&i.Subtree,
+		&i.StageID,
+		&i.SlaPolicyID,
+		&i.DueAt,
+		&i.ResolvedAt,
+		&i.Priority,
+		&i.Assignee,
+		&i.AssignedBy,
+		&i.AssignedAt,
+		&i.DeveloperID,
+		&i.TeamID,
+		&i.TeamApproval,
+		&i.TeamApprovalBy,
+		&i.TeamApprovalAt,
+		&i.TeamApprovalNote,
+	)
+	return i, err
+}
+
+const searchMimixObjReq = `-- name: SearchMimixObjReq :many
+SELECT r.id, r.obj_name, r.requester, r.created_at, r.updated_at, r.lib, r.obj_ver, r.obj_type, r.promote_date, r.developer, r.promote_status, r.source_obj_id, r.req_status, r.ticket_id, r.version, r.deleted_at, r.deleted_by, r.obj_kind, r.prc_type, r.subtree, r.stage_id, r.sla_policy_id, r.due_at, r.resolved_at, r.priority, r.assignee, r.assigned_by, r.assigned_at, r.developer_id, r.team_id, r.team_approval, r.team_approval_by, r.team_approval_at, r.team_approval_note FROM mimix_obj_req r
+LEFT JOIN users d ON d.id = r.developer_id
+WHERE
+    r.deleted_at IS NULL
+AND (
+    r.obj_name ILIKE '%' || $1 || '%'
+ OR r.requester ILIKE '%' || $1 || '%'
+ OR COALESCE(d.username, r.developer) ILIKE '%' || $1 || '%'
+ OR r.lib ILIKE '%' || $1 || '%'
+)
+ORDER BY r.priority DESC, r.created_at
+`
+
+func (q *Queries) SearchMimixObjReq(ctx context.Context, dollar_1 sql.NullString) ([]MimixObjReq, error) {
+	rows, err := q.db.QueryContext(ctx, searchMimixObjReq, dollar_1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MimixObjReq
+	for rows.Next() {
+		var i MimixObjReq
+		if err := rows.Scan(
+			&i.ID,
+			&i.ObjName,
+			&i.Requester,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Lib,
+			&i.ObjVer,
+			&i.ObjType,
+			&i.PromoteDate,
+			&i.Developer,
+			&i.PromoteStatus,
+			&i.SourceObjID,
+			&i.ReqStatus,
+			&i.TicketID,
+			&i.Version,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.ObjKind,
+			&i.PrcType,
+			&i.Subtree,
+			&i.StageID,
+			&i.SlaPolicyID,
+			&i.DueAt,
+			&i.ResolvedAt,
+			&i.Priority,
+			&i.Assignee,
+			&i.AssignedBy,
+			&i.AssignedAt,
+			&i.DeveloperID,
+			&i.TeamID,
+			&i.TeamApproval,
+			&i.TeamApprovalBy,
+			&i.TeamApprovalAt,
+			&i.TeamApprovalNote,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setMimixObjReqTicket = `-- name: SetMimixObjReqTicket :execrows
+UPDATE mimix_obj_req
+SET ticket_id = $2, updated_at = NOW(), version = version + 1
+WHERE id = $1 AND deleted_at IS NULL AND (ticket_id IS NULL OR ticket_id = $2)
+`
+
+type SetMimixObjReqTicketParams struct {
+	ID       uuid.UUID
+	TicketID uuid.NullUUID
+}
+
+func (q *Queries) SetMimixObjReqTicket(ctx context.Context, arg SetMimixObjReqTicketParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setMimixObjReqTicket, arg.ID, arg.TicketID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setObjReqSLA = `-- name: SetObjReqSLA :exec
+UPDATE mimix_obj_req
+SET sla_policy_id = $2, due_at = $3
+WHERE id = $1
+`
+
+type SetObjReqSLAParams struct {
+	ID          uuid.UUID
+	SlaPolicyID uuid.NullUUID
+	DueAt       sql.NullTime
+}
+
+func (q *Queries) SetObjReqSLA(ctx context.Context, arg SetObjReqSLAParams) error {
+	_, err := q.db.ExecContext(ctx, setObjReqSLA, arg.ID, arg.SlaPolicyID, arg.DueAt)
+	return err
+}
+
+const setObjReqTeam = `-- name: SetObjReqTeam :one
+UPDATE mimix_obj_req
+SET team_id = $2,
+    team_approval = $3,
+    team_approval_by = NULL,
+    team_approval_at = NULL,
+    team_approval_note = NULL,
+    updated_at = NOW(),
+    version = version + 1
+WHERE id = $1
+RETURNING id, obj_name, requester, created_at, updated_at, lib, obj_ver, obj_type, promote_date, developer, promote_status, source_obj_id, req_status, ticket_id, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, stage_id, sla_policy_id, due_at, resolved_at, priority, assignee, assigned_by, assigned_at, developer_id, team_id, team_approval, team_approval_by, team_approval_at, team_approval_note
+`
+
+type SetObjReqTeamParams struct {
+	ID           uuid.UUID
+	TeamID       uuid.NullUUID
+	TeamApproval NullTeamApproval
+}
+
+func (q *Queries) SetObjReqTeam(ctx context.Context, arg SetObjReqTeamParams) (MimixObjReq, error) {
+	row := q.db.QueryRowContext(ctx, setObjReqTeam, arg.ID, arg.TeamID, arg.TeamApproval)
+	var i MimixObjReq
+	err := row.Scan(
+		&i.ID,
+		&i.ObjName,
+		&i.Requester,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Lib,
+		&i.ObjVer,
+		&i.ObjType,
+		&i.PromoteDate,
+		&i.Developer,
+		&i.PromoteStatus,
+		&i.SourceObjID,
+		&i.ReqStatus,
+		&i.TicketID,
+		&i.Version,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.ObjKind,
+		&i.PrcType,
+		&i.Subtree,
+		&i.StageID,
+		&i.SlaPolicyID,
+		&i.DueAt,
+		&i.ResolvedAt,
+		&i.Priority,
+		&i.Assignee,
+		&i.AssignedBy,
+		&i.AssignedAt,
+		&i.DeveloperID,
+		&i.TeamID,
+		&i.TeamApproval,
+		&i.TeamApprovalBy,
+		&i.TeamApprovalAt,
+		&i.TeamApprovalNote,
+	)
+	return i, err
+}
+
+const setObjReqTeamApproval = `-- name: SetObjReqTeamApproval :one
+UPDATE mimix_obj_req
+SET team_approval = $2,
+    team_approval_by = $3,
+    team_approval_at = NOW(),
+    team_approval_note = $4,
+    updated_at = NOW(),
+    version = version + 1
+WHERE id = $1 AND team_approval = 'awaiting' AND deleted_at IS NULL
+RETURNING id, obj_name, requester, created_at, updated_at, lib, obj_ver, obj_type, promote_date, developer, promote_status, source_obj_id, req_status, ticket_id, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, stage_id, sla_policy_id, due_at, resolved_at, priority, assignee, assigned_by, assigned_at, developer_id, team_id, team_approval, team_approval_by, team_approval_at, team_approval_note
+`
+
+type SetObjReqTeamApprovalParams struct {
+	ID               uuid.UUID
+	TeamApproval     NullTeamApproval
+	TeamApprovalBy   sql.NullString
+	TeamApprovalNote sql.NullString
+}
+
+func (q *Queries) SetObjReqTeamApproval(ctx context.Context, arg SetObjReqTeamApprovalParams) (MimixObjReq, error) {
+	row := q.db.QueryRowContext(ctx, setObjReqTeamApproval,
+		arg.ID,
+		arg.TeamApproval,
+		arg.TeamApprovalBy,
+		arg.TeamApprovalNote,
+	)
+	var i MimixObjReq
+	err := row.Scan(
+		&i.ID,
+		&i.ObjName,
+		&i.Requester,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Lib,
+		&i.ObjVer,
+		&i.ObjType,
+		&i.PromoteDate,
+		&i.Developer,
+		&i.PromoteStatus,
+		&i.SourceObjID,
+		&i.ReqStatus,
+		&i.TicketID,
+		&i.Version,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.ObjKind,
+		&i.PrcType,
+		&i.Subtree,
+		&i.StageID,
+		&i.SlaPolicyID,
+		&i.DueAt,
+		&i.ResolvedAt,
+		&i.Priority,
+		&i.Assignee,
+		&i.AssignedBy,
+		&i.AssignedAt,
+		&i.DeveloperID,
+		&i.TeamID,
+		&i.TeamApproval,
+		&i.TeamApprovalBy,
+		&i.TeamApprovalAt,
+		&i.TeamApprovalNote,
+	)
+	return i, err
+}
+
+const updateMimixObjReqInfo = `-- name: UpdateMimixObjReqInfo :one
+UPDATE mimix_obj_req
+SET obj_name = $2,
+    lib = $3,
+    obj_ver = $4,
+    obj_type = $5,
+    promote_date = $6,
+    developer = $7,
+    developer_id = (SELECT id FROM users WHERE LOWER(username) = LOWER($7)),
+    updated_at = NOW(),
+    promote_status = $8,
+    req_status = $9,
+    resolved_at = CASE WHEN $9 = 'pending' THEN NULL ELSE COALESCE(resolved_at, NOW()) END,
+    obj_kind = $11,
+    prc_type = $12,
+    subtree = $13,
+    priority = $14,
+    version = version + 1
+WHERE id = $1 AND version = $10 AND deleted_at IS NULL
+RETURNING id, obj_name, requester, created_at, updated_at, lib, obj_ver, obj_type, promote_date, developer, promote_status, source_obj_id, req_status, ticket_id, version, deleted_at, deleted_by, obj_kind, prc_type, subtree, stage_id, sla_policy_id, due_at, resolved_at, priority, assignee, assigned_by, assigned_at, developer_id, team_id, team_approval, team_approval_by, team_approval_at, team_approval_note
+`
+
+type UpdateMimixObjReqInfoParams struct {
+	ID            uuid.UUID
+	ObjName       string
+	Lib           string
+	ObjVer        string
+	ObjType       string
+	PromoteDate   time.Time
+	Developer     sql.NullString
+	PromoteStatus NullPromoteStatus
+	ReqStatus     ReqStatus
+	Version       int32
+	ObjKind       ObjKind
+	PrcType       PrcType
+	Subtree       bool
+	Priority      ReqPriority
+}
+
+func (q *Queries) UpdateMimixObjReqInfo(ctx context.Context, arg UpdateMimixObjReqInfoParams) (MimixObjReq, error) {
 	row := q.db.QueryRowContext(ctx, updateMimixObjReqInfo,
 		arg.ID,
 		arg.ObjName,
@@ -350,29 +1751,58 @@ func (q *Queries) UpdateMimixObjReqInfo(ctx context.Context, arg UpdateMimixObjR
 		arg.Developer,
 		arg.PromoteStatus,
 		arg.ReqStatus,
+		arg.Version,
+		arg.ObjKind,
+		arg.PrcType,
+		arg.Subtree,
+		arg.Priority,
 	)
-	var i UpdateMimixObjReqInfoRow
+	var i MimixObjReq
 	err := row.Scan(
 		&i.ID,
 		&i.ObjName,
 		&i.Requester,
-		&i.ReqStatus,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 		&i.Lib,
 		&i.ObjVer,
 		&i.ObjType,
 		&i.PromoteDate,
 		&i.Developer,
-		&i.CreatedAt,
-		&i.UpdatedAt,
 		&i.PromoteStatus,
+		&i.SourceObjID,
+		&i.ReqStatus,
+		&i.TicketID,
+		&i.Version,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.ObjKind,
+		&i.PrcType,
+		&i.Subtree,
+		&i.StageID,
+		&i.SlaPolicyID,
+		&i.DueAt,
+		&i.ResolvedAt,
+		&i.Priority,
+		&i.Assignee,
+		&i.AssignedBy,
+		&i.AssignedAt,
+		&i.DeveloperID,
+		&i.TeamID,
+		&i.TeamApproval,
+		&i.TeamApprovalBy,
+		&i.TeamApprovalAt,
+		&i.TeamApprovalNote,
 	)
 	return i, err
 }
 
 const updateMimixObjReqStatus = `-- name: UpdateMimixObjReqStatus :exec
 UPDATE mimix_obj_req
-SET req_status = $1
-WHERE id = $2
+SET req_status = $1,
+    resolved_at = CASE WHEN $1 = 'pending' THEN NULL ELSE COALESCE(resolved_at, NOW()) END,
+    version = version + 1
+WHERE id = $2 AND deleted_at IS NULL
 `
 
 type UpdateMimixObjReqStatusParams struct {
@@ -387,8 +1817,8 @@ func (q *Queries) UpdateMimixObjReqStatus(ctx context.Context, arg UpdateMimixOb
 
 const updatePromoteStatus = `-- name: UpdatePromoteStatus :exec
 UPDATE mimix_obj_req
-SET promote_status = $2
-WHERE id = $1
+SET promote_status = $2, version = version + 1
+WHERE id = $1 AND deleted_at IS NULL
 `
 
 type UpdatePromoteStatusParams struct {
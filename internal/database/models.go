@@ -13,6 +13,311 @@ import (
 	"github.com/google/uuid"
 )
 
+type CommentChange string
+
+const (
+	CommentChangeEdited  CommentChange = "edited"
+	CommentChangeDeleted CommentChange = "deleted"
+)
+
+func (e *CommentChange) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = CommentChange(s)
+	case string:
+		*e = CommentChange(s)
+	default:
+		return fmt.Errorf("unsupported scan type for CommentChange: %T", src)
+	}
+	return nil
+}
+
+type NullCommentChange struct {
+	CommentChange CommentChange
+	Valid         bool // Valid is true if CommentChange is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullCommentChange) Scan(value interface{}) error {
+	if value == nil {
+		ns.CommentChange, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.CommentChange.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullCommentChange) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.CommentChange), nil
+}
+
+type DepSource string
+
+const (
+	DepSourceManual    DepSource = "manual"
+	DepSourceDsppgmref DepSource = "dsppgmref"
+	DepSourceDspdbr    DepSource = "dspdbr"
+)
+
+func (e *DepSource) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DepSource(s)
+	case string:
+		*e = DepSource(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DepSource: %T", src)
+	}
+	return nil
+}
+
+type NullDepSource struct {
+	DepSource DepSource
+	Valid     bool // Valid is true if DepSource is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDepSource) Scan(value interface{}) error {
+	if value == nil {
+		ns.DepSource, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DepSource.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDepSource) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DepSource), nil
+}
+
+type DeploymentEventStatus string
+
+const (
+	DeploymentEventStatusMatched   DeploymentEventStatus = "matched"
+	DeploymentEventStatusUnmatched DeploymentEventStatus = "unmatched"
+	DeploymentEventStatusAmbiguous DeploymentEventStatus = "ambiguous"
+	DeploymentEventStatusResolved  DeploymentEventStatus = "resolved"
+	DeploymentEventStatusDismissed DeploymentEventStatus = "dismissed"
+)
+
+func (e *DeploymentEventStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DeploymentEventStatus(s)
+	case string:
+		*e = DeploymentEventStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DeploymentEventStatus: %T", src)
+	}
+	return nil
+}
+
+type NullDeploymentEventStatus struct {
+	DeploymentEventStatus DeploymentEventStatus
+	Valid                 bool // Valid is true if DeploymentEventStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDeploymentEventStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.DeploymentEventStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DeploymentEventStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDeploymentEventStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DeploymentEventStatus), nil
+}
+
+type DeveloperMatchMethod string
+
+const (
+	DeveloperMatchMethodExact      DeveloperMatchMethod = "exact"
+	DeveloperMatchMethodNormalized DeveloperMatchMethod = "normalized"
+	DeveloperMatchMethodFuzzy      DeveloperMatchMethod = "fuzzy"
+	DeveloperMatchMethodManual     DeveloperMatchMethod = "manual"
+	DeveloperMatchMethodUnmatched  DeveloperMatchMethod = "unmatched"
+)
+
+func (e *DeveloperMatchMethod) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DeveloperMatchMethod(s)
+	case string:
+		*e = DeveloperMatchMethod(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DeveloperMatchMethod: %T", src)
+	}
+	return nil
+}
+
+type NullDeveloperMatchMethod struct {
+	DeveloperMatchMethod DeveloperMatchMethod
+	Valid                bool // Valid is true if DeveloperMatchMethod is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDeveloperMatchMethod) Scan(value interface{}) error {
+	if value == nil {
+		ns.DeveloperMatchMethod, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DeveloperMatchMethod.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDeveloperMatchMethod) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DeveloperMatchMethod), nil
+}
+
+type EmergencyReviewStatus string
+
+const (
+	EmergencyReviewStatusAwaitingJustification EmergencyReviewStatus = "awaiting_justification"
+	EmergencyReviewStatusAwaitingReview        EmergencyReviewStatus = "awaiting_review"
+	EmergencyReviewStatusAccepted              EmergencyReviewStatus = "accepted"
+	EmergencyReviewStatusRejected              EmergencyReviewStatus = "rejected"
+)
+
+func (e *EmergencyReviewStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = EmergencyReviewStatus(s)
+	case string:
+		*e = EmergencyReviewStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for EmergencyReviewStatus: %T", src)
+	}
+	return nil
+}
+
+type NullEmergencyReviewStatus struct {
+	EmergencyReviewStatus EmergencyReviewStatus
+	Valid                 bool // Valid is true if EmergencyReviewStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullEmergencyReviewStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.EmergencyReviewStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.EmergencyReviewStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullEmergencyReviewStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.EmergencyReviewStatus), nil
+}
+
+type JobRunStatus string
+
+const (
+	JobRunStatusRunning   JobRunStatus = "running"
+	JobRunStatusSucceeded JobRunStatus = "succeeded"
+	JobRunStatusFailed    JobRunStatus = "failed"
+)
+
+func (e *JobRunStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = JobRunStatus(s)
+	case string:
+		*e = JobRunStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for JobRunStatus: %T", src)
+	}
+	return nil
+}
+
+type NullJobRunStatus struct {
+	JobRunStatus JobRunStatus
+	Valid        bool // Valid is true if JobRunStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullJobRunStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.JobRunStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.JobRunStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullJobRunStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.JobRunStatus), nil
+}
+
+type LibPermission string
+
+const (
+	LibPermissionRead    LibPermission = "read"
+	LibPermissionRequest LibPermission = "request"
+	LibPermissionManage  LibPermission = "manage"
+)
+
+func (e *LibPermission) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = LibPermission(s)
+	case string:
+		*e = LibPermission(s)
+	default:
+		return fmt.Errorf("unsupported scan type for LibPermission: %T", src)
+	}
+	return nil
+}
+
+type NullLibPermission struct {
+	LibPermission LibPermission
+	Valid         bool // Valid is true if LibPermission is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullLibPermission) Scan(value interface{}) error {
+	if value == nil {
+		ns.LibPermission, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.LibPermission.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullLibPermission) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.LibPermission), nil
+}
+
 type MimixStatus string
 
 const (
@@ -58,6 +363,135 @@ func (ns NullMimixStatus) Value() (driver.Value, error) {
 	return string(ns.MimixStatus), nil
 }
 
+type ObjKind string
+
+const (
+	ObjKindLib ObjKind = "lib"
+	ObjKindIfs ObjKind = "ifs"
+	ObjKindDlo ObjKind = "dlo"
+)
+
+func (e *ObjKind) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ObjKind(s)
+	case string:
+		*e = ObjKind(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ObjKind: %T", src)
+	}
+	return nil
+}
+
+type NullObjKind struct {
+	ObjKind ObjKind
+	Valid   bool // Valid is true if ObjKind is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullObjKind) Scan(value interface{}) error {
+	if value == nil {
+		ns.ObjKind, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ObjKind.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullObjKind) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ObjKind), nil
+}
+
+type ObjectVersionSource string
+
+const (
+	ObjectVersionSourceInitial   ObjectVersionSource = "initial"
+	ObjectVersionSourceCreated   ObjectVersionSource = "created"
+	ObjectVersionSourceUpdated   ObjectVersionSource = "updated"
+	ObjectVersionSourceConverted ObjectVersionSource = "converted"
+)
+
+func (e *ObjectVersionSource) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ObjectVersionSource(s)
+	case string:
+		*e = ObjectVersionSource(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ObjectVersionSource: %T", src)
+	}
+	return nil
+}
+
+type NullObjectVersionSource struct {
+	ObjectVersionSource ObjectVersionSource
+	Valid               bool // Valid is true if ObjectVersionSource is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullObjectVersionSource) Scan(value interface{}) error {
+	if value == nil {
+		ns.ObjectVersionSource, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ObjectVersionSource.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullObjectVersionSource) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ObjectVersionSource), nil
+}
+
+type PrcType string
+
+const (
+	PrcTypeInclude PrcType = "include"
+	PrcTypeExclude PrcType = "exclude"
+)
+
+func (e *PrcType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PrcType(s)
+	case string:
+		*e = PrcType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PrcType: %T", src)
+	}
+	return nil
+}
+
+type NullPrcType struct {
+	PrcType PrcType
+	Valid   bool // Valid is true if PrcType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPrcType) Scan(value interface{}) error {
+	if value == nil {
+		ns.PrcType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PrcType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPrcType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PrcType), nil
+}
+
 type PromoteStatus string
 
 const (
@@ -100,11 +534,56 @@ func (ns NullPromoteStatus) Value() (driver.Value, error) {
 	return string(ns.PromoteStatus), nil
 }
 
+type ReqPriority string
+
+const (
+	ReqPriorityLow       ReqPriority = "low"
+	ReqPriorityNormal    ReqPriority = "normal"
+	ReqPriorityHigh      ReqPriority = "high"
+	ReqPriorityEmergency ReqPriority = "emergency"
+)
+
+func (e *ReqPriority) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ReqPriority(s)
+	case string:
+		*e = ReqPriority(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ReqPriority: %T", src)
+	}
+	return nil
+}
+
+type NullReqPriority struct {
+	ReqPriority ReqPriority
+	Valid       bool // Valid is true if ReqPriority is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullReqPriority) Scan(value interface{}) error {
+	if value == nil {
+		ns.ReqPriority, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ReqPriority.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullReqPriority) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ReqPriority), nil
+}
+
 type ReqStatus string
 
 const (
 	ReqStatusPending   ReqStatus = "pending"
 	ReqStatusCompleted ReqStatus = "completed"
+	ReqStatusRejected  ReqStatus = "rejected"
 )
 
 func (e *ReqStatus) Scan(src interface{}) error {
@@ -142,6 +621,49 @@ func (ns NullReqStatus) Value() (driver.Value, error) {
 	return string(ns.ReqStatus), nil
 }
 
+type TeamApproval string
+
+const (
+	TeamApprovalAwaiting TeamApproval = "awaiting"
+	TeamApprovalApproved TeamApproval = "approved"
+	TeamApprovalRejected TeamApproval = "rejected"
+)
+
+func (e *TeamApproval) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = TeamApproval(s)
+	case string:
+		*e = TeamApproval(s)
+	default:
+		return fmt.Errorf("unsupported scan type for TeamApproval: %T", src)
+	}
+	return nil
+}
+
+type NullTeamApproval struct {
+	TeamApproval TeamApproval
+	Valid        bool // Valid is true if TeamApproval is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullTeamApproval) Scan(value interface{}) error {
+	if value == nil {
+		ns.TeamApproval, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.TeamApproval.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullTeamApproval) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.TeamApproval), nil
+}
+
 type UserJob string
 
 const (
@@ -186,9 +708,137 @@ func (ns NullUserJob) Value() (driver.Value, error) {
 	return string(ns.UserJob), nil
 }
 
+type Attachment struct {
+	ID          uuid.UUID
+	ObjID       uuid.NullUUID
+	ObjReqID    uuid.NullUUID
+	FileName    string
+	ContentType string
+	SizeBytes   int64
+	Sha256      string
+	StorageKey  string
+	UploadedBy  string
+	CreatedAt   time.Time
+}
+
+type ChangeTicket struct {
+	ID          uuid.UUID
+	RefNo       string
+	Description string
+	Owner       string
+	WindowStart sql.NullTime
+	WindowEnd   sql.NullTime
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type Comment struct {
+	ID        uuid.UUID
+	ObjID     uuid.NullUUID
+	ObjReqID  uuid.NullUUID
+	ParentID  uuid.NullUUID
+	Author    string
+	Body      string
+	CreatedAt time.Time
+	EditedAt  sql.NullTime
+	DeletedAt sql.NullTime
+	DeletedBy sql.NullString
+}
+
+type CommentRevision struct {
+	ID        uuid.UUID
+	CommentID uuid.UUID
+	Change    CommentChange
+	Body      string
+	ChangedBy string
+	ChangedAt time.Time
+}
+
+type CoverageRule struct {
+	ID          uuid.UUID
+	ObjKind     ObjKind
+	LibPattern  string
+	ObjPattern  string
+	ObjType     string
+	PrcType     PrcType
+	MimixStatus MimixStatus
+	Description string
+	CreatedBy   string
+	CreatedAt   time.Time
+}
+
+type DeploymentEvent struct {
+	ID         uuid.UUID
+	EventID    string
+	ObjKind    ObjKind
+	Lib        string
+	Obj        string
+	ObjType    string
+	ObjVer     string
+	DeployedAt time.Time
+	Payload    string
+	Status     DeploymentEventStatus
+	Reason     string
+	ObjReqID   uuid.NullUUID
+	ReceivedAt time.Time
+	ResolvedBy sql.NullString
+	ResolvedAt sql.NullTime
+}
+
+type DeveloperMatch struct {
+	Developer  string
+	UserID     uuid.NullUUID
+	Method     DeveloperMatchMethod
+	Distance   sql.NullInt32
+	ObjCount   int32
+	ReqCount   int32
+	ResolvedBy sql.NullString
+	ResolvedAt sql.NullTime
+	CreatedAt  time.Time
+}
+
+type EmergencyReview struct {
+	ID            uuid.UUID
+	ObjReqID      uuid.UUID
+	PromotionID   uuid.UUID
+	SkippedStages string
+	Actor         string
+	Status        EmergencyReviewStatus
+	Justification string
+	JustifiedAt   sql.NullTime
+	Reviewer      sql.NullString
+	ReviewNote    string
+	ReviewedAt    sql.NullTime
+	CreatedAt     time.Time
+}
+
+type JobRun struct {
+	ID           uuid.UUID
+	JobName      string
+	ScheduledFor time.Time
+	Runner       string
+	Status       JobRunStatus
+	Summary      string
+	Error        string
+	StartedAt    time.Time
+	FinishedAt   sql.NullTime
+}
+
+type LibAcl struct {
+	ID         uuid.UUID
+	LibID      uuid.UUID
+	UserID     uuid.NullUUID
+	TeamID     uuid.NullUUID
+	Permission LibPermission
+	GrantedBy  string
+	CreatedAt  time.Time
+}
+
 type MimixLib struct {
-	ID  uuid.UUID
-	Lib string
+	ID         uuid.UUID
+	Lib        string
+	TeamID     uuid.NullUUID
+	Restricted bool
 }
 
 type MimixObj struct {
@@ -197,28 +847,162 @@ type MimixObj struct {
 	ObjType     string
 	PromoteDate sql.NullTime
 	Lib         string
-	LibID       uuid.UUID
+	LibID       uuid.NullUUID
 	ObjVer      string
 	MimixStatus MimixStatus
 	Developer   string
 	Keterangan  sql.NullString
 	UpdatedAt   time.Time
+	Version     int32
+	DeletedAt   sql.NullTime
+	DeletedBy   sql.NullString
+	ObjKind     ObjKind
+	PrcType     PrcType
+	Subtree     bool
+	RuleID      uuid.NullUUID
+	StageID     uuid.NullUUID
+	DeveloperID uuid.NullUUID
 }
 
 type MimixObjReq struct {
-	ID            uuid.UUID
-	ObjName       string
-	Requester     string
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Lib           string
-	ObjVer        string
-	ObjType       string
-	PromoteDate   time.Time
-	Developer     sql.NullString
-	PromoteStatus NullPromoteStatus
-	SourceObjID   uuid.NullUUID
-	ReqStatus     ReqStatus
+	ID               uuid.UUID
+	ObjName          string
+	Requester        string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	Lib              string
+	ObjVer           string
+	ObjType          string
+	PromoteDate      time.Time
+	Developer        sql.NullString
+	PromoteStatus    NullPromoteStatus
+	SourceObjID      uuid.NullUUID
+	ReqStatus        ReqStatus
+	TicketID         uuid.NullUUID
+	Version          int32
+	DeletedAt        sql.NullTime
+	DeletedBy        sql.NullString
+	ObjKind          ObjKind
+	PrcType          PrcType
+	Subtree          bool
+	StageID          uuid.NullUUID
+	SlaPolicyID      uuid.NullUUID
+	DueAt            sql.NullTime
+	ResolvedAt       sql.NullTime
+	Priority         ReqPriority
+	Assignee         sql.NullString
+	AssignedBy       sql.NullString
+	AssignedAt       sql.NullTime
+	DeveloperID      uuid.NullUUID
+	TeamID           uuid.NullUUID
+	TeamApproval     NullTeamApproval
+	TeamApprovalBy   sql.NullString
+	TeamApprovalAt   sql.NullTime
+	TeamApprovalNote sql.NullString
+}
+
+type Notification struct {
+	ID        uuid.UUID
+	Recipient string
+	Kind      string
+	Message   string
+	ObjID     uuid.NullUUID
+	ObjReqID  uuid.NullUUID
+	DedupeKey sql.NullString
+	CreatedAt time.Time
+	ReadAt    sql.NullTime
+}
+
+type ObjectDependency struct {
+	ID        uuid.UUID
+	Lib       string
+	Obj       string
+	ObjType   string
+	DepLib    string
+	DepObj    string
+	DepType   string
+	Source    DepSource
+	CreatedBy string
+	CreatedAt time.Time
+}
+
+type ObjectVersion struct {
+	ID          uuid.UUID
+	ObjID       uuid.UUID
+	ObjVer      string
+	PromoteDate sql.NullTime
+	Developer   string
+	ObjReqID    uuid.NullUUID
+	Source      ObjectVersionSource
+	ChangedBy   sql.NullString
+	CreatedAt   time.Time
+}
+
+type PromotionHistory struct {
+	ID          uuid.UUID
+	ObjID       uuid.NullUUID
+	ObjReqID    uuid.NullUUID
+	FromStageID uuid.NullUUID
+	ToStageID   uuid.UUID
+	Actor       string
+	Note        string
+	PromotedAt  time.Time
+}
+
+type PromotionStage struct {
+	ID           uuid.UUID
+	Name         string
+	Position     int32
+	IsProduction bool
+	CreatedAt    time.Time
+}
+
+type Role struct {
+	ID          uuid.UUID
+	Name        string
+	Description string
+	Builtin     bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type RolePermission struct {
+	RoleID     uuid.UUID
+	Permission string
+}
+
+type SlaHoliday struct {
+	HolidayDate time.Time
+	Name        string
+	CreatedBy   string
+	CreatedAt   time.Time
+}
+
+type SlaPolicy struct {
+	ID          uuid.UUID
+	Name        string
+	Priority    NullReqPriority
+	LibPattern  string
+	TargetHours int32
+	WarnPercent int32
+	EscalateTo  string
+	CreatedBy   string
+	CreatedAt   time.Time
+}
+
+type Team struct {
+	ID          uuid.UUID
+	Name        string
+	Description string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type TeamMember struct {
+	TeamID  uuid.UUID
+	UserID  uuid.UUID
+	IsLead  bool
+	AddedAt time.Time
 }
 
 type User struct {
@@ -228,4 +1012,11 @@ type User struct {
 	UpdatedAt      time.Time
 	HashedPassword string
 	Job            UserJob
+	Directory      bool
+}
+
+type UserRole struct {
+	UserID    uuid.UUID
+	RoleID    uuid.UUID
+	GrantedAt time.Time
 }
@@ -12,6 +12,22 @@ import (
 	"github.com/google/uuid"
 )
 
+const checkPermissionHeld = `-- name: CheckPermissionHeld :one
+SELECT EXISTS(
+    SELECT 1
+    FROM user_roles ur
+    JOIN role_permissions rp ON rp.role_id = ur.role_id
+    WHERE rp.permission = $1
+)
+`
+
+func (q *Queries) CheckPermissionHeld(ctx context.Context, permission string) (bool, error) {
+	row := q.db.QueryRowContext(ctx, checkPermissionHeld, permission)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const checkUserExists = `-- name: CheckUserExists :one
 SELECT EXISTS(SELECT 1 FROM users WHERE LOWER(username) = LOWER($1))
 `
@@ -82,7 +98,7 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (GetUserByIDRow
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, username, job, hashed_password, created_at, updated_at
+SELECT id, username, job, hashed_password, created_at, updated_at, directory
 FROM users
 WHERE LOWER(username) = LOWER($1)
 `
@@ -94,6 +110,7 @@ type GetUserByUsernameRow struct {
 	HashedPassword string
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
+	Directory      bool
 }
 
 func (q *Queries) GetUserByUsername(ctx context.Context, lower string) (GetUserByUsernameRow, error) {
@@ -106,12 +123,191 @@ func (q *Queries) GetUserByUsername(ctx context.Context, lower string) (GetUserB
 		&i.HashedPassword,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Directory,
 	)
 	return i, err
 }
 
+const listDCWorkload = `-- name: ListDCWorkload :many
+SELECT u.username, COUNT(r.id) AS pending
+FROM users u
+LEFT JOIN mimix_obj_req r
+  ON r.assignee = u.username AND r.req_status = 'pending' AND r.deleted_at IS NULL
+WHERE EXISTS (
+    SELECT 1
+    FROM user_roles ur
+    JOIN role_permissions rp ON rp.role_id = ur.role_id
+    WHERE ur.user_id = u.id AND rp.permission = 'req.work'
+)
+GROUP BY u.username
+ORDER BY pending, u.username
+`
+
+type ListDCWorkloadRow struct {
+	Username string
+	Pending  int64
+}
+
+func (q *Queries) ListDCWorkload(ctx context.Context) ([]ListDCWorkloadRow, error) {
+	rows, err := q.db.QueryContext(ctx, listDCWorkload)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDCWorkloadRow
+	for rows.Next() {
+		var i ListDCWorkloadRow
+		if err := rows.Scan(&i.Username, &i.Pending); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsernamesWithPermission = `-- name: ListUsernamesWithPermission :many
+SELECT DISTINCT u.username
+FROM users u
+JOIN user_roles ur ON ur.user_id = u.id
+JOIN role_permissions rp ON rp.role_id = ur.role_id
+WHERE rp.permission = $1
+ORDER BY u.username
+`
+
+func (q *Queries) ListUsernamesWithPermission(ctx context.Context, permission string) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listUsernamesWithPermission, permission)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return nil, err
+		}
+		items = append(items, username)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsernamesWithRole = `-- name: ListUsernamesWithRole :many
+SELECT u.username
+FROM users u
+JOIN user_roles ur ON ur.user_id = u.id
+JOIN roles r ON r.id = ur.role_id
+WHERE LOWER(r.name) = LOWER($1)
+ORDER BY u.username
+`
+
+func (q *Queries) ListUsernamesWithRole(ctx context.Context, lower string) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listUsernamesWithRole, lower)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return nil, err
+		}
+		items = append(items, username)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockUsersWithPermission = `-- name: LockUsersWithPermission :many
+SELECT u.id
+FROM users u
+WHERE EXISTS (
+    SELECT 1
+    FROM user_roles ur
+    JOIN role_permissions rp ON rp.role_id = ur.role_id
+    WHERE ur.user_id = u.id AND rp.permission = $1
+)
+ORDER BY u.id
+FOR UPDATE
+`
+
+func (q *Queries) LockUsersWithPermission(ctx context.Context, permission string) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, lockUsersWithPermission, permission)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setUserDirectory = `-- name: SetUserDirectory :exec
+UPDATE users
+SET directory = $2,
+    -- the directory holds the password of a directory user
+    hashed_password = CASE WHEN $2 THEN '' ELSE hashed_password END,
+    updated_at = NOW()
+WHERE id = $1
+`
+
+type SetUserDirectoryParams struct {
+	ID        uuid.UUID
+	Directory bool
+}
+
+func (q *Queries) SetUserDirectory(ctx context.Context, arg SetUserDirectoryParams) error {
+	_, err := q.db.ExecContext(ctx, setUserDirectory, arg.ID, arg.Directory)
+	return err
+}
+
+const setUserJob = `-- name: SetUserJob :exec
+UPDATE users
+SET job = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetUserJobParams struct {
+	ID  uuid.UUID
+	Job UserJob
+}
+
+func (q *Queries) SetUserJob(ctx context.Context, arg SetUserJobParams) error {
+	_, err := q.db.ExecContext(ctx, setUserJob, arg.ID, arg.Job)
+	return err
+}
+
 const userLogin = `-- name: UserLogin :one
-SELECT id, username, created_at, updated_at, hashed_password, job
+SELECT id, username, created_at, updated_at, hashed_password, job, directory
 FROM users
 WHERE LOWER(username) = LOWER($1)
 `
@@ -126,6 +322,7 @@ func (q *Queries) UserLogin(ctx context.Context, lower string) (User, error) {
 		&i.UpdatedAt,
 		&i.HashedPassword,
 		&i.Job,
+		&i.Directory,
 	)
 	return i, err
 }
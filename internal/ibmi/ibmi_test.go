@@ -0,0 +1,209 @@
+package ibmi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"simple", "custmast", "CUSTMAST", false},
+		{"trimmed", "  ordhdr ", "ORDHDR", false},
+		{"special first chars", "$tmp#1@", "$TMP#1@", false},
+		{"underscore and period", "a_b.c", "A_B.C", false},
+		{"ten characters", "abcdefghij", "ABCDEFGHIJ", false},
+		{"too long", "abcdefghijk", "", true},
+		{"empty", "   ", "", true},
+		{"digit first", "1abc", "", true},
+		{"underscore first", "_abc", "", true},
+		{"blank inside", "cust mast", "", true},
+		{"type suffix", "custmast.file", "", true},
+		{"short type suffix", "x.pgm", "", true},
+		{"quoted", `"my obj"`, "", true},
+		{"quoted lower", `"abc"`, `"abc"`, false},
+		{"quoted simple", `"ABC"`, "ABC", false},
+		{"quoted special", `"a-b%c"`, `"a-b%c"`, false},
+		{"quoted too long", `"abcdefghi"`, "", true},
+		{"quoted unterminated", `"abc`, "", true},
+		{"quoted empty", `""`, "", true},
+		{"quoted asterisk", `"a*b"`, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeName(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeName(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeName(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeType(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"*PGM", "*PGM", false},
+		{"pgm", "*PGM", false},
+		{" *srvpgm ", "*SRVPGM", false},
+		{"File", "*FILE", false},
+		{"*dtaara", "*DTAARA", false},
+		{"", "", true},
+		{"*", "", true},
+		{"*NOTATYPE", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeType(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NormalizeType(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeType(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestTypes(t *testing.T) {
+	types := Types()
+	for i := 1; i < len(types); i++ {
+		if types[i-1].Type >= types[i].Type {
+			t.Fatalf("Types() not sorted at %d: %s >= %s", i, types[i-1].Type, types[i].Type)
+		}
+	}
+
+	if got := TypesWithAttribute("pf"); len(got) != 1 || got[0] != "*FILE" {
+		t.Errorf("TypesWithAttribute(pf) = %v, want [*FILE]", got)
+	}
+	if _, err := NormalizeType("RPGLE"); err == nil || !strings.Contains(err.Error(), "*PGM") {
+		t.Errorf("NormalizeType(RPGLE) error = %v, want hint naming *PGM", err)
+	}
+
+	file, ok := LookupType("*FILE")
+	if !ok || len(file.Attributes) == 0 {
+		t.Errorf("LookupType(*FILE) = %+v, %v; want attributes", file, ok)
+	}
+}
+
+func TestNormalizeIFSPath(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"/home/app/config.json", "/home/app/config.json", false},
+		{" /home/app/logs/ ", "/home/app/logs", false},
+		{"/home//app/./x", "/home/app/x", false},
+		{"home/app", "", true},
+		{"/", "", true},
+		{"", "", true},
+		{"/QSYS.LIB/PRODLIB.LIB/X.PGM", "", true},
+		{"/qdls/flr1/doc", "", true},
+		{"/home/a\x00b", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeIFSPath(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NormalizeIFSPath(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeIFSPath(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeDLOPath(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"flr1/sub/doc.txt", "FLR1/SUB/DOC.TXT", false},
+		{"/QDLS/FLR1/DOC", "FLR1/DOC", false},
+		{"FLR1", "FLR1", false},
+		{"flr1/document1.txt", "", true},
+		{"flr1/doc.text", "", true},
+		{"flr1//doc", "", true},
+		{"flr 1/doc", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeDLOPath(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NormalizeDLOPath(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeDLOPath(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	if folder, name := SplitDLOPath("FLR1/SUB/DOC.TXT"); folder != "FLR1/SUB" || name != "DOC.TXT" {
+		t.Errorf("SplitDLOPath() = %q, %q", folder, name)
+	}
+}
+
+func TestParsePgmRef(t *testing.T) {
+	outfile := `WHLIB,WHPNAM,WHTEXT,WHFNUM,WHSPKG,WHLNAM,WHFNAM,WHOTYP
+"PAYLIB    ","PAYCALC   ","Pay calc",1,"P","PAYLIB    ","PAYMAST   ","*FILE"
+PAYLIB,PAYCALC,,2,P,QGPL,DATEUTIL,*SRVPGM
+PAYLIB,PAYSRV,,1,V,*LIBL,PAYMAST,*FILE
+PAYLIB,EMPTY,,0,P,,,
+PAYLIB,BAD,,1,X,PAYLIB,PAYMAST,*FILE
+`
+	refs, rowErrs, err := ParsePgmRef(strings.NewReader(outfile))
+	if err != nil {
+		t.Fatalf("ParsePgmRef() error = %v", err)
+	}
+
+	want := []Reference{
+		{"PAYLIB", "PAYCALC", "*PGM", "PAYLIB", "PAYMAST", "*FILE"},
+		{"PAYLIB", "PAYCALC", "*PGM", "QGPL", "DATEUTIL", "*SRVPGM"},
+	}
+	if len(refs) != len(want) {
+		t.Fatalf("ParsePgmRef() = %+v, want %+v", refs, want)
+	}
+	for i := range want {
+		if refs[i] != want[i] {
+			t.Errorf("ref %d = %+v, want %+v", i, refs[i], want[i])
+		}
+	}
+
+	if len(rowErrs) != 2 || rowErrs[0].Line != 4 || rowErrs[1].Line != 6 {
+		t.Errorf("row errors = %v, want lines 4 and 6", rowErrs)
+	}
+	if !strings.Contains(rowErrs[0].Error(), "*LIBL") {
+		t.Errorf("row error = %v, want it to name *LIBL", rowErrs[0])
+	}
+}
+
+func TestParseDBRelation(t *testing.T) {
+	outfile := "whrfi,whrli,whrefi,whreli,whtype\nPAYMAST,PAYLIB,PAYMASTL1,PAYLIB,D\nORDHDR,ORDLIB,*NONE,,\n"
+	refs, rowErrs, err := ParseDBRelation(strings.NewReader(outfile))
+	if err != nil || len(rowErrs) != 0 {
+		t.Fatalf("ParseDBRelation() error = %v, row errors = %v", err, rowErrs)
+	}
+
+	want := Reference{"PAYLIB", "PAYMASTL1", "*FILE", "PAYLIB", "PAYMAST", "*FILE"}
+	if len(refs) != 1 || refs[0] != want {
+		t.Errorf("ParseDBRelation() = %+v, want [%+v]", refs, want)
+	}
+
+	if _, _, err := ParseDBRelation(strings.NewReader("WHRFI,WHRLI\n")); err == nil {
+		t.Error("ParseDBRelation() with missing columns: want error")
+	}
+}
@@ -0,0 +1,108 @@
+// Package ibmi knows the IBM i rules for object and library names and keeps a
+// catalogue of the object types the registry accepts.
+package ibmi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// MaxNameLen is the longest object or library name IBM i allows, including
+// the quotes of a quoted name.
+const MaxNameLen = 10
+
+var (
+	ErrEmptyName  = errors.New("name is required")
+	ErrNameLength = fmt.Errorf("name is longer than %d characters", MaxNameLen)
+)
+
+// NormalizeName validates an object or library name and returns it in the
+// form IBM i stores it: simple names are upper cased, quoted names keep their
+// case. A quoted name that is also a valid simple name loses its quotes, as
+// the system does.
+//
+// Simple names start with A-Z, $, # or @ and continue with A-Z, 0-9, $, #, @,
+// _ or a period. Quoted names may hold any character except blanks, '*', '?',
+// apostrophes, quotes and control characters.
+func NormalizeName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", ErrEmptyName
+	}
+
+	if strings.HasPrefix(name, `"`) {
+		return normalizeQuotedName(name)
+	}
+
+	if err := typeSuffixError(name); err != nil {
+		return "", err
+	}
+	if len(name) > MaxNameLen {
+		return "", ErrNameLength
+	}
+
+	upper := strings.ToUpper(name)
+	for i, r := range upper {
+		if i == 0 && !isSimpleFirst(r) {
+			return "", fmt.Errorf("name must start with A-Z, $, # or @, not %q", r)
+		}
+		if !isSimpleRest(r) {
+			return "", fmt.Errorf("character %q is not allowed in a simple name", r)
+		}
+	}
+	return upper, nil
+}
+
+// IsQuoted reports whether name is a quoted name.
+func IsQuoted(name string) bool {
+	name = strings.TrimSpace(name)
+	return len(name) >= 2 && name[0] == '"' && name[len(name)-1] == '"'
+}
+
+func normalizeQuotedName(name string) (string, error) {
+	if len(name) < 2 || !strings.HasSuffix(name, `"`) {
+		return "", errors.New("quoted name is missing its closing quote")
+	}
+	if len(name) > MaxNameLen {
+		return "", ErrNameLength
+	}
+
+	inner := name[1 : len(name)-1]
+	if inner == "" {
+		return "", ErrEmptyName
+	}
+	for _, r := range inner {
+		if r > unicode.MaxASCII || unicode.IsControl(r) || strings.ContainsRune(` *?'"`, r) {
+			return "", fmt.Errorf("character %q is not allowed in a quoted name", r)
+		}
+	}
+
+	// "ABC" and ABC are the same object
+	if simple, err := NormalizeName(inner); err == nil && simple == inner {
+		return simple, nil
+	}
+	return name, nil
+}
+
+// typeSuffixError catches names such as "custmast.file" where the object type
+// was typed into the name.
+func typeSuffixError(name string) error {
+	dot := strings.LastIndexByte(name, '.')
+	if dot <= 0 || dot == len(name)-1 {
+		return nil
+	}
+	if _, ok := LookupType(name[dot+1:]); ok {
+		return fmt.Errorf("name ends with object type %q, set it as the object type instead", name[dot:])
+	}
+	return nil
+}
+
+func isSimpleFirst(r rune) bool {
+	return (r >= 'A' && r <= 'Z') || r == '$' || r == '#' || r == '@'
+}
+
+func isSimpleRest(r rune) bool {
+	return isSimpleFirst(r) || (r >= '0' && r <= '9') || r == '_' || r == '.'
+}
@@ -0,0 +1,167 @@
+package ibmi
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Reference says that the object Lib/Obj of type Type uses RefLib/RefObj of
+// type RefType. Names are in the form NormalizeName returns.
+type Reference struct {
+	Lib     string
+	Obj     string
+	Type    string
+	RefLib  string
+	RefObj  string
+	RefType string
+}
+
+// RowError is a row of an outfile that could not be read. Line counts the
+// header as line 1.
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e RowError) Error() string { return fmt.Sprintf("line %d: %v", e.Line, e.Err) }
+
+// pgmRefTypes maps the program type column (WHSPKG) of a DSPPGMREF outfile to
+// an object type.
+var pgmRefTypes = map[string]string{
+	"P": "*PGM",
+	"V": "*SRVPGM",
+	"M": "*MODULE",
+	"S": "*SQLPKG",
+}
+
+// ParsePgmRef reads a DSPPGMREF outfile (QADSPPGM) copied to CSV with column
+// names in the first row, e.g. CPYTOIMPF ... RCDDLM(*CRLF) ADDCOLNAM(*SYS).
+// Every row is a program using an object.
+func ParsePgmRef(r io.Reader) ([]Reference, []RowError, error) {
+	return parseOutfile(r, []string{"WHLIB", "WHPNAM", "WHSPKG", "WHLNAM", "WHFNAM", "WHOTYP"}, func(row map[string]string) (Reference, bool, error) {
+		if row["WHFNAM"] == "" {
+			// a program without references still gets a row
+			return Reference{}, false, nil
+		}
+
+		objType, ok := pgmRefTypes[strings.ToUpper(row["WHSPKG"])]
+		if !ok {
+			return Reference{}, false, fmt.Errorf("unknown program type %q", row["WHSPKG"])
+		}
+		return newReference(row["WHLIB"], row["WHPNAM"], objType, row["WHLNAM"], row["WHFNAM"], row["WHOTYP"])
+	})
+}
+
+// ParseDBRelation reads a DSPDBR outfile (QADSPDBR) copied to CSV with column
+// names in the first row. Every row is a dependent file (a logical file, view
+// or file with a constraint) using a physical file.
+func ParseDBRelation(r io.Reader) ([]Reference, []RowError, error) {
+	return parseOutfile(r, []string{"WHRFI", "WHRLI", "WHREFI", "WHRELI"}, func(row map[string]string) (Reference, bool, error) {
+		if row["WHREFI"] == "" || strings.EqualFold(row["WHREFI"], "*NONE") {
+			// a file without dependents still gets a row
+			return Reference{}, false, nil
+		}
+		return newReference(row["WHRELI"], row["WHREFI"], "*FILE", row["WHRLI"], row["WHRFI"], "*FILE")
+	})
+}
+
+func newReference(lib, obj, objType, refLib, refObj, refType string) (Reference, bool, error) {
+	var (
+		ref Reference
+		err error
+	)
+	if ref.Lib, err = outfileLib(lib); err != nil {
+		return ref, false, err
+	}
+	if ref.Obj, err = NormalizeName(obj); err != nil {
+		return ref, false, fmt.Errorf("object %q: %w", obj, err)
+	}
+	if ref.Type, err = NormalizeType(objType); err != nil {
+		return ref, false, err
+	}
+	if ref.RefLib, err = outfileLib(refLib); err != nil {
+		return ref, false, err
+	}
+	if ref.RefObj, err = NormalizeName(refObj); err != nil {
+		return ref, false, fmt.Errorf("object %q: %w", refObj, err)
+	}
+	if ref.RefType, err = NormalizeType(refType); err != nil {
+		return ref, false, err
+	}
+	return ref, true, nil
+}
+
+// outfileLib validates a library column. Special values such as *LIBL are
+// resolved when the program runs, so the outfile cannot say which library
+// is meant.
+func outfileLib(lib string) (string, error) {
+	if strings.HasPrefix(lib, "*") {
+		return "", fmt.Errorf("library %s cannot be resolved", strings.ToUpper(lib))
+	}
+	name, err := NormalizeName(lib)
+	if err != nil {
+		return "", fmt.Errorf("library %q: %w", lib, err)
+	}
+	return name, nil
+}
+
+// parseOutfile reads a CSV outfile, checks that the header holds the wanted
+// columns and calls parse for every row. Rows that parse rejects are returned
+// as RowErrors; a broken file is returned as an error.
+func parseOutfile(r io.Reader, columns []string, parse func(row map[string]string) (Reference, bool, error)) ([]Reference, []RowError, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, errors.New("outfile is empty")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, col := range columns {
+		if _, ok := index[col]; !ok {
+			return nil, nil, fmt.Errorf("outfile has no %s column", col)
+		}
+	}
+
+	var (
+		refs    []Reference
+		rowErrs []RowError
+	)
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		row := make(map[string]string, len(columns))
+		for _, col := range columns {
+			if i := index[col]; i < len(record) {
+				row[col] = strings.TrimSpace(record[i])
+			}
+		}
+
+		ref, ok, err := parse(row)
+		if err != nil {
+			rowErrs = append(rowErrs, RowError{Line: line, Err: err})
+			continue
+		}
+		if ok {
+			refs = append(refs, ref)
+		}
+	}
+	return refs, rowErrs, nil
+}
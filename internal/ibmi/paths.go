@@ -0,0 +1,122 @@
+package ibmi
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"unicode"
+)
+
+// Kind says which file system an object lives in.
+type Kind string
+
+const (
+	// KindLib is a QSYS object: a name in a library.
+	KindLib Kind = "lib"
+	// KindIFS is a path in the integrated file system.
+	KindIFS Kind = "ifs"
+	// KindDLO is a document or folder in QDLS.
+	KindDLO Kind = "dlo"
+)
+
+const (
+	// MaxIFSPathLen caps IFS paths. The system allows longer paths but MIMIX
+	// data group IFS entries do not.
+	MaxIFSPathLen = 1024
+	// MaxDLOPathLen is the longest folder path QDLS allows.
+	MaxDLOPathLen = 63
+)
+
+// NormalizeIFSPath validates an absolute IFS path and returns it cleaned,
+// without a trailing slash. Paths into /QSYS.LIB and /QDLS are rejected
+// because those objects have their own kinds.
+func NormalizeIFSPath(p string) (string, error) {
+	p = strings.TrimSpace(p)
+	if p == "" {
+		return "", errors.New("path is required")
+	}
+	if !strings.HasPrefix(p, "/") {
+		return "", errors.New("path must be absolute")
+	}
+	for _, r := range p {
+		if r == 0 || unicode.IsControl(r) {
+			return "", fmt.Errorf("character %q is not allowed in a path", r)
+		}
+	}
+
+	p = path.Clean(p)
+	if p == "/" {
+		return "", errors.New("path cannot be the root directory")
+	}
+	if len(p) > MaxIFSPathLen {
+		return "", fmt.Errorf("path is longer than %d characters", MaxIFSPathLen)
+	}
+
+	first := strings.ToUpper(strings.SplitN(p[1:], "/", 2)[0])
+	switch first {
+	case "QSYS.LIB":
+		return "", errors.New("paths under /QSYS.LIB are library objects, use kind lib")
+	case "QDLS":
+		return "", errors.New("paths under /QDLS are document library objects, use kind dlo")
+	}
+	return p, nil
+}
+
+// NormalizeDLOPath validates a folder path with an optional document at the
+// end, e.g. "FLR1/SUB/DOC.TXT", and returns it upper cased. A leading "/QDLS/"
+// is dropped. Every part is a DLO name: up to 8 characters with an optional
+// extension of up to 3.
+func NormalizeDLOPath(p string) (string, error) {
+	p = strings.ToUpper(strings.TrimSpace(p))
+	p = strings.TrimPrefix(p, "/QDLS/")
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return "", errors.New("folder path is required")
+	}
+	if len(p) > MaxDLOPathLen {
+		return "", fmt.Errorf("folder path is longer than %d characters", MaxDLOPathLen)
+	}
+
+	for _, part := range strings.Split(p, "/") {
+		if err := validateDLOName(part); err != nil {
+			return "", err
+		}
+	}
+	return p, nil
+}
+
+// SplitDLOPath splits a normalised DLO path into its folder and last name.
+// The folder is empty for a top level folder.
+func SplitDLOPath(p string) (folder, name string) {
+	i := strings.LastIndexByte(p, '/')
+	if i < 0 {
+		return "", p
+	}
+	return p[:i], p[i+1:]
+}
+
+func validateDLOName(name string) error {
+	if name == "" {
+		return errors.New("folder path has an empty part")
+	}
+
+	base, ext, hasExt := strings.Cut(name, ".")
+	if base == "" || len(base) > 8 {
+		return fmt.Errorf("%q: name must be 1 to 8 characters", name)
+	}
+	if hasExt && (ext == "" || len(ext) > 3) {
+		return fmt.Errorf("%q: extension must be 1 to 3 characters", name)
+	}
+
+	for _, r := range base + ext {
+		if !isDLORune(r) {
+			return fmt.Errorf("%q: character %q is not allowed", name, r)
+		}
+	}
+	return nil
+}
+
+func isDLORune(r rune) bool {
+	return (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || strings.ContainsRune("$#@_-", r)
+}
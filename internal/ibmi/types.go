@@ -0,0 +1,144 @@
+package ibmi
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ObjectType describes one IBM i object type. Attributes lists the common
+// object attributes (as shown by DSPOBJD) for types that have them.
+type ObjectType struct {
+	Type        string   `json:"type"`
+	Kind        Kind     `json:"kind"`
+	Description string   `json:"description"`
+	Attributes  []string `json:"attributes,omitempty"`
+}
+
+var catalogue = map[string]ObjectType{}
+
+func init() {
+	for _, t := range []ObjectType{
+		{Type: "*ALRTBL", Description: "Alert table"},
+		{Type: "*AUTL", Description: "Authorization list"},
+		{Type: "*BNDDIR", Description: "Binding directory"},
+		{Type: "*CLD", Description: "C locale description"},
+		{Type: "*CLS", Description: "Class"},
+		{Type: "*CMD", Description: "Command"},
+		{Type: "*CRQD", Description: "Change request description"},
+		{Type: "*CSI", Description: "Communications side information"},
+		{Type: "*DTAARA", Description: "Data area"},
+		{Type: "*DTADCT", Description: "Data dictionary"},
+		{Type: "*DTAQ", Description: "Data queue", Attributes: []string{"DDM"}},
+		{Type: "*EDTD", Description: "Edit description"},
+		{Type: "*FILE", Description: "File", Attributes: []string{"PF", "LF", "DSPF", "PRTF", "SAVF", "DDMF", "ICFF", "BSCF", "MXDF", "TAPF", "DKTF"}},
+		{Type: "*FNTRSC", Description: "Font resource"},
+		{Type: "*FORMDF", Description: "Form definition"},
+		{Type: "*FTR", Description: "Filter"},
+		{Type: "*GSS", Description: "Graphics symbol set"},
+		{Type: "*JOBD", Description: "Job description"},
+		{Type: "*JOBQ", Description: "Job queue"},
+		{Type: "*JOBSCD", Description: "Job schedule"},
+		{Type: "*JRN", Description: "Journal"},
+		{Type: "*JRNRCV", Description: "Journal receiver"},
+		{Type: "*LIB", Description: "Library", Attributes: []string{"PROD", "TEST"}},
+		{Type: "*LOCALE", Description: "Locale"},
+		{Type: "*MENU", Description: "Menu", Attributes: []string{"UIM", "DSPF", "PGM"}},
+		{Type: "*MODULE", Description: "Module", Attributes: []string{"RPGLE", "SQLRPGLE", "CLLE", "CBLLE", "SQLCBLLE", "CLE", "CPPLE"}},
+		{Type: "*MSGF", Description: "Message file"},
+		{Type: "*MSGQ", Description: "Message queue"},
+		{Type: "*NODL", Description: "Node list"},
+		{Type: "*OUTQ", Description: "Output queue"},
+		{Type: "*OVL", Description: "Overlay"},
+		{Type: "*PAGDFN", Description: "Page definition"},
+		{Type: "*PAGSEG", Description: "Page segment"},
+		{Type: "*PGM", Description: "Program", Attributes: []string{"RPGLE", "SQLRPGLE", "RPG", "CLLE", "CLP", "CBLLE", "SQLCBLLE", "CBL", "CLE", "CPPLE", "PLI"}},
+		{Type: "*PNLGRP", Description: "Panel group"},
+		{Type: "*PSFCFG", Description: "Print services facility configuration"},
+		{Type: "*QMFORM", Description: "Query management form"},
+		{Type: "*QMQRY", Description: "Query management query"},
+		{Type: "*QRYDFN", Description: "Query definition"},
+		{Type: "*SBSD", Description: "Subsystem description"},
+		{Type: "*SCHIDX", Description: "Search index"},
+		{Type: "*SPADCT", Description: "Spelling aid dictionary"},
+		{Type: "*SQLPKG", Description: "SQL package"},
+		{Type: "*SQLUDT", Description: "SQL user-defined type"},
+		{Type: "*SRVPGM", Description: "Service program", Attributes: []string{"RPGLE", "SQLRPGLE", "CLLE", "CBLLE", "CLE", "CPPLE"}},
+		{Type: "*TBL", Description: "Table"},
+		{Type: "*USRIDX", Description: "User index"},
+		{Type: "*USRPRF", Description: "User profile"},
+		{Type: "*USRQ", Description: "User queue"},
+		{Type: "*USRSPC", Description: "User space"},
+		{Type: "*VLDL", Description: "Validation list"},
+		{Type: "*WSCST", Description: "Workstation customizing object"},
+
+		{Type: "*STMF", Kind: KindIFS, Description: "Stream file"},
+		{Type: "*DIR", Kind: KindIFS, Description: "Directory"},
+		{Type: "*SYMLNK", Kind: KindIFS, Description: "Symbolic link"},
+
+		{Type: "*DOC", Kind: KindDLO, Description: "Document"},
+		{Type: "*FLR", Kind: KindDLO, Description: "Folder"},
+	} {
+		if t.Kind == "" {
+			t.Kind = KindLib
+		}
+		catalogue[t.Type] = t
+	}
+}
+
+// LookupType finds an object type by name. The leading '*' and the case are
+// optional, so "pgm", "PGM" and "*pgm" all find *PGM.
+func LookupType(name string) (ObjectType, bool) {
+	t, ok := catalogue[canonicalType(name)]
+	return t, ok
+}
+
+// NormalizeType returns the catalogue spelling of an object type.
+func NormalizeType(name string) (string, error) {
+	if strings.TrimSpace(name) == "" {
+		return "", fmt.Errorf("object type is required")
+	}
+	t, ok := LookupType(name)
+	if !ok {
+		// a common mistake is to give the attribute (PF, RPGLE) instead
+		if owners := TypesWithAttribute(name); len(owners) > 0 {
+			return "", fmt.Errorf("%q is an object attribute, not a type; use %s",
+				strings.TrimSpace(name), strings.Join(owners, " or "))
+		}
+		return "", fmt.Errorf("unknown object type %q", strings.TrimSpace(name))
+	}
+	return t.Type, nil
+}
+
+// TypesWithAttribute returns the types that list attr as one of their
+// attributes, sorted.
+func TypesWithAttribute(attr string) []string {
+	attr = strings.ToUpper(strings.TrimSpace(attr))
+	var owners []string
+	for _, t := range catalogue {
+		if slices.Contains(t.Attributes, attr) {
+			owners = append(owners, t.Type)
+		}
+	}
+	sort.Strings(owners)
+	return owners
+}
+
+// Types returns the whole catalogue sorted by type.
+func Types() []ObjectType {
+	types := make([]ObjectType, 0, len(catalogue))
+	for _, t := range catalogue {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].Type < types[j].Type })
+	return types
+}
+
+func canonicalType(name string) string {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if !strings.HasPrefix(name, "*") {
+		name = "*" + name
+	}
+	return name
+}
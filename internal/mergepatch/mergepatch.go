@@ -0,0 +1,136 @@
+// Package mergepatch reads flat JSON Merge Patch documents (RFC 7396).
+//
+// A field that is absent from the patch is left unchanged, a field set to
+// null is cleared and any other value replaces the current one.
+package mergepatch
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+// ContentType is the media type of a JSON Merge Patch document.
+const ContentType = "application/merge-patch+json"
+
+// Patch holds the top level members of a merge patch document.
+type Patch map[string]json.RawMessage
+
+// Parse decodes a merge patch document. The document must be a JSON object.
+func Parse(data []byte) (Patch, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		return nil, fmt.Errorf("merge patch must be a JSON object")
+	}
+
+	var p Patch
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("invalid merge patch: %w", err)
+	}
+	return p, nil
+}
+
+// Has reports whether field is present in the patch, including as null.
+func (p Patch) Has(field string) bool {
+	_, ok := p[field]
+	return ok
+}
+
+// IsNull reports whether field is present and set to null.
+func (p Patch) IsNull(field string) bool {
+	raw, ok := p[field]
+	return ok && bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
+
+// Unknown returns the fields of the patch that are not in allowed, sorted.
+func (p Patch) Unknown(allowed ...string) []string {
+	var unknown []string
+	for field := range p {
+		if !slices.Contains(allowed, field) {
+			unknown = append(unknown, field)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// String sets dst when field is present. null is rejected because the
+// target cannot be cleared.
+func (p Patch) String(field string, dst *string) (bool, error) {
+	if !p.Has(field) {
+		return false, nil
+	}
+	if p.IsNull(field) {
+		return false, fmt.Errorf("%s cannot be null", field)
+	}
+	if err := json.Unmarshal(p[field], dst); err != nil {
+		return false, fmt.Errorf("%s must be a string", field)
+	}
+	return true, nil
+}
+
+// NullString sets dst when field is present; null clears it.
+func (p Patch) NullString(field string, dst *sql.NullString) (bool, error) {
+	if !p.Has(field) {
+		return false, nil
+	}
+	if p.IsNull(field) {
+		*dst = sql.NullString{}
+		return true, nil
+	}
+	var s string
+	if err := json.Unmarshal(p[field], &s); err != nil {
+		return false, fmt.Errorf("%s must be a string or null", field)
+	}
+	*dst = sql.NullString{String: s, Valid: true}
+	return true, nil
+}
+
+// Bool sets dst when field is present. null is rejected.
+func (p Patch) Bool(field string, dst *bool) (bool, error) {
+	if !p.Has(field) {
+		return false, nil
+	}
+	if p.IsNull(field) {
+		return false, fmt.Errorf("%s cannot be null", field)
+	}
+	if err := json.Unmarshal(p[field], dst); err != nil {
+		return false, fmt.Errorf("%s must be true or false", field)
+	}
+	return true, nil
+}
+
+// Time sets dst when field is present. null is rejected.
+func (p Patch) Time(field string, dst *time.Time) (bool, error) {
+	if !p.Has(field) {
+		return false, nil
+	}
+	if p.IsNull(field) {
+		return false, fmt.Errorf("%s cannot be null", field)
+	}
+	if err := json.Unmarshal(p[field], dst); err != nil {
+		return false, fmt.Errorf("%s must be an RFC 3339 timestamp", field)
+	}
+	return true, nil
+}
+
+// NullTime sets dst when field is present; null clears it.
+func (p Patch) NullTime(field string, dst *sql.NullTime) (bool, error) {
+	if !p.Has(field) {
+		return false, nil
+	}
+	if p.IsNull(field) {
+		*dst = sql.NullTime{}
+		return true, nil
+	}
+	var t time.Time
+	if err := json.Unmarshal(p[field], &t); err != nil {
+		return false, fmt.Errorf("%s must be an RFC 3339 timestamp or null", field)
+	}
+	*dst = sql.NullTime{Time: t, Valid: true}
+	return true, nil
+}
@@ -0,0 +1,116 @@
+package mergepatch
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{"object", `{"obj": "custmast"}`, false},
+		{"empty object", `{}`, false},
+		{"array", `[{"obj": "custmast"}]`, true},
+		{"null", `null`, true},
+		{"empty body", ``, true},
+		{"malformed", `{"obj": }`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	p, _ := Parse([]byte(`{"obj": "custmast", "lib": null, "obj_ver": 3}`))
+
+	got := "old"
+	ok, err := p.String("obj", &got)
+	if err != nil || !ok || got != "custmast" {
+		t.Errorf("String(obj) = %q, %v, %v", got, ok, err)
+	}
+
+	got = "old"
+	ok, err = p.String("obj_type", &got)
+	if err != nil || ok || got != "old" {
+		t.Errorf("String(absent) changed value: %q, %v, %v", got, ok, err)
+	}
+
+	if _, err := p.String("lib", &got); err == nil {
+		t.Error("String(null) should fail for non-nullable field")
+	}
+	if _, err := p.String("obj_ver", &got); err == nil {
+		t.Error("String(number) should fail")
+	}
+}
+
+func TestBool(t *testing.T) {
+	p, _ := Parse([]byte(`{"subtree": true, "bad": "yes", "cleared": null}`))
+
+	var got bool
+	if ok, err := p.Bool("subtree", &got); err != nil || !ok || !got {
+		t.Errorf("Bool(subtree) = %v, %v, %v", got, ok, err)
+	}
+	if _, err := p.Bool("bad", &got); err == nil {
+		t.Error("Bool(string) should fail")
+	}
+	if _, err := p.Bool("cleared", &got); err == nil {
+		t.Error("Bool(null) should fail")
+	}
+}
+
+func TestNullString(t *testing.T) {
+	p, _ := Parse([]byte(`{"keterangan": null, "developer": "budi"}`))
+
+	ket := sql.NullString{String: "old note", Valid: true}
+	ok, err := p.NullString("keterangan", &ket)
+	if err != nil || !ok || ket.Valid {
+		t.Errorf("NullString(null) = %+v, %v, %v", ket, ok, err)
+	}
+
+	var dev sql.NullString
+	ok, err = p.NullString("developer", &dev)
+	if err != nil || !ok || !dev.Valid || dev.String != "budi" {
+		t.Errorf("NullString(value) = %+v, %v, %v", dev, ok, err)
+	}
+}
+
+func TestTimes(t *testing.T) {
+	p, _ := Parse([]byte(`{"promote_date": "2026-01-02T00:00:00Z", "cleared": null, "bad": "02/01/2026"}`))
+	want := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	var tm time.Time
+	if ok, err := p.Time("promote_date", &tm); err != nil || !ok || !tm.Equal(want) {
+		t.Errorf("Time() = %v, %v, %v", tm, ok, err)
+	}
+	if _, err := p.Time("cleared", &tm); err == nil {
+		t.Error("Time(null) should fail")
+	}
+	if _, err := p.Time("bad", &tm); err == nil {
+		t.Error("Time(non RFC 3339) should fail")
+	}
+
+	nt := sql.NullTime{Time: want, Valid: true}
+	if ok, err := p.NullTime("cleared", &nt); err != nil || !ok || nt.Valid {
+		t.Errorf("NullTime(null) = %+v, %v, %v", nt, ok, err)
+	}
+}
+
+func TestUnknown(t *testing.T) {
+	p, _ := Parse([]byte(`{"obj": "a", "zzz": 1, "id": "x"}`))
+	got := p.Unknown("obj", "lib")
+	want := []string{"id", "zzz"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unknown() = %v, want %v", got, want)
+	}
+}
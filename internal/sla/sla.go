@@ -0,0 +1,246 @@
+// Package sla measures request turnaround in business time: it picks the SLA
+// policy that applies to a request, computes when the request is due and
+// reports whether it is on track.
+package sla
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// All is the library pattern that matches every request.
+const All = "*ALL"
+
+// dateLayout keys holidays.
+const dateLayout = "2006-01-02"
+
+// maxDays bounds the search for business time, so a calendar without any
+// open day cannot loop forever.
+const maxDays = 3660
+
+// Calendar is the business time a policy target is counted in.
+type Calendar struct {
+	// Start and End are the opening hours as minutes since midnight.
+	Start, End int
+	Workdays   [7]bool
+	// Holidays are closed dates, keyed as 2006-01-02.
+	Holidays map[string]bool
+	Location *time.Location
+}
+
+// ParseHours parses opening hours written as 08:00-17:00.
+func ParseHours(s string) (start, end int, err error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(s), "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("business hours %q must look like 08:00-17:00", s)
+	}
+	if start, err = parseClock(from); err != nil {
+		return 0, 0, err
+	}
+	if end, err = parseClock(to); err != nil {
+		return 0, 0, err
+	}
+	if start >= end {
+		return 0, 0, fmt.Errorf("business hours %q end before they start", s)
+	}
+	return start, end, nil
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+var weekdays = map[string]time.Weekday{
+	"SUN": time.Sunday, "MON": time.Monday, "TUE": time.Tuesday, "WED": time.Wednesday,
+	"THU": time.Thursday, "FRI": time.Friday, "SAT": time.Saturday,
+}
+
+// ParseWorkdays parses a list of days and day ranges, e.g. MON-FRI or
+// MON,WED,FRI-SAT.
+func ParseWorkdays(s string) ([7]bool, error) {
+	var days [7]bool
+	for _, part := range strings.Split(s, ",") {
+		from, to, isRange := strings.Cut(strings.ToUpper(strings.TrimSpace(part)), "-")
+		lo, ok := weekdays[from]
+		if !ok {
+			return days, fmt.Errorf("invalid day %q", from)
+		}
+		hi := lo
+		if isRange {
+			if hi, ok = weekdays[to]; !ok {
+				return days, fmt.Errorf("invalid day %q", to)
+			}
+		}
+		for d := lo; ; d = (d + 1) % 7 {
+			days[d] = true
+			if d == hi {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+func (c Calendar) open(day time.Time) bool {
+	return c.Workdays[day.Weekday()] && !c.Holidays[day.Format(dateLayout)]
+}
+
+// window returns the opening hours of the day t falls on.
+func (c Calendar) window(t time.Time) (start, end time.Time) {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, c.Start, 0, 0, c.Location), time.Date(y, m, d, 0, c.End, 0, 0, c.Location)
+}
+
+func nextDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, t.Location())
+}
+
+// Add returns the time d of business time after t. It returns the zero time
+// if the calendar has no open day.
+func (c Calendar) Add(t time.Time, d time.Duration) time.Time {
+	t = t.In(c.Location)
+	for i := 0; i < maxDays; i++ {
+		start, end := c.window(t)
+		if !c.open(t) || !t.Before(end) {
+			t = nextDay(t)
+			continue
+		}
+		if t.Before(start) {
+			t = start
+		}
+		left := end.Sub(t)
+		if d <= left {
+			return t.Add(d)
+		}
+		d -= left
+		t = nextDay(t)
+	}
+	return time.Time{}
+}
+
+// Between returns the business time from from to to.
+func (c Calendar) Between(from, to time.Time) time.Duration {
+	from, to = from.In(c.Location), to.In(c.Location)
+	var total time.Duration
+	for i := 0; i < maxDays && from.Before(to); i++ {
+		start, end := c.window(from)
+		if c.open(from) {
+			if from.After(start) {
+				start = from
+			}
+			if to.Before(end) {
+				end = to
+			}
+			if end.After(start) {
+				total += end.Sub(start)
+			}
+		}
+		from = nextDay(from)
+	}
+	return total
+}
+
+// Policy is an SLA target for a set of requests.
+type Policy struct {
+	ID uuid.UUID
+	// Priority limits the policy to one priority; empty matches any.
+	Priority string
+	// Lib is a library, a generic prefix such as PAY* or *ALL.
+	Lib         string
+	Target      time.Duration
+	WarnPercent int
+}
+
+func isAll(pattern string) bool {
+	return pattern == "" || strings.EqualFold(pattern, All)
+}
+
+// matchLib reports whether lib matches pattern, ignoring case.
+func matchLib(pattern, lib string) bool {
+	if isAll(pattern) {
+		return true
+	}
+	pattern, lib = strings.ToLower(pattern), strings.ToLower(lib)
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(lib, prefix)
+	}
+	return pattern == lib
+}
+
+// libSpecificity ranks patterns: *ALL < generic (longer prefix wins) < exact.
+func libSpecificity(pattern string) int {
+	switch {
+	case isAll(pattern):
+		return 0
+	case strings.HasSuffix(pattern, "*"):
+		return len(pattern)
+	default:
+		return 1 << 16
+	}
+}
+
+// Matches reports whether the policy applies to a request.
+func (p Policy) Matches(priority, lib string) bool {
+	if p.Priority != "" && !strings.EqualFold(p.Priority, priority) {
+		return false
+	}
+	return matchLib(p.Lib, lib)
+}
+
+// Select returns the policy that applies to a request: the one with the most
+// specific library pattern, and among those one for the request's priority
+// before one for any priority.
+func Select(policies []Policy, priority, lib string) (Policy, bool) {
+	var matched []Policy
+	for _, p := range policies {
+		if p.Matches(priority, lib) {
+			matched = append(matched, p)
+		}
+	}
+	if len(matched) == 0 {
+		return Policy{}, false
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if sa, sb := libSpecificity(a.Lib), libSpecificity(b.Lib); sa != sb {
+			return sa > sb
+		}
+		return a.Priority != "" && b.Priority == ""
+	})
+	return matched[0], true
+}
+
+// State is how a request stands against its SLA.
+type State string
+
+const (
+	OnTrack  State = "on_track"
+	AtRisk   State = "at_risk"
+	Breached State = "breached"
+	Met      State = "met"
+)
+
+// Evaluate reports the state of a request opened at start and due at due.
+// end is when the request was resolved, or now while it is still open. An
+// open request is at risk once it has used WarnPercent of its target.
+func (c Calendar) Evaluate(p Policy, start, due, end time.Time, resolved bool) State {
+	switch {
+	case end.After(due):
+		return Breached
+	case resolved:
+		return Met
+	case p.Target > 0 && c.Between(start, end)*100 >= p.Target*time.Duration(p.WarnPercent):
+		return AtRisk
+	default:
+		return OnTrack
+	}
+}
@@ -0,0 +1,156 @@
+package sla
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func calendar(t *testing.T) Calendar {
+	t.Helper()
+	start, end, err := ParseHours("08:00-17:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	days, err := ParseWorkdays("MON-FRI")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Calendar{
+		Start:    start,
+		End:      end,
+		Workdays: days,
+		Holidays: map[string]bool{"2026-01-16": true},
+		Location: time.UTC,
+	}
+}
+
+func at(day, hour, min int) time.Time {
+	return time.Date(2026, 1, day, hour, min, 0, 0, time.UTC)
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, s := range []string{"", "08:00", "17:00-08:00", "8-17", "08:00-25:00"} {
+		if _, _, err := ParseHours(s); err == nil {
+			t.Errorf("ParseHours(%q): want error", s)
+		}
+	}
+	for _, s := range []string{"", "MON-FUN", "MONDAY"} {
+		if _, err := ParseWorkdays(s); err == nil {
+			t.Errorf("ParseWorkdays(%q): want error", s)
+		}
+	}
+}
+
+func TestParseWorkdaysWraps(t *testing.T) {
+	days, err := ParseWorkdays("fri-mon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [7]bool{true, true, false, false, false, true, true}
+	if days != want {
+		t.Errorf("ParseWorkdays() = %v, want %v", days, want)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	c := calendar(t)
+
+	// Wednesday 14 January 2026; Friday the 16th is a holiday
+	tests := []struct {
+		name  string
+		from  time.Time
+		hours int
+		want  time.Time
+	}{
+		{"same day", at(14, 9, 0), 4, at(14, 13, 0)},
+		{"before opening", at(14, 6, 30), 2, at(14, 10, 0)},
+		{"rolls to next day", at(14, 15, 0), 4, at(15, 10, 0)},
+		{"after closing", at(14, 20, 0), 1, at(15, 9, 0)},
+		{"skips holiday and weekend", at(15, 16, 0), 3, at(19, 10, 0)},
+		{"ends at closing", at(14, 8, 0), 9, at(14, 17, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.Add(tt.from, time.Duration(tt.hours)*time.Hour); !got.Equal(tt.want) {
+				t.Errorf("Add() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBetween(t *testing.T) {
+	c := calendar(t)
+
+	if got := c.Between(at(15, 16, 0), at(19, 10, 0)); got != 3*time.Hour {
+		t.Errorf("Between() = %v, want 3h", got)
+	}
+	if got := c.Between(at(14, 12, 0), at(14, 11, 0)); got != 0 {
+		t.Errorf("Between() = %v, want 0", got)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	all := Policy{ID: uuid.New(), Lib: All}
+	high := Policy{ID: uuid.New(), Priority: "high", Lib: All}
+	pay := Policy{ID: uuid.New(), Lib: "PAY*"}
+	payHigh := Policy{ID: uuid.New(), Priority: "high", Lib: "pay*"}
+	payroll := Policy{ID: uuid.New(), Lib: "PAYROLL"}
+	policies := []Policy{all, high, pay, payHigh, payroll}
+
+	tests := []struct {
+		name     string
+		priority string
+		lib      string
+		want     Policy
+	}{
+		{"default", "normal", "ORDLIB", all},
+		{"priority", "high", "ORDLIB", high},
+		{"library beats priority", "normal", "paylib", pay},
+		{"library and priority", "high", "PAYLIB", payHigh},
+		{"exact library", "high", "PAYROLL", payroll},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Select(policies, tt.priority, tt.lib)
+			if !ok || got.ID != tt.want.ID {
+				t.Errorf("Select() = %+v, %v; want %+v", got, ok, tt.want)
+			}
+		})
+	}
+
+	if _, ok := Select([]Policy{pay}, "normal", "ORDLIB"); ok {
+		t.Error("Select() matched a policy for another library")
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	c := calendar(t)
+	p := Policy{Target: 10 * time.Hour, WarnPercent: 80}
+	start := at(14, 8, 0)
+	due := c.Add(start, p.Target)
+
+	tests := []struct {
+		name     string
+		end      time.Time
+		resolved bool
+		want     State
+	}{
+		{"on track", at(14, 12, 0), false, OnTrack},
+		{"at risk", at(15, 8, 0), false, AtRisk},
+		{"breached", at(15, 10, 0), false, Breached},
+		{"met", at(15, 8, 30), true, Met},
+		{"resolved late", at(19, 9, 0), true, Breached},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.Evaluate(p, start, due, tt.end, tt.resolved); got != tt.want {
+				t.Errorf("Evaluate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSignVerify(t *testing.T) {
+	secret := []byte("s3cret")
+	body := []byte(`{"event_id":"1","lib":"PAYLIB"}`)
+	sig := Sign(secret, body)
+
+	tests := []struct {
+		name      string
+		secret    []byte
+		body      []byte
+		signature string
+		want      error
+	}{
+		{"valid", secret, body, sig, nil},
+		{"valid with spaces", secret, body, " " + sig + " ", nil},
+		{"missing", secret, body, "", ErrMissingSignature},
+		{"wrong secret", []byte("other"), body, sig, ErrInvalidSignature},
+		{"tampered body", secret, []byte(`{"event_id":"2","lib":"PAYLIB"}`), sig, ErrInvalidSignature},
+		{"no prefix", secret, body, sig[len("sha256="):], ErrInvalidSignature},
+		{"not hex", secret, body, "sha256=zz", ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Verify(tt.secret, tt.body, tt.signature); !errors.Is(err, tt.want) {
+				t.Errorf("Verify() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignKnownValue(t *testing.T) {
+	// HMAC-SHA256("key", "The quick brown fox jumps over the lazy dog")
+	want := "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"
+	if got := Sign([]byte("key"), []byte("The quick brown fox jumps over the lazy dog")); got != want {
+		t.Errorf("Sign() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/paul39-33/imimix/internal/database"
+	"github.com/paul39-33/imimix/internal/sla"
+)
+
+// maxPageLimit caps ?limit= on paginated listings.
+const maxPageLimit = 500
+
+// objFilter holds the optional query filters of obj listings. libs, when
+// set, holds the libraries of the ?team= filter; access hides the libraries
+// the caller cannot read.
+type objFilter struct {
+	text   string
+	kind   database.ObjKind
+	status database.MimixStatus
+	libs   map[string]bool
+	access libAccess
+}
+
+// parseObjFilter reads ?q=, ?kind=lib|ifs|dlo and ?status=.
+func parseObjFilter(c *gin.Context) (objFilter, error) {
+	var f objFilter
+	f.text = strings.ToLower(strings.TrimSpace(c.Query("q")))
+	if k := strings.ToLower(strings.TrimSpace(c.Query("kind"))); k != "" {
+		var ok bool
+		if f.kind, ok = allowedObjKind[k]; !ok {
+			return f, errors.New("invalid kind")
+		}
+	}
+	if s := strings.ToLower(strings.TrimSpace(c.Query("status"))); s != "" {
+		var ok bool
+		if f.status, ok = allowedMimixStatus[s]; !ok {
+			return f, errors.New("invalid status")
+		}
+	}
+	return f, nil
+}
+
+func (f objFilter) match(obj database.MimixObj) bool {
+	if f.kind != "" && obj.ObjKind != f.kind {
+		return false
+	}
+	if f.status != "" && obj.MimixStatus != f.status {
+		return false
+	}
+	if f.libs != nil && !f.libs[obj.Lib] {
+		return false
+	}
+	if !f.access.can(obj.Lib, database.LibPermissionRead) {
+		return false
+	}
+	return f.text == "" || containsFold(f.text, obj.Obj, obj.Lib, obj.Developer)
+}
+
+// objReqFilter holds the optional query filters of obj request listings.
+type objReqFilter struct {
+	text   string
+	kind   database.ObjKind
+	status database.ReqStatus
+	sla    sla.State
+	libs   map[string]bool
+	access libAccess
+}
+
+// parseObjReqFilter reads ?q=, ?kind=lib|ifs|dlo, ?status= and
+// ?sla=on_track|at_risk|breached|met.
+func parseObjReqFilter(c *gin.Context) (objReqFilter, error) {
+	var f objReqFilter
+	f.text = strings.ToLower(strings.TrimSpace(c.Query("q")))
+	if k := strings.ToLower(strings.TrimSpace(c.Query("kind"))); k != "" {
+		var ok bool
+		if f.kind, ok = allowedObjKind[k]; !ok {
+			return f, errors.New("invalid kind")
+		}
+	}
+	if s := strings.ToLower(strings.TrimSpace(c.Query("status"))); s != "" {
+		var ok bool
+		if f.status, ok = allowedReqStatus[s]; !ok {
+			return f, errors.New("invalid status")
+		}
+	}
+	f.sla = sla.State(strings.ToLower(strings.TrimSpace(c.Query("sla"))))
+	switch f.sla {
+	case "", sla.OnTrack, sla.AtRisk, sla.Breached, sla.Met:
+	default:
+		return f, errors.New("invalid sla")
+	}
+	return f, nil
+}
+
+// match checks a request that already carries its SLA status.
+func (f objReqFilter) match(req MimixObjReq) bool {
+	if f.kind != "" && req.ObjKind != string(f.kind) {
+		return false
+	}
+	if f.status != "" && req.ReqStatus != string(f.status) {
+		return false
+	}
+	if f.sla != "" && (req.SLA == nil || req.SLA.State != string(f.sla)) {
+		return false
+	}
+	if f.libs != nil && !f.libs[req.Lib] {
+		return false
+	}
+	if !f.access.can(req.Lib, database.LibPermissionRead) {
+		return false
+	}
+	return f.text == "" || containsFold(f.text, req.ObjName, req.Requester, req.Developer, req.Lib)
+}
+
+// containsFold reports whether any of fields contains the lower-cased needle.
+func containsFold(needle string, fields ...string) bool {
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), needle) {
+			return true
+		}
+	}
+	return false
+}
+
+// page is a window over a listing. A zero limit means no limit.
+type page struct {
+	limit  int
+	offset int
+}
+
+// parsePage reads ?limit= (at most maxPageLimit) and ?offset=. Without
+// ?limit= the whole listing is returned, as before pagination existed.
+func parsePage(c *gin.Context) (page, error) {
+	var p page
+	if val := c.Query("limit"); val != "" {
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 1 {
+			return p, errors.New("invalid limit")
+		}
+		p.limit = min(n, maxPageLimit)
+	}
+	if val := c.Query("offset"); val != "" {
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 0 {
+			return p, errors.New("invalid offset")
+		}
+		p.offset = n
+	}
+	return p, nil
+}
+
+// paginate cuts the page out of items and reports the unpaginated size in
+// the X-Total-Count header.
+func paginate[T any](c *gin.Context, p page, items []T) []T {
+	c.Header("X-Total-Count", strconv.Itoa(len(items)))
+	if p.offset > 0 {
+		items = items[min(p.offset, len(items)):]
+	}
+	if p.limit > 0 && len(items) > p.limit {
+		items = items[:p.limit]
+	}
+	return items
+}
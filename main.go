@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"os"
@@ -28,8 +29,53 @@ func main() {
 
 	//apiCfg
 	apiCfg := apiConfig{
-		dbQueries: dbQueries,
-		secret:    secret,
+		db:                 db,
+		dbQueries:          dbQueries,
+		secret:             secret,
+		trashRetentionDays: trashRetentionDaysFromEnv(),
+		webhookSecret:      os.Getenv("DEPLOY_WEBHOOK_SECRET"),
+		assignStrategy:     assignStrategyFromEnv(),
+		attachmentMaxBytes: attachmentMaxBytesFromEnv(),
+		attachmentTypes:    attachmentTypesFromEnv(),
+		permissions:        newPermCache(permissionCacheTTLFromEnv()),
+	}
+
+	apiCfg.attachments, err = attachmentStoreFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to set up attachment storage: %v", err)
+	}
+
+	apiCfg.authenticator, err = authenticatorFromEnv(dbQueries)
+	if err != nil {
+		log.Fatalf("Failed to set up authentication: %v", err)
+	}
+	apiCfg.groupRoles, err = groupRolesFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid LDAP group roles: %v", err)
+	}
+
+	apiCfg.slaCalendar, err = slaCalendarFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid SLA calendar: %v", err)
+	}
+	if err := apiCfg.bootstrapLead(context.Background()); err != nil {
+		log.Printf("error appointing the first lead: %v", err)
+	}
+
+	//due dates follow SLA_TIMEZONE, which may have changed since the last start
+	if n, err := apiCfg.recalculateSLA(context.Background(), dbQueries); err != nil {
+		log.Printf("error recalculating SLA due dates: %v", err)
+	} else if n > 0 {
+		log.Printf("recalculated the SLA due date of %d requests", n)
+	}
+
+	//background jobs, including the trash purge
+	apiCfg.scheduler, err = apiCfg.newScheduler()
+	if err != nil {
+		log.Fatalf("Failed to set up scheduler: %v", err)
+	}
+	if schedulerEnabled() {
+		go apiCfg.scheduler.run(context.Background())
 	}
 
 	//create Gin router
@@ -38,10 +84,17 @@ func main() {
 	//set frontend directory by url /app/... from .
 	r.StaticFS("/app", gin.Dir("./public", false))
 
-	api := r.Group("/api")
+	public := r.Group("/api")
+	{
+		public.POST("/create_user", apiCfg.CreateUser)
+		public.POST("/login", apiCfg.UserLogin)
+		//signed with DEPLOY_WEBHOOK_SECRET instead of a user token
+		public.POST("/webhooks/deployments", apiCfg.ReceiveDeploymentEvent)
+	}
+
+	//every other route resolves the caller and their permissions first
+	api := public.Group("", apiCfg.AuthMiddleware())
 	{
-		api.POST("/create_user", apiCfg.CreateUser)
-		api.POST("/login", apiCfg.UserLogin)
 		api.POST("/add_mimix_obj", apiCfg.CreateObj)
 		api.POST("/create_obj_req", apiCfg.CreateObjReq)
 		api.DELETE("/delete_mimix_obj/:obj", apiCfg.RemoveObj)
@@ -59,6 +112,133 @@ func main() {
 
 		api.GET("/obj_req/search/:query", apiCfg.SearchObjReq)
 		api.GET("/obj_req/search", apiCfg.SearchObjReq) // Handle empty search
+
+		api.GET("/obj/:id", apiCfg.GetObj)
+		api.GET("/obj_req/:id", apiCfg.GetObjReq)
+
+		api.GET("/obj/:id/dependencies", apiCfg.GetObjDependencies)
+		api.POST("/obj/:id/dependencies", apiCfg.AddObjDependency)
+		api.DELETE("/dependencies/:id", apiCfg.DeleteObjDependency)
+		api.POST("/dependencies/import", apiCfg.ImportDependencies)
+
+		api.GET("/stages", apiCfg.ListPromotionStages)
+		api.POST("/stages", apiCfg.CreatePromotionStage)
+		api.PUT("/stages/:id", apiCfg.UpdatePromotionStage)
+		api.DELETE("/stages/:id", apiCfg.DeletePromotionStage)
+		api.POST("/obj/:id/promote", apiCfg.PromoteObj)
+		api.GET("/obj/:id/promotions", apiCfg.ListObjPromotions)
+		api.POST("/obj_req/:id/promote", apiCfg.PromoteObjReq)
+		api.GET("/obj_req/:id/promotions", apiCfg.ListObjReqPromotions)
+
+		api.GET("/deployments/review", apiCfg.ListDeploymentReview)
+		api.POST("/deployments/:id/resolve", apiCfg.ResolveDeploymentEvent)
+		api.POST("/deployments/:id/dismiss", apiCfg.DismissDeploymentEvent)
+
+		api.GET("/sla/policies", apiCfg.ListSLAPolicies)
+		api.POST("/sla/policies", apiCfg.CreateSLAPolicy)
+		api.PUT("/sla/policies/:id", apiCfg.UpdateSLAPolicy)
+		api.DELETE("/sla/policies/:id", apiCfg.DeleteSLAPolicy)
+		api.GET("/sla/holidays", apiCfg.ListSLAHolidays)
+		api.POST("/sla/holidays", apiCfg.AddSLAHoliday)
+		api.DELETE("/sla/holidays/:date", apiCfg.DeleteSLAHoliday)
+		api.GET("/obj_req/:id/sla", apiCfg.GetObjReqSLA)
+
+		api.GET("/emergency_reviews", apiCfg.ListEmergencyReviews)
+		api.POST("/emergency_reviews/:id/justify", apiCfg.JustifyEmergencyReview)
+		api.POST("/emergency_reviews/:id/review", apiCfg.ReviewEmergencyReview)
+		api.GET("/obj_req/:id/emergency_reviews", apiCfg.ListObjReqEmergencyReviews)
+
+		api.POST("/obj_req/:id/claim", apiCfg.ClaimObjReq)
+		api.POST("/obj_req/:id/unclaim", apiCfg.UnclaimObjReq)
+		api.POST("/obj_req/:id/assign", apiCfg.AssignObjReq)
+		api.GET("/me/queue", apiCfg.MyQueue)
+		api.GET("/me/requests", apiCfg.MyObjReqs)
+		api.GET("/me/objects", apiCfg.MyObjs)
+		api.GET("/me/summary", apiCfg.MySummary)
+		api.GET("/me/approvals", apiCfg.MyTeamApprovals)
+		api.GET("/queue/unassigned", apiCfg.ListUnassignedQueue)
+		api.GET("/queue/workload", apiCfg.ListWorkload)
+		api.PUT("/admin/users/:username/lead", apiCfg.SetUserLead)
+		api.PUT("/admin/users/:username/directory", apiCfg.SetUserDirectory)
+		api.POST("/admin/users/:username/reassign", apiCfg.ReassignDeveloper)
+		api.GET("/admin/developer_matches", apiCfg.ListDeveloperMatches)
+		api.POST("/admin/developer_matches/resolve", apiCfg.ResolveDeveloperMatch)
+
+		api.GET("/obj/:id/versions", apiCfg.ListObjVersions)
+		api.GET("/obj/:id/versions/diff", apiCfg.DiffObjVersions)
+
+		api.GET("/obj/:id/comments", apiCfg.ListObjComments)
+		api.POST("/obj/:id/comments", apiCfg.AddObjComment)
+		api.GET("/obj_req/:id/comments", apiCfg.ListObjReqComments)
+		api.POST("/obj_req/:id/comments", apiCfg.AddObjReqComment)
+		api.PUT("/comments/:id", apiCfg.UpdateComment)
+		api.DELETE("/comments/:id", apiCfg.DeleteComment)
+		api.GET("/comments/:id/history", apiCfg.ListCommentHistory)
+
+		api.GET("/obj/:id/attachments", apiCfg.ListObjAttachments)
+		api.POST("/obj/:id/attachments", apiCfg.UploadObjAttachment)
+		api.GET("/obj_req/:id/attachments", apiCfg.ListObjReqAttachments)
+		api.POST("/obj_req/:id/attachments", apiCfg.UploadObjReqAttachment)
+		api.GET("/attachments/:id", apiCfg.DownloadAttachment)
+		api.DELETE("/attachments/:id", apiCfg.DeleteAttachment)
+
+		api.GET("/teams", apiCfg.ListTeams)
+		api.POST("/teams", apiCfg.CreateTeam)
+		api.GET("/teams/:id", apiCfg.GetTeam)
+		api.DELETE("/teams/:id", apiCfg.DeleteTeam)
+		api.GET("/teams/:id/stats", apiCfg.GetTeamStats)
+		api.PUT("/teams/:id/members/:username", apiCfg.SetTeamMember)
+		api.DELETE("/teams/:id/members/:username", apiCfg.RemoveTeamMember)
+		api.PUT("/libs/:lib/team", apiCfg.SetLibTeam)
+		api.POST("/obj_req/:id/team_approval", apiCfg.ReviewTeamApproval)
+
+		api.GET("/libs/:lib/acl", apiCfg.ListLibACL)
+		api.PUT("/libs/:lib/restricted", apiCfg.SetLibRestricted)
+		api.POST("/libs/:lib/acl", apiCfg.GrantLibAccess)
+		api.DELETE("/lib_acl/:id", apiCfg.RevokeLibAccess)
+		api.GET("/admin/users/:username/permissions", apiCfg.GetUserPermissions)
+
+		api.GET("/admin/permissions", apiCfg.ListPermissions)
+		api.GET("/admin/roles", apiCfg.ListRoles)
+		api.POST("/admin/roles", apiCfg.CreateRole)
+		api.PUT("/admin/roles/:name", apiCfg.UpdateRole)
+		api.DELETE("/admin/roles/:name", apiCfg.DeleteRole)
+		api.GET("/admin/users/:username/roles", apiCfg.GetUserRoles)
+		api.PUT("/admin/users/:username/roles", apiCfg.SetUserRoles)
+
+		api.GET("/notifications", apiCfg.ListNotifications)
+		api.POST("/notifications/:id/read", apiCfg.MarkNotificationRead)
+		api.POST("/notifications/read_all", apiCfg.MarkAllNotificationsRead)
+
+		api.GET("/admin/jobs", apiCfg.ListJobs)
+		api.GET("/admin/jobs/runs", apiCfg.ListJobRuns)
+		api.POST("/admin/jobs/:name/run", apiCfg.RunJob)
+
+		api.POST("/tickets", apiCfg.CreateTicket)
+		api.GET("/tickets", apiCfg.ListTickets)
+		api.GET("/tickets/:id", apiCfg.GetTicket)
+		api.POST("/tickets/:id/requests", apiCfg.AddTicketRequests)
+		api.POST("/tickets/:id/convert", apiCfg.ConvertTicket)
+		api.POST("/tickets/:id/reject", apiCfg.RejectTicket)
+
+		api.POST("/bulk/obj/delete", apiCfg.BulkRemoveObj)
+		api.POST("/bulk/obj/status", apiCfg.BulkUpdateObjStatus)
+		api.POST("/bulk/obj_req/convert", apiCfg.BulkObjReqToObj)
+
+		api.GET("/trash", apiCfg.ListTrash)
+		api.POST("/trash/obj/:id/restore", apiCfg.RestoreObj)
+		api.POST("/trash/obj_req/:id/restore", apiCfg.RestoreObjReq)
+		api.DELETE("/trash/obj/:id", apiCfg.PurgeObj)
+		api.DELETE("/trash/obj_req/:id", apiCfg.PurgeObjReq)
+
+		api.GET("/object_types", apiCfg.ListObjectTypes)
+		api.GET("/admin/naming_report", apiCfg.GetNamingReport)
+
+		api.GET("/coverage/rules", apiCfg.ListCoverageRules)
+		api.POST("/coverage/rules", apiCfg.CreateCoverageRule)
+		api.DELETE("/coverage/rules/:id", apiCfg.DeleteCoverageRule)
+		api.POST("/coverage/preview", apiCfg.PreviewCoverageRule)
+		api.GET("/coverage/explain/:id", apiCfg.ExplainObjCoverage)
 	}
 
 	//start server on port 8080
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// StatusCounts is a total with its break-down by status.
+type StatusCounts struct {
+	Total    int64            `json:"total"`
+	ByStatus map[string]int64 `json:"by_status"`
+}
+
+// MySummary is the overview of the caller's own work.
+type MySummary struct {
+	Requests StatusCounts `json:"requests"`
+	Objects  StatusCounts `json:"objects"`
+}
+
+// MyObjReqs returns the requests the caller filed, highest priority first.
+// It takes the same ?q=, ?kind=, ?status=, ?sla=, ?team=, ?limit= and
+// ?offset= as obj request search.
+func (cfg *apiConfig) MyObjReqs(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c)
+	if !ok {
+		return
+	}
+
+	filter, err := parseObjReqFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	teamLibs, ok := cfg.teamLibsParam(c)
+	if !ok {
+		return
+	}
+	access, ok := cfg.libAccessFor(c, user)
+	if !ok {
+		return
+	}
+	filter.libs, filter.access = teamLibs, access
+	p, err := parsePage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	rows, err := cfg.dbQueries.GetMimixObjReqByRequester(ctx, user.Username)
+	if err != nil {
+		log.Printf("error listing obj requests by requester: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get requests"})
+		return
+	}
+	slas, err := loadSLASetup(ctx, cfg.dbQueries, cfg.slaCalendar)
+	if err != nil {
+		log.Printf("error loading sla policies: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get requests"})
+		return
+	}
+
+	now := time.Now()
+	reqs := []MimixObjReq{}
+	for _, row := range rows {
+		req := toMimixObjReq(row)
+		req.SLA = slas.status(row, now)
+		if filter.match(req) {
+			reqs = append(reqs, req)
+		}
+	}
+
+	c.JSON(http.StatusOK, paginate(c, p, reqs))
+}
+
+// MyObjs returns the objs the caller is the developer of, most recently
+// updated first. It takes the same ?q=, ?kind=, ?status=, ?team=, ?limit=
+// and ?offset= as obj search.
+func (cfg *apiConfig) MyObjs(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c)
+	if !ok {
+		return
+	}
+
+	filter, err := parseObjFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	teamLibs, ok := cfg.teamLibsParam(c)
+	if !ok {
+		return
+	}
+	access, ok := cfg.libAccessFor(c, user)
+	if !ok {
+		return
+	}
+	filter.libs, filter.access = teamLibs, access
+	p, err := parsePage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, err := cfg.dbQueries.ListObjByDeveloper(c.Request.Context(), uuid.NullUUID{UUID: user.ID, Valid: true})
+	if err != nil {
+		log.Printf("error listing objs by developer: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get objs"})
+		return
+	}
+
+	objs := []MimixObj{}
+	for _, row := range rows {
+		if filter.match(row) {
+			objs = append(objs, toMimixObj(row))
+		}
+	}
+
+	c.JSON(http.StatusOK, paginate(c, p, objs))
+}
+
+// MySummary counts the caller's requests and objs by status.
+func (cfg *apiConfig) MySummary(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	reqCounts, err := cfg.dbQueries.CountObjReqByRequester(ctx, user.Username)
+	if err != nil {
+		log.Printf("error counting obj requests by requester: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get summary"})
+		return
+	}
+	objCounts, err := cfg.dbQueries.CountObjByDeveloper(ctx, uuid.NullUUID{UUID: user.ID, Valid: true})
+	if err != nil {
+		log.Printf("error counting objs by developer: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get summary"})
+		return
+	}
+
+	summary := MySummary{
+		Requests: StatusCounts{ByStatus: map[string]int64{}},
+		Objects:  StatusCounts{ByStatus: map[string]int64{}},
+	}
+	for _, row := range reqCounts {
+		summary.Requests.ByStatus[string(row.ReqStatus)] = row.Count
+		summary.Requests.Total += row.Count
+	}
+	for _, row := range objCounts {
+		summary.Objects.ByStatus[string(row.MimixStatus)] = row.Count
+		summary.Objects.Total += row.Count
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
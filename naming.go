@@ -0,0 +1,284 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/paul39-33/imimix/internal/database"
+	"github.com/paul39-33/imimix/internal/ibmi"
+)
+
+// fieldError reports a field that does not follow the IBM i naming rules.
+// Its message is safe to return to the caller.
+type fieldError struct {
+	field string
+	err   error
+}
+
+func (e fieldError) Error() string { return "invalid " + e.field + ": " + e.err.Error() }
+
+// NamingIssue is one stored value that does not match its normalised form.
+// Suggestion is set when normalising alone would fix the value.
+type NamingIssue struct {
+	Table      string    `json:"table"`
+	ID         uuid.UUID `json:"id"`
+	Field      string    `json:"field"`
+	Value      string    `json:"value"`
+	Error      string    `json:"error,omitempty"`
+	Suggestion string    `json:"suggestion,omitempty"`
+}
+
+type NamingReport struct {
+	Checked       int           `json:"checked"`
+	NonConforming int           `json:"non_conforming"`
+	Issues        []NamingIssue `json:"issues"`
+}
+
+// normalizeObjName validates an obj or lib name. Simple names are stored in
+// lower case like the rest of the registry; quoted names keep their case.
+func normalizeObjName(field, name string) (string, error) {
+	n, err := ibmi.NormalizeName(name)
+	if err != nil {
+		return "", fieldError{field: field, err: err}
+	}
+	if !ibmi.IsQuoted(n) {
+		n = strings.ToLower(n)
+	}
+	return n, nil
+}
+
+// normalizeObjType returns the catalogue spelling of an object type, e.g. *PGM.
+func normalizeObjType(objType string) (string, error) {
+	t, err := ibmi.NormalizeType(objType)
+	if err != nil {
+		return "", fieldError{field: "obj_type", err: err}
+	}
+	return t, nil
+}
+
+// checkKindType rejects a normalised object type that belongs to another
+// kind, such as a *STMF on a library object.
+func checkKindType(kind database.ObjKind, objType string) error {
+	if t, _ := ibmi.LookupType(objType); t.Kind != ibmi.Kind(kind) {
+		return fieldError{field: "obj_type", err: fmt.Errorf("%s is not a %s object type", objType, kind)}
+	}
+	return nil
+}
+
+// pseudo libraries holding IFS and DLO entries, which have no real library.
+// '*' cannot start a library name so they never clash with one.
+const (
+	ifsLib = "*ifs"
+	dloLib = "*dlo"
+)
+
+var allowedObjKind = map[string]database.ObjKind{
+	"lib": database.ObjKindLib,
+	"ifs": database.ObjKindIfs,
+	"dlo": database.ObjKindDlo,
+}
+
+var allowedPrcType = map[string]database.PrcType{
+	"include": database.PrcTypeInclude,
+	"exclude": database.PrcTypeExclude,
+}
+
+// objIdentity is what names an obj or obj request. Name holds the object name
+// for library objects, the full path for IFS entries and the folder path
+// (ending in the document, if any) for DLOs.
+type objIdentity struct {
+	Kind    database.ObjKind
+	Name    string
+	Lib     string
+	ObjType string
+	Subtree bool
+}
+
+// parseObjKind reads an obj_kind field; empty means a library object so older
+// clients keep working.
+func parseObjKind(kind string) (database.ObjKind, error) {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	if kind == "" {
+		return database.ObjKindLib, nil
+	}
+	val, ok := allowedObjKind[kind]
+	if !ok {
+		return "", fieldError{field: "obj_kind", err: errors.New("must be lib, ifs or dlo")}
+	}
+	return val, nil
+}
+
+// parsePrcType reads a prc_type field; empty means include.
+func parsePrcType(prcType string) (database.PrcType, error) {
+	prcType = strings.ToLower(strings.TrimSpace(prcType))
+	if prcType == "" {
+		return database.PrcTypeInclude, nil
+	}
+	val, ok := allowedPrcType[prcType]
+	if !ok {
+		return "", fieldError{field: "prc_type", err: errors.New("must be include or exclude")}
+	}
+	return val, nil
+}
+
+// normalize validates the identity against the rules of its kind and puts it
+// in stored form. nameField is the json name of the name field.
+func (id *objIdentity) normalize(nameField string) error {
+	var err error
+	if id.ObjType, err = normalizeObjType(id.ObjType); err != nil {
+		return err
+	}
+	if err := checkKindType(id.Kind, id.ObjType); err != nil {
+		return err
+	}
+
+	switch id.Kind {
+	case database.ObjKindIfs:
+		path, err := ibmi.NormalizeIFSPath(id.Name)
+		if err != nil {
+			return fieldError{field: nameField, err: err}
+		}
+		if id.Subtree && id.ObjType != "*DIR" {
+			return fieldError{field: "subtree", err: errors.New("only a *DIR can cover a subtree")}
+		}
+		id.Name, id.Lib = path, ifsLib
+
+	case database.ObjKindDlo:
+		path, err := ibmi.NormalizeDLOPath(id.Name)
+		if err != nil {
+			return fieldError{field: nameField, err: err}
+		}
+		if folder, _ := ibmi.SplitDLOPath(path); id.ObjType == "*DOC" && folder == "" {
+			return fieldError{field: nameField, err: errors.New("a document must be inside a folder")}
+		}
+		if id.Subtree && id.ObjType != "*FLR" {
+			return fieldError{field: "subtree", err: errors.New("only a *FLR can cover a subtree")}
+		}
+		id.Name, id.Lib = strings.ToLower(path), dloLib
+
+	default:
+		if id.Subtree {
+			return fieldError{field: "subtree", err: errors.New("only ifs and dlo entries can cover a subtree")}
+		}
+		if id.Name, err = normalizeObjName(nameField, id.Name); err != nil {
+			return err
+		}
+		if id.Lib, err = normalizeObjName("lib", id.Lib); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// displayName is how an obj is shown: LIB/OBJ for library objects, the path
+// for IFS entries and /QDLS/ plus the folder path for DLOs.
+func displayName(kind database.ObjKind, name, lib string, subtree bool) string {
+	var display string
+	switch kind {
+	case database.ObjKindIfs:
+		display = name
+	case database.ObjKindDlo:
+		display = "/QDLS/" + strings.ToUpper(name)
+	default:
+		return lib + "/" + name
+	}
+	if subtree {
+		display += "/*"
+	}
+	return display
+}
+
+// dloFolder returns the folder part of a DLO path, empty for other kinds.
+func dloFolder(kind database.ObjKind, name string) string {
+	if kind != database.ObjKindDlo {
+		return ""
+	}
+	folder, _ := ibmi.SplitDLOPath(name)
+	return folder
+}
+
+// checkNaming adds an issue to the report for every field of a row whose
+// stored value differs from its normalised form.
+func (r *NamingReport) checkNaming(table string, id uuid.UUID, kind database.ObjKind, name, lib, objType, nameField string) {
+	// IFS and DLO entries are validated as a whole on every write
+	if kind != database.ObjKindLib {
+		return
+	}
+	r.Checked++
+	before := len(r.Issues)
+
+	check := func(field, value string, normalize func(string) (string, error)) {
+		issue := NamingIssue{Table: table, ID: id, Field: field, Value: value}
+		normalized, err := normalize(value)
+		switch {
+		case err != nil:
+			issue.Error = err.Error()
+		case normalized != value:
+			issue.Suggestion = normalized
+		default:
+			return
+		}
+		r.Issues = append(r.Issues, issue)
+	}
+
+	check(nameField, name, func(v string) (string, error) { return normalizeObjName(nameField, v) })
+	check("lib", lib, func(v string) (string, error) { return normalizeObjName("lib", v) })
+	check("obj_type", objType, normalizeObjType)
+
+	if len(r.Issues) > before {
+		r.NonConforming++
+	}
+}
+
+// GetNamingReport lists existing objs and obj requests whose name, library or
+// type does not follow the IBM i rules, for cleaning up rows stored before
+// validation existed.
+func (cfg *apiConfig) GetNamingReport(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permReportsView)
+	if !ok {
+		return
+	}
+	access, ok := cfg.libAccessFor(c, user)
+	if !ok {
+		return
+	}
+
+	objs, err := cfg.dbQueries.SearchMimixObj(c.Request.Context(), sql.NullString{String: "", Valid: true})
+	if err != nil {
+		log.Printf("error listing mimix objects: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get mimix objects"})
+		return
+	}
+
+	reqs, err := cfg.dbQueries.GetMimixObjReq(c.Request.Context())
+	if err != nil {
+		log.Printf("error listing mimix object requests: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get mimix object requests"})
+		return
+	}
+
+	report := NamingReport{Issues: []NamingIssue{}}
+	for _, obj := range access.readableObjs(objs) {
+		report.checkNaming("mimix_obj", obj.ID, obj.ObjKind, obj.Obj, obj.Lib, obj.ObjType, "obj")
+	}
+	for _, req := range access.readableObjReqs(reqs) {
+		report.checkNaming("mimix_obj_req", req.ID, req.ObjKind, req.ObjName, req.Lib, req.ObjType, "obj_name")
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ListObjectTypes returns the object type catalogue.
+func (cfg *apiConfig) ListObjectTypes(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c); !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, ibmi.Types())
+}
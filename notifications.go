@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/paul39-33/imimix/internal/database"
+)
+
+// notification kinds
+const (
+	notifyPromoteReminder   = "promote_reminder"
+	notifyPendingEscalation = "pending_escalation"
+	notifyStaleDaftarkan    = "stale_daftarkan"
+)
+
+type Notification struct {
+	ID        uuid.UUID  `json:"id"`
+	Kind      string     `json:"kind"`
+	Message   string     `json:"message"`
+	ObjID     *uuid.UUID `json:"obj_id,omitempty"`
+	ObjReqID  *uuid.UUID `json:"obj_req_id,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+}
+
+// notice is a notification to send. DedupeKey, when set, makes sending the
+// same notice again a no-op.
+type notice struct {
+	recipient string
+	kind      string
+	message   string
+	objID     uuid.UUID
+	objReqID  uuid.UUID
+	dedupeKey string
+}
+
+func toNotification(n database.Notification) Notification {
+	return Notification{
+		ID:        n.ID,
+		Kind:      n.Kind,
+		Message:   n.Message,
+		ObjID:     NullUUIDToPtr(n.ObjID),
+		ObjReqID:  NullUUIDToPtr(n.ObjReqID),
+		CreatedAt: n.CreatedAt,
+		ReadAt:    NullTimeToPtr(n.ReadAt),
+	}
+}
+
+// notify stores a notification and reports whether it is new. Recipients are
+// usernames, compared without case.
+func notify(ctx context.Context, q *database.Queries, n notice) (bool, error) {
+	recipient := strings.ToLower(strings.TrimSpace(n.recipient))
+	if recipient == "" {
+		return false, nil
+	}
+
+	rows, err := q.CreateNotification(ctx, database.CreateNotificationParams{
+		Recipient: recipient,
+		Kind:      n.kind,
+		Message:   n.message,
+		ObjID:     uuid.NullUUID{UUID: n.objID, Valid: n.objID != uuid.Nil},
+		ObjReqID:  uuid.NullUUID{UUID: n.objReqID, Valid: n.objReqID != uuid.Nil},
+		DedupeKey: sql.NullString{String: n.dedupeKey, Valid: n.dedupeKey != ""},
+	})
+	return rows > 0, err
+}
+
+// ListNotifications returns the caller's latest notifications. ?unread=true
+// leaves out the ones already read.
+func (cfg *apiConfig) ListNotifications(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c)
+	if !ok {
+		return
+	}
+
+	rows, err := cfg.dbQueries.ListNotifications(c.Request.Context(), database.ListNotificationsParams{
+		Recipient:  strings.ToLower(user.Username),
+		UnreadOnly: c.Query("unread") == "true",
+	})
+	if err != nil {
+		log.Printf("error listing notifications: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get notifications"})
+		return
+	}
+
+	notifications := make([]Notification, 0, len(rows))
+	for _, row := range rows {
+		notifications = append(notifications, toNotification(row))
+	}
+	c.JSON(http.StatusOK, notifications)
+}
+
+// MarkNotificationRead marks one of the caller's notifications read.
+func (cfg *apiConfig) MarkNotificationRead(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid notification id"})
+		return
+	}
+
+	rows, err := cfg.dbQueries.MarkNotificationRead(c.Request.Context(), database.MarkNotificationReadParams{
+		ID:        id,
+		Recipient: strings.ToLower(user.Username),
+	})
+	if err != nil {
+		log.Printf("error marking notification read: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not update notification"})
+		return
+	}
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unread notification not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "notification marked read"})
+}
+
+// MarkAllNotificationsRead marks every unread notification of the caller
+// read.
+func (cfg *apiConfig) MarkAllNotificationsRead(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c)
+	if !ok {
+		return
+	}
+
+	rows, err := cfg.dbQueries.MarkAllNotificationsRead(c.Request.Context(), strings.ToLower(user.Username))
+	if err != nil {
+		log.Printf("error marking notifications read: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not update notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"marked_read": rows})
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/paul39-33/imimix/internal/database"
+	"github.com/paul39-33/imimix/internal/mergepatch"
+)
+
+// objPatchFields and objReqPatchFields are the fields a merge patch may touch.
+var objPatchFields = []string{
+	"obj", "obj_type", "lib", "promote_date", "obj_ver", "developer", "mimix_status", "keterangan",
+	"obj_kind", "prc_type", "subtree",
+}
+
+var objReqPatchFields = []string{
+	"obj_name", "lib", "promote_date", "obj_ver", "obj_type", "developer", "promote_status", "req_status",
+	"obj_kind", "prc_type", "subtree", "priority",
+}
+
+// bindMergePatch reads the request body as a JSON Merge Patch. Plain
+// application/json is accepted too and gets the same merge semantics, so
+// older clients keep working.
+func bindMergePatch(c *gin.Context, allowed []string) (mergepatch.Patch, bool) {
+	mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if err != nil || (mediaType != mergepatch.ContentType && mediaType != "application/json") {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{
+			"error": "content type must be " + mergepatch.ContentType + " or application/json",
+		})
+		return nil, false
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		log.Printf("error reading request body: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid parameters"})
+		return nil, false
+	}
+
+	patch, err := mergepatch.Parse(body)
+	if err != nil {
+		log.Printf("error parsing merge patch: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid parameters"})
+		return nil, false
+	}
+
+	if unknown := patch.Unknown(allowed...); len(unknown) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "unknown fields: " + strings.Join(unknown, ", "),
+		})
+		return nil, false
+	}
+	return patch, true
+}
+
+// patchRequiredString applies a non-nullable text field; when present it must
+// not be blank.
+func patchRequiredString(p mergepatch.Patch, field string, dst *string) error {
+	val := *dst
+	changed, err := p.String(field, &val)
+	if err != nil || !changed {
+		return err
+	}
+	if strings.TrimSpace(val) == "" {
+		return errors.New(field + " cannot be empty")
+	}
+	*dst = val
+	return nil
+}
+
+// patchObjIdentity applies the identity fields of a patch (name, lib, type,
+// kind, subtree and prc_type) and normalises what changed. For library objects
+// unchanged fields are left alone so old rows stay editable; any other kind,
+// or a change of kind or subtree, is checked as a whole.
+func patchObjIdentity(p mergepatch.Patch, nameField string, id *objIdentity, prcType *database.PrcType) error {
+	var s string
+	if changed, err := p.String("prc_type", &s); err != nil {
+		return err
+	} else if changed {
+		if *prcType, err = parsePrcType(s); err != nil {
+			return err
+		}
+	}
+
+	if changed, err := p.String("obj_kind", &s); err != nil {
+		return err
+	} else if changed {
+		if id.Kind, err = parseObjKind(s); err != nil {
+			return err
+		}
+	}
+	if _, err := p.Bool("subtree", &id.Subtree); err != nil {
+		return err
+	}
+
+	if !p.Has(nameField) && !p.Has("lib") && !p.Has("obj_type") && !p.Has("obj_kind") && !p.Has("subtree") {
+		return nil
+	}
+	if id.Kind != database.ObjKindLib || p.Has("obj_kind") || p.Has("subtree") {
+		return id.normalize(nameField)
+	}
+
+	var err error
+	if p.Has(nameField) {
+		if id.Name, err = normalizeObjName(nameField, id.Name); err != nil {
+			return err
+		}
+	}
+	if p.Has("lib") {
+		if id.Lib, err = normalizeObjName("lib", id.Lib); err != nil {
+			return err
+		}
+	}
+	if p.Has("obj_type") {
+		if id.ObjType, err = normalizeObjType(id.ObjType); err != nil {
+			return err
+		}
+		if err := checkKindType(id.Kind, id.ObjType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyObjPatch applies a merge patch on top of the current obj row. Only
+// fields present in the patch are validated.
+func applyObjPatch(p mergepatch.Patch, params *database.UpdateObjInfoParams) error {
+	for _, f := range []struct {
+		name string
+		dst  *string
+	}{
+		{"obj", &params.Obj},
+		{"obj_type", &params.ObjType},
+		{"lib", &params.Lib},
+		{"obj_ver", &params.ObjVer},
+	} {
+		if err := patchRequiredString(p, f.name, f.dst); err != nil {
+			return err
+		}
+	}
+	identity := objIdentity{Kind: params.ObjKind, Name: params.Obj, Lib: params.Lib, ObjType: params.ObjType, Subtree: params.Subtree}
+	if err := patchObjIdentity(p, "obj", &identity, &params.PrcType); err != nil {
+		return err
+	}
+	params.ObjKind, params.Obj, params.Lib, params.ObjType, params.Subtree =
+		identity.Kind, identity.Name, identity.Lib, identity.ObjType, identity.Subtree
+
+	if _, err := p.NullTime("promote_date", &params.PromoteDate); err != nil {
+		return err
+	}
+
+	// developer is NOT NULL on mimix_obj, so null clears it to ""
+	if p.IsNull("developer") {
+		params.Developer = ""
+	} else if _, err := p.String("developer", &params.Developer); err != nil {
+		return err
+	} else if p.Has("developer") {
+		params.Developer = strings.ToLower(strings.TrimSpace(params.Developer))
+	}
+
+	var status string
+	if changed, err := p.String("mimix_status", &status); err != nil {
+		return err
+	} else if changed {
+		statusVal, ok := allowedMimixStatus[strings.ToLower(strings.TrimSpace(status))]
+		if !ok {
+			return errors.New("invalid mimix_status")
+		}
+		params.MimixStatus = statusVal
+	}
+
+	if _, err := p.NullString("keterangan", &params.Keterangan); err != nil {
+		return err
+	}
+	// a blank note is stored as null, same as on create
+	if strings.TrimSpace(params.Keterangan.String) == "" {
+		params.Keterangan.Valid = false
+	}
+	return nil
+}
+
+// applyObjReqPatch is applyObjPatch for obj requests.
+func applyObjReqPatch(p mergepatch.Patch, params *database.UpdateMimixObjReqInfoParams) error {
+	for _, f := range []struct {
+		name string
+		dst  *string
+	}{
+		{"obj_name", &params.ObjName},
+		{"lib", &params.Lib},
+		{"obj_ver", &params.ObjVer},
+		{"obj_type", &params.ObjType},
+	} {
+		if err := patchRequiredString(p, f.name, f.dst); err != nil {
+			return err
+		}
+	}
+	identity := objIdentity{Kind: params.ObjKind, Name: params.ObjName, Lib: params.Lib, ObjType: params.ObjType, Subtree: params.Subtree}
+	if err := patchObjIdentity(p, "obj_name", &identity, &params.PrcType); err != nil {
+		return err
+	}
+	params.ObjKind, params.ObjName, params.Lib, params.ObjType, params.Subtree =
+		identity.Kind, identity.Name, identity.Lib, identity.ObjType, identity.Subtree
+
+	if _, err := p.Time("promote_date", &params.PromoteDate); err != nil {
+		return err
+	}
+
+	if _, err := p.NullString("developer", &params.Developer); err != nil {
+		return err
+	}
+	if strings.TrimSpace(params.Developer.String) == "" {
+		params.Developer.Valid = false
+	}
+
+	// null or "" clears promote_status
+	var promoteStatus string
+	if p.IsNull("promote_status") {
+		params.PromoteStatus = database.NullPromoteStatus{}
+	} else if changed, err := p.String("promote_status", &promoteStatus); err != nil {
+		return err
+	} else if changed {
+		psKey := strings.ToLower(strings.TrimSpace(promoteStatus))
+		if psKey == "" {
+			params.PromoteStatus = database.NullPromoteStatus{}
+		} else {
+			psVal, ok := allowedPromoteStatus[psKey]
+			if !ok {
+				return errors.New("invalid promote_status")
+			}
+			params.PromoteStatus = database.NullPromoteStatus{PromoteStatus: psVal, Valid: true}
+		}
+	}
+
+	var reqStatus string
+	if changed, err := p.String("req_status", &reqStatus); err != nil {
+		return err
+	} else if changed {
+		reqVal, ok := allowedReqStatus[strings.ToLower(strings.TrimSpace(reqStatus))]
+		if !ok {
+			return errors.New("invalid req_status")
+		}
+		params.ReqStatus = reqVal
+	}
+
+	var priority string
+	if changed, err := p.String("priority", &priority); err != nil {
+		return err
+	} else if changed {
+		if params.Priority, err = parseReqPriority(priority); err != nil {
+			return err
+		}
+	}
+	return nil
+}
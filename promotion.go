@@ -0,0 +1,628 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/paul39-33/imimix/internal/database"
+)
+
+// PromotionStage is an environment objects are promoted through, e.g. DEV,
+// QA, UAT and PROD. MIMIX registration is only needed at the production
+// stage.
+type PromotionStage struct {
+	ID           uuid.UUID `json:"id"`
+	Name         string    `json:"name"`
+	Position     int32     `json:"position"`
+	IsProduction bool      `json:"is_production"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type PromotionStageInput struct {
+	Name         string `json:"name" binding:"required"`
+	Position     int32  `json:"position" binding:"required,min=1"`
+	IsProduction bool   `json:"is_production"`
+}
+
+// PromoteInput names the target stage by name or id. Emergency asks to skip
+// the stages in between, which only emergency requests may do.
+type PromoteInput struct {
+	Stage     string `json:"stage" binding:"required"`
+	Note      string `json:"note"`
+	Emergency bool   `json:"emergency"`
+}
+
+type Promotion struct {
+	ID          uuid.UUID  `json:"id"`
+	ObjID       *uuid.UUID `json:"obj_id,omitempty"`
+	ObjReqID    *uuid.UUID `json:"obj_req_id,omitempty"`
+	FromStageID *uuid.UUID `json:"from_stage_id,omitempty"`
+	FromStage   string     `json:"from_stage,omitempty"`
+	ToStageID   uuid.UUID  `json:"to_stage_id"`
+	ToStage     string     `json:"to_stage"`
+	Actor       string     `json:"actor"`
+	Note        string     `json:"note"`
+	PromotedAt  time.Time  `json:"promoted_at"`
+}
+
+// stageMoveError explains why an obj cannot move to a stage. Its message is
+// safe to return to the caller.
+type stageMoveError struct{ msg string }
+
+func (e stageMoveError) Error() string { return e.msg }
+
+func toPromotionStage(s database.PromotionStage) PromotionStage {
+	return PromotionStage{
+		ID:           s.ID,
+		Name:         s.Name,
+		Position:     s.Position,
+		IsProduction: s.IsProduction,
+		CreatedAt:    s.CreatedAt,
+	}
+}
+
+func toPromotion(p database.PromotionHistory, from, to string) Promotion {
+	return Promotion{
+		ID:          p.ID,
+		ObjID:       NullUUIDToPtr(p.ObjID),
+		ObjReqID:    NullUUIDToPtr(p.ObjReqID),
+		FromStageID: NullUUIDToPtr(p.FromStageID),
+		FromStage:   from,
+		ToStageID:   p.ToStageID,
+		ToStage:     to,
+		Actor:       p.Actor,
+		Note:        p.Note,
+		PromotedAt:  p.PromotedAt,
+	}
+}
+
+// stageIndex returns the index of the stage with the given id, or -1.
+func stageIndex(stages []database.PromotionStage, id uuid.NullUUID) int {
+	if !id.Valid {
+		return -1
+	}
+	for i, s := range stages {
+		if s.ID == id.UUID {
+			return i
+		}
+	}
+	return -1
+}
+
+// findStage resolves a stage by id or case-insensitive name.
+func findStage(stages []database.PromotionStage, ref string) (database.PromotionStage, bool) {
+	ref = strings.TrimSpace(ref)
+	id, err := uuid.Parse(ref)
+	for _, s := range stages {
+		if (err == nil && s.ID == id) || strings.EqualFold(s.Name, ref) {
+			return s, true
+		}
+	}
+	return database.PromotionStage{}, false
+}
+
+// checkStageMove enforces the pipeline order: an obj moves forward one stage
+// at a time, starting at the first one, but may be rolled back to any
+// earlier stage.
+func checkStageMove(stages []database.PromotionStage, from uuid.NullUUID, to database.PromotionStage) error {
+	fromIdx := stageIndex(stages, from)
+	toIdx := stageIndex(stages, uuid.NullUUID{UUID: to.ID, Valid: true})
+
+	switch {
+	case fromIdx == toIdx:
+		return stageMoveError{msg: "already at stage " + to.Name}
+	case toIdx < fromIdx:
+		return nil
+	case toIdx != fromIdx+1:
+		return stageMoveError{msg: fmt.Sprintf("must be promoted to %s before %s", stages[fromIdx+1].Name, to.Name)}
+	}
+	return nil
+}
+
+// promoteStatus keeps the older in_progress/deployed flag in step with the
+// stage: only the production stage counts as deployed.
+func promoteStatus(stage database.PromotionStage) database.NullPromoteStatus {
+	if stage.IsProduction {
+		return database.NullPromoteStatus{PromoteStatus: database.PromoteStatusDeployed, Valid: true}
+	}
+	return database.NullPromoteStatus{PromoteStatus: database.PromoteStatusInProgress, Valid: true}
+}
+
+// flagForRegistration sets an obj that reached production to "daftarkan"
+// unless it is already registered or needs no registration. It reports
+// whether the status changed.
+func flagForRegistration(ctx context.Context, q *database.Queries, objID uuid.UUID) (bool, error) {
+	status, err := q.GetMimixStatusByID(ctx, objID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if isRegistered(status) || status == database.MimixStatusDaftarkan {
+		return false, nil
+	}
+
+	err = q.UpdateMimixStatus(ctx, database.UpdateMimixStatusParams{
+		ID:          objID,
+		MimixStatus: database.MimixStatusDaftarkan,
+	})
+	return err == nil, err
+}
+
+// bindPromotion reads the target stage of a promotion. The stages are
+// returned in pipeline order.
+func (cfg *apiConfig) bindPromotion(c *gin.Context) (PromoteInput, []database.PromotionStage, database.PromotionStage, bool) {
+	var input PromoteInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		log.Printf("error binding json: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid parameters"})
+		return input, nil, database.PromotionStage{}, false
+	}
+
+	stages, err := cfg.dbQueries.ListPromotionStages(c.Request.Context())
+	if err != nil {
+		log.Printf("error listing promotion stages: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get promotion stages"})
+		return input, nil, database.PromotionStage{}, false
+	}
+
+	to, ok := findStage(stages, input.Stage)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown stage"})
+		return input, nil, database.PromotionStage{}, false
+	}
+	input.Note = strings.TrimSpace(input.Note)
+	return input, stages, to, true
+}
+
+// respondPromotionError turns an error from a promotion transaction into a
+// response.
+func respondPromotionError(c *gin.Context, err error, notFound string) {
+	var moveErr stageMoveError
+	switch {
+	case errors.As(err, &moveErr):
+		c.JSON(http.StatusConflict, gin.H{"error": moveErr.Error()})
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+	default:
+		log.Printf("error promoting: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not promote"})
+	}
+}
+
+// PromoteObj moves an obj to another stage and records it in the history.
+// Reaching production flags the obj for MIMIX registration.
+func (cfg *apiConfig) PromoteObj(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permPromote)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid obj id"})
+		return
+	}
+	if !cfg.requireObjLib(c, user, id, database.LibPermissionManage, "no matching obj found") {
+		return
+	}
+
+	input, stages, to, ok := cfg.bindPromotion(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	var (
+		obj       database.MimixObj
+		promotion database.PromotionHistory
+		flagged   bool
+	)
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		current, err := q.GetObjByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkStageMove(stages, current.StageID, to); err != nil {
+			return err
+		}
+
+		if _, err := q.SetObjStage(ctx, database.SetObjStageParams{
+			ID:      id,
+			StageID: uuid.NullUUID{UUID: to.ID, Valid: true},
+		}); err != nil {
+			return err
+		}
+		if promotion, err = q.CreatePromotion(ctx, database.CreatePromotionParams{
+			ObjID:       uuid.NullUUID{UUID: id, Valid: true},
+			FromStageID: current.StageID,
+			ToStageID:   to.ID,
+			Actor:       user.Username,
+			Note:        input.Note,
+		}); err != nil {
+			return err
+		}
+
+		if to.IsProduction {
+			if flagged, err = flagForRegistration(ctx, q, id); err != nil {
+				return err
+			}
+		}
+		obj, err = q.GetObjByID(ctx, id)
+		return err
+	})
+	if err != nil {
+		respondPromotionError(c, err, "no matching obj found")
+		return
+	}
+
+	c.Header("ETag", etag(obj.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"obj":                      toMimixObj(obj),
+		"promotion":                promotionWithNames(stages, promotion),
+		"flagged_for_registration": flagged,
+	})
+}
+
+// PromoteObjReq moves an obj request to another stage. Reaching production
+// marks the request deployed and flags its source obj, if any, for MIMIX
+// registration.
+func (cfg *apiConfig) PromoteObjReq(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permPromote)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid obj req id"})
+		return
+	}
+	if !cfg.requireObjReqLib(c, user, id, database.LibPermissionRequest, "obj request not found") {
+		return
+	}
+
+	input, stages, to, ok := cfg.bindPromotion(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	var (
+		req       database.MimixObjReq
+		promotion database.PromotionHistory
+		review    *database.EmergencyReview
+		flagged   bool
+	)
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		current, err := q.GetMimixObjReqByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		skipped, err := checkEmergencyMove(ctx, q, user.Username, current, stages, to, input.Emergency)
+		if err != nil {
+			return err
+		}
+
+		if req, err = q.SetObjReqStage(ctx, database.SetObjReqStageParams{
+			ID:            id,
+			StageID:       uuid.NullUUID{UUID: to.ID, Valid: true},
+			PromoteStatus: promoteStatus(to),
+		}); err != nil {
+			return err
+		}
+		if promotion, err = q.CreatePromotion(ctx, database.CreatePromotionParams{
+			ObjReqID:    uuid.NullUUID{UUID: id, Valid: true},
+			FromStageID: current.StageID,
+			ToStageID:   to.ID,
+			Actor:       user.Username,
+			Note:        input.Note,
+		}); err != nil {
+			return err
+		}
+
+		if len(skipped) > 0 {
+			r, err := q.CreateEmergencyReview(ctx, database.CreateEmergencyReviewParams{
+				ObjReqID:      id,
+				PromotionID:   promotion.ID,
+				SkippedStages: strings.Join(skipped, ","),
+				Actor:         user.Username,
+			})
+			if err != nil {
+				return err
+			}
+			review = &r
+		}
+
+		if to.IsProduction && req.SourceObjID.Valid {
+			flagged, err = flagForRegistration(ctx, q, req.SourceObjID.UUID)
+		}
+		return err
+	})
+	if err != nil {
+		respondPromotionError(c, err, "obj request not found")
+		return
+	}
+
+	resp := gin.H{
+		"obj_req":                  toMimixObjReq(req),
+		"promotion":                promotionWithNames(stages, promotion),
+		"flagged_for_registration": flagged,
+	}
+	if review != nil {
+		if _, err := notify(ctx, cfg.dbQueries, notice{
+			recipient: user.Username,
+			kind:      notifyEmergencyJustify,
+			message:   fmt.Sprintf("justify your emergency promotion that skipped %s", review.SkippedStages),
+			objReqID:  id,
+			dedupeKey: fmt.Sprintf("%s:%s", notifyEmergencyJustify, review.ID),
+		}); err != nil {
+			log.Printf("error notifying actor: %v", err)
+		}
+		resp["emergency_review"] = toEmergencyReview(*review)
+	}
+
+	c.Header("ETag", etag(req.Version))
+	c.JSON(http.StatusOK, resp)
+}
+
+// promotionWithNames fills in the stage names of a new history row.
+func promotionWithNames(stages []database.PromotionStage, p database.PromotionHistory) Promotion {
+	var from, to string
+	if i := stageIndex(stages, p.FromStageID); i >= 0 {
+		from = stages[i].Name
+	}
+	if i := stageIndex(stages, uuid.NullUUID{UUID: p.ToStageID, Valid: true}); i >= 0 {
+		to = stages[i].Name
+	}
+	return toPromotion(p, from, to)
+}
+
+// ListObjPromotions returns the promotion history of an obj, oldest first.
+func (cfg *apiConfig) ListObjPromotions(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid obj id"})
+		return
+	}
+	if !cfg.requireObjLib(c, user, id, database.LibPermissionRead, "no matching obj found") {
+		return
+	}
+
+	rows, err := cfg.dbQueries.ListObjPromotions(c.Request.Context(), uuid.NullUUID{UUID: id, Valid: true})
+	if err != nil {
+		log.Printf("error listing promotions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get promotions"})
+		return
+	}
+
+	promotions := make([]Promotion, 0, len(rows))
+	for _, row := range rows {
+		promotions = append(promotions, toPromotion(database.PromotionHistory{
+			ID:          row.ID,
+			ObjID:       row.ObjID,
+			ObjReqID:    row.ObjReqID,
+			FromStageID: row.FromStageID,
+			ToStageID:   row.ToStageID,
+			Actor:       row.Actor,
+			Note:        row.Note,
+			PromotedAt:  row.PromotedAt,
+		}, NullStringToString(row.FromStage), row.ToStage))
+	}
+	c.JSON(http.StatusOK, promotions)
+}
+
+// ListObjReqPromotions returns the promotion history of an obj request,
+// oldest first.
+func (cfg *apiConfig) ListObjReqPromotions(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid obj req id"})
+		return
+	}
+	if !cfg.requireObjReqLib(c, user, id, database.LibPermissionRead, "obj request not found") {
+		return
+	}
+
+	rows, err := cfg.dbQueries.ListObjReqPromotions(c.Request.Context(), uuid.NullUUID{UUID: id, Valid: true})
+	if err != nil {
+		log.Printf("error listing promotions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get promotions"})
+		return
+	}
+
+	promotions := make([]Promotion, 0, len(rows))
+	for _, row := range rows {
+		promotions = append(promotions, toPromotion(database.PromotionHistory{
+			ID:          row.ID,
+			ObjID:       row.ObjID,
+			ObjReqID:    row.ObjReqID,
+			FromStageID: row.FromStageID,
+			ToStageID:   row.ToStageID,
+			Actor:       row.Actor,
+			Note:        row.Note,
+			PromotedAt:  row.PromotedAt,
+		}, NullStringToString(row.FromStage), row.ToStage))
+	}
+	c.JSON(http.StatusOK, promotions)
+}
+
+// ListPromotionStages returns the stages in pipeline order.
+func (cfg *apiConfig) ListPromotionStages(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c); !ok {
+		return
+	}
+
+	rows, err := cfg.dbQueries.ListPromotionStages(c.Request.Context())
+	if err != nil {
+		log.Printf("error listing promotion stages: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get promotion stages"})
+		return
+	}
+
+	stages := make([]PromotionStage, 0, len(rows))
+	for _, row := range rows {
+		stages = append(stages, toPromotionStage(row))
+	}
+	c.JSON(http.StatusOK, stages)
+}
+
+// bindPromotionStage reads a stage and checks it against the other stages:
+// names and positions are unique and only one stage is production.
+func (cfg *apiConfig) bindPromotionStage(c *gin.Context, id uuid.UUID) (PromotionStageInput, bool) {
+	var input PromotionStageInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		log.Printf("error binding json: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid parameters"})
+		return input, false
+	}
+	input.Name = strings.ToUpper(strings.TrimSpace(input.Name))
+	if input.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return input, false
+	}
+
+	stages, err := cfg.dbQueries.ListPromotionStages(c.Request.Context())
+	if err != nil {
+		log.Printf("error listing promotion stages: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get promotion stages"})
+		return input, false
+	}
+
+	for _, s := range stages {
+		if s.ID == id {
+			continue
+		}
+		switch {
+		case s.Name == input.Name:
+			c.JSON(http.StatusConflict, gin.H{"error": "a stage with this name already exists"})
+			return input, false
+		case s.Position == input.Position:
+			c.JSON(http.StatusConflict, gin.H{"error": "stage " + s.Name + " already has this position"})
+			return input, false
+		case s.IsProduction && input.IsProduction:
+			c.JSON(http.StatusConflict, gin.H{"error": "stage " + s.Name + " is already the production stage"})
+			return input, false
+		}
+	}
+	return input, true
+}
+
+// CreatePromotionStage adds a stage to the pipeline.
+func (cfg *apiConfig) CreatePromotionStage(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c, permStageManage); !ok {
+		return
+	}
+
+	input, ok := cfg.bindPromotionStage(c, uuid.Nil)
+	if !ok {
+		return
+	}
+
+	stage, err := cfg.dbQueries.CreatePromotionStage(c.Request.Context(), database.CreatePromotionStageParams{
+		Name:         input.Name,
+		Position:     input.Position,
+		IsProduction: input.IsProduction,
+	})
+	if err != nil {
+		log.Printf("error creating promotion stage: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create promotion stage"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toPromotionStage(stage))
+}
+
+// UpdatePromotionStage renames, moves or changes the production flag of a
+// stage.
+func (cfg *apiConfig) UpdatePromotionStage(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c, permStageManage); !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid stage id"})
+		return
+	}
+
+	input, ok := cfg.bindPromotionStage(c, id)
+	if !ok {
+		return
+	}
+
+	stage, err := cfg.dbQueries.UpdatePromotionStage(c.Request.Context(), database.UpdatePromotionStageParams{
+		ID:           id,
+		Name:         input.Name,
+		Position:     input.Position,
+		IsProduction: input.IsProduction,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "stage not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("error updating promotion stage: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not update promotion stage"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toPromotionStage(stage))
+}
+
+// DeletePromotionStage removes a stage no obj, request or history row uses.
+func (cfg *apiConfig) DeletePromotionStage(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c, permStageManage); !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid stage id"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	inUse, err := cfg.dbQueries.CheckPromotionStageInUse(ctx, uuid.NullUUID{UUID: id, Valid: true})
+	if err != nil {
+		log.Printf("error checking promotion stage: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not delete promotion stage"})
+		return
+	}
+	if inUse {
+		c.JSON(http.StatusConflict, gin.H{"error": "stage is in use"})
+		return
+	}
+
+	rows, err := cfg.dbQueries.DeletePromotionStage(ctx, id)
+	if err != nil {
+		log.Printf("error deleting promotion stage: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not delete promotion stage"})
+		return
+	}
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "stage not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "stage deleted"})
+}
@@ -56,6 +56,24 @@ const paginationControls = document.getElementById('paginationControls');
 
 // Initial Load
 fetchObjects();
+fetchObjectTypes();
+
+// Fill the object type suggestions from the server catalogue
+async function fetchObjectTypes() {
+    try {
+        const response = await fetch('/api/object_types', {
+            headers: { 'Authorization': `Bearer ${token}` }
+        });
+        if (!response.ok) return;
+        const types = await response.json();
+        const list = document.getElementById('obj-type-options');
+        list.innerHTML = types
+            .map(t => `<option value="${t.type}">${t.description}</option>`)
+            .join('');
+    } catch (error) {
+        console.error('Fetch object types error:', error);
+    }
+}
 
 // Search Event
 searchBtn.addEventListener('click', () => {
@@ -140,7 +158,7 @@ if (addReqForm) {
                 throw new Error(result.error || 'Failed to create request');
             }
 
-            alert('Request created successfully!');
+            alert('Request created successfully!' + dependencyWarningText(result.warnings));
             toggleModal(false);
             addReqForm.reset();
             // Switch to requests tab and refresh
@@ -302,7 +320,7 @@ function renderTable() {
         const updateDate = obj.updated_at ? new Date(obj.updated_at).toLocaleString() : '-';
 
         row.innerHTML = `
-            <td style="font-weight: 500; color: white;">${obj.obj}</td>
+            <td style="font-weight: 500; color: white;">${obj.obj_kind && obj.obj_kind !== 'lib' ? obj.display : obj.obj}</td>
             <td>${updateDate}</td>
             <td>${obj.obj_type}</td>
             <td>${dateStr}</td>
@@ -462,7 +480,7 @@ function renderRequestsTable() {
 
 
         row.innerHTML = `
-            <td style="font-weight: 500; color: white;">${req.obj_name}</td>
+            <td style="font-weight: 500; color: white;">${req.obj_kind && req.obj_kind !== 'lib' ? req.display : req.obj_name}</td>
             <td>${req.requester}</td>
             <td>${updateDate}</td>
             <td>${req.lib}</td>
@@ -562,7 +580,7 @@ const icons = {
 };
 
 async function deleteObject(id) {
-    if (!confirm('Are you sure you want to delete this object?')) return;
+    if (!confirm('Move this object to the trash? It can be restored until it is purged.')) return;
 
     try {
         const response = await fetch(`/api/delete_mimix_obj/${id}`, {
@@ -588,12 +606,12 @@ async function addToRequest(id) {
             headers: { 'Authorization': `Bearer ${token}` }
         });
 
+        const data = await response.json();
         if (!response.ok) {
-            const data = await response.json();
             throw new Error(data.error || 'Failed to add to request');
         }
 
-        alert('Object added to request successfully');
+        alert('Object added to request successfully' + dependencyWarningText(data.warnings));
         // Optionally switch tab: switchTab('requests');
     } catch (error) {
         console.error('Add Request error:', error);
@@ -601,6 +619,38 @@ async function addToRequest(id) {
     }
 }
 
+// dependencyWarningText lists the unregistered dependencies returned with a
+// new request, or nothing when there are none.
+function dependencyWarningText(warnings) {
+    if (!warnings || warnings.length === 0) return '';
+    const lines = warnings.map(w => `- ${w.display}: ${w.reason}`);
+    return '\n\nWarning, dependencies not registered yet:\n' + lines.join('\n');
+}
+
+// changedFields returns the entries of edited that differ from the original
+// row, ready to be sent as a JSON Merge Patch. Empty strings and unset dates
+// count as null, and dates are compared by instant.
+function changedFields(original, edited) {
+    const toComparable = (key, v) => {
+        if (v === undefined || v === '') return null;
+        if (v !== null && key.endsWith('_date')) {
+            const d = new Date(v);
+            return d.getUTCFullYear() <= 1 ? null : d.getTime();
+        }
+        return v;
+    };
+
+    const patch = {};
+    for (const [key, value] of Object.entries(edited)) {
+        const before = toComparable(key, original[key]);
+        const after = toComparable(key, value);
+        if (before !== after) {
+            patch[key] = value ?? null;
+        }
+    }
+    return patch;
+}
+
 // Global variable to store original row HTML
 let editingRowId = null;
 let originalRowHTML = '';
@@ -680,25 +730,42 @@ async function saveObject(id) {
     }
 
     try {
-        // Update Info including Name and Type
+        // Send only the fields that were actually changed (JSON Merge Patch)
+        const objData = allObjects.find(o => o.id === id) || {};
+        const patch = changedFields(objData, {
+            obj: objName,
+            obj_type: objType,
+            promote_date: promoteDate,
+            lib: lib,
+            obj_ver: ver,
+            developer: dev,
+            keterangan: ket || null,
+            mimix_status: status
+        });
+
+        if (Object.keys(patch).length === 0) {
+            cancelEdit(id);
+            return;
+        }
+
         const responseInfo = await fetch(`/api/update_mimix_obj_info/${id}`, {
             method: 'PATCH',
             headers: {
-                'Content-Type': 'application/json',
-                'Authorization': `Bearer ${token}`
+                'Content-Type': 'application/merge-patch+json',
+                'Authorization': `Bearer ${token}`,
+                'If-Match': `"${objData.version}"`
             },
-            body: JSON.stringify({
-                obj: objName,
-                obj_type: objType,
-                promote_date: promoteDate,
-                lib: lib,
-                obj_ver: ver,
-                developer: dev,
-                keterangan: ket,
-                mimix_status: status // Include status in info update as well if supported/needed
-            })
+            body: JSON.stringify(patch)
         });
 
+        if (responseInfo.status === 412) {
+            // Someone else saved first; reload so the user edits the latest copy
+            alert('This object was changed by someone else. The latest version has been reloaded.');
+            editingRowId = null;
+            fetchObjects(searchInput.value);
+            return;
+        }
+
         if (!responseInfo.ok) {
             const res = await responseInfo.json();
             throw new Error(res.error || 'Failed to update info');
@@ -738,7 +805,7 @@ async function convertRequest(id) {
 }
 
 async function deleteRequest(id) {
-    if (!confirm('Are you sure you want to delete this request?')) return;
+    if (!confirm('Move this request to the trash? It can be restored until it is purged.')) return;
 
     try {
         const response = await fetch(`/api/delete_obj_req/${id}`, {
@@ -835,9 +902,9 @@ async function saveRequest(id) {
         lib: lib,
         obj_ver: ver,
         obj_type: type,
-        developer: dev,
+        developer: dev || null,
         req_status: rStatus,
-        promote_status: pStatus || ""
+        promote_status: pStatus || null
     };
 
     if (pDate) {
@@ -851,15 +918,33 @@ async function saveRequest(id) {
     }
 
     try {
+        // Send only the fields that were actually changed (JSON Merge Patch)
+        const reqData = allRequests.find(r => r.id === id) || {};
+        const patch = changedFields(reqData, data);
+
+        if (Object.keys(patch).length === 0) {
+            cancelEditRequest(id);
+            return;
+        }
+
         const response = await fetch(`/api/update_obj_req_info/${id}`, {
             method: 'PATCH',
             headers: {
-                'Content-Type': 'application/json',
-                'Authorization': `Bearer ${token}`
+                'Content-Type': 'application/merge-patch+json',
+                'Authorization': `Bearer ${token}`,
+                'If-Match': `"${reqData.version}"`
             },
-            body: JSON.stringify(data)
+            body: JSON.stringify(patch)
         });
 
+        if (response.status === 412) {
+            // Someone else saved first; reload so the user edits the latest copy
+            alert('This request was changed by someone else. The latest version has been reloaded.');
+            editingReqId = null;
+            fetchRequests();
+            return;
+        }
+
         if (!response.ok) {
             const json = await response.json();
             throw new Error(json.error || 'Failed to update request');
@@ -0,0 +1,658 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/paul39-33/imimix/internal/database"
+)
+
+// gin context key of the permissions of the calling user
+const ctxPermissions = "permissions"
+
+const defaultPermissionCacheSeconds = 30
+
+// the builtin role that administers users, roles and access
+const leadRole = "lead"
+
+// permissions a role can be given; handlers are gated on these
+const (
+	permRead             = "read"
+	permComment          = "comment"
+	permObjCreate        = "obj.create"
+	permObjEdit          = "obj.edit"
+	permObjStatus        = "obj.status"
+	permObjDelete        = "obj.delete"
+	permObjAttach        = "obj.attach"
+	permReqCreate        = "req.create"
+	permReqDelete        = "req.delete"
+	permReqAttach        = "req.attach"
+	permReqConvert       = "req.convert"
+	permReqWork          = "req.work"
+	permReqAssign        = "req.assign"
+	permDependencyEdit   = "dependency.edit"
+	permDependencyImport = "dependency.import"
+	permPromote          = "promote"
+	permStageManage      = "stage.manage"
+	permDeploymentReview = "deployment.review"
+	permEmergencyReview  = "emergency.review"
+	permCoverageManage   = "coverage.manage"
+	permCoveragePreview  = "coverage.preview"
+	permSLAManage        = "sla.manage"
+	permTrashPurge       = "trash.purge"
+	permCommentModerate  = "comment.moderate"
+	permReportsView      = "reports.view"
+	permSchedulerRun     = "scheduler.run"
+	permAdminView        = "admin.view"
+	permUsersManage      = "users.manage"
+	permRolesManage      = "roles.manage"
+	permACLManage        = "acl.manage"
+	permTeamsManage      = "teams.manage"
+)
+
+// Permission is one entry of the permission catalogue.
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// permissionCatalogue is every permission a role can be given.
+var permissionCatalogue = []Permission{
+	{Name: permRead, Description: "view objs, requests, teams and reports"},
+	{Name: permComment, Description: "comment on objs and requests and justify emergency changes"},
+	{Name: permObjCreate, Description: "add objs"},
+	{Name: permObjEdit, Description: "edit the details of objs"},
+	{Name: permObjStatus, Description: "change the mimix status of objs"},
+	{Name: permObjDelete, Description: "delete and restore objs and see everyone's trash"},
+	{Name: permObjAttach, Description: "add and delete the attachments of objs"},
+	{Name: permReqCreate, Description: "create and edit requests and tickets"},
+	{Name: permReqDelete, Description: "delete and restore requests"},
+	{Name: permReqAttach, Description: "add and delete the attachments of requests"},
+	{Name: permReqConvert, Description: "convert requests to objs and decide tickets"},
+	{Name: permReqWork, Description: "claim requests and be assigned them"},
+	{Name: permReqAssign, Description: "assign requests to anyone and act on requests assigned to others"},
+	{Name: permDependencyEdit, Description: "add and delete dependencies"},
+	{Name: permDependencyImport, Description: "import dependencies"},
+	{Name: permPromote, Description: "promote objs and requests"},
+	{Name: permStageManage, Description: "define promotion stages"},
+	{Name: permDeploymentReview, Description: "resolve and dismiss deployment events"},
+	{Name: permEmergencyReview, Description: "review emergency promotions"},
+	{Name: permCoverageManage, Description: "define coverage rules"},
+	{Name: permCoveragePreview, Description: "preview coverage rules"},
+	{Name: permSLAManage, Description: "define sla policies and holidays"},
+	{Name: permTrashPurge, Description: "purge the trash"},
+	{Name: permCommentModerate, Description: "edit and delete anyone's comments"},
+	{Name: permReportsView, Description: "view the naming report"},
+	{Name: permSchedulerRun, Description: "view and run the background jobs"},
+	{Name: permAdminView, Description: "view roles, user permissions, library access and developer matches"},
+	{Name: permUsersManage, Description: "link users to the directory and reassign developers"},
+	{Name: permRolesManage, Description: "define roles and give them to users"},
+	{Name: permACLManage, Description: "grant library access and see every library"},
+	{Name: permTeamsManage, Description: "create and delete teams and manage any team"},
+}
+
+// primaryJobOrder is the order a user's roles are checked in to pick the
+// job kept in users.job.
+var primaryJobOrder = []database.UserJob{
+	database.UserJobDc,
+	database.UserJobCmt,
+	database.UserJobDev,
+	database.UserJobUser,
+}
+
+func isKnownPermission(name string) bool {
+	return slices.ContainsFunc(permissionCatalogue, func(p Permission) bool { return p.Name == name })
+}
+
+// permSet is the union of the permissions of a user's roles.
+type permSet map[string]bool
+
+// any reports whether p grants any of perms.
+func (p permSet) any(perms ...string) bool {
+	for _, perm := range perms {
+		if p[perm] {
+			return true
+		}
+	}
+	return false
+}
+
+// allows reports whether p passes authorizeUser: any of perms when given,
+// otherwise read access.
+func (p permSet) allows(perms ...string) bool {
+	if len(perms) == 0 {
+		return p[permRead]
+	}
+	return p.any(perms...)
+}
+
+// permCache keeps the resolved permissions of each user for ttl, so a
+// request does not join the role tables again. Role changes made here
+// invalidate it; those made on another replica show once entries expire.
+type permCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[uuid.UUID]cachedPerms
+}
+
+type cachedPerms struct {
+	perms   permSet
+	expires time.Time
+}
+
+func newPermCache(ttl time.Duration) *permCache {
+	return &permCache{ttl: ttl, entries: map[uuid.UUID]cachedPerms{}}
+}
+
+// permissionCacheTTLFromEnv reads PERMISSION_CACHE_SECONDS.
+func permissionCacheTTLFromEnv() time.Duration {
+	return time.Duration(intFromEnv("PERMISSION_CACHE_SECONDS", defaultPermissionCacheSeconds)) * time.Second
+}
+
+func (pc *permCache) get(ctx context.Context, q *database.Queries, userID uuid.UUID) (permSet, error) {
+	pc.mu.Lock()
+	entry, ok := pc.entries[userID]
+	pc.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.perms, nil
+	}
+
+	names, err := q.ListUserPermissions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	perms := make(permSet, len(names))
+	for _, name := range names {
+		perms[name] = true
+	}
+
+	pc.mu.Lock()
+	pc.entries[userID] = cachedPerms{perms: perms, expires: time.Now().Add(pc.ttl)}
+	pc.mu.Unlock()
+	return perms, nil
+}
+
+// invalidate drops the cached permissions of userIDs, or of every user
+// when none are given.
+func (pc *permCache) invalidate(userIDs ...uuid.UUID) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	if len(userIDs) == 0 {
+		clear(pc.entries)
+		return
+	}
+	for _, id := range userIDs {
+		delete(pc.entries, id)
+	}
+}
+
+// contextPermissions returns the permissions stored by authorizeUser.
+func contextPermissions(c *gin.Context) permSet {
+	perms, _ := c.Get(ctxPermissions)
+	p, _ := perms.(permSet)
+	return p
+}
+
+// requirePermission checks a permission of the user loaded by
+// authorizeUser, writing the error response itself when it is missing.
+func (cfg *apiConfig) requirePermission(c *gin.Context, perm string) bool {
+	if !contextPermissions(c)[perm] {
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden: insufficient permissions"})
+		return false
+	}
+	return true
+}
+
+// requireUserPermission checks that userID holds one of perms through their
+// roles, for the handlers that validate the token themselves. The error
+// response is written here.
+func (cfg *apiConfig) requireUserPermission(c *gin.Context, userID uuid.UUID, perms ...string) bool {
+	userPerms, err := cfg.permissions.get(c.Request.Context(), cfg.dbQueries, userID)
+	if err != nil {
+		log.Printf("error getting user permissions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get user"})
+		return false
+	}
+	if !userPerms.any(perms...) {
+		log.Printf("user %s lacks permissions %v", userID, perms)
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden: insufficient permissions"})
+		return false
+	}
+	c.Set(ctxPermissions, userPerms)
+	return true
+}
+
+// primaryJob picks the users.job of a user holding roles.
+func primaryJob(roles []database.Role) database.UserJob {
+	for _, job := range primaryJobOrder {
+		for _, role := range roles {
+			if role.Builtin && role.Name == string(job) {
+				return job
+			}
+		}
+	}
+	return database.UserJobUser
+}
+
+// Role is a named set of permissions. Builtin roles are the former jobs
+// and lead, and cannot be changed.
+type Role struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Builtin     bool      `json:"builtin"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// UserRoles is the roles a user holds and the permissions they add up to.
+type UserRoles struct {
+	Username    string   `json:"username"`
+	Job         string   `json:"job"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+}
+
+func toRole(r database.Role, perms []string) Role {
+	if perms == nil {
+		perms = []string{}
+	}
+	return Role{
+		ID:          r.ID,
+		Name:        r.Name,
+		Description: r.Description,
+		Builtin:     r.Builtin,
+		Permissions: perms,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+}
+
+// rolePermissions groups the permissions of every role by role id.
+func rolePermissions(ctx context.Context, q *database.Queries) (map[uuid.UUID][]string, error) {
+	rows, err := q.ListRolePermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	perms := map[uuid.UUID][]string{}
+	for _, row := range rows {
+		perms[row.RoleID] = append(perms[row.RoleID], row.Permission)
+	}
+	return perms, nil
+}
+
+// roleParams is the body of CreateRole and UpdateRole.
+type roleParams struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// validPermissions normalizes perms, which must be a non-empty selection
+// from the catalogue.
+func validPermissions(perms []string) ([]string, bool) {
+	out := make([]string, 0, len(perms))
+	for _, p := range perms {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if !isKnownPermission(p) {
+			return nil, false
+		}
+		if !slices.Contains(out, p) {
+			out = append(out, p)
+		}
+	}
+	return out, len(out) > 0
+}
+
+// getRoleParam loads the role named by the :name path param, writing the
+// error response itself when it cannot.
+func (cfg *apiConfig) getRoleParam(c *gin.Context) (database.Role, bool) {
+	role, err := cfg.dbQueries.GetRoleByName(c.Request.Context(), strings.TrimSpace(c.Param("name")))
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "role not found"})
+		return role, false
+	}
+	if err != nil {
+		log.Printf("error getting role: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get role"})
+		return role, false
+	}
+	return role, true
+}
+
+// ListPermissions returns the permission catalogue roles are defined from.
+func (cfg *apiConfig) ListPermissions(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c, permAdminView); !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"permissions": permissionCatalogue})
+}
+
+// ListRoles returns every role with its permissions.
+func (cfg *apiConfig) ListRoles(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c, permAdminView); !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	rows, err := cfg.dbQueries.ListRoles(ctx)
+	if err != nil {
+		log.Printf("error listing roles: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get roles"})
+		return
+	}
+	perms, err := rolePermissions(ctx, cfg.dbQueries)
+	if err != nil {
+		log.Printf("error listing role permissions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get roles"})
+		return
+	}
+
+	roles := make([]Role, 0, len(rows))
+	for _, row := range rows {
+		roles = append(roles, toRole(row, perms[row.ID]))
+	}
+	c.JSON(http.StatusOK, gin.H{"roles": roles})
+}
+
+// CreateRole defines a role from the permission catalogue.
+func (cfg *apiConfig) CreateRole(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c, permRolesManage); !ok {
+		return
+	}
+
+	var params roleParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	params.Name = strings.ToLower(strings.TrimSpace(params.Name))
+	if params.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+	perms, ok := validPermissions(params.Permissions)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "permissions must be a non-empty selection from the catalogue"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	_, err := cfg.dbQueries.GetRoleByName(ctx, params.Name)
+	if err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "role already exists"})
+		return
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("error getting role: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create role"})
+		return
+	}
+
+	var role database.Role
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		var err error
+		role, err = q.CreateRole(ctx, database.CreateRoleParams{
+			Name:        params.Name,
+			Description: strings.TrimSpace(params.Description),
+		})
+		if err != nil {
+			return err
+		}
+		for _, p := range perms {
+			if err := q.AddRolePermission(ctx, database.AddRolePermissionParams{RoleID: role.ID, Permission: p}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("error creating role: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create role"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"role": toRole(role, perms)})
+}
+
+// UpdateRole replaces the description and permissions of a custom role.
+func (cfg *apiConfig) UpdateRole(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c, permRolesManage); !ok {
+		return
+	}
+
+	role, ok := cfg.getRoleParam(c)
+	if !ok {
+		return
+	}
+	if role.Builtin {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "builtin roles cannot be changed"})
+		return
+	}
+
+	var params roleParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	perms, ok := validPermissions(params.Permissions)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "permissions must be a non-empty selection from the catalogue"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	err := cfg.withTx(ctx, func(q *database.Queries) error {
+		return keepsLead(ctx, q, func() error {
+			role.Description = strings.TrimSpace(params.Description)
+			if err := q.UpdateRoleDescription(ctx, database.UpdateRoleDescriptionParams{
+				ID:          role.ID,
+				Description: role.Description,
+			}); err != nil {
+				return err
+			}
+			if err := q.ClearRolePermissions(ctx, role.ID); err != nil {
+				return err
+			}
+			for _, p := range perms {
+				if err := q.AddRolePermission(ctx, database.AddRolePermissionParams{RoleID: role.ID, Permission: p}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	if errors.Is(err, errLastLead) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("error updating role: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not update role"})
+		return
+	}
+	cfg.permissions.invalidate()
+
+	role.UpdatedAt = time.Now()
+	c.JSON(http.StatusOK, gin.H{"role": toRole(role, perms)})
+}
+
+// DeleteRole removes a custom role nobody holds any more.
+func (cfg *apiConfig) DeleteRole(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c, permRolesManage); !ok {
+		return
+	}
+
+	role, ok := cfg.getRoleParam(c)
+	if !ok {
+		return
+	}
+	if role.Builtin {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "builtin roles cannot be deleted"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	holders, err := cfg.dbQueries.CountRoleUsers(ctx, role.ID)
+	if err != nil {
+		log.Printf("error counting role users: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not delete role"})
+		return
+	}
+	if holders > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "role is still held by users"})
+		return
+	}
+
+	if _, err := cfg.dbQueries.DeleteRole(ctx, role.ID); err != nil {
+		log.Printf("error deleting role: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not delete role"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// userRoles loads the roles and permissions of a user.
+func (cfg *apiConfig) userRoles(ctx context.Context, username string, job database.UserJob, id uuid.UUID) (UserRoles, error) {
+	roles, err := cfg.dbQueries.ListUserRoleNames(ctx, id)
+	if err != nil {
+		return UserRoles{}, err
+	}
+	perms, err := cfg.dbQueries.ListUserPermissions(ctx, id)
+	if err != nil {
+		return UserRoles{}, err
+	}
+	return UserRoles{
+		Username:    username,
+		Job:         string(job),
+		Roles:       append([]string{}, roles...),
+		Permissions: append([]string{}, perms...),
+	}, nil
+}
+
+// getUserParam loads the user named by the :username path param, writing
+// the error response itself when it cannot.
+func (cfg *apiConfig) getUserParam(c *gin.Context) (database.GetUserByUsernameRow, bool) {
+	user, err := cfg.dbQueries.GetUserByUsername(c.Request.Context(), strings.TrimSpace(c.Param("username")))
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return user, false
+	}
+	if err != nil {
+		log.Printf("error getting user: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get user"})
+		return user, false
+	}
+	return user, true
+}
+
+// GetUserRoles returns the roles a user holds.
+func (cfg *apiConfig) GetUserRoles(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c, permAdminView); !ok {
+		return
+	}
+
+	user, ok := cfg.getUserParam(c)
+	if !ok {
+		return
+	}
+
+	roles, err := cfg.userRoles(c.Request.Context(), user.Username, user.Job, user.ID)
+	if err != nil {
+		log.Printf("error getting user roles: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get user roles"})
+		return
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+// SetUserRoles replaces the roles of a user. users.job follows the
+// highest builtin role kept.
+func (cfg *apiConfig) SetUserRoles(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c, permRolesManage); !ok {
+		return
+	}
+
+	type parameters struct {
+		Roles []string `json:"roles"`
+	}
+
+	var params parameters
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(params.Roles) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "at least one role is required"})
+		return
+	}
+
+	user, ok := cfg.getUserParam(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	roles := make([]database.Role, 0, len(params.Roles))
+	for _, name := range params.Roles {
+		role, err := cfg.dbQueries.GetRoleByName(ctx, strings.TrimSpace(name))
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown role: " + name})
+			return
+		}
+		if err != nil {
+			log.Printf("error getting role: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not update user roles"})
+			return
+		}
+		roles = append(roles, role)
+	}
+
+	job := primaryJob(roles)
+	if err := cfg.withTx(ctx, func(q *database.Queries) error {
+		return assignRoles(ctx, q, user.ID, job, roles)
+	}); errors.Is(err, errLastLead) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		log.Printf("error setting user roles: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not update user roles"})
+		return
+	}
+	cfg.permissions.invalidate(user.ID)
+
+	result, err := cfg.userRoles(ctx, user.Username, job, user.ID)
+	if err != nil {
+		log.Printf("error getting user roles: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get user roles"})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// assignRoles replaces the roles of a user and sets their primary job. The
+// last user who can manage roles cannot lose that permission.
+func assignRoles(ctx context.Context, q *database.Queries, userID uuid.UUID, job database.UserJob, roles []database.Role) error {
+	return keepsLead(ctx, q, func() error {
+		if err := q.ClearUserRoles(ctx, userID); err != nil {
+			return err
+		}
+		for _, role := range roles {
+			if err := q.AddUserRole(ctx, database.AddUserRoleParams{UserID: userID, RoleID: role.ID}); err != nil {
+				return err
+			}
+		}
+		return q.SetUserJob(ctx, database.SetUserJobParams{ID: userID, Job: job})
+	})
+}
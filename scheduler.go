@@ -0,0 +1,515 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/paul39-33/imimix/internal/database"
+	"github.com/paul39-33/imimix/internal/schedule"
+)
+
+// schedulerLockKey is the Postgres advisory lock held by the replica that
+// runs scheduled jobs ("imimix" in hex).
+const schedulerLockKey int64 = 0x696d696d6978
+
+// schedulerTick is how often the scheduler checks for due jobs and, on
+// replicas that are not the leader, tries to take the lock.
+const schedulerTick = 30 * time.Second
+
+const (
+	defaultReminderDays       = 2
+	defaultStaleDaftarkanDays = 7
+)
+
+// job is a scheduled task. run returns a short summary for the run history.
+type job struct {
+	name        string
+	description string
+	spec        string
+	schedule    *schedule.Schedule
+	run         func(ctx context.Context) (string, error)
+}
+
+// scheduler runs jobs on the replica holding the advisory lock. Every run is
+// also claimed in job_run by (job, scheduled time), so a run is never done
+// twice even while the lock changes hands.
+type scheduler struct {
+	cfg    *apiConfig
+	jobs   []*job
+	runner string
+
+	mu   sync.Mutex
+	next map[string]time.Time
+	conn *sql.Conn
+}
+
+type Job struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Schedule    string     `json:"schedule"`
+	NextRun     *time.Time `json:"next_run,omitempty"`
+	LastRun     *JobRun    `json:"last_run,omitempty"`
+}
+
+type JobRun struct {
+	ID           uuid.UUID  `json:"id"`
+	JobName      string     `json:"job_name"`
+	ScheduledFor time.Time  `json:"scheduled_for"`
+	Runner       string     `json:"runner"`
+	Status       string     `json:"status"`
+	Summary      string     `json:"summary"`
+	Error        string     `json:"error,omitempty"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+}
+
+func toJobRun(r database.JobRun) JobRun {
+	return JobRun{
+		ID:           r.ID,
+		JobName:      r.JobName,
+		ScheduledFor: r.ScheduledFor,
+		Runner:       r.Runner,
+		Status:       string(r.Status),
+		Summary:      r.Summary,
+		Error:        r.Error,
+		StartedAt:    r.StartedAt,
+		FinishedAt:   NullTimeToPtr(r.FinishedAt),
+	}
+}
+
+// intFromEnv reads a non-negative integer setting, falling back to def.
+func intFromEnv(name string, def int32) int32 {
+	val := os.Getenv(name)
+	if val == "" {
+		return def
+	}
+	n, err := strconv.ParseInt(val, 10, 32)
+	if err != nil || n < 0 {
+		log.Printf("invalid %s %q, using %d", name, val, def)
+		return def
+	}
+	return int32(n)
+}
+
+// schedulerEnabled reads SCHEDULER_ENABLED; the scheduler runs unless it is
+// set to false.
+func schedulerEnabled() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("SCHEDULER_ENABLED"))
+	return err != nil || enabled
+}
+
+// newScheduler defines the jobs. A job's schedule can be overridden with
+// JOB_SCHEDULE_<NAME>, e.g. JOB_SCHEDULE_PROMOTE_REMINDER="0 7 * * MON-FRI".
+func (cfg *apiConfig) newScheduler() (*scheduler, error) {
+	host, _ := os.Hostname()
+	s := &scheduler{
+		cfg:    cfg,
+		runner: fmt.Sprintf("%s:%d", host, os.Getpid()),
+		next:   map[string]time.Time{},
+	}
+
+	reminderDays := intFromEnv("REMINDER_DAYS", defaultReminderDays)
+	staleDays := intFromEnv("STALE_DAFTARKAN_DAYS", defaultStaleDaftarkanDays)
+
+	jobs := []*job{
+		{
+			name:        "promote_reminder",
+			description: fmt.Sprintf("remind developers of pending requests promoted within %d days", reminderDays),
+			spec:        "0 8 * * *",
+			run:         func(ctx context.Context) (string, error) { return cfg.remindPromoteDate(ctx, reminderDays) },
+		},
+		{
+			name:        "pending_escalation",
+			description: "escalate requests still pending after their promote date",
+			spec:        "0 * * * *",
+			run:         cfg.escalatePendingRequests,
+		},
+		{
+			name:        "stale_daftarkan",
+			description: fmt.Sprintf("flag objs still in daftarkan %d days after promotion", staleDays),
+			spec:        "0 9 * * *",
+			run:         func(ctx context.Context) (string, error) { return cfg.flagStaleDaftarkan(ctx, staleDays) },
+		},
+		{
+			name:        "sla_escalation",
+			description: "escalate requests close to or past their SLA due date",
+			spec:        "*/15 * * * *",
+			run:         cfg.escalateSLA,
+		},
+		{
+			name:        "emergency_justification",
+			description: "remind actors of emergency promotions they have not justified",
+			spec:        "0 10 * * *",
+			run:         cfg.remindEmergencyJustification,
+		},
+		{
+			name:        "attachment_sweep",
+			description: "remove attachments of purged objs and requests",
+			spec:        "30 * * * *",
+			run:         cfg.sweepAttachments,
+		},
+	}
+	//0 keeps trash forever
+	if cfg.trashRetentionDays > 0 {
+		jobs = append(jobs, &job{
+			name:        "trash_purge",
+			description: fmt.Sprintf("purge rows in the trash for more than %d days", cfg.trashRetentionDays),
+			spec:        "@hourly",
+			run:         cfg.runTrashPurgeJob,
+		})
+	}
+
+	for _, j := range jobs {
+		if spec := os.Getenv("JOB_SCHEDULE_" + strings.ToUpper(j.name)); spec != "" {
+			j.spec = spec
+		}
+		sched, err := schedule.Parse(j.spec)
+		if err != nil {
+			return nil, fmt.Errorf("job %s: %w", j.name, err)
+		}
+		j.schedule = sched
+	}
+	s.jobs = jobs
+	return s, nil
+}
+
+func (s *scheduler) job(name string) *job {
+	for _, j := range s.jobs {
+		if j.name == name {
+			return j
+		}
+	}
+	return nil
+}
+
+// run checks for due jobs every schedulerTick until ctx is done.
+func (s *scheduler) run(ctx context.Context) {
+	ticker := time.NewTicker(schedulerTick)
+	defer ticker.Stop()
+	defer s.releaseLeadership()
+
+	for {
+		s.tick(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick runs the jobs that are due. Replicas that are not the leader still
+// move their schedule on, so a new leader does not replay old runs.
+func (s *scheduler) tick(ctx context.Context, now time.Time) {
+	leader := s.ensureLeadership(ctx)
+
+	for _, j := range s.jobs {
+		s.mu.Lock()
+		next, ok := s.next[j.name]
+		if !ok {
+			next = j.schedule.Next(now)
+			s.next[j.name] = next
+		}
+		due := !next.IsZero() && !now.Before(next)
+		if due {
+			s.next[j.name] = j.schedule.Next(now)
+		}
+		s.mu.Unlock()
+
+		if due && leader {
+			if _, _, err := s.runJob(ctx, j, next, s.runner); err != nil {
+				log.Printf("error running job %s: %v", j.name, err)
+			}
+		}
+	}
+}
+
+// ensureLeadership takes the advisory lock on a dedicated connection, or
+// checks that the connection holding it is still alive. Postgres releases
+// the lock when that connection dies, letting another replica take over.
+func (s *scheduler) ensureLeadership(ctx context.Context) bool {
+	if s.conn != nil {
+		if err := s.conn.PingContext(ctx); err == nil {
+			return true
+		}
+		log.Printf("scheduler lost its database connection, giving up leadership")
+		s.releaseLeadership()
+	}
+
+	conn, err := s.cfg.db.Conn(ctx)
+	if err != nil {
+		log.Printf("error getting scheduler connection: %v", err)
+		return false
+	}
+	locked, err := database.New(conn).TrySchedulerLock(ctx, schedulerLockKey)
+	if err != nil || !locked {
+		if err != nil {
+			log.Printf("error taking scheduler lock: %v", err)
+		}
+		conn.Close()
+		return false
+	}
+
+	log.Printf("scheduler leadership taken by %s", s.runner)
+	s.conn = conn
+	return true
+}
+
+func (s *scheduler) releaseLeadership() {
+	if s.conn != nil {
+		// closing the connection releases the session lock
+		s.conn.Close()
+		s.conn = nil
+	}
+}
+
+// runJob claims and runs one run of a job. claimed is false when the run was
+// already claimed by another replica.
+func (s *scheduler) runJob(ctx context.Context, j *job, scheduledFor time.Time, runner string) (run database.JobRun, claimed bool, err error) {
+	q := s.cfg.dbQueries
+	run, err = q.ClaimJobRun(ctx, database.ClaimJobRunParams{
+		JobName:      j.name,
+		ScheduledFor: scheduledFor,
+		Runner:       runner,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return run, false, nil
+	}
+	if err != nil {
+		return run, false, err
+	}
+
+	summary, jobErr := j.run(ctx)
+	params := database.FinishJobRunParams{
+		ID:      run.ID,
+		Status:  database.JobRunStatusSucceeded,
+		Summary: summary,
+	}
+	if jobErr != nil {
+		params.Status = database.JobRunStatusFailed
+		params.Error = jobErr.Error()
+		log.Printf("job %s failed: %v", j.name, jobErr)
+	}
+
+	// record the outcome even if the request that triggered the run is gone
+	run, err = q.FinishJobRun(context.WithoutCancel(ctx), params)
+	return run, true, err
+}
+
+// remindPromoteDate reminds the developer (or the requester when no
+// developer is set) of pending requests promoted within days.
+func (cfg *apiConfig) remindPromoteDate(ctx context.Context, days int32) (string, error) {
+	reqs, err := cfg.dbQueries.ListObjReqDueForReminder(ctx, days)
+	if err != nil {
+		return "", err
+	}
+
+	sent := 0
+	for _, req := range reqs {
+		recipient := NullStringToString(req.Developer)
+		if strings.TrimSpace(recipient) == "" {
+			recipient = req.Requester
+		}
+		date := req.PromoteDate.Format(time.DateOnly)
+		isNew, err := notify(ctx, cfg.dbQueries, notice{
+			recipient: recipient,
+			kind:      notifyPromoteReminder,
+			message:   fmt.Sprintf("%s is due for promotion on %s", displayName(req.ObjKind, req.ObjName, req.Lib, req.Subtree), date),
+			objReqID:  req.ID,
+			dedupeKey: fmt.Sprintf("%s:%s:%s", notifyPromoteReminder, req.ID, date),
+		})
+		if err != nil {
+			return "", err
+		}
+		if isNew {
+			sent++
+		}
+	}
+	return fmt.Sprintf("%d requests due, %d reminders sent", len(reqs), sent), nil
+}
+
+// escalatePendingRequests tells the requester and the DC team about requests
+// still pending after their promote date.
+func (cfg *apiConfig) escalatePendingRequests(ctx context.Context) (string, error) {
+	reqs, err := cfg.dbQueries.ListObjReqPastPromoteDate(ctx)
+	if err != nil {
+		return "", err
+	}
+	dcUsers, err := cfg.dbQueries.ListUsernamesWithPermission(ctx, permReqWork)
+	if err != nil {
+		return "", err
+	}
+
+	sent := 0
+	for _, req := range reqs {
+		date := req.PromoteDate.Format(time.DateOnly)
+		message := fmt.Sprintf("%s is still pending, its promote date %s has passed", displayName(req.ObjKind, req.ObjName, req.Lib, req.Subtree), date)
+		for _, recipient := range append([]string{req.Requester}, dcUsers...) {
+			isNew, err := notify(ctx, cfg.dbQueries, notice{
+				recipient: recipient,
+				kind:      notifyPendingEscalation,
+				message:   message,
+				objReqID:  req.ID,
+				dedupeKey: fmt.Sprintf("%s:%s:%s:%s", notifyPendingEscalation, req.ID, date, strings.ToLower(recipient)),
+			})
+			if err != nil {
+				return "", err
+			}
+			if isNew {
+				sent++
+			}
+		}
+	}
+	return fmt.Sprintf("%d requests overdue, %d escalations sent", len(reqs), sent), nil
+}
+
+// flagStaleDaftarkan tells the developer and the DC team about objs still
+// waiting for registration days after their promote date.
+func (cfg *apiConfig) flagStaleDaftarkan(ctx context.Context, days int32) (string, error) {
+	objs, err := cfg.dbQueries.ListStaleDaftarkanObj(ctx, days)
+	if err != nil {
+		return "", err
+	}
+	dcUsers, err := cfg.dbQueries.ListUsernamesWithPermission(ctx, permReqWork)
+	if err != nil {
+		return "", err
+	}
+
+	sent := 0
+	for _, obj := range objs {
+		date := obj.PromoteDate.Time.Format(time.DateOnly)
+		message := fmt.Sprintf("%s is still in daftarkan, it was promoted on %s", displayName(obj.ObjKind, obj.Obj, obj.Lib, obj.Subtree), date)
+		for _, recipient := range append([]string{obj.Developer}, dcUsers...) {
+			isNew, err := notify(ctx, cfg.dbQueries, notice{
+				recipient: recipient,
+				kind:      notifyStaleDaftarkan,
+				message:   message,
+				objID:     obj.ID,
+				dedupeKey: fmt.Sprintf("%s:%s:%s:%s", notifyStaleDaftarkan, obj.ID, date, strings.ToLower(recipient)),
+			})
+			if err != nil {
+				return "", err
+			}
+			if isNew {
+				sent++
+			}
+		}
+	}
+	return fmt.Sprintf("%d objs stale, %d notifications sent", len(objs), sent), nil
+}
+
+func (cfg *apiConfig) runTrashPurgeJob(ctx context.Context) (string, error) {
+	objs, objReqs, err := cfg.purgeExpiredTrash(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("purged %d objs and %d obj requests", objs, objReqs), nil
+}
+
+// ListJobs returns every job with its schedule, next run and last run.
+func (cfg *apiConfig) ListJobs(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c, permSchedulerRun); !ok {
+		return
+	}
+
+	now := time.Now()
+	jobs := make([]Job, 0, len(cfg.scheduler.jobs))
+	for _, j := range cfg.scheduler.jobs {
+		out := Job{Name: j.name, Description: j.description, Schedule: j.spec}
+
+		cfg.scheduler.mu.Lock()
+		next, ok := cfg.scheduler.next[j.name]
+		cfg.scheduler.mu.Unlock()
+		if !ok {
+			next = j.schedule.Next(now)
+		}
+		if !next.IsZero() {
+			out.NextRun = &next
+		}
+
+		last, err := cfg.dbQueries.GetLastJobRun(c.Request.Context(), j.name)
+		if err == nil {
+			run := toJobRun(last)
+			out.LastRun = &run
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("error getting last job run: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get jobs"})
+			return
+		}
+		jobs = append(jobs, out)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled": schedulerEnabled(),
+		"jobs":    jobs,
+	})
+}
+
+// ListJobRuns returns the run history, newest first. ?job= narrows it to one
+// job and ?limit= caps the number of runs (default 50, at most 500).
+func (cfg *apiConfig) ListJobRuns(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c, permSchedulerRun); !ok {
+		return
+	}
+
+	limit := 50
+	if val := c.Query("limit"); val != "" {
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		limit = min(n, 500)
+	}
+
+	rows, err := cfg.dbQueries.ListJobRuns(c.Request.Context(), database.ListJobRunsParams{
+		JobName: c.Query("job"),
+		MaxRows: int32(limit),
+	})
+	if err != nil {
+		log.Printf("error listing job runs: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get job runs"})
+		return
+	}
+
+	runs := make([]JobRun, 0, len(rows))
+	for _, row := range rows {
+		runs = append(runs, toJobRun(row))
+	}
+	c.JSON(http.StatusOK, runs)
+}
+
+// RunJob runs a job now, outside its schedule, and returns the run.
+func (cfg *apiConfig) RunJob(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permSchedulerRun)
+	if !ok {
+		return
+	}
+
+	j := cfg.scheduler.job(c.Param("name"))
+	if j == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+		return
+	}
+
+	run, _, err := cfg.scheduler.runJob(c.Request.Context(), j, time.Now().Truncate(time.Second), "manual:"+user.Username)
+	if err != nil {
+		log.Printf("error running job %s: %v", j.name, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not run job"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toJobRun(run))
+}
@@ -0,0 +1,790 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/paul39-33/imimix/internal/database"
+	"github.com/paul39-33/imimix/internal/sla"
+)
+
+const (
+	defaultBusinessHours = "08:00-17:00"
+	defaultWorkdays      = "MON-FRI"
+	defaultWarnPercent   = 80
+	// defaultEscalateTo is the role told about requests close to breaching
+	defaultEscalateTo = "dc"
+	// escalateTeamPrefix marks an escalate_to naming a team
+	escalateTeamPrefix = "team:"
+)
+
+// notification kinds
+const (
+	notifySLAAtRisk = "sla_at_risk"
+	notifySLABreach = "sla_breach"
+)
+
+type SLAPolicy struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Priority    string    `json:"priority,omitempty"`
+	LibPattern  string    `json:"lib_pattern"`
+	TargetHours int32     `json:"target_hours"`
+	WarnPercent int32     `json:"warn_percent"`
+	EscalateTo  string    `json:"escalate_to"`
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type SLAPolicyInput struct {
+	Name        string `json:"name"`
+	Priority    string `json:"priority"`
+	LibPattern  string `json:"lib_pattern"`
+	TargetHours int32  `json:"target_hours"`
+	WarnPercent int32  `json:"warn_percent"`
+	EscalateTo  string `json:"escalate_to"`
+}
+
+type SLAHoliday struct {
+	Date      string    `json:"date"`
+	Name      string    `json:"name"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ObjReqSLA is how a request stands against its SLA.
+type ObjReqSLA struct {
+	PolicyID     *uuid.UUID `json:"policy_id,omitempty"`
+	Policy       string     `json:"policy,omitempty"`
+	DueAt        time.Time  `json:"due_at"`
+	State        string     `json:"state"`
+	AtRisk       bool       `json:"at_risk"`
+	Breached     bool       `json:"breached"`
+	TargetHours  int32      `json:"target_hours,omitempty"`
+	ElapsedHours float64    `json:"elapsed_hours"`
+}
+
+// slaCalendarFromEnv reads the business calendar: SLA_BUSINESS_HOURS
+// (08:00-17:00), SLA_WORKDAYS (MON-FRI) and SLA_TIMEZONE (the server's).
+// Holidays come from the database.
+func slaCalendarFromEnv() (sla.Calendar, error) {
+	hours := os.Getenv("SLA_BUSINESS_HOURS")
+	if hours == "" {
+		hours = defaultBusinessHours
+	}
+	start, end, err := sla.ParseHours(hours)
+	if err != nil {
+		return sla.Calendar{}, fmt.Errorf("SLA_BUSINESS_HOURS: %w", err)
+	}
+
+	days := os.Getenv("SLA_WORKDAYS")
+	if days == "" {
+		days = defaultWorkdays
+	}
+	workdays, err := sla.ParseWorkdays(days)
+	if err != nil {
+		return sla.Calendar{}, fmt.Errorf("SLA_WORKDAYS: %w", err)
+	}
+
+	loc := time.Local
+	if tz := os.Getenv("SLA_TIMEZONE"); tz != "" {
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return sla.Calendar{}, fmt.Errorf("SLA_TIMEZONE: %w", err)
+		}
+	}
+
+	return sla.Calendar{Start: start, End: end, Workdays: workdays, Location: loc}, nil
+}
+
+func toSLAPolicy(p database.SlaPolicy) SLAPolicy {
+	return SLAPolicy{
+		ID:          p.ID,
+		Name:        p.Name,
+		Priority:    string(p.Priority.ReqPriority),
+		LibPattern:  p.LibPattern,
+		TargetHours: p.TargetHours,
+		WarnPercent: p.WarnPercent,
+		EscalateTo:  p.EscalateTo,
+		CreatedBy:   p.CreatedBy,
+		CreatedAt:   p.CreatedAt,
+	}
+}
+
+func toSLAHoliday(h database.SlaHoliday) SLAHoliday {
+	return SLAHoliday{
+		Date:      h.HolidayDate.Format(time.DateOnly),
+		Name:      h.Name,
+		CreatedBy: h.CreatedBy,
+		CreatedAt: h.CreatedAt,
+	}
+}
+
+// slaSetup is the calendar, with holidays, and the policies requests are
+// measured against.
+type slaSetup struct {
+	cal      sla.Calendar
+	policies map[uuid.UUID]database.SlaPolicy
+	matchers []sla.Policy
+}
+
+func loadSLASetup(ctx context.Context, q *database.Queries, cal sla.Calendar) (slaSetup, error) {
+	holidays, err := q.ListSLAHolidays(ctx)
+	if err != nil {
+		return slaSetup{}, err
+	}
+	cal.Holidays = make(map[string]bool, len(holidays))
+	for _, h := range holidays {
+		cal.Holidays[h.HolidayDate.Format(time.DateOnly)] = true
+	}
+
+	policies, err := q.ListSLAPolicies(ctx)
+	if err != nil {
+		return slaSetup{}, err
+	}
+	s := slaSetup{cal: cal, policies: make(map[uuid.UUID]database.SlaPolicy, len(policies))}
+	for _, p := range policies {
+		s.policies[p.ID] = p
+		s.matchers = append(s.matchers, slaMatcher(p))
+	}
+	return s, nil
+}
+
+func slaMatcher(p database.SlaPolicy) sla.Policy {
+	return sla.Policy{
+		ID:          p.ID,
+		Priority:    string(p.Priority.ReqPriority),
+		Lib:         p.LibPattern,
+		Target:      time.Duration(p.TargetHours) * time.Hour,
+		WarnPercent: int(p.WarnPercent),
+	}
+}
+
+// local reads an instant in the calendar's zone.
+func (s slaSetup) local(t time.Time) time.Time {
+	return t.In(s.cal.Location)
+}
+
+// assign picks the policy for a request and computes when it is due,
+// counting business time from when the request was made.
+func (s slaSetup) assign(req database.MimixObjReq) (uuid.NullUUID, sql.NullTime) {
+	lib := ""
+	if req.ObjKind == database.ObjKindLib {
+		lib = req.Lib
+	}
+	p, ok := sla.Select(s.matchers, string(req.Priority), lib)
+	if !ok {
+		return uuid.NullUUID{}, sql.NullTime{}
+	}
+	due := s.cal.Add(s.local(req.CreatedAt), p.Target)
+	if due.IsZero() {
+		return uuid.NullUUID{}, sql.NullTime{}
+	}
+	return uuid.NullUUID{UUID: p.ID, Valid: true}, sql.NullTime{Time: due, Valid: true}
+}
+
+// status reports how a request stands against its SLA, or nil when it has
+// none.
+func (s slaSetup) status(req database.MimixObjReq, now time.Time) *ObjReqSLA {
+	if !req.DueAt.Valid {
+		return nil
+	}
+
+	var m sla.Policy
+	out := &ObjReqSLA{DueAt: req.DueAt.Time}
+	if p, ok := s.policies[req.SlaPolicyID.UUID]; ok && req.SlaPolicyID.Valid {
+		m = slaMatcher(p)
+		out.PolicyID = &p.ID
+		out.Policy = p.Name
+		out.TargetHours = p.TargetHours
+	}
+
+	start, due, end := s.local(req.CreatedAt), s.local(req.DueAt.Time), now.In(s.cal.Location)
+	if req.ResolvedAt.Valid {
+		end = s.local(req.ResolvedAt.Time)
+	}
+	state := s.cal.Evaluate(m, start, due, end, req.ResolvedAt.Valid)
+
+	out.State = string(state)
+	out.AtRisk = state == sla.AtRisk
+	out.Breached = state == sla.Breached
+	out.ElapsedHours = s.cal.Between(start, end).Round(time.Minute).Hours()
+	return out
+}
+
+// applySLA sets the policy and due date of a request and returns the due
+// date.
+func (cfg *apiConfig) applySLA(ctx context.Context, q *database.Queries, id uuid.UUID) (sql.NullTime, error) {
+	req, err := q.GetMimixObjReqByID(ctx, id)
+	if err != nil {
+		return sql.NullTime{}, err
+	}
+	s, err := loadSLASetup(ctx, q, cfg.slaCalendar)
+	if err != nil {
+		return sql.NullTime{}, err
+	}
+	policyID, due := s.assign(req)
+	return due, q.SetObjReqSLA(ctx, database.SetObjReqSLAParams{
+		ID:          req.ID,
+		SlaPolicyID: policyID,
+		DueAt:       due,
+	})
+}
+
+// recalculateSLA reassigns the policy and due date of every pending request,
+// after the policies or the holidays changed.
+func (cfg *apiConfig) recalculateSLA(ctx context.Context, q *database.Queries) (int, error) {
+	s, err := loadSLASetup(ctx, q, cfg.slaCalendar)
+	if err != nil {
+		return 0, err
+	}
+	reqs, err := q.ListPendingObjReq(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, req := range reqs {
+		policyID, due := s.assign(req)
+		if policyID == req.SlaPolicyID && due.Valid == req.DueAt.Valid && due.Time.Equal(s.local(req.DueAt.Time)) {
+			continue
+		}
+		if err := q.SetObjReqSLA(ctx, database.SetObjReqSLAParams{
+			ID:          req.ID,
+			SlaPolicyID: policyID,
+			DueAt:       due,
+		}); err != nil {
+			return 0, err
+		}
+		changed++
+	}
+	return changed, nil
+}
+
+// escalationRecipients resolves a policy's escalate_to: team:<name> means
+// the members of that team, a role such as dc or auditor every user holding
+// it, anything else is a username.
+func escalationRecipients(ctx context.Context, q *database.Queries, escalateTo string) ([]string, error) {
+	if team, ok := strings.CutPrefix(escalateTo, escalateTeamPrefix); ok {
+		return q.ListTeamMemberNames(ctx, team)
+	}
+	_, err := q.GetRoleByName(ctx, escalateTo)
+	if errors.Is(err, sql.ErrNoRows) {
+		return []string{escalateTo}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return q.ListUsernamesWithRole(ctx, escalateTo)
+}
+
+// escalateSLA assigns an SLA to pending requests that have none yet and
+// tells each policy's escalation user, role or team about requests that are
+// at risk or breached.
+func (cfg *apiConfig) escalateSLA(ctx context.Context) (string, error) {
+	q := cfg.dbQueries
+	s, err := loadSLASetup(ctx, q, cfg.slaCalendar)
+	if err != nil {
+		return "", err
+	}
+	reqs, err := q.ListPendingObjReq(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	recipients := map[string][]string{}
+	atRisk, breached, sent := 0, 0, 0
+	for _, req := range reqs {
+		if !req.DueAt.Valid {
+			if req.SlaPolicyID, req.DueAt = s.assign(req); !req.DueAt.Valid {
+				continue
+			}
+			if err := q.SetObjReqSLA(ctx, database.SetObjReqSLAParams{
+				ID:          req.ID,
+				SlaPolicyID: req.SlaPolicyID,
+				DueAt:       req.DueAt,
+			}); err != nil {
+				return "", err
+			}
+		}
+
+		status := s.status(req, now)
+		var kind, message string
+		name := displayName(req.ObjKind, req.ObjName, req.Lib, req.Subtree)
+		due := s.local(req.DueAt.Time).Format("2006-01-02 15:04")
+		switch {
+		case status.Breached:
+			breached++
+			kind, message = notifySLABreach, fmt.Sprintf("%s breached its SLA, it was due %s", name, due)
+		case status.AtRisk:
+			atRisk++
+			kind, message = notifySLAAtRisk, fmt.Sprintf("%s is close to breaching its SLA, it is due %s", name, due)
+		default:
+			continue
+		}
+
+		escalateTo := defaultEscalateTo
+		if p, ok := s.policies[req.SlaPolicyID.UUID]; ok {
+			escalateTo = p.EscalateTo
+		}
+		to, ok := recipients[escalateTo]
+		if !ok {
+			if to, err = escalationRecipients(ctx, q, escalateTo); err != nil {
+				return "", err
+			}
+			recipients[escalateTo] = to
+		}
+
+		for _, recipient := range to {
+			isNew, err := notify(ctx, q, notice{
+				recipient: recipient,
+				kind:      kind,
+				message:   message,
+				objReqID:  req.ID,
+				dedupeKey: fmt.Sprintf("%s:%s:%s:%s", kind, req.ID, due, strings.ToLower(recipient)),
+			})
+			if err != nil {
+				return "", err
+			}
+			if isNew {
+				sent++
+			}
+		}
+	}
+	return fmt.Sprintf("%d at risk, %d breached, %d notifications sent", atRisk, breached, sent), nil
+}
+
+func (in SLAPolicyInput) normalize(ctx context.Context, q *database.Queries) (database.CreateSLAPolicyParams, error) {
+	params := database.CreateSLAPolicyParams{
+		Name:        strings.TrimSpace(in.Name),
+		TargetHours: in.TargetHours,
+		WarnPercent: in.WarnPercent,
+		EscalateTo:  strings.ToLower(strings.TrimSpace(in.EscalateTo)),
+	}
+	if params.Name == "" {
+		return params, fieldError{field: "name", err: errors.New("is required")}
+	}
+
+	if p := strings.ToLower(strings.TrimSpace(in.Priority)); p != "" {
+		priority, ok := allowedReqPriority[p]
+		if !ok {
+			return params, fieldError{field: "priority", err: errors.New("must be low, normal, high or emergency")}
+		}
+		params.Priority = database.NullReqPriority{ReqPriority: priority, Valid: true}
+	}
+
+	lib, err := normalizeNamePattern("lib_pattern", in.LibPattern)
+	if err != nil {
+		return params, err
+	}
+	params.LibPattern = lib
+
+	if params.TargetHours <= 0 {
+		return params, fieldError{field: "target_hours", err: errors.New("must be positive")}
+	}
+	if params.WarnPercent == 0 {
+		params.WarnPercent = defaultWarnPercent
+	}
+	if params.WarnPercent < 1 || params.WarnPercent > 99 {
+		return params, fieldError{field: "warn_percent", err: errors.New("must be between 1 and 99")}
+	}
+
+	if params.EscalateTo == "" {
+		params.EscalateTo = defaultEscalateTo
+	}
+	exists, err := escalationTargetExists(ctx, q, params.EscalateTo)
+	if err != nil {
+		return params, err
+	}
+	if !exists {
+		return params, fieldError{field: "escalate_to", err: errors.New("must be a role, team:<team> or an existing username")}
+	}
+	return params, nil
+}
+
+// escalationTargetExists reports whether escalate_to names an existing
+// team, role or user, in the order escalationRecipients tries them.
+func escalationTargetExists(ctx context.Context, q *database.Queries, escalateTo string) (bool, error) {
+	if team, ok := strings.CutPrefix(escalateTo, escalateTeamPrefix); ok {
+		_, err := q.GetTeamByName(ctx, team)
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+	_, err := q.GetRoleByName(ctx, escalateTo)
+	if err == nil {
+		return true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+	return q.CheckUserExists(ctx, escalateTo)
+}
+
+// bindSLAPolicy reads and validates a policy, writing the error response
+// itself when it cannot.
+func (cfg *apiConfig) bindSLAPolicy(c *gin.Context, id uuid.UUID) (database.CreateSLAPolicyParams, bool) {
+	var input SLAPolicyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		log.Printf("error binding json: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid parameters"})
+		return database.CreateSLAPolicyParams{}, false
+	}
+
+	ctx := c.Request.Context()
+	params, err := input.normalize(ctx, cfg.dbQueries)
+	var fe fieldError
+	if errors.As(err, &fe) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return params, false
+	}
+	if err != nil {
+		log.Printf("error validating sla policy: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not save sla policy"})
+		return params, false
+	}
+
+	exists, err := cfg.dbQueries.CheckSLAPolicyScopeExists(ctx, database.CheckSLAPolicyScopeExistsParams{
+		Priority:   params.Priority,
+		LibPattern: params.LibPattern,
+		ID:         id,
+	})
+	if err != nil {
+		log.Printf("error checking sla policy: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not save sla policy"})
+		return params, false
+	}
+	if exists {
+		c.JSON(http.StatusConflict, gin.H{"error": "a policy for this priority and lib pattern already exists"})
+		return params, false
+	}
+	return params, true
+}
+
+func (cfg *apiConfig) ListSLAPolicies(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c); !ok {
+		return
+	}
+
+	rows, err := cfg.dbQueries.ListSLAPolicies(c.Request.Context())
+	if err != nil {
+		log.Printf("error listing sla policies: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get sla policies"})
+		return
+	}
+
+	policies := make([]SLAPolicy, 0, len(rows))
+	for _, row := range rows {
+		policies = append(policies, toSLAPolicy(row))
+	}
+	c.JSON(http.StatusOK, policies)
+}
+
+// CreateSLAPolicy adds a policy and recalculates the due dates of pending
+// requests.
+func (cfg *apiConfig) CreateSLAPolicy(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permSLAManage)
+	if !ok {
+		return
+	}
+
+	params, ok := cfg.bindSLAPolicy(c, uuid.Nil)
+	if !ok {
+		return
+	}
+	params.CreatedBy = user.Username
+
+	ctx := c.Request.Context()
+	var (
+		policy       database.SlaPolicy
+		recalculated int
+	)
+	err := cfg.withTx(ctx, func(q *database.Queries) error {
+		var err error
+		if policy, err = q.CreateSLAPolicy(ctx, params); err != nil {
+			return err
+		}
+		recalculated, err = cfg.recalculateSLA(ctx, q)
+		return err
+	})
+	if err != nil {
+		log.Printf("error creating sla policy: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create sla policy"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"policy":       toSLAPolicy(policy),
+		"recalculated": recalculated,
+	})
+}
+
+// UpdateSLAPolicy replaces a policy and recalculates the due dates of pending
+// requests.
+func (cfg *apiConfig) UpdateSLAPolicy(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c, permSLAManage); !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	params, ok := cfg.bindSLAPolicy(c, id)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	var (
+		policy       database.SlaPolicy
+		recalculated int
+	)
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		if policy, err = q.UpdateSLAPolicy(ctx, database.UpdateSLAPolicyParams{
+			ID:          id,
+			Name:        params.Name,
+			Priority:    params.Priority,
+			LibPattern:  params.LibPattern,
+			TargetHours: params.TargetHours,
+			WarnPercent: params.WarnPercent,
+			EscalateTo:  params.EscalateTo,
+		}); err != nil {
+			return err
+		}
+		recalculated, err = cfg.recalculateSLA(ctx, q)
+		return err
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "sla policy not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("error updating sla policy: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not update sla policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"policy":       toSLAPolicy(policy),
+		"recalculated": recalculated,
+	})
+}
+
+// DeleteSLAPolicy removes a policy. Pending requests it covered fall back to
+// the next matching policy, if any.
+func (cfg *apiConfig) DeleteSLAPolicy(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c, permSLAManage); !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var recalculated int
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		rows, err := q.DeleteSLAPolicy(ctx, id)
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return sql.ErrNoRows
+		}
+		recalculated, err = cfg.recalculateSLA(ctx, q)
+		return err
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "sla policy not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("error deleting sla policy: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not delete sla policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "sla policy deleted",
+		"recalculated": recalculated,
+	})
+}
+
+func (cfg *apiConfig) ListSLAHolidays(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c); !ok {
+		return
+	}
+
+	rows, err := cfg.dbQueries.ListSLAHolidays(c.Request.Context())
+	if err != nil {
+		log.Printf("error listing sla holidays: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get holidays"})
+		return
+	}
+
+	holidays := make([]SLAHoliday, 0, len(rows))
+	for _, row := range rows {
+		holidays = append(holidays, toSLAHoliday(row))
+	}
+	c.JSON(http.StatusOK, holidays)
+}
+
+// parseHolidayDate reads a YYYY-MM-DD date.
+func parseHolidayDate(s string) (time.Time, error) {
+	d, err := time.Parse(time.DateOnly, strings.TrimSpace(s))
+	if err != nil {
+		return time.Time{}, fieldError{field: "date", err: errors.New("must be YYYY-MM-DD")}
+	}
+	return d, nil
+}
+
+// AddSLAHoliday adds a holiday, or renames it, and recalculates the due
+// dates of pending requests.
+func (cfg *apiConfig) AddSLAHoliday(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permSLAManage)
+	if !ok {
+		return
+	}
+
+	type parameters struct {
+		Date string `json:"date" binding:"required"`
+		Name string `json:"name"`
+	}
+
+	var params parameters
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	date, err := parseHolidayDate(params.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var (
+		holiday      database.SlaHoliday
+		recalculated int
+	)
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		if holiday, err = q.UpsertSLAHoliday(ctx, database.UpsertSLAHolidayParams{
+			HolidayDate: date,
+			Name:        strings.TrimSpace(params.Name),
+			CreatedBy:   user.Username,
+		}); err != nil {
+			return err
+		}
+		recalculated, err = cfg.recalculateSLA(ctx, q)
+		return err
+	})
+	if err != nil {
+		log.Printf("error adding sla holiday: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not add holiday"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"holiday":      toSLAHoliday(holiday),
+		"recalculated": recalculated,
+	})
+}
+
+func (cfg *apiConfig) DeleteSLAHoliday(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c, permSLAManage); !ok {
+		return
+	}
+
+	date, err := parseHolidayDate(c.Param("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var recalculated int
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		rows, err := q.DeleteSLAHoliday(ctx, date)
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return sql.ErrNoRows
+		}
+		recalculated, err = cfg.recalculateSLA(ctx, q)
+		return err
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "holiday not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("error deleting sla holiday: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not delete holiday"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "holiday deleted",
+		"recalculated": recalculated,
+	})
+}
+
+// GetObjReqSLA returns how a request stands against its SLA.
+func (cfg *apiConfig) GetObjReqSLA(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid obj request id"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	req, err := cfg.dbQueries.GetMimixObjReqByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "obj request not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("error getting mimix object request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get mimix object request"})
+		return
+	}
+	if !cfg.requireLib(c, user, req.Lib, database.LibPermissionRead, "obj request not found") {
+		return
+	}
+
+	s, err := loadSLASetup(ctx, cfg.dbQueries, cfg.slaCalendar)
+	if err != nil {
+		log.Printf("error loading sla policies: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get sla"})
+		return
+	}
+
+	status := s.status(req, time.Now())
+	if status == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no sla policy applies to this request"})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
@@ -0,0 +1,36 @@
+-- name: CreateAttachment :one
+INSERT INTO attachment (id, obj_id, obj_req_id, file_name, content_type, size_bytes, sha256, storage_key, uploaded_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: GetAttachmentByID :one
+SELECT *
+FROM attachment
+WHERE id = $1;
+
+-- name: ListObjAttachments :many
+SELECT *
+FROM attachment
+WHERE obj_id = $1
+ORDER BY created_at;
+
+-- name: ListObjReqAttachments :many
+SELECT *
+FROM attachment
+WHERE obj_req_id = $1
+ORDER BY created_at;
+
+-- name: ListOrphanAttachments :many
+SELECT *
+FROM attachment
+WHERE obj_id IS NULL AND obj_req_id IS NULL
+ORDER BY created_at;
+
+-- name: DeleteAttachment :execrows
+DELETE FROM attachment
+WHERE id = $1;
+
+-- name: DetachAttachment :execrows
+UPDATE attachment
+SET obj_id = NULL, obj_req_id = NULL
+WHERE id = $1;
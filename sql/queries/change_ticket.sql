@@ -0,0 +1,37 @@
+-- name: CreateChangeTicket :one
+INSERT INTO change_ticket (ref_no, description, owner, window_start, window_end)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetChangeTicketByID :one
+SELECT *
+FROM change_ticket
+WHERE id = $1;
+
+-- name: CheckChangeTicketRefExists :one
+SELECT EXISTS(SELECT 1 FROM change_ticket WHERE ref_no = $1);
+
+-- name: ListChangeTickets :many
+SELECT t.*,
+    COUNT(r.id) AS total,
+    COUNT(r.id) FILTER (WHERE r.req_status = 'pending') AS pending,
+    COUNT(r.id) FILTER (WHERE r.req_status = 'completed') AS completed,
+    COUNT(r.id) FILTER (WHERE r.req_status = 'rejected') AS rejected
+FROM change_ticket AS t
+LEFT JOIN mimix_obj_req AS r ON r.ticket_id = t.id AND r.deleted_at IS NULL
+GROUP BY t.id
+ORDER BY t.created_at DESC;
+
+-- name: GetChangeTicketProgress :one
+SELECT
+    COUNT(id) AS total,
+    COUNT(id) FILTER (WHERE req_status = 'pending') AS pending,
+    COUNT(id) FILTER (WHERE req_status = 'completed') AS completed,
+    COUNT(id) FILTER (WHERE req_status = 'rejected') AS rejected
+FROM mimix_obj_req
+WHERE ticket_id = $1 AND deleted_at IS NULL;
+
+-- name: TouchChangeTicket :exec
+UPDATE change_ticket
+SET updated_at = NOW()
+WHERE id = $1;
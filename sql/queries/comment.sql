@@ -0,0 +1,60 @@
+-- name: CreateComment :one
+INSERT INTO comment (obj_id, obj_req_id, parent_id, author, body)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetCommentByID :one
+SELECT *
+FROM comment
+WHERE id = $1;
+
+-- name: ListObjComments :many
+SELECT *
+FROM comment
+WHERE obj_id = $1
+ORDER BY created_at;
+
+-- name: ListObjReqComments :many
+SELECT *
+FROM comment
+WHERE obj_req_id = $1
+ORDER BY created_at;
+
+-- name: CountObjComments :one
+SELECT COUNT(*)
+FROM comment
+WHERE obj_id = $1 AND deleted_at IS NULL;
+
+-- name: CountObjReqComments :one
+SELECT COUNT(*)
+FROM comment
+WHERE obj_req_id = $1 AND deleted_at IS NULL;
+
+-- name: UpdateCommentBody :one
+UPDATE comment
+SET body = $2, edited_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: DeleteComment :one
+UPDATE comment
+SET body = '', deleted_at = NOW(), deleted_by = $2
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: CreateCommentRevision :exec
+INSERT INTO comment_revision (comment_id, change, body, changed_by)
+VALUES ($1, $2, $3, $4);
+
+-- name: ListCommentRevisions :many
+SELECT *
+FROM comment_revision
+WHERE comment_id = $1
+ORDER BY changed_at;
+
+-- name: GetCommentLib :one
+SELECT COALESCE(o.lib, r.lib, '')::TEXT AS lib
+FROM comment c
+LEFT JOIN mimix_obj o ON o.id = c.obj_id
+LEFT JOIN mimix_obj_req r ON r.id = c.obj_req_id
+WHERE c.id = $1;
@@ -0,0 +1,25 @@
+-- name: CreateCoverageRule :one
+INSERT INTO coverage_rule (obj_kind, lib_pattern, obj_pattern, obj_type, prc_type, mimix_status, description, created_by, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+RETURNING *;
+
+-- name: ListCoverageRules :many
+SELECT *
+FROM coverage_rule
+ORDER BY created_at, id;
+
+-- name: GetCoverageRuleByID :one
+SELECT *
+FROM coverage_rule
+WHERE id = $1;
+
+-- name: CheckCoverageRuleExists :one
+SELECT EXISTS (
+    SELECT 1
+    FROM coverage_rule
+    WHERE obj_kind = $1 AND lib_pattern = $2 AND obj_pattern = $3 AND obj_type = $4
+);
+
+-- name: DeleteCoverageRule :execrows
+DELETE FROM coverage_rule
+WHERE id = $1;
@@ -0,0 +1,26 @@
+-- name: CreateDeploymentEvent :one
+INSERT INTO deployment_event (event_id, obj_kind, lib, obj, obj_type, obj_ver, deployed_at, payload, status, reason, obj_req_id, received_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW())
+RETURNING *;
+
+-- name: GetDeploymentEventByEventID :one
+SELECT *
+FROM deployment_event
+WHERE event_id = $1;
+
+-- name: GetDeploymentEventByID :one
+SELECT *
+FROM deployment_event
+WHERE id = $1;
+
+-- name: ListDeploymentEventsForReview :many
+SELECT *
+FROM deployment_event
+WHERE status IN ('unmatched', 'ambiguous')
+ORDER BY received_at, id;
+
+-- name: ResolveDeploymentEvent :one
+UPDATE deployment_event
+SET status = $2, obj_req_id = $3, resolved_by = $4, resolved_at = NOW()
+WHERE id = $1 AND status IN ('unmatched', 'ambiguous')
+RETURNING *;
@@ -0,0 +1,15 @@
+-- name: ListDeveloperMatches :many
+SELECT *
+FROM developer_match
+WHERE sqlc.arg(method)::TEXT = '' OR method::TEXT = sqlc.arg(method)::TEXT
+ORDER BY developer;
+
+-- name: GetDeveloperMatch :one
+SELECT *
+FROM developer_match
+WHERE developer = $1;
+
+-- name: ResolveDeveloperMatch :exec
+UPDATE developer_match
+SET user_id = $2, method = 'manual', distance = NULL, resolved_by = $3, resolved_at = NOW()
+WHERE developer = $1;
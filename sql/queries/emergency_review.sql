@@ -0,0 +1,45 @@
+-- name: CreateEmergencyReview :one
+INSERT INTO emergency_review (obj_req_id, promotion_id, skipped_stages, actor)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetEmergencyReviewByID :one
+SELECT *
+FROM emergency_review
+WHERE id = $1;
+
+-- name: ListEmergencyReviews :many
+SELECT *
+FROM emergency_review
+WHERE sqlc.narg(status)::emergency_review_status IS NULL OR status = sqlc.narg(status)
+ORDER BY created_at;
+
+-- name: ListObjReqEmergencyReviews :many
+SELECT *
+FROM emergency_review
+WHERE obj_req_id = $1
+ORDER BY created_at;
+
+-- name: CheckUnjustifiedEmergency :one
+SELECT EXISTS (
+    SELECT 1
+    FROM emergency_review
+    WHERE actor = $1 AND status = 'awaiting_justification'
+);
+
+-- name: JustifyEmergencyReview :one
+UPDATE emergency_review
+SET justification = $2, justified_at = NOW(), status = 'awaiting_review'
+WHERE id = $1 AND status = 'awaiting_justification'
+RETURNING *;
+
+-- name: ReviewEmergencyReview :one
+UPDATE emergency_review
+SET status = $2, reviewer = $3, review_note = $4, reviewed_at = NOW()
+WHERE id = $1 AND status = 'awaiting_review'
+RETURNING *;
+
+-- name: ListEmergencyReviewLibs :many
+SELECT e.id, r.lib
+FROM emergency_review e
+JOIN mimix_obj_req r ON r.id = e.obj_req_id;
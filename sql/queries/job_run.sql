@@ -0,0 +1,28 @@
+-- name: TrySchedulerLock :one
+SELECT pg_try_advisory_lock($1) AS locked;
+
+-- name: ClaimJobRun :one
+INSERT INTO job_run (job_name, scheduled_for, runner, status, started_at)
+VALUES ($1, $2, $3, 'running', NOW())
+ON CONFLICT (job_name, scheduled_for) DO NOTHING
+RETURNING *;
+
+-- name: FinishJobRun :one
+UPDATE job_run
+SET status = $2, summary = $3, error = $4, finished_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: ListJobRuns :many
+SELECT *
+FROM job_run
+WHERE sqlc.arg(job_name)::text = '' OR job_name = sqlc.arg(job_name)::text
+ORDER BY started_at DESC
+LIMIT sqlc.arg(max_rows)::int;
+
+-- name: GetLastJobRun :one
+SELECT *
+FROM job_run
+WHERE job_name = $1
+ORDER BY started_at DESC
+LIMIT 1;
@@ -0,0 +1,46 @@
+-- name: ListRestrictedLibs :many
+SELECT lib
+FROM mimix_lib
+WHERE restricted
+ORDER BY lib;
+
+-- name: SetLibRestricted :exec
+UPDATE mimix_lib
+SET restricted = $2
+WHERE id = $1;
+
+-- name: ListUserLibGrants :many
+SELECT l.lib, a.permission, t.name AS team_name
+FROM lib_acl a
+JOIN mimix_lib l ON l.id = a.lib_id
+LEFT JOIN team t ON t.id = a.team_id
+WHERE a.user_id = $1
+   OR a.team_id IN (SELECT team_id FROM team_member WHERE user_id = $1)
+ORDER BY l.lib, t.name NULLS FIRST;
+
+-- name: ListLibACL :many
+SELECT a.id, a.user_id, u.username, a.team_id, t.name AS team_name,
+       a.permission, a.granted_by, a.created_at
+FROM lib_acl a
+LEFT JOIN users u ON u.id = a.user_id
+LEFT JOIN team t ON t.id = a.team_id
+WHERE a.lib_id = $1
+ORDER BY a.created_at;
+
+-- name: UpsertLibUserGrant :one
+INSERT INTO lib_acl (lib_id, user_id, permission, granted_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (lib_id, user_id) WHERE user_id IS NOT NULL
+DO UPDATE SET permission = EXCLUDED.permission, granted_by = EXCLUDED.granted_by
+RETURNING *;
+
+-- name: UpsertLibTeamGrant :one
+INSERT INTO lib_acl (lib_id, team_id, permission, granted_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (lib_id, team_id) WHERE team_id IS NOT NULL
+DO UPDATE SET permission = EXCLUDED.permission, granted_by = EXCLUDED.granted_by
+RETURNING *;
+
+-- name: DeleteLibACL :execrows
+DELETE FROM lib_acl
+WHERE id = $1;
@@ -9,8 +9,3 @@ INSERT INTO mimix_lib (lib)
 VALUES ($1)
 RETURNING id, lib;
 
--- name: UpdateObjLibID :exec
-UPDATE mimix_obj
-SET lib_id = $2
-WHERE id = $1;
-
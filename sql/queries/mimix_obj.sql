@@ -1,24 +1,25 @@
 -- name: AddObj :one
-INSERT INTO mimix_obj (obj, obj_type, promote_date, obj_ver, lib, lib_id, mimix_status, developer, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
-RETURNING id, obj, obj_type, promote_date, obj_ver, lib, lib_id, mimix_status, developer, updated_at;
+INSERT INTO mimix_obj (obj, obj_type, promote_date, obj_ver, lib, lib_id, mimix_status, developer, developer_id, obj_kind, prc_type, subtree, rule_id, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, (SELECT id FROM users WHERE LOWER(username) = LOWER($8)), $9, $10, $11, $12, NOW())
+RETURNING id, obj, obj_type, promote_date, obj_ver, lib, lib_id, mimix_status, developer, updated_at, version, obj_kind, prc_type, subtree, rule_id;
 
 -- name: UpdateObjStatus :exec
 UPDATE mimix_obj
-SET mimix_status = $2, updated_at = NOW()
-WHERE obj = $1
+SET mimix_status = $2, rule_id = NULL, updated_at = NOW(), version = version + 1
+WHERE obj = $1 AND deleted_at IS NULL
 RETURNING obj, mimix_status;
 
 
 -- name: GetObjByID :one
 SELECT *
 FROM mimix_obj
-WHERE id = $1;
+WHERE id = $1 AND deleted_at IS NULL;
 
 
--- name: RemoveObjByID :exec
-DELETE FROM mimix_obj
-WHERE id = $1;
+-- name: RemoveObjByID :execrows
+UPDATE mimix_obj
+SET deleted_at = NOW(), deleted_by = $2, updated_at = NOW(), version = version + 1
+WHERE id = $1 AND deleted_at IS NULL;
 
 -- name: AddObjToObjReq :exec
 INSERT INTO mimix_obj_req (
@@ -32,7 +33,11 @@ INSERT INTO mimix_obj_req (
     obj_type,
     promote_date,
     developer,
-    source_obj_id
+    developer_id,
+    source_obj_id,
+    obj_kind,
+    prc_type,
+    subtree
 )
 SELECT
     o.obj,
@@ -45,14 +50,18 @@ SELECT
     o.obj_type,
     o.promote_date,
     o.developer,
-    o.id          -- source obj id
+    o.developer_id,
+    o.id,         -- source obj id
+    o.obj_kind,
+    o.prc_type,
+    o.subtree
 FROM mimix_obj AS o
-WHERE o.id = $1;
+WHERE o.id = $1 AND o.deleted_at IS NULL;
 
 -- name: CompleteObjMimixStatus :exec
 UPDATE mimix_obj
-SET mimix_status = 'done', updated_at = NOW()
-WHERE id = $1;
+SET mimix_status = 'done', rule_id = NULL, updated_at = NOW(), version = version + 1
+WHERE id = $1 AND deleted_at IS NULL;
 
 -- name: UpdateObjInfo :one
 UPDATE mimix_obj
@@ -64,31 +73,134 @@ SET
     promote_date  = $6,
     mimix_status  = $7,
     developer     = $8,
+    developer_id  = (SELECT id FROM users WHERE LOWER(username) = LOWER($8)),
     keterangan    = $9,
-    updated_at    = NOW()
-WHERE id = $1
+    obj_kind      = $11,
+    prc_type      = $12,
+    subtree       = $13,
+    -- a status set by hand is no longer explained by a coverage rule
+    rule_id       = CASE WHEN mimix_status = $7 THEN rule_id END,
+    updated_at    = NOW(),
+    version       = version + 1
+WHERE id = $1 AND version = $10 AND deleted_at IS NULL
 RETURNING *;
 
 -- name: UpdateMimixStatus :exec
 UPDATE mimix_obj
-SET mimix_status = $2, updated_at = NOW()
-WHERE id = $1;
+SET mimix_status = $2, rule_id = NULL, updated_at = NOW(), version = version + 1
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: ConvertObjVersion :one
+UPDATE mimix_obj
+SET
+    obj_ver       = $2,
+    promote_date  = $3,
+    -- a request without a developer keeps the obj's
+    developer     = COALESCE(NULLIF($4, ''), developer),
+    developer_id  = CASE WHEN $4 = '' THEN developer_id ELSE (SELECT id FROM users WHERE LOWER(username) = LOWER($4)) END,
+    mimix_status  = 'done',
+    rule_id       = NULL,
+    updated_at    = NOW(),
+    version       = version + 1
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
 
 -- name: GetMimixStatusByID :one
 SELECT mimix_status
 FROM mimix_obj
-WHERE id = $1;
+WHERE id = $1 AND deleted_at IS NULL;
 
 -- name: SearchMimixObj :many
-SELECT *
-FROM mimix_obj
+SELECT o.*
+FROM mimix_obj o
+LEFT JOIN users d ON d.id = o.developer_id
 WHERE
-    obj ILIKE '%' || $1 || '%'
- OR lib ILIKE '%' || $1 || '%'
- OR developer ILIKE '%' || $1 || '%'
-ORDER BY updated_at DESC;
+    o.deleted_at IS NULL
+AND (
+    o.obj ILIKE '%' || $1 || '%'
+ OR o.lib ILIKE '%' || $1 || '%'
+ OR COALESCE(d.username, o.developer) ILIKE '%' || $1 || '%'
+)
+ORDER BY o.updated_at DESC;
 
 -- name: GetObjByNameAndLib :one
 SELECT *
 FROM mimix_obj
-WHERE obj = $1 AND lib = $2;
\ No newline at end of file
+WHERE obj = $1 AND lib = $2 AND deleted_at IS NULL;
+
+-- name: ListDeletedObj :many
+SELECT *
+FROM mimix_obj
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC;
+
+-- name: RestoreObjByID :one
+UPDATE mimix_obj
+SET deleted_at = NULL, deleted_by = NULL, updated_at = NOW(), version = version + 1
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: GetDeletedObjByID :one
+SELECT *
+FROM mimix_obj
+WHERE id = $1 AND deleted_at IS NOT NULL;
+
+-- name: PurgeObjByID :execrows
+DELETE FROM mimix_obj
+WHERE id = $1 AND deleted_at IS NOT NULL;
+
+-- name: PurgeExpiredObj :execrows
+DELETE FROM mimix_obj
+WHERE deleted_at < NOW() - make_interval(days => sqlc.arg(retention_days)::int);
+
+-- name: SetObjCoverage :exec
+UPDATE mimix_obj
+SET mimix_status = $2, rule_id = $3, updated_at = NOW(), version = version + 1
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: ResetObjCoverageByRule :exec
+UPDATE mimix_obj
+SET mimix_status = 'unset', rule_id = NULL, updated_at = NOW(), version = version + 1
+WHERE rule_id = $1 AND deleted_at IS NULL;
+
+-- name: ListStaleDaftarkanObj :many
+SELECT *
+FROM mimix_obj
+WHERE mimix_status = 'daftarkan' AND deleted_at IS NULL
+  AND promote_date < NOW() - make_interval(days => sqlc.arg(days)::int)
+ORDER BY promote_date;
+
+-- name: ListObjByDeveloper :many
+SELECT *
+FROM mimix_obj
+WHERE developer_id = $1 AND deleted_at IS NULL
+ORDER BY updated_at DESC;
+
+-- name: CountObjByDeveloper :many
+SELECT mimix_status, COUNT(*) AS count
+FROM mimix_obj
+WHERE developer_id = $1 AND deleted_at IS NULL
+GROUP BY mimix_status;
+
+-- name: ReassignObjDeveloper :many
+UPDATE mimix_obj
+SET developer_id = sqlc.arg(to_id),
+    developer = (SELECT LOWER(username) FROM users WHERE id = sqlc.arg(to_id)),
+    updated_at = NOW(),
+    version = version + 1
+WHERE developer_id = sqlc.arg(from_id) AND deleted_at IS NULL
+RETURNING id, obj_ver, promote_date, developer;
+
+-- name: LinkObjDeveloper :many
+UPDATE mimix_obj
+SET developer_id = sqlc.arg(user_id),
+    developer = (SELECT LOWER(username) FROM users WHERE id = sqlc.arg(user_id)),
+    updated_at = NOW(),
+    version = version + 1
+WHERE developer_id IS NULL AND LOWER(TRIM(developer)) = sqlc.arg(developer)::TEXT
+RETURNING id, obj_ver, promote_date, developer;
+
+-- name: ListObjLibsByName :many
+SELECT DISTINCT lib
+FROM mimix_obj
+WHERE obj = $1 AND deleted_at IS NULL;
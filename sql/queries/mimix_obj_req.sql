@@ -7,47 +7,59 @@ INSERT INTO mimix_obj_req (
     obj_ver,
     obj_type,
     promote_date,
-    developer
+    developer,
+    ticket_id,
+    obj_kind,
+    prc_type,
+    subtree,
+    priority,
+    developer_id
 )
 VALUES (
     $1, $2, $3, $4,
-    $5, $6, $7, $8
+    $5, $6, $7, $8,
+    $9, $10, $11, $12,
+    $13, (SELECT id FROM users WHERE LOWER(username) = LOWER($8))
 )
-RETURNING id, obj_name, requester, req_status, lib, obj_ver, obj_type, promote_date, developer, created_at, updated_at;
+RETURNING id, obj_name, requester, req_status, lib, obj_ver, obj_type, promote_date, developer, created_at, updated_at, ticket_id, version, obj_kind, prc_type, subtree, priority;
 
 -- name: UpdateMimixObjReqStatus :exec
 UPDATE mimix_obj_req
-SET req_status = $1
-WHERE id = $2;
+SET req_status = $1,
+    resolved_at = CASE WHEN $1 = 'pending' THEN NULL ELSE COALESCE(resolved_at, NOW()) END,
+    version = version + 1
+WHERE id = $2 AND deleted_at IS NULL;
 
 -- name: GetMimixObjReqByRequester :many
 SELECT *
 FROM mimix_obj_req
-WHERE requester = $1;
+WHERE requester = $1 AND deleted_at IS NULL
+ORDER BY priority DESC, created_at;
 
 -- name: GetMimixObjReq :many
 SELECT *
-FROM mimix_obj_req;
+FROM mimix_obj_req
+WHERE deleted_at IS NULL;
 
--- name: RemoveMimixObjReq :exec
-DELETE FROM mimix_obj_req
-WHERE id = $1
-RETURNING obj_name;
+-- name: RemoveMimixObjReq :execrows
+UPDATE mimix_obj_req
+SET deleted_at = NOW(), deleted_by = $2, updated_at = NOW(), version = version + 1
+WHERE id = $1 AND deleted_at IS NULL;
 
 -- name: GetMimixObjReqByID :one
 SELECT *
 FROM mimix_obj_req
-WHERE id = $1;
+WHERE id = $1 AND deleted_at IS NULL;
 
 -- name: CompleteMimixObjReq :exec
 UPDATE mimix_obj_req
-SET req_status = 'completed', updated_at = NOW()
-WHERE id = $1;
+SET req_status = 'completed', resolved_at = NOW(), updated_at = NOW(), version = version + 1
+WHERE id = $1 AND deleted_at IS NULL;
 
 -- name: UpdatePromoteStatus :exec
 UPDATE mimix_obj_req
-SET promote_status = $2
-WHERE id = $1;
+SET promote_status = $2, version = version + 1
+WHERE id = $1 AND deleted_at IS NULL;
 
 -- name: UpdateMimixObjReqInfo :one
 UPDATE mimix_obj_req
@@ -57,22 +69,223 @@ SET obj_name = $2,
     obj_type = $5,
     promote_date = $6,
     developer = $7,
+    developer_id = (SELECT id FROM users WHERE LOWER(username) = LOWER($7)),
     updated_at = NOW(),
     promote_status = $8,
-    req_status = $9
-WHERE id = $1
-RETURNING id, obj_name, requester, req_status, lib, obj_ver, obj_type, promote_date, developer, created_at, updated_at, promote_status;
+    req_status = $9,
+    resolved_at = CASE WHEN $9 = 'pending' THEN NULL ELSE COALESCE(resolved_at, NOW()) END,
+    obj_kind = $11,
+    prc_type = $12,
+    subtree = $13,
+    priority = $14,
+    version = version + 1
+WHERE id = $1 AND version = $10 AND deleted_at IS NULL
+RETURNING *;
 
 -- name: SearchMimixObjReq :many
-SELECT * FROM mimix_obj_req
+SELECT r.* FROM mimix_obj_req r
+LEFT JOIN users d ON d.id = r.developer_id
 WHERE
-    obj_name ILIKE '%' || $1 || '%'
- OR requester ILIKE '%' || $1 || '%'
- OR developer ILIKE '%' || $1 || '%'
- OR lib ILIKE '%' || $1 || '%'
-ORDER BY updated_at DESC;
+    r.deleted_at IS NULL
+AND (
+    r.obj_name ILIKE '%' || $1 || '%'
+ OR r.requester ILIKE '%' || $1 || '%'
+ OR COALESCE(d.username, r.developer) ILIKE '%' || $1 || '%'
+ OR r.lib ILIKE '%' || $1 || '%'
+)
+ORDER BY r.priority DESC, r.created_at;
 
 -- name: GetPendingObjReqByNameAndLib :one
 SELECT *
 FROM mimix_obj_req
-WHERE obj_name = $1 AND lib = $2 AND req_status = 'pending';
+WHERE obj_name = $1 AND lib = $2 AND req_status = 'pending' AND deleted_at IS NULL;
+
+-- name: GetMimixObjReqByTicketID :many
+SELECT *
+FROM mimix_obj_req
+WHERE ticket_id = $1 AND deleted_at IS NULL
+ORDER BY created_at;
+
+-- name: SetMimixObjReqTicket :execrows
+UPDATE mimix_obj_req
+SET ticket_id = $2, updated_at = NOW(), version = version + 1
+WHERE id = $1 AND deleted_at IS NULL AND (ticket_id IS NULL OR ticket_id = $2);
+
+-- name: RejectMimixObjReq :exec
+UPDATE mimix_obj_req
+SET req_status = 'rejected', resolved_at = NOW(), updated_at = NOW(), version = version + 1
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: ListDeletedMimixObjReq :many
+SELECT *
+FROM mimix_obj_req
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC;
+
+-- name: RestoreMimixObjReq :one
+UPDATE mimix_obj_req
+SET deleted_at = NULL, deleted_by = NULL, updated_at = NOW(), version = version + 1
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: PurgeMimixObjReq :execrows
+DELETE FROM mimix_obj_req
+WHERE id = $1 AND deleted_at IS NOT NULL;
+
+-- name: PurgeExpiredMimixObjReq :execrows
+DELETE FROM mimix_obj_req
+WHERE deleted_at < NOW() - make_interval(days => sqlc.arg(retention_days)::int);
+
+-- name: ClearObjReqSource :exec
+UPDATE mimix_obj_req
+SET source_obj_id = NULL
+WHERE source_obj_id = $1;
+
+-- name: ClearExpiredObjReqSources :exec
+UPDATE mimix_obj_req
+SET source_obj_id = NULL
+WHERE source_obj_id IN (
+    SELECT id FROM mimix_obj
+    WHERE deleted_at < NOW() - make_interval(days => sqlc.arg(retention_days)::int)
+);
+
+-- name: ListPendingObjReqForDeployment :many
+SELECT *
+FROM mimix_obj_req
+WHERE obj_kind = $1 AND obj_name = $2 AND lib = $3 AND obj_type = $4
+  AND req_status = 'pending' AND deleted_at IS NULL
+ORDER BY created_at;
+
+-- name: ApplyObjReqDeployment :one
+UPDATE mimix_obj_req
+SET promote_status = 'deployed', obj_ver = $2, stage_id = $3, updated_at = NOW(), version = version + 1
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: ListObjReqDueForReminder :many
+SELECT *
+FROM mimix_obj_req
+WHERE req_status = 'pending' AND deleted_at IS NULL
+  AND promote_date >= NOW()
+  AND promote_date < NOW() + make_interval(days => sqlc.arg(days)::int)
+ORDER BY promote_date;
+
+-- name: ListObjReqPastPromoteDate :many
+SELECT *
+FROM mimix_obj_req
+WHERE req_status = 'pending' AND deleted_at IS NULL AND promote_date < NOW()
+ORDER BY promote_date;
+
+-- name: SetObjReqSLA :exec
+UPDATE mimix_obj_req
+SET sla_policy_id = $2, due_at = $3
+WHERE id = $1;
+
+-- name: ListPendingObjReq :many
+SELECT *
+FROM mimix_obj_req
+WHERE req_status = 'pending' AND deleted_at IS NULL
+ORDER BY created_at;
+
+-- name: ClaimObjReq :one
+UPDATE mimix_obj_req
+SET assignee = $2, assigned_by = $2, assigned_at = NOW(), updated_at = NOW(), version = version + 1
+WHERE id = $1 AND req_status = 'pending' AND deleted_at IS NULL
+  AND (assignee IS NULL OR assignee = $2)
+RETURNING *;
+
+-- name: AssignObjReq :one
+UPDATE mimix_obj_req
+SET assignee = sqlc.narg(assignee),
+    assigned_by = sqlc.arg(assigned_by),
+    assigned_at = CASE WHEN sqlc.narg(assignee)::text IS NULL THEN NULL ELSE NOW() END,
+    updated_at = NOW(),
+    version = version + 1
+WHERE id = sqlc.arg(id) AND req_status = 'pending' AND deleted_at IS NULL
+RETURNING *;
+
+-- name: ListObjReqByAssignee :many
+SELECT *
+FROM mimix_obj_req
+WHERE assignee = $1 AND req_status = 'pending' AND deleted_at IS NULL
+ORDER BY priority DESC, due_at NULLS LAST, created_at;
+
+-- name: ListUnassignedObjReq :many
+SELECT *
+FROM mimix_obj_req
+WHERE assignee IS NULL AND req_status = 'pending' AND deleted_at IS NULL
+ORDER BY priority DESC, due_at NULLS LAST, created_at;
+
+-- name: GetLastAutoAssignee :one
+SELECT assignee
+FROM mimix_obj_req
+WHERE assigned_by = 'auto' AND assignee IS NOT NULL
+ORDER BY assigned_at DESC
+LIMIT 1;
+
+-- name: ListObjLinkedRequests :many
+SELECT *
+FROM mimix_obj_req
+WHERE deleted_at IS NULL
+  AND (
+    source_obj_id = sqlc.arg(obj_id)::uuid
+    OR id IN (SELECT obj_req_id FROM object_versions WHERE obj_id = sqlc.arg(obj_id)::uuid)
+  )
+ORDER BY created_at DESC;
+
+-- name: CountObjReqByRequester :many
+SELECT req_status, COUNT(*) AS count
+FROM mimix_obj_req
+WHERE requester = $1 AND deleted_at IS NULL
+GROUP BY req_status;
+
+-- name: ReassignObjReqDeveloper :execrows
+UPDATE mimix_obj_req
+SET developer_id = sqlc.arg(to_id),
+    developer = (SELECT LOWER(username) FROM users WHERE id = sqlc.arg(to_id)),
+    updated_at = NOW(),
+    version = version + 1
+WHERE developer_id = sqlc.arg(from_id) AND req_status = 'pending' AND deleted_at IS NULL;
+
+-- name: LinkObjReqDeveloper :execrows
+UPDATE mimix_obj_req
+SET developer_id = sqlc.arg(user_id),
+    developer = (SELECT LOWER(username) FROM users WHERE id = sqlc.arg(user_id)),
+    updated_at = NOW(),
+    version = version + 1
+WHERE developer_id IS NULL AND LOWER(TRIM(developer)) = sqlc.arg(developer)::TEXT;
+
+-- name: SetObjReqTeam :one
+UPDATE mimix_obj_req
+SET team_id = $2,
+    team_approval = $3,
+    team_approval_by = NULL,
+    team_approval_at = NULL,
+    team_approval_note = NULL,
+    updated_at = NOW(),
+    version = version + 1
+WHERE id = $1
+RETURNING *;
+
+-- name: SetObjReqTeamApproval :one
+UPDATE mimix_obj_req
+SET team_approval = $2,
+    team_approval_by = $3,
+    team_approval_at = NOW(),
+    team_approval_note = $4,
+    updated_at = NOW(),
+    version = version + 1
+WHERE id = $1 AND team_approval = 'awaiting' AND deleted_at IS NULL
+RETURNING *;
+
+-- name: ListAwaitingTeamApproval :many
+SELECT r.*
+FROM mimix_obj_req r
+JOIN team_member m ON m.team_id = r.team_id
+WHERE m.user_id = $1 AND m.is_lead AND r.team_approval = 'awaiting' AND r.deleted_at IS NULL
+ORDER BY r.priority DESC, r.created_at;
+
+-- name: GetDeletedMimixObjReqByID :one
+SELECT *
+FROM mimix_obj_req
+WHERE id = $1 AND deleted_at IS NOT NULL;
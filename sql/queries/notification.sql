@@ -0,0 +1,21 @@
+-- name: CreateNotification :execrows
+INSERT INTO notification (recipient, kind, message, obj_id, obj_req_id, dedupe_key, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW())
+ON CONFLICT (dedupe_key) DO NOTHING;
+
+-- name: ListNotifications :many
+SELECT *
+FROM notification
+WHERE recipient = sqlc.arg(recipient) AND (NOT sqlc.arg(unread_only)::bool OR read_at IS NULL)
+ORDER BY created_at DESC
+LIMIT 200;
+
+-- name: MarkNotificationRead :execrows
+UPDATE notification
+SET read_at = NOW()
+WHERE id = $1 AND recipient = $2 AND read_at IS NULL;
+
+-- name: MarkAllNotificationsRead :execrows
+UPDATE notification
+SET read_at = NOW()
+WHERE recipient = $1 AND read_at IS NULL;
@@ -0,0 +1,59 @@
+-- name: CreateObjDependency :one
+INSERT INTO object_dependency (lib, obj, obj_type, dep_lib, dep_obj, dep_type, source, created_by, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+RETURNING *;
+
+-- name: ImportObjDependency :execrows
+INSERT INTO object_dependency (lib, obj, obj_type, dep_lib, dep_obj, dep_type, source, created_by, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+ON CONFLICT (lib, obj, obj_type, dep_lib, dep_obj, dep_type) DO NOTHING;
+
+-- name: CheckObjDependencyExists :one
+SELECT EXISTS (
+    SELECT 1
+    FROM object_dependency
+    WHERE lib = $1 AND obj = $2 AND obj_type = $3 AND dep_lib = $4 AND dep_obj = $5 AND dep_type = $6
+);
+
+-- name: ListObjDependencies :many
+SELECT *
+FROM object_dependency
+WHERE lib = $1 AND obj = $2 AND obj_type = $3
+ORDER BY dep_lib, dep_obj, dep_type;
+
+-- name: ListObjDependents :many
+SELECT *
+FROM object_dependency
+WHERE dep_lib = $1 AND dep_obj = $2 AND dep_type = $3
+ORDER BY lib, obj, obj_type;
+
+-- name: DeleteObjDependency :execrows
+DELETE FROM object_dependency
+WHERE id = $1;
+
+-- name: GetObjDependencyByID :one
+SELECT *
+FROM object_dependency
+WHERE id = $1;
+
+-- name: ListDependencyClosure :many
+WITH RECURSIVE reach (lib, obj, obj_type, depth) AS (
+    SELECT d.dep_lib, d.dep_obj, d.dep_type, 1
+    FROM object_dependency d
+    WHERE d.lib = sqlc.arg(lib) AND d.obj = sqlc.arg(obj) AND d.obj_type = sqlc.arg(obj_type)
+      AND NOT (d.dep_lib = ANY(sqlc.arg(hidden_libs)::text[]))
+    UNION
+    SELECT d.dep_lib, d.dep_obj, d.dep_type, r.depth + 1
+    FROM reach r
+    JOIN object_dependency d ON d.lib = r.lib AND d.obj = r.obj AND d.obj_type = r.obj_type
+    WHERE r.depth < sqlc.arg(max_depth)::int
+      AND NOT (d.dep_lib = ANY(sqlc.arg(hidden_libs)::text[]))
+)
+SELECT r.lib, r.obj, r.obj_type, MIN(r.depth)::int AS depth, o.mimix_status
+FROM reach r
+LEFT JOIN mimix_obj o
+  ON o.lib = r.lib AND o.obj = r.obj AND o.obj_type = r.obj_type AND o.deleted_at IS NULL
+WHERE NOT (r.lib = sqlc.arg(lib) AND r.obj = sqlc.arg(obj) AND r.obj_type = sqlc.arg(obj_type))
+GROUP BY r.lib, r.obj, r.obj_type, o.mimix_status
+ORDER BY depth, r.lib, r.obj, r.obj_type
+LIMIT sqlc.arg(max_nodes)::int;
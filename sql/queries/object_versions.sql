@@ -0,0 +1,9 @@
+-- name: CreateObjectVersion :exec
+INSERT INTO object_versions (obj_id, obj_ver, promote_date, developer, obj_req_id, source, changed_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: ListObjectVersions :many
+SELECT *
+FROM object_versions
+WHERE obj_id = $1
+ORDER BY created_at, id;
@@ -0,0 +1,67 @@
+-- name: ListPromotionStages :many
+SELECT *
+FROM promotion_stage
+ORDER BY position;
+
+-- name: GetPromotionStageByID :one
+SELECT *
+FROM promotion_stage
+WHERE id = $1;
+
+-- name: CreatePromotionStage :one
+INSERT INTO promotion_stage (name, position, is_production, created_at)
+VALUES ($1, $2, $3, NOW())
+RETURNING *;
+
+-- name: UpdatePromotionStage :one
+UPDATE promotion_stage
+SET name = $2, position = $3, is_production = $4
+WHERE id = $1
+RETURNING *;
+
+-- name: CheckPromotionStageInUse :one
+SELECT EXISTS (SELECT 1 FROM mimix_obj WHERE stage_id = $1)
+    OR EXISTS (SELECT 1 FROM mimix_obj_req WHERE stage_id = $1)
+    OR EXISTS (SELECT 1 FROM promotion_history WHERE from_stage_id = $1 OR to_stage_id = $1) AS in_use;
+
+-- name: DeletePromotionStage :execrows
+DELETE FROM promotion_stage
+WHERE id = $1;
+
+-- name: SetObjStage :one
+UPDATE mimix_obj
+SET stage_id = $2, updated_at = NOW(), version = version + 1
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: SetObjReqStage :one
+UPDATE mimix_obj_req
+SET stage_id = $2, promote_status = $3, updated_at = NOW(), version = version + 1
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: CreatePromotion :one
+INSERT INTO promotion_history (obj_id, obj_req_id, from_stage_id, to_stage_id, actor, note, promoted_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW())
+RETURNING *;
+
+-- name: ListObjPromotions :many
+SELECT h.*, f.name AS from_stage, t.name AS to_stage
+FROM promotion_history AS h
+LEFT JOIN promotion_stage AS f ON f.id = h.from_stage_id
+JOIN promotion_stage AS t ON t.id = h.to_stage_id
+WHERE h.obj_id = $1
+ORDER BY h.promoted_at, h.id;
+
+-- name: ListObjReqPromotions :many
+SELECT h.*, f.name AS from_stage, t.name AS to_stage
+FROM promotion_history AS h
+LEFT JOIN promotion_stage AS f ON f.id = h.from_stage_id
+JOIN promotion_stage AS t ON t.id = h.to_stage_id
+WHERE h.obj_req_id = $1
+ORDER BY h.promoted_at, h.id;
+
+-- name: GetProductionStage :one
+SELECT *
+FROM promotion_stage
+WHERE is_production;
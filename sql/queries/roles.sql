@@ -0,0 +1,77 @@
+-- name: CreateRole :one
+INSERT INTO roles (name, description)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: GetRoleByName :one
+SELECT *
+FROM roles
+WHERE LOWER(name) = LOWER($1);
+
+-- name: ListRoles :many
+SELECT *
+FROM roles
+ORDER BY builtin DESC, name;
+
+-- name: UpdateRoleDescription :exec
+UPDATE roles
+SET description = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: DeleteRole :execrows
+DELETE FROM roles
+WHERE id = $1 AND NOT builtin;
+
+-- name: CountRoleUsers :one
+SELECT COUNT(*)
+FROM user_roles
+WHERE role_id = $1;
+
+-- name: ListRolePermissions :many
+SELECT role_id, permission
+FROM role_permissions
+ORDER BY permission;
+
+-- name: AddRolePermission :exec
+INSERT INTO role_permissions (role_id, permission)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: ClearRolePermissions :exec
+DELETE FROM role_permissions
+WHERE role_id = $1;
+
+-- name: ListUserRoleNames :many
+SELECT r.name
+FROM user_roles ur
+JOIN roles r ON r.id = ur.role_id
+WHERE ur.user_id = $1
+ORDER BY r.name;
+
+-- name: AddUserRole :exec
+INSERT INTO user_roles (user_id, role_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveUserRole :exec
+DELETE FROM user_roles
+WHERE user_id = $1 AND role_id = $2;
+
+-- name: ClearUserRoles :exec
+DELETE FROM user_roles
+WHERE user_id = $1;
+
+-- name: ListUserPermissions :many
+SELECT DISTINCT rp.permission
+FROM user_roles ur
+JOIN role_permissions rp ON rp.role_id = ur.role_id
+WHERE ur.user_id = $1
+ORDER BY rp.permission;
+
+-- name: AddJobRole :exec
+INSERT INTO user_roles (user_id, role_id)
+SELECT u.id, r.id
+FROM users u
+JOIN roles r ON r.name = u.job::text AND r.builtin
+WHERE u.username = $1
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,52 @@
+-- name: ListSLAPolicies :many
+SELECT *
+FROM sla_policy
+ORDER BY name;
+
+-- name: GetSLAPolicyByID :one
+SELECT *
+FROM sla_policy
+WHERE id = $1;
+
+-- name: CheckSLAPolicyScopeExists :one
+SELECT EXISTS (
+    SELECT 1
+    FROM sla_policy
+    WHERE priority IS NOT DISTINCT FROM $1 AND lib_pattern = $2 AND id <> $3
+);
+
+-- name: CreateSLAPolicy :one
+INSERT INTO sla_policy (name, priority, lib_pattern, target_hours, warn_percent, escalate_to, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: UpdateSLAPolicy :one
+UPDATE sla_policy
+SET name = $2,
+    priority = $3,
+    lib_pattern = $4,
+    target_hours = $5,
+    warn_percent = $6,
+    escalate_to = $7
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteSLAPolicy :execrows
+DELETE FROM sla_policy
+WHERE id = $1;
+
+-- name: ListSLAHolidays :many
+SELECT *
+FROM sla_holiday
+ORDER BY holiday_date;
+
+-- name: UpsertSLAHoliday :one
+INSERT INTO sla_holiday (holiday_date, name, created_by)
+VALUES ($1, $2, $3)
+ON CONFLICT (holiday_date) DO UPDATE
+SET name = EXCLUDED.name
+RETURNING *;
+
+-- name: DeleteSLAHoliday :execrows
+DELETE FROM sla_holiday
+WHERE holiday_date = $1;
@@ -0,0 +1,92 @@
+-- name: CreateTeam :one
+INSERT INTO team (name, description)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: GetTeamByID :one
+SELECT *
+FROM team
+WHERE id = $1;
+
+-- name: GetTeamByName :one
+SELECT *
+FROM team
+WHERE LOWER(name) = LOWER($1);
+
+-- name: ListTeams :many
+SELECT *
+FROM team
+ORDER BY name;
+
+-- name: DeleteTeam :execrows
+DELETE FROM team
+WHERE id = $1;
+
+-- name: UpsertTeamMember :exec
+INSERT INTO team_member (team_id, user_id, is_lead)
+VALUES ($1, $2, $3)
+ON CONFLICT (team_id, user_id) DO UPDATE SET is_lead = EXCLUDED.is_lead;
+
+-- name: RemoveTeamMember :execrows
+DELETE FROM team_member
+WHERE team_id = $1 AND user_id = $2;
+
+-- name: ListTeamMembers :many
+SELECT u.id, u.username, u.job, m.is_lead, m.added_at
+FROM team_member m
+JOIN users u ON u.id = m.user_id
+WHERE m.team_id = $1
+ORDER BY m.is_lead DESC, u.username;
+
+-- name: ListTeamMemberNames :many
+SELECT u.username
+FROM team_member m
+JOIN team t ON t.id = m.team_id
+JOIN users u ON u.id = m.user_id
+WHERE LOWER(t.name) = LOWER($1)
+ORDER BY u.username;
+
+-- name: ListTeamLeadNames :many
+SELECT u.username
+FROM team_member m
+JOIN users u ON u.id = m.user_id
+WHERE m.team_id = $1 AND m.is_lead
+ORDER BY u.username;
+
+-- name: IsTeamLead :one
+SELECT EXISTS(SELECT 1 FROM team_member WHERE team_id = $1 AND user_id = $2 AND is_lead);
+
+-- name: ListTeamLibs :many
+SELECT lib
+FROM mimix_lib
+WHERE team_id = $1
+ORDER BY lib;
+
+-- name: GetLibTeam :one
+SELECT team_id
+FROM mimix_lib
+WHERE lib = $1;
+
+-- name: SetLibTeam :exec
+UPDATE mimix_lib
+SET team_id = $2
+WHERE id = $1;
+
+-- name: CountTeamObjsByStatus :many
+SELECT o.mimix_status, COUNT(*) AS count
+FROM mimix_obj o
+JOIN mimix_lib l ON l.id = o.lib_id
+WHERE l.team_id = $1 AND o.deleted_at IS NULL
+GROUP BY o.mimix_status;
+
+-- name: CountTeamObjReqsByStatus :many
+SELECT r.req_status, COUNT(*) AS count
+FROM mimix_obj_req r
+JOIN mimix_lib l ON l.lib = r.lib
+WHERE l.team_id = $1 AND r.deleted_at IS NULL
+GROUP BY r.req_status;
+
+-- name: CountTeamAwaitingApproval :one
+SELECT COUNT(*)
+FROM mimix_obj_req
+WHERE team_id = $1 AND team_approval = 'awaiting' AND deleted_at IS NULL;
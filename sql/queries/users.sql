@@ -14,9 +14,72 @@ FROM users
 WHERE id = $1;
 
 -- name: GetUserByUsername :one
-SELECT id, username, job, hashed_password, created_at, updated_at
+SELECT id, username, job, hashed_password, created_at, updated_at, directory
 FROM users
 WHERE LOWER(username) = LOWER($1);
 
 -- name: CheckUserExists :one
-SELECT EXISTS(SELECT 1 FROM users WHERE LOWER(username) = LOWER($1));
\ No newline at end of file
+SELECT EXISTS(SELECT 1 FROM users WHERE LOWER(username) = LOWER($1));
+
+-- name: ListUsernamesWithPermission :many
+SELECT DISTINCT u.username
+FROM users u
+JOIN user_roles ur ON ur.user_id = u.id
+JOIN role_permissions rp ON rp.role_id = ur.role_id
+WHERE rp.permission = $1
+ORDER BY u.username;
+
+-- name: ListUsernamesWithRole :many
+SELECT u.username
+FROM users u
+JOIN user_roles ur ON ur.user_id = u.id
+JOIN roles r ON r.id = ur.role_id
+WHERE LOWER(r.name) = LOWER($1)
+ORDER BY u.username;
+
+-- name: SetUserJob :exec
+UPDATE users
+SET job = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: CheckPermissionHeld :one
+SELECT EXISTS(
+    SELECT 1
+    FROM user_roles ur
+    JOIN role_permissions rp ON rp.role_id = ur.role_id
+    WHERE rp.permission = $1
+);
+
+-- name: LockUsersWithPermission :many
+SELECT u.id
+FROM users u
+WHERE EXISTS (
+    SELECT 1
+    FROM user_roles ur
+    JOIN role_permissions rp ON rp.role_id = ur.role_id
+    WHERE ur.user_id = u.id AND rp.permission = $1
+)
+ORDER BY u.id
+FOR UPDATE;
+
+-- name: ListDCWorkload :many
+SELECT u.username, COUNT(r.id) AS pending
+FROM users u
+LEFT JOIN mimix_obj_req r
+  ON r.assignee = u.username AND r.req_status = 'pending' AND r.deleted_at IS NULL
+WHERE EXISTS (
+    SELECT 1
+    FROM user_roles ur
+    JOIN role_permissions rp ON rp.role_id = ur.role_id
+    WHERE ur.user_id = u.id AND rp.permission = 'req.work'
+)
+GROUP BY u.username
+ORDER BY pending, u.username;
+
+-- name: SetUserDirectory :exec
+UPDATE users
+SET directory = $2,
+    -- the directory holds the password of a directory user
+    hashed_password = CASE WHEN $2 THEN '' ELSE hashed_password END,
+    updated_at = NOW()
+WHERE id = $1;
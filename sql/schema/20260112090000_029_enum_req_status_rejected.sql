@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TYPE req_status ADD VALUE IF NOT EXISTS 'rejected';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- 1. Move rejected rows back to pending
+UPDATE mimix_obj_req
+SET req_status = 'pending'
+WHERE req_status = 'rejected';
+
+-- 2. Recreate enum without 'rejected'
+ALTER TYPE req_status RENAME TO req_status_old;
+
+CREATE TYPE req_status AS ENUM (
+    'pending',
+    'completed'
+);
+
+ALTER TABLE mimix_obj_req
+ALTER COLUMN req_status DROP DEFAULT;
+
+ALTER TABLE mimix_obj_req
+ALTER COLUMN req_status
+TYPE req_status
+USING req_status::text::req_status;
+
+ALTER TABLE mimix_obj_req
+ALTER COLUMN req_status SET DEFAULT 'pending';
+
+DROP TYPE req_status_old;
+
+-- +goose StatementEnd
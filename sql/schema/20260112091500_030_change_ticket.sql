@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE change_ticket (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    ref_no TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    owner TEXT NOT NULL,
+    window_start TIMESTAMP NULL,
+    window_end TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+ALTER TABLE mimix_obj_req
+ADD COLUMN ticket_id UUID NULL REFERENCES change_ticket(id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE mimix_obj_req DROP COLUMN ticket_id;
+
+DROP TABLE change_ticket;
+-- +goose StatementEnd
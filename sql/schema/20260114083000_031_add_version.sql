@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE mimix_obj
+ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE mimix_obj_req
+ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE mimix_obj DROP COLUMN version;
+
+ALTER TABLE mimix_obj_req DROP COLUMN version;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE mimix_obj
+ADD COLUMN deleted_at TIMESTAMP NULL,
+ADD COLUMN deleted_by TEXT NULL;
+ALTER TABLE mimix_obj_req
+ADD COLUMN deleted_at TIMESTAMP NULL,
+ADD COLUMN deleted_by TEXT NULL;
+
+CREATE INDEX mimix_obj_deleted_at_idx ON mimix_obj (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX mimix_obj_req_deleted_at_idx ON mimix_obj_req (deleted_at) WHERE deleted_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX mimix_obj_req_deleted_at_idx;
+DROP INDEX mimix_obj_deleted_at_idx;
+ALTER TABLE mimix_obj_req DROP COLUMN deleted_by, DROP COLUMN deleted_at;
+ALTER TABLE mimix_obj DROP COLUMN deleted_by, DROP COLUMN deleted_at;
+-- +goose StatementEnd
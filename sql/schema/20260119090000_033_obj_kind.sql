@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE obj_kind AS ENUM ('lib', 'ifs', 'dlo');
+CREATE TYPE prc_type AS ENUM ('include', 'exclude');
+
+ALTER TABLE mimix_obj
+ADD COLUMN obj_kind obj_kind NOT NULL DEFAULT 'lib',
+ADD COLUMN prc_type prc_type NOT NULL DEFAULT 'include',
+ADD COLUMN subtree BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE mimix_obj_req
+ADD COLUMN obj_kind obj_kind NOT NULL DEFAULT 'lib',
+ADD COLUMN prc_type prc_type NOT NULL DEFAULT 'include',
+ADD COLUMN subtree BOOLEAN NOT NULL DEFAULT false;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE mimix_obj_req DROP COLUMN subtree, DROP COLUMN prc_type, DROP COLUMN obj_kind;
+ALTER TABLE mimix_obj DROP COLUMN subtree, DROP COLUMN prc_type, DROP COLUMN obj_kind;
+
+DROP TYPE prc_type;
+DROP TYPE obj_kind;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE coverage_rule (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    obj_kind obj_kind NOT NULL DEFAULT 'lib',
+    lib_pattern TEXT NOT NULL DEFAULT '*ALL',
+    obj_pattern TEXT NOT NULL DEFAULT '*ALL',
+    obj_type TEXT NOT NULL DEFAULT '*ALL',
+    prc_type prc_type NOT NULL DEFAULT 'include',
+    mimix_status mimix_status NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (obj_kind, lib_pattern, obj_pattern, obj_type)
+);
+
+ALTER TABLE mimix_obj
+ADD COLUMN rule_id UUID REFERENCES coverage_rule(id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE mimix_obj DROP COLUMN rule_id;
+DROP TABLE coverage_rule;
+-- +goose StatementEnd
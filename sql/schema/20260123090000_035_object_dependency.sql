@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE dep_source AS ENUM ('manual', 'dsppgmref', 'dspdbr');
+
+-- lib/obj/obj_type uses dep_lib/dep_obj/dep_type. Both ends are names rather
+-- than mimix_obj ids so a dependency can be known before it is registered.
+CREATE TABLE object_dependency (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    lib TEXT NOT NULL,
+    obj TEXT NOT NULL,
+    obj_type TEXT NOT NULL,
+    dep_lib TEXT NOT NULL,
+    dep_obj TEXT NOT NULL,
+    dep_type TEXT NOT NULL,
+    source dep_source NOT NULL DEFAULT 'manual',
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (lib, obj, obj_type, dep_lib, dep_obj, dep_type)
+);
+
+CREATE INDEX object_dependency_dep_idx ON object_dependency (dep_lib, dep_obj, dep_type);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE object_dependency;
+DROP TYPE dep_source;
+-- +goose StatementEnd
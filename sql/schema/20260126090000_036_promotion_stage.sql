@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE promotion_stage (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL UNIQUE,
+    position INTEGER NOT NULL UNIQUE,
+    is_production BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- only one stage is production
+CREATE UNIQUE INDEX promotion_stage_production_idx ON promotion_stage (is_production) WHERE is_production;
+
+INSERT INTO promotion_stage (name, position, is_production) VALUES
+    ('DEV', 1, false),
+    ('QA', 2, false),
+    ('UAT', 3, false),
+    ('PROD', 4, true);
+
+ALTER TABLE mimix_obj
+ADD COLUMN stage_id UUID REFERENCES promotion_stage(id);
+ALTER TABLE mimix_obj_req
+ADD COLUMN stage_id UUID REFERENCES promotion_stage(id);
+
+-- requests that were already deployed went to production
+UPDATE mimix_obj_req
+SET stage_id = (SELECT id FROM promotion_stage WHERE is_production)
+WHERE promote_status = 'deployed';
+
+CREATE TABLE promotion_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    obj_id UUID REFERENCES mimix_obj(id) ON DELETE CASCADE,
+    obj_req_id UUID REFERENCES mimix_obj_req(id) ON DELETE CASCADE,
+    from_stage_id UUID REFERENCES promotion_stage(id),
+    to_stage_id UUID NOT NULL REFERENCES promotion_stage(id),
+    actor TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    promoted_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK ((obj_id IS NULL) <> (obj_req_id IS NULL))
+);
+
+CREATE INDEX promotion_history_obj_idx ON promotion_history (obj_id);
+CREATE INDEX promotion_history_obj_req_idx ON promotion_history (obj_req_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE promotion_history;
+ALTER TABLE mimix_obj_req DROP COLUMN stage_id;
+ALTER TABLE mimix_obj DROP COLUMN stage_id;
+DROP TABLE promotion_stage;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE deployment_event_status AS ENUM ('matched', 'unmatched', 'ambiguous', 'resolved', 'dismissed');
+
+CREATE TABLE deployment_event (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id TEXT NOT NULL UNIQUE,
+    obj_kind obj_kind NOT NULL DEFAULT 'lib',
+    lib TEXT NOT NULL,
+    obj TEXT NOT NULL,
+    obj_type TEXT NOT NULL,
+    obj_ver TEXT NOT NULL,
+    deployed_at TIMESTAMP NOT NULL,
+    payload TEXT NOT NULL,
+    status deployment_event_status NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    obj_req_id UUID REFERENCES mimix_obj_req(id) ON DELETE SET NULL,
+    received_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    resolved_by TEXT,
+    resolved_at TIMESTAMP
+);
+
+CREATE INDEX deployment_event_review_idx ON deployment_event (received_at)
+WHERE status IN ('unmatched', 'ambiguous');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE deployment_event;
+DROP TYPE deployment_event_status;
+-- +goose StatementEnd
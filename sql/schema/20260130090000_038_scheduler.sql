@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE job_run_status AS ENUM ('running', 'succeeded', 'failed');
+
+CREATE TABLE job_run (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    job_name TEXT NOT NULL,
+    scheduled_for TIMESTAMP NOT NULL,
+    runner TEXT NOT NULL,
+    status job_run_status NOT NULL DEFAULT 'running',
+    summary TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP,
+    -- a scheduled run is claimed once even if two replicas try it
+    UNIQUE (job_name, scheduled_for)
+);
+
+CREATE INDEX job_run_job_idx ON job_run (job_name, started_at DESC);
+
+CREATE TABLE notification (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    recipient TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    message TEXT NOT NULL,
+    obj_id UUID REFERENCES mimix_obj(id) ON DELETE CASCADE,
+    obj_req_id UUID REFERENCES mimix_obj_req(id) ON DELETE CASCADE,
+    -- the same reminder is only sent once
+    dedupe_key TEXT UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    read_at TIMESTAMP
+);
+
+CREATE INDEX notification_recipient_idx ON notification (recipient, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE notification;
+DROP TABLE job_run;
+DROP TYPE job_run_status;
+-- +goose StatementEnd
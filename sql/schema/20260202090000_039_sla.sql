@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE req_priority AS ENUM (
+    'low',
+    'normal',
+    'high',
+    'emergency'
+);
+
+-- a policy applies to requests of its priority (NULL: any priority) in
+-- libraries matching lib_pattern, like a data group entry
+CREATE TABLE sla_policy (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL UNIQUE,
+    priority req_priority,
+    lib_pattern TEXT NOT NULL DEFAULT '*ALL',
+    target_hours INTEGER NOT NULL CHECK (target_hours > 0),
+    warn_percent INTEGER NOT NULL DEFAULT 80 CHECK (warn_percent BETWEEN 1 AND 99),
+    escalate_to TEXT NOT NULL DEFAULT 'dc',
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE sla_holiday (
+    holiday_date DATE PRIMARY KEY,
+    name TEXT NOT NULL DEFAULT '',
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE mimix_obj_req
+ADD COLUMN sla_policy_id UUID REFERENCES sla_policy(id) ON DELETE SET NULL,
+ADD COLUMN due_at TIMESTAMP,
+ADD COLUMN resolved_at TIMESTAMP,
+ADD COLUMN priority req_priority NOT NULL DEFAULT 'normal';
+
+-- best guess for requests handled before resolved_at existed
+UPDATE mimix_obj_req
+SET resolved_at = updated_at
+WHERE req_status <> 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE mimix_obj_req
+DROP COLUMN IF EXISTS priority,
+DROP COLUMN IF EXISTS resolved_at,
+DROP COLUMN IF EXISTS due_at,
+DROP COLUMN IF EXISTS sla_policy_id;
+
+DROP TABLE IF EXISTS sla_holiday;
+DROP TABLE IF EXISTS sla_policy;
+DROP TYPE IF EXISTS req_priority;
+-- +goose StatementEnd
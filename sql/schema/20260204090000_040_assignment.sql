@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- leads can reassign requests between DC operators
+ALTER TABLE users
+ADD COLUMN is_lead BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE mimix_obj_req
+ADD COLUMN assignee TEXT,
+ADD COLUMN assigned_by TEXT,
+ADD COLUMN assigned_at TIMESTAMP;
+
+CREATE INDEX mimix_obj_req_assignee_idx ON mimix_obj_req (assignee)
+WHERE req_status = 'pending' AND deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS mimix_obj_req_assignee_idx;
+
+ALTER TABLE mimix_obj_req
+DROP COLUMN IF EXISTS assigned_at,
+DROP COLUMN IF EXISTS assigned_by,
+DROP COLUMN IF EXISTS assignee;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS is_lead;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE emergency_review_status AS ENUM (
+    'awaiting_justification',
+    'awaiting_review',
+    'accepted',
+    'rejected'
+);
+
+-- an emergency request promoted past stages must be justified afterwards
+-- by whoever promoted it, and the justification reviewed by someone else
+CREATE TABLE emergency_review (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    obj_req_id UUID NOT NULL REFERENCES mimix_obj_req(id) ON DELETE CASCADE,
+    promotion_id UUID NOT NULL REFERENCES promotion_history(id) ON DELETE CASCADE,
+    skipped_stages TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    status emergency_review_status NOT NULL DEFAULT 'awaiting_justification',
+    justification TEXT NOT NULL DEFAULT '',
+    justified_at TIMESTAMP,
+    reviewer TEXT,
+    review_note TEXT NOT NULL DEFAULT '',
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX emergency_review_status_idx ON emergency_review (status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS emergency_review;
+DROP TYPE IF EXISTS emergency_review_status;
+-- +goose StatementEnd
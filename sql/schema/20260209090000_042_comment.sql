@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE comment_change AS ENUM ('edited', 'deleted');
+
+CREATE TABLE comment (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    obj_id UUID REFERENCES mimix_obj(id) ON DELETE CASCADE,
+    obj_req_id UUID REFERENCES mimix_obj_req(id) ON DELETE CASCADE,
+    parent_id UUID REFERENCES comment(id) ON DELETE CASCADE,
+    author TEXT NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    edited_at TIMESTAMP,
+    deleted_at TIMESTAMP,
+    deleted_by TEXT,
+    -- a comment belongs to exactly one obj or obj request
+    CHECK ((obj_id IS NULL) <> (obj_req_id IS NULL))
+);
+
+CREATE INDEX comment_obj_idx ON comment (obj_id, created_at);
+CREATE INDEX comment_obj_req_idx ON comment (obj_req_id, created_at);
+
+-- the body a comment had before each edit or deletion
+CREATE TABLE comment_revision (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    comment_id UUID NOT NULL REFERENCES comment(id) ON DELETE CASCADE,
+    change comment_change NOT NULL,
+    body TEXT NOT NULL,
+    changed_by TEXT NOT NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX comment_revision_comment_idx ON comment_revision (comment_id, changed_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS comment_revision;
+DROP TABLE IF EXISTS comment;
+DROP TYPE IF EXISTS comment_change;
+-- +goose StatementEnd
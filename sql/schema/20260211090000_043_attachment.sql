@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE attachment (
+    id UUID PRIMARY KEY,
+    -- purging the owner only unlinks the attachment; the sweep job then
+    -- removes the stored file and the row
+    obj_id UUID REFERENCES mimix_obj(id) ON DELETE SET NULL,
+    obj_req_id UUID REFERENCES mimix_obj_req(id) ON DELETE SET NULL,
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    sha256 TEXT NOT NULL,
+    storage_key TEXT NOT NULL UNIQUE,
+    uploaded_by TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (obj_id IS NULL OR obj_req_id IS NULL)
+);
+
+CREATE INDEX attachment_obj_idx ON attachment (obj_id);
+CREATE INDEX attachment_obj_req_idx ON attachment (obj_req_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS attachment;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE object_version_source AS ENUM ('initial', 'created', 'updated', 'converted');
+
+-- every obj_ver an obj has had, appended whenever it changes
+CREATE TABLE object_versions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    obj_id UUID NOT NULL REFERENCES mimix_obj(id) ON DELETE CASCADE,
+    obj_ver TEXT NOT NULL,
+    promote_date TIMESTAMP,
+    developer TEXT NOT NULL,
+    -- the request that caused the change, if any
+    obj_req_id UUID REFERENCES mimix_obj_req(id) ON DELETE SET NULL,
+    source object_version_source NOT NULL,
+    changed_by TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX object_versions_obj_idx ON object_versions (obj_id, created_at);
+
+-- the timeline starts with what each obj holds today
+INSERT INTO object_versions (obj_id, obj_ver, promote_date, developer, source, created_at)
+SELECT id, obj_ver, promote_date, developer, 'initial', updated_at
+FROM mimix_obj;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS object_versions;
+DROP TYPE IF EXISTS object_version_source;
+-- +goose StatementEnd
@@ -0,0 +1,127 @@
+-- +goose Up
+-- +goose StatementBegin
+-- developer stays as the display name; developer_id is the account behind it
+ALTER TABLE mimix_obj
+ADD COLUMN developer_id UUID REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE mimix_obj_req
+ADD COLUMN developer_id UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX mimix_obj_developer_idx ON mimix_obj (developer_id);
+CREATE INDEX mimix_obj_req_developer_idx ON mimix_obj_req (developer_id);
+
+CREATE TYPE developer_match_method AS ENUM ('exact', 'normalized', 'fuzzy', 'manual', 'unmatched');
+
+-- how each free-text developer found before this migration was matched to
+-- an account; the 'unmatched' rows are left for review
+CREATE TABLE developer_match (
+    developer TEXT PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    method developer_match_method NOT NULL,
+    -- edit distance between the normalized names, for fuzzy matches
+    distance INT,
+    obj_count INT NOT NULL DEFAULT 0,
+    req_count INT NOT NULL DEFAULT 0,
+    resolved_by TEXT,
+    resolved_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- the name with case, an email domain and punctuation stripped
+CREATE FUNCTION developer_match_key(name TEXT) RETURNS TEXT AS $$
+    SELECT regexp_replace(split_part(LOWER(TRIM(name)), '@', 1), '[^a-z0-9]', '', 'g');
+$$ LANGUAGE sql IMMUTABLE;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION developer_match_distance(a TEXT, b TEXT) RETURNS INT AS $$
+DECLARE
+    prev INT[];
+    cur INT[];
+BEGIN
+    IF a = b THEN
+        RETURN 0;
+    END IF;
+    prev := ARRAY(SELECT generate_series(0, length(b)));
+    FOR i IN 1..length(a) LOOP
+        cur := ARRAY[i];
+        FOR j IN 1..length(b) LOOP
+            cur := cur || LEAST(
+                prev[j + 1] + 1,
+                cur[j] + 1,
+                prev[j] + CASE WHEN substr(a, i, 1) = substr(b, j, 1) THEN 0 ELSE 1 END
+            );
+        END LOOP;
+        prev := cur;
+    END LOOP;
+    RETURN prev[length(b) + 1];
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO developer_match (developer, method, obj_count, req_count)
+SELECT developer, 'unmatched', SUM(objs), SUM(reqs)
+FROM (
+    SELECT LOWER(TRIM(developer)) AS developer, 1 AS objs, 0 AS reqs FROM mimix_obj
+    UNION ALL
+    SELECT LOWER(TRIM(developer)), 0, 1 FROM mimix_obj_req WHERE developer IS NOT NULL
+) AS found
+WHERE developer <> ''
+GROUP BY developer;
+
+UPDATE developer_match m
+SET user_id = u.id, method = 'exact', distance = 0
+FROM users u
+WHERE LOWER(u.username) = m.developer;
+
+-- the closest account wins when it is the only one at that distance and
+-- close enough for a typo: at most 2 edits, and at most a third of the name
+WITH candidates AS (
+    SELECT m.developer, u.id AS user_id,
+           developer_match_distance(developer_match_key(m.developer), developer_match_key(u.username)) AS distance
+    FROM developer_match m
+    CROSS JOIN users u
+    WHERE m.method = 'unmatched'
+), best AS (
+    SELECT c.developer, MIN(c.user_id::TEXT)::UUID AS user_id, c.distance
+    FROM candidates c
+    WHERE c.distance = (SELECT MIN(distance) FROM candidates o WHERE o.developer = c.developer)
+    GROUP BY c.developer, c.distance
+    HAVING COUNT(*) = 1
+)
+UPDATE developer_match m
+SET user_id = b.user_id,
+    method = CASE WHEN b.distance = 0 THEN 'normalized' ELSE 'fuzzy' END::developer_match_method,
+    distance = b.distance
+FROM best b
+WHERE b.developer = m.developer
+  AND b.distance <= LEAST(2, length(developer_match_key(m.developer)) / 3);
+
+UPDATE mimix_obj o
+SET developer_id = u.id, developer = LOWER(u.username)
+FROM developer_match m
+JOIN users u ON u.id = m.user_id
+WHERE m.developer = LOWER(TRIM(o.developer));
+
+UPDATE mimix_obj_req r
+SET developer_id = u.id, developer = LOWER(u.username)
+FROM developer_match m
+JOIN users u ON u.id = m.user_id
+WHERE m.developer = LOWER(TRIM(r.developer));
+
+DROP FUNCTION developer_match_distance(TEXT, TEXT);
+DROP FUNCTION developer_match_key(TEXT);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS developer_match;
+DROP TYPE IF EXISTS developer_match_method;
+DROP INDEX IF EXISTS mimix_obj_req_developer_idx;
+DROP INDEX IF EXISTS mimix_obj_developer_idx;
+ALTER TABLE mimix_obj_req DROP COLUMN IF EXISTS developer_id;
+ALTER TABLE mimix_obj DROP COLUMN IF EXISTS developer_id;
+-- +goose StatementEnd
@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE team (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX team_name_idx ON team (LOWER(name));
+
+CREATE TABLE team_member (
+    team_id UUID NOT NULL REFERENCES team(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    is_lead BOOLEAN NOT NULL DEFAULT FALSE,
+    added_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (team_id, user_id)
+);
+
+CREATE INDEX team_member_user_idx ON team_member (user_id);
+
+-- the team that owns a library approves the requests made against it
+ALTER TABLE mimix_lib
+ADD COLUMN team_id UUID REFERENCES team(id) ON DELETE SET NULL;
+
+CREATE TYPE team_approval AS ENUM ('awaiting', 'approved', 'rejected');
+
+ALTER TABLE mimix_obj_req
+ADD COLUMN team_id UUID REFERENCES team(id) ON DELETE SET NULL,
+ADD COLUMN team_approval team_approval,
+ADD COLUMN team_approval_by TEXT,
+ADD COLUMN team_approval_at TIMESTAMP,
+ADD COLUMN team_approval_note TEXT;
+
+CREATE INDEX mimix_obj_req_team_approval_idx ON mimix_obj_req (team_id) WHERE team_approval = 'awaiting';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS mimix_obj_req_team_approval_idx;
+ALTER TABLE mimix_obj_req
+DROP COLUMN IF EXISTS team_approval_note,
+DROP COLUMN IF EXISTS team_approval_at,
+DROP COLUMN IF EXISTS team_approval_by,
+DROP COLUMN IF EXISTS team_approval,
+DROP COLUMN IF EXISTS team_id;
+DROP TYPE IF EXISTS team_approval;
+ALTER TABLE mimix_lib DROP COLUMN IF EXISTS team_id;
+DROP TABLE IF EXISTS team_member;
+DROP TABLE IF EXISTS team;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE lib_permission AS ENUM ('read', 'request', 'manage');
+
+-- a library without grants stays open to everyone; once it has one, only
+-- the users granted access, directly or through a team, can see it
+CREATE TABLE lib_acl (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    lib_id UUID NOT NULL REFERENCES mimix_lib(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    team_id UUID REFERENCES team(id) ON DELETE CASCADE,
+    permission lib_permission NOT NULL,
+    granted_by TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK ((user_id IS NULL) <> (team_id IS NULL))
+);
+
+CREATE UNIQUE INDEX lib_acl_user_idx ON lib_acl (lib_id, user_id) WHERE user_id IS NOT NULL;
+CREATE UNIQUE INDEX lib_acl_team_idx ON lib_acl (lib_id, team_id) WHERE team_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS lib_acl;
+DROP TYPE IF EXISTS lib_permission;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- IFS and DLO entries sit in the *ifs and *dlo pseudo libraries, which are
+-- not libraries that can be owned by a team or restricted by an ACL
+ALTER TABLE mimix_obj ALTER COLUMN lib_id DROP NOT NULL;
+
+UPDATE mimix_obj
+SET lib_id = NULL
+WHERE obj_kind IN ('ifs', 'dlo');
+
+DELETE FROM mimix_lib
+WHERE lib IN ('*ifs', '*dlo');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+INSERT INTO mimix_lib (lib)
+SELECT DISTINCT lib
+FROM mimix_obj
+WHERE lib_id IS NULL
+ON CONFLICT DO NOTHING;
+
+UPDATE mimix_obj o
+SET lib_id = l.id
+FROM mimix_lib l
+WHERE o.lib_id IS NULL AND l.lib = o.lib;
+
+ALTER TABLE mimix_obj ALTER COLUMN lib_id SET NOT NULL;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- NOW() writes wall clock time in the session's zone, which need not be
+-- SLA_TIMEZONE; store the SLA instants with their offset instead. Due dates
+-- of pending requests are recalculated when the server starts.
+ALTER TABLE mimix_obj_req
+ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN due_at TYPE TIMESTAMPTZ USING due_at AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN resolved_at TYPE TIMESTAMPTZ USING resolved_at AT TIME ZONE current_setting('TimeZone');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE mimix_obj_req
+ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN due_at TYPE TIMESTAMP USING due_at AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN resolved_at TYPE TIMESTAMP USING resolved_at AT TIME ZONE current_setting('TimeZone');
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- a lead restricts a library explicitly; its grants only say who gets in,
+-- so revoking the last one leaves the library closed rather than open
+ALTER TABLE mimix_lib
+ADD COLUMN restricted BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE mimix_lib
+SET restricted = TRUE
+WHERE id IN (SELECT lib_id FROM lib_acl);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE mimix_lib
+DROP COLUMN IF EXISTS restricted;
+-- +goose StatementEnd
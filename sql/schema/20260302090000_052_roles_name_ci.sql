@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- role names are looked up case-insensitively, so keep them unique that way
+ALTER TABLE roles DROP CONSTRAINT roles_name_key;
+
+CREATE UNIQUE INDEX roles_name_lower_idx ON roles (LOWER(name));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS roles_name_lower_idx;
+
+ALTER TABLE roles ADD CONSTRAINT roles_name_key UNIQUE (name);
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- a directory login only signs in to accounts it provisioned or an admin
+-- linked to the directory, never to a local account of the same name
+ALTER TABLE users
+ADD COLUMN directory BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- directory users are the ones without a local password
+UPDATE users
+SET directory = TRUE
+WHERE hashed_password = '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+DROP COLUMN IF EXISTS directory;
+-- +goose StatementEnd
@@ -0,0 +1,113 @@
+-- +goose Up
+-- +goose StatementBegin
+-- handlers are gated on per-action permissions instead of the job.<job>
+-- ones, and leads are the users holding the builtin lead role
+CREATE TEMPORARY TABLE job_permissions (job TEXT NOT NULL, permission TEXT NOT NULL) ON COMMIT DROP;
+
+INSERT INTO job_permissions (job, permission) VALUES
+    ('cmt', 'obj.create'),
+    ('cmt', 'obj.edit'),
+    ('cmt', 'obj.status'),
+    ('cmt', 'obj.delete'),
+    ('cmt', 'obj.attach'),
+    ('cmt', 'req.create'),
+    ('cmt', 'req.delete'),
+    ('cmt', 'req.attach'),
+    ('cmt', 'dependency.edit'),
+    ('cmt', 'dependency.import'),
+    ('cmt', 'promote'),
+    ('cmt', 'stage.manage'),
+    ('cmt', 'deployment.review'),
+    ('cmt', 'emergency.review'),
+    ('cmt', 'coverage.preview'),
+    ('dev', 'obj.status'),
+    ('dev', 'req.create'),
+    ('dev', 'req.delete'),
+    ('dev', 'req.attach'),
+    ('dev', 'dependency.edit'),
+    ('dev', 'promote'),
+    ('dc', 'obj.status'),
+    ('dc', 'obj.delete'),
+    ('dc', 'obj.attach'),
+    ('dc', 'req.delete'),
+    ('dc', 'req.attach'),
+    ('dc', 'req.convert'),
+    ('dc', 'req.work'),
+    ('dc', 'dependency.edit'),
+    ('dc', 'dependency.import'),
+    ('dc', 'promote'),
+    ('dc', 'stage.manage'),
+    ('dc', 'deployment.review'),
+    ('dc', 'emergency.review'),
+    ('dc', 'coverage.manage'),
+    ('dc', 'coverage.preview'),
+    ('dc', 'sla.manage'),
+    ('dc', 'trash.purge'),
+    ('dc', 'comment.moderate'),
+    ('dc', 'reports.view'),
+    ('dc', 'scheduler.run'),
+    ('dc', 'admin.view');
+
+-- every role, custom ones included, keeps what its job permissions allowed
+INSERT INTO role_permissions (role_id, permission)
+SELECT rp.role_id, jp.permission
+FROM role_permissions rp
+JOIN job_permissions jp ON rp.permission = 'job.' || jp.job
+ON CONFLICT DO NOTHING;
+
+DELETE FROM role_permissions
+WHERE permission LIKE 'job.%';
+
+INSERT INTO roles (name, description, builtin)
+VALUES ('lead', 'administers users, roles and access', TRUE);
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.permission
+FROM roles r
+CROSS JOIN (VALUES
+    ('req.assign'),
+    ('users.manage'),
+    ('roles.manage'),
+    ('acl.manage'),
+    ('teams.manage')
+) AS p (permission)
+WHERE r.name = 'lead';
+
+INSERT INTO user_roles (user_id, role_id)
+SELECT u.id, r.id
+FROM users u
+JOIN roles r ON r.name = 'lead'
+WHERE u.is_lead;
+
+ALTER TABLE users
+DROP COLUMN is_lead;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+ADD COLUMN is_lead BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE users u
+SET is_lead = TRUE
+WHERE u.job = 'dc' AND EXISTS (
+    SELECT 1
+    FROM user_roles ur
+    JOIN roles r ON r.id = ur.role_id
+    WHERE ur.user_id = u.id AND r.name = 'lead'
+);
+
+DELETE FROM roles
+WHERE name = 'lead';
+
+-- the builtin roles get their job permission back; custom roles keep the
+-- action permissions, which nothing reads any more
+DELETE FROM role_permissions rp
+USING roles r
+WHERE r.id = rp.role_id AND r.builtin AND rp.permission NOT IN ('read', 'comment');
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, 'job.' || r.name
+FROM roles r
+WHERE r.builtin;
+-- +goose StatementEnd
@@ -0,0 +1,689 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/paul39-33/imimix/internal/database"
+)
+
+// notification kinds
+const (
+	notifyTeamApproval        = "team_approval"
+	notifyTeamApprovalOutcome = "team_approval_outcome"
+)
+
+// errTeamApproval is returned by convertObjReq for a request the owning
+// team has not approved.
+var errTeamApproval = errors.New("obj request is not approved by the owning team")
+
+// Team is an application team. Members and Libs are only filled in on the
+// team detail.
+type Team struct {
+	ID          uuid.UUID    `json:"id"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	CreatedAt   time.Time    `json:"created_at"`
+	Members     []TeamMember `json:"members,omitempty"`
+	Libs        []string     `json:"libs,omitempty"`
+}
+
+type TeamMember struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Job      string    `json:"job"`
+	IsLead   bool      `json:"is_lead"`
+	AddedAt  time.Time `json:"added_at"`
+}
+
+// TeamStats summarises the objs and requests in the libraries of a team.
+type TeamStats struct {
+	Team             Team         `json:"team"`
+	Libs             int          `json:"libs"`
+	Members          int          `json:"members"`
+	Objects          StatusCounts `json:"objects"`
+	Requests         StatusCounts `json:"requests"`
+	AwaitingApproval int64        `json:"awaiting_approval"`
+}
+
+func toTeam(t database.Team) Team {
+	return Team{
+		ID:          t.ID,
+		Name:        t.Name,
+		Description: t.Description,
+		CreatedAt:   t.CreatedAt,
+	}
+}
+
+// teamByRef looks a team up by id or name.
+func teamByRef(ctx context.Context, q *database.Queries, ref string) (database.Team, error) {
+	if id, err := uuid.Parse(ref); err == nil {
+		return q.GetTeamByID(ctx, id)
+	}
+	return q.GetTeamByName(ctx, ref)
+}
+
+// getTeamParam loads the team named by the :id path param, by id or name,
+// writing the error response itself when it cannot.
+func (cfg *apiConfig) getTeamParam(c *gin.Context) (database.Team, bool) {
+	team, err := teamByRef(c.Request.Context(), cfg.dbQueries, c.Param("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "team not found"})
+		return team, false
+	}
+	if err != nil {
+		log.Printf("error getting team: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get team"})
+		return team, false
+	}
+	return team, true
+}
+
+// canManageTeam reports whether user, holding perms, may change the members
+// of a team: those who manage teams for every team, and the team's own
+// leads.
+func canManageTeam(ctx context.Context, q *database.Queries, user database.GetUserByIDRow, perms permSet, teamID uuid.UUID) (bool, error) {
+	if perms[permTeamsManage] {
+		return true, nil
+	}
+	return q.IsTeamLead(ctx, database.IsTeamLeadParams{TeamID: teamID, UserID: user.ID})
+}
+
+// teamLibsParam reads the optional ?team= filter, by id or name, as the set
+// of libraries the team owns. A nil set means no filter.
+func (cfg *apiConfig) teamLibsParam(c *gin.Context) (map[string]bool, bool) {
+	ref := strings.TrimSpace(c.Query("team"))
+	if ref == "" {
+		return nil, true
+	}
+
+	team, err := teamByRef(c.Request.Context(), cfg.dbQueries, ref)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid team"})
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("error getting team: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get team"})
+		return nil, false
+	}
+
+	libs, err := cfg.dbQueries.ListTeamLibs(c.Request.Context(), uuid.NullUUID{UUID: team.ID, Valid: true})
+	if err != nil {
+		log.Printf("error listing team libs: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get team"})
+		return nil, false
+	}
+	set := make(map[string]bool, len(libs))
+	for _, lib := range libs {
+		set[lib] = true
+	}
+	return set, true
+}
+
+// routeToTeam hands a request to the team owning its library for approval,
+// and notifies the team's leads. It does nothing while the owner is
+// unchanged, so an approval survives edits that keep the library. It
+// returns the request as routed. Callers run it in the transaction that
+// creates or moves the request, so none escapes its team's approval.
+func routeToTeam(ctx context.Context, q *database.Queries, id uuid.UUID) (database.MimixObjReq, error) {
+	req, err := q.GetMimixObjReqByID(ctx, id)
+	if err != nil {
+		return req, err
+	}
+	teamID, err := q.GetLibTeam(ctx, req.Lib)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return req, err
+	}
+	if teamID == req.TeamID {
+		return req, nil
+	}
+
+	approval := database.NullTeamApproval{}
+	if teamID.Valid {
+		approval = database.NullTeamApproval{TeamApproval: database.TeamApprovalAwaiting, Valid: true}
+	}
+	req, err = q.SetObjReqTeam(ctx, database.SetObjReqTeamParams{
+		ID:           req.ID,
+		TeamID:       teamID,
+		TeamApproval: approval,
+	})
+	if err != nil || !teamID.Valid {
+		return req, err
+	}
+
+	leads, err := q.ListTeamLeadNames(ctx, teamID.UUID)
+	if err != nil {
+		return req, err
+	}
+	for _, lead := range leads {
+		if _, err := notify(ctx, q, notice{
+			recipient: lead,
+			kind:      notifyTeamApproval,
+			message:   fmt.Sprintf("%s by %s needs your team's approval", displayName(req.ObjKind, req.ObjName, req.Lib, req.Subtree), req.Requester),
+			objReqID:  req.ID,
+			dedupeKey: fmt.Sprintf("team_approval:%s:%s", req.ID, teamID.UUID),
+		}); err != nil {
+			return req, err
+		}
+	}
+	return req, nil
+}
+
+// checkTeamApproval fails for a request still awaiting, or refused, the
+// owning team's approval.
+func checkTeamApproval(req database.MimixObjReq) error {
+	if req.TeamApproval.Valid && req.TeamApproval.TeamApproval != database.TeamApprovalApproved {
+		return errTeamApproval
+	}
+	return nil
+}
+
+// ListTeams returns every team.
+func (cfg *apiConfig) ListTeams(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c); !ok {
+		return
+	}
+
+	rows, err := cfg.dbQueries.ListTeams(c.Request.Context())
+	if err != nil {
+		log.Printf("error listing teams: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get teams"})
+		return
+	}
+
+	teams := make([]Team, 0, len(rows))
+	for _, row := range rows {
+		teams = append(teams, toTeam(row))
+	}
+	c.JSON(http.StatusOK, teams)
+}
+
+// CreateTeam adds a team. It needs the teams.manage permission.
+func (cfg *apiConfig) CreateTeam(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c, permTeamsManage); !ok {
+		return
+	}
+
+	type parameters struct {
+		Name        string `json:"name" binding:"required"`
+		Description string `json:"description"`
+	}
+
+	var params parameters
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	params.Name = strings.TrimSpace(params.Name)
+	if params.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name cannot be empty"})
+		return
+	}
+	if _, err := uuid.Parse(params.Name); err == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name cannot be a uuid"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if _, err := cfg.dbQueries.GetTeamByName(ctx, params.Name); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "team already exists"})
+		return
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("error getting team: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create team"})
+		return
+	}
+
+	team, err := cfg.dbQueries.CreateTeam(ctx, database.CreateTeamParams{
+		Name:        params.Name,
+		Description: strings.TrimSpace(params.Description),
+	})
+	if err != nil {
+		log.Printf("error creating team: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create team"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toTeam(team))
+}
+
+// GetTeam returns a team with its members and libraries.
+func (cfg *apiConfig) GetTeam(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c); !ok {
+		return
+	}
+	row, ok := cfg.getTeamParam(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	members, err := cfg.dbQueries.ListTeamMembers(ctx, row.ID)
+	if err != nil {
+		log.Printf("error listing team members: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get team"})
+		return
+	}
+	libs, err := cfg.dbQueries.ListTeamLibs(ctx, uuid.NullUUID{UUID: row.ID, Valid: true})
+	if err != nil {
+		log.Printf("error listing team libs: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get team"})
+		return
+	}
+
+	team := toTeam(row)
+	team.Members = make([]TeamMember, 0, len(members))
+	for _, m := range members {
+		team.Members = append(team.Members, TeamMember{
+			UserID:   m.ID,
+			Username: m.Username,
+			Job:      string(m.Job),
+			IsLead:   m.IsLead,
+			AddedAt:  m.AddedAt,
+		})
+	}
+	team.Libs = libs
+	c.JSON(http.StatusOK, team)
+}
+
+// DeleteTeam removes a team. Its libraries are left without an owner, and
+// requests awaiting its approval keep waiting until they are edited or the
+// library gets a new owner. It needs the teams.manage permission.
+func (cfg *apiConfig) DeleteTeam(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c, permTeamsManage); !ok {
+		return
+	}
+	team, ok := cfg.getTeamParam(c)
+	if !ok {
+		return
+	}
+
+	if _, err := cfg.dbQueries.DeleteTeam(c.Request.Context(), team.ID); err != nil {
+		log.Printf("error deleting team: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not delete team"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "team deleted"})
+}
+
+// SetTeamMember adds a user to a team, or changes whether they lead it.
+func (cfg *apiConfig) SetTeamMember(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c)
+	if !ok {
+		return
+	}
+	team, ok := cfg.getTeamParam(c)
+	if !ok {
+		return
+	}
+
+	type parameters struct {
+		IsLead bool `json:"is_lead"`
+	}
+
+	var params parameters
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	allowed, err := canManageTeam(ctx, cfg.dbQueries, user, contextPermissions(c), team.ID)
+	if err != nil {
+		log.Printf("error checking team lead: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not update team"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only a lead of the team can change its members"})
+		return
+	}
+
+	member, err := cfg.dbQueries.GetUserByUsername(ctx, c.Param("username"))
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("error getting user by username: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get user"})
+		return
+	}
+
+	if err := cfg.dbQueries.UpsertTeamMember(ctx, database.UpsertTeamMemberParams{
+		TeamID: team.ID,
+		UserID: member.ID,
+		IsLead: params.IsLead,
+	}); err != nil {
+		log.Printf("error setting team member: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not update team"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "team member updated", "username": member.Username, "is_lead": params.IsLead})
+}
+
+// RemoveTeamMember takes a user out of a team.
+func (cfg *apiConfig) RemoveTeamMember(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c)
+	if !ok {
+		return
+	}
+	team, ok := cfg.getTeamParam(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	allowed, err := canManageTeam(ctx, cfg.dbQueries, user, contextPermissions(c), team.ID)
+	if err != nil {
+		log.Printf("error checking team lead: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not update team"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only a lead of the team can change its members"})
+		return
+	}
+
+	member, err := cfg.dbQueries.GetUserByUsername(ctx, c.Param("username"))
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("error getting user by username: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get user"})
+		return
+	}
+
+	rows, err := cfg.dbQueries.RemoveTeamMember(ctx, database.RemoveTeamMemberParams{TeamID: team.ID, UserID: member.ID})
+	if err != nil {
+		log.Printf("error removing team member: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not update team"})
+		return
+	}
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user is not a member of the team"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "team member removed"})
+}
+
+// SetLibTeam gives a library to a team, or with an empty team leaves it
+// without an owner. It needs the teams.manage permission. Pending requests
+// on the library are routed to the new owner.
+func (cfg *apiConfig) SetLibTeam(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c, permTeamsManage); !ok {
+		return
+	}
+
+	type parameters struct {
+		Team string `json:"team"`
+	}
+
+	var params parameters
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lib, err := normalizeObjName("lib", c.Param("lib"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var teamID uuid.NullUUID
+	if ref := strings.TrimSpace(params.Team); ref != "" {
+		team, err := teamByRef(ctx, cfg.dbQueries, ref)
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid team"})
+			return
+		}
+		if err != nil {
+			log.Printf("error getting team: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get team"})
+			return
+		}
+		teamID = uuid.NullUUID{UUID: team.ID, Valid: true}
+	}
+
+	var routed int
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		libID, err := getOrCreateLib(ctx, q, lib)
+		if err != nil {
+			return err
+		}
+		if err := q.SetLibTeam(ctx, database.SetLibTeamParams{ID: libID, TeamID: teamID}); err != nil {
+			return err
+		}
+
+		reqs, err := q.ListPendingObjReq(ctx)
+		if err != nil {
+			return err
+		}
+		for _, req := range reqs {
+			if req.Lib != lib || req.TeamID == teamID {
+				continue
+			}
+			if _, err := routeToTeam(ctx, q, req.ID); err != nil {
+				return err
+			}
+			routed++
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("error setting lib team: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not update lib"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "lib owner updated", "lib": lib, "team_id": NullUUIDToPtr(teamID), "requests_routed": routed})
+}
+
+// GetTeamStats counts the objs and requests in a team's libraries by
+// status, and the requests waiting for its approval.
+func (cfg *apiConfig) GetTeamStats(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c); !ok {
+		return
+	}
+	team, ok := cfg.getTeamParam(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	teamID := uuid.NullUUID{UUID: team.ID, Valid: true}
+	stats := TeamStats{
+		Team:     toTeam(team),
+		Objects:  StatusCounts{ByStatus: map[string]int64{}},
+		Requests: StatusCounts{ByStatus: map[string]int64{}},
+	}
+
+	libs, err := cfg.dbQueries.ListTeamLibs(ctx, teamID)
+	if err != nil {
+		log.Printf("error listing team libs: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get team stats"})
+		return
+	}
+	stats.Libs = len(libs)
+
+	members, err := cfg.dbQueries.ListTeamMembers(ctx, team.ID)
+	if err != nil {
+		log.Printf("error listing team members: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get team stats"})
+		return
+	}
+	stats.Members = len(members)
+
+	objCounts, err := cfg.dbQueries.CountTeamObjsByStatus(ctx, teamID)
+	if err != nil {
+		log.Printf("error counting team objs: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get team stats"})
+		return
+	}
+	for _, row := range objCounts {
+		stats.Objects.ByStatus[string(row.MimixStatus)] = row.Count
+		stats.Objects.Total += row.Count
+	}
+
+	reqCounts, err := cfg.dbQueries.CountTeamObjReqsByStatus(ctx, teamID)
+	if err != nil {
+		log.Printf("error counting team obj requests: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get team stats"})
+		return
+	}
+	for _, row := range reqCounts {
+		stats.Requests.ByStatus[string(row.ReqStatus)] = row.Count
+		stats.Requests.Total += row.Count
+	}
+
+	if stats.AwaitingApproval, err = cfg.dbQueries.CountTeamAwaitingApproval(ctx, teamID); err != nil {
+		log.Printf("error counting team approvals: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get team stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// ReviewTeamApproval lets a lead of the owning team approve or refuse a
+// request. A refused request cannot be converted; it can be rejected, or
+// moved to a library of another team.
+func (cfg *apiConfig) ReviewTeamApproval(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c)
+	if !ok {
+		return
+	}
+
+	type parameters struct {
+		Approved *bool  `json:"approved" binding:"required"`
+		Note     string `json:"note"`
+	}
+
+	var params parameters
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid obj request id"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	req, err := cfg.dbQueries.GetMimixObjReqByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "obj request not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("error getting mimix object request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get mimix object request"})
+		return
+	}
+	if !cfg.requireLib(c, user, req.Lib, database.LibPermissionRead, "obj request not found") {
+		return
+	}
+	if !req.TeamApproval.Valid || req.TeamApproval.TeamApproval != database.TeamApprovalAwaiting {
+		c.JSON(http.StatusConflict, gin.H{"error": "obj request is not awaiting team approval"})
+		return
+	}
+
+	isLead, err := cfg.dbQueries.IsTeamLead(ctx, database.IsTeamLeadParams{TeamID: req.TeamID.UUID, UserID: user.ID})
+	if err != nil {
+		log.Printf("error checking team lead: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not review obj request"})
+		return
+	}
+	if !isLead {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only a lead of the owning team can review this request"})
+		return
+	}
+	if *params.Approved && strings.EqualFold(req.Requester, user.Username) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "another lead of the team must approve your own request"})
+		return
+	}
+
+	approval := database.TeamApprovalRejected
+	if *params.Approved {
+		approval = database.TeamApprovalApproved
+	}
+
+	var updated database.MimixObjReq
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		var err error
+		updated, err = q.SetObjReqTeamApproval(ctx, database.SetObjReqTeamApprovalParams{
+			ID:               req.ID,
+			TeamApproval:     database.NullTeamApproval{TeamApproval: approval, Valid: true},
+			TeamApprovalBy:   sql.NullString{String: user.Username, Valid: true},
+			TeamApprovalNote: sql.NullString{String: strings.TrimSpace(params.Note), Valid: strings.TrimSpace(params.Note) != ""},
+		})
+		if err != nil {
+			return err
+		}
+		_, err = notify(ctx, q, notice{
+			recipient: req.Requester,
+			kind:      notifyTeamApprovalOutcome,
+			message:   fmt.Sprintf("%s was %s by %s", displayName(req.ObjKind, req.ObjName, req.Lib, req.Subtree), approval, user.Username),
+			objReqID:  req.ID,
+		})
+		return err
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusConflict, gin.H{"error": "obj request is not awaiting team approval"})
+		return
+	}
+	if err != nil {
+		log.Printf("error reviewing team approval: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not review obj request"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toMimixObjReq(updated))
+}
+
+// MyTeamApprovals returns the requests waiting for the approval of a team
+// the caller leads, highest priority first.
+func (cfg *apiConfig) MyTeamApprovals(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	rows, err := cfg.dbQueries.ListAwaitingTeamApproval(ctx, user.ID)
+	if err != nil {
+		log.Printf("error listing team approvals: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get approvals"})
+		return
+	}
+	access, ok := cfg.libAccessFor(c, user)
+	if !ok {
+		return
+	}
+	reqs, err := cfg.withSLA(ctx, access.readableObjReqs(rows))
+	if err != nil {
+		log.Printf("error loading sla policies: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get approvals"})
+		return
+	}
+
+	c.JSON(http.StatusOK, reqs)
+}
@@ -0,0 +1,436 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/paul39-33/imimix/internal/database"
+)
+
+type ChangeTicket struct {
+	ID          uuid.UUID      `json:"id"`
+	RefNo       string         `json:"ref_no"`
+	Description string         `json:"description"`
+	Owner       string         `json:"owner"`
+	WindowStart time.Time      `json:"window_start"`
+	WindowEnd   time.Time      `json:"window_end"`
+	Status      string         `json:"status"`
+	Progress    TicketProgress `json:"progress"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+type TicketProgress struct {
+	Total      int64  `json:"total"`
+	Pending    int64  `json:"pending"`
+	Registered int64  `json:"registered"`
+	Rejected   int64  `json:"rejected"`
+	Summary    string `json:"summary"`
+}
+
+// TicketItemResult reports the outcome of a bulk action for one request.
+type TicketItemResult struct {
+	ReqID   uuid.UUID  `json:"req_id"`
+	ObjName string     `json:"obj_name"`
+	Success bool       `json:"success"`
+	ObjID   *uuid.UUID `json:"obj_id,omitempty"`
+	Error   string     `json:"error,omitempty"`
+}
+
+// ticket statuses derived from the requests a ticket holds
+const (
+	ticketStatusOpen       = "open"
+	ticketStatusInProgress = "in_progress"
+	ticketStatusCompleted  = "completed"
+	ticketStatusRejected   = "rejected"
+)
+
+func newTicketProgress(total, pending, completed, rejected int64) TicketProgress {
+	return TicketProgress{
+		Total:      total,
+		Pending:    pending,
+		Registered: completed,
+		Rejected:   rejected,
+		Summary:    fmt.Sprintf("%d of %d registered", completed, total),
+	}
+}
+
+// ticketStatus derives a ticket status from its children: a ticket is open
+// until DC acts on any request, in progress while some are still pending and
+// completed once none are pending. A ticket whose requests were all rejected
+// is rejected.
+func ticketStatus(p TicketProgress) string {
+	switch {
+	case p.Total == 0 || p.Pending == p.Total:
+		return ticketStatusOpen
+	case p.Pending > 0:
+		return ticketStatusInProgress
+	case p.Rejected == p.Total:
+		return ticketStatusRejected
+	default:
+		return ticketStatusCompleted
+	}
+}
+
+func toChangeTicket(t database.ChangeTicket, p TicketProgress) ChangeTicket {
+	return ChangeTicket{
+		ID:          t.ID,
+		RefNo:       t.RefNo,
+		Description: t.Description,
+		Owner:       t.Owner,
+		WindowStart: NullTimeToTime(t.WindowStart),
+		WindowEnd:   NullTimeToTime(t.WindowEnd),
+		Status:      ticketStatus(p),
+		Progress:    p,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+}
+
+// getTicketParam loads the ticket named by the :id path param, writing the
+// error response itself when it cannot.
+func (cfg *apiConfig) getTicketParam(c *gin.Context) (database.ChangeTicket, bool) {
+	ticketID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Printf("error parsing ticket id: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ticket id"})
+		return database.ChangeTicket{}, false
+	}
+
+	ticket, err := cfg.dbQueries.GetChangeTicketByID(c.Request.Context(), ticketID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "change ticket not found"})
+		return database.ChangeTicket{}, false
+	}
+	if err != nil {
+		log.Printf("error getting change ticket: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get change ticket"})
+		return database.ChangeTicket{}, false
+	}
+	return ticket, true
+}
+
+func (cfg *apiConfig) CreateTicket(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permReqCreate)
+	if !ok {
+		return
+	}
+
+	type parameters struct {
+		RefNo       string    `json:"ref_no" binding:"required"`
+		Description string    `json:"description"`
+		Owner       string    `json:"owner"`
+		WindowStart time.Time `json:"window_start"`
+		WindowEnd   time.Time `json:"window_end"`
+	}
+
+	var params parameters
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	params.RefNo = strings.ToUpper(strings.TrimSpace(params.RefNo))
+	if params.RefNo == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ref_no is required"})
+		return
+	}
+
+	// owner defaults to the caller
+	params.Owner = strings.ToLower(strings.TrimSpace(params.Owner))
+	if params.Owner == "" {
+		params.Owner = user.Username
+	}
+
+	if !params.WindowStart.IsZero() && !params.WindowEnd.IsZero() && params.WindowEnd.Before(params.WindowStart) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "window_end must not be before window_start"})
+		return
+	}
+
+	exists, err := cfg.dbQueries.CheckChangeTicketRefExists(c.Request.Context(), params.RefNo)
+	if err != nil {
+		log.Printf("error checking change ticket ref: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not check change ticket"})
+		return
+	}
+	if exists {
+		c.JSON(http.StatusConflict, gin.H{"error": "change ticket with this ref_no already exists"})
+		return
+	}
+
+	ticket, err := cfg.dbQueries.CreateChangeTicket(c.Request.Context(), database.CreateChangeTicketParams{
+		RefNo:       params.RefNo,
+		Description: strings.TrimSpace(params.Description),
+		Owner:       params.Owner,
+		WindowStart: ToNullTime(params.WindowStart),
+		WindowEnd:   ToNullTime(params.WindowEnd),
+	})
+	if err != nil {
+		log.Printf("error creating change ticket: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create change ticket"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "change ticket created successfully",
+		"data":    toChangeTicket(ticket, newTicketProgress(0, 0, 0, 0)),
+	})
+}
+
+func (cfg *apiConfig) ListTickets(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c); !ok {
+		return
+	}
+
+	rows, err := cfg.dbQueries.ListChangeTickets(c.Request.Context())
+	if err != nil {
+		log.Printf("error listing change tickets: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not list change tickets"})
+		return
+	}
+
+	tickets := []ChangeTicket{}
+	for _, row := range rows {
+		tickets = append(tickets, toChangeTicket(database.ChangeTicket{
+			ID:          row.ID,
+			RefNo:       row.RefNo,
+			Description: row.Description,
+			Owner:       row.Owner,
+			WindowStart: row.WindowStart,
+			WindowEnd:   row.WindowEnd,
+			CreatedAt:   row.CreatedAt,
+			UpdatedAt:   row.UpdatedAt,
+		}, newTicketProgress(row.Total, row.Pending, row.Completed, row.Rejected)))
+	}
+
+	c.JSON(http.StatusOK, tickets)
+}
+
+func (cfg *apiConfig) GetTicket(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c)
+	if !ok {
+		return
+	}
+
+	ticket, ok := cfg.getTicketParam(c)
+	if !ok {
+		return
+	}
+
+	reqs, err := cfg.dbQueries.GetMimixObjReqByTicketID(c.Request.Context(), uuid.NullUUID{UUID: ticket.ID, Valid: true})
+	if err != nil {
+		log.Printf("error getting ticket requests: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get ticket requests"})
+		return
+	}
+	access, ok := cfg.libAccessFor(c, user)
+	if !ok {
+		return
+	}
+
+	var pending, completed, rejected int64
+	resultReqs := []MimixObjReq{}
+	for _, req := range reqs {
+		switch req.ReqStatus {
+		case database.ReqStatusPending:
+			pending++
+		case database.ReqStatusCompleted:
+			completed++
+		case database.ReqStatusRejected:
+			rejected++
+		}
+		// the progress counts the whole ticket; only readable requests are listed
+		if access.can(req.Lib, database.LibPermissionRead) {
+			resultReqs = append(resultReqs, toMimixObjReq(req))
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"ticket":   toChangeTicket(ticket, newTicketProgress(int64(len(reqs)), pending, completed, rejected)),
+		"requests": resultReqs,
+	})
+}
+
+func (cfg *apiConfig) AddTicketRequests(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permReqCreate)
+	if !ok {
+		return
+	}
+
+	ticket, ok := cfg.getTicketParam(c)
+	if !ok {
+		return
+	}
+	access, ok := cfg.libAccessFor(c, user)
+	if !ok {
+		return
+	}
+
+	type parameters struct {
+		ReqIDs []uuid.UUID `json:"req_ids" binding:"required"`
+	}
+
+	var params parameters
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results := []TicketItemResult{}
+	for _, reqID := range params.ReqIDs {
+		result := TicketItemResult{ReqID: reqID}
+
+		objReq, err := cfg.dbQueries.GetMimixObjReqByID(c.Request.Context(), reqID)
+		switch {
+		case errors.Is(err, sql.ErrNoRows), err == nil && !access.can(objReq.Lib, database.LibPermissionRead):
+			result.Error = "obj request not found"
+		case err != nil:
+			log.Printf("error getting mimix object request: %v", err)
+			result.Error = "could not get mimix object request"
+		case !access.can(objReq.Lib, database.LibPermissionRequest):
+			result.ObjName = objReq.ObjName
+			result.Error = "forbidden: request permission on the lib required"
+		case objReq.ReqStatus != database.ReqStatusPending:
+			result.ObjName = objReq.ObjName
+			result.Error = "only pending requests can be added to a ticket"
+		case objReq.TicketID.Valid && objReq.TicketID.UUID != ticket.ID:
+			result.ObjName = objReq.ObjName
+			result.Error = "obj request already belongs to another ticket"
+		default:
+			result.ObjName = objReq.ObjName
+			// the guard in the update also refuses a request another
+			// ticket took in the meantime
+			rows, err := cfg.dbQueries.SetMimixObjReqTicket(c.Request.Context(), database.SetMimixObjReqTicketParams{
+				ID:       objReq.ID,
+				TicketID: uuid.NullUUID{UUID: ticket.ID, Valid: true},
+			})
+			switch {
+			case err != nil:
+				log.Printf("error adding obj request to ticket: %v", err)
+				result.Error = "could not add obj request to ticket"
+			case rows == 0:
+				result.Error = "obj request already belongs to another ticket"
+			default:
+				result.Success = true
+			}
+		}
+		results = append(results, result)
+	}
+
+	if err := cfg.dbQueries.TouchChangeTicket(c.Request.Context(), ticket.ID); err != nil {
+		log.Printf("error updating change ticket: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "obj requests added to change ticket",
+		"results": results,
+	})
+}
+
+// ConvertTicket converts every pending request on the ticket, each in its
+// own transaction, and reports the outcome per request.
+func (cfg *apiConfig) ConvertTicket(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permReqConvert)
+	if !ok {
+		return
+	}
+
+	cfg.actOnTicketRequests(c, "converted", func(q *database.Queries, objReq database.MimixObjReq, result *TicketItemResult) error {
+		if !canActOn(user, contextPermissions(c), objReq) {
+			return errAssignedElsewhere
+		}
+		objID, _, err := convertObjReq(c.Request.Context(), q, objReq, user.Username)
+		if err != nil {
+			return err
+		}
+		result.ObjID = &objID
+		return nil
+	})
+}
+
+// RejectTicket rejects every pending request on the ticket.
+func (cfg *apiConfig) RejectTicket(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c, permReqConvert)
+	if !ok {
+		return
+	}
+
+	cfg.actOnTicketRequests(c, "rejected", func(q *database.Queries, objReq database.MimixObjReq, _ *TicketItemResult) error {
+		if !canActOn(user, contextPermissions(c), objReq) {
+			return errAssignedElsewhere
+		}
+		return q.RejectMimixObjReq(c.Request.Context(), objReq.ID)
+	})
+}
+
+func (cfg *apiConfig) actOnTicketRequests(c *gin.Context, action string, fn func(q *database.Queries, objReq database.MimixObjReq, result *TicketItemResult) error) {
+	ticket, ok := cfg.getTicketParam(c)
+	if !ok {
+		return
+	}
+	access, ok := cfg.libAccessFor(c, contextUser(c))
+	if !ok {
+		return
+	}
+
+	reqs, err := cfg.dbQueries.GetMimixObjReqByTicketID(c.Request.Context(), uuid.NullUUID{UUID: ticket.ID, Valid: true})
+	if err != nil {
+		log.Printf("error getting ticket requests: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get ticket requests"})
+		return
+	}
+
+	results := []TicketItemResult{}
+	for _, objReq := range reqs {
+		// requests in libraries the caller cannot read are left alone
+		if objReq.ReqStatus != database.ReqStatusPending || !access.can(objReq.Lib, database.LibPermissionRead) {
+			continue
+		}
+
+		result := TicketItemResult{ReqID: objReq.ID, ObjName: objReq.ObjName}
+		if !access.can(objReq.Lib, database.LibPermissionManage) {
+			result.Error = "forbidden: manage permission on the lib required"
+			results = append(results, result)
+			continue
+		}
+		err := cfg.withTx(c.Request.Context(), func(q *database.Queries) error {
+			return fn(q, objReq, &result)
+		})
+		var fieldErr fieldError
+		if errors.Is(err, errObjExists) || errors.Is(err, errAssignedElsewhere) || errors.Is(err, errTeamApproval) || errors.As(err, &fieldErr) {
+			result.ObjID = nil
+			result.Error = err.Error()
+		} else if err != nil {
+			log.Printf("error processing ticket request %s: %v", objReq.ID, err)
+			result.ObjID = nil
+			result.Error = "obj request could not be " + action
+		} else {
+			result.Success = true
+		}
+		results = append(results, result)
+	}
+
+	if err := cfg.dbQueries.TouchChangeTicket(c.Request.Context(), ticket.ID); err != nil {
+		log.Printf("error updating change ticket: %v", err)
+	}
+
+	progress, err := cfg.dbQueries.GetChangeTicketProgress(c.Request.Context(), uuid.NullUUID{UUID: ticket.ID, Valid: true})
+	if err != nil {
+		log.Printf("error getting change ticket progress: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get change ticket progress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("change ticket requests %s", action),
+		"ticket":  toChangeTicket(ticket, newTicketProgress(progress.Total, progress.Pending, progress.Completed, progress.Rejected)),
+		"results": results,
+	})
+}
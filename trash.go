@@ -0,0 +1,309 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/paul39-33/imimix/internal/database"
+)
+
+// defaultTrashRetentionDays is used when TRASH_RETENTION_DAYS is not set.
+const defaultTrashRetentionDays = 30
+
+// errNotInTrash is returned when a restore or purge targets a row that is
+// not in the trash (either live or already purged).
+var errNotInTrash = errors.New("not found in trash")
+
+type Trash struct {
+	RetentionDays int32         `json:"retention_days"`
+	Objs          []MimixObj    `json:"objs"`
+	ObjReqs       []MimixObjReq `json:"obj_reqs"`
+}
+
+// trashRetentionDaysFromEnv reads TRASH_RETENTION_DAYS. 0 disables the
+// automatic purge.
+func trashRetentionDaysFromEnv() int32 {
+	return intFromEnv("TRASH_RETENTION_DAYS", defaultTrashRetentionDays)
+}
+
+// purgeExpiredTrash permanently deletes rows that have been in the trash for
+// longer than the retention period.
+func (cfg *apiConfig) purgeExpiredTrash(ctx context.Context) (objs, objReqs int64, err error) {
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		// requests converted from a purged obj no longer have a source
+		if err := q.ClearExpiredObjReqSources(ctx, cfg.trashRetentionDays); err != nil {
+			return err
+		}
+		if objs, err = q.PurgeExpiredObj(ctx, cfg.trashRetentionDays); err != nil {
+			return err
+		}
+		objReqs, err = q.PurgeExpiredMimixObjReq(ctx, cfg.trashRetentionDays)
+		return err
+	})
+	return objs, objReqs, err
+}
+
+// ListTrash lists deleted objs and requests to cmt and dc, who may delete
+// and restore them. Anyone else only sees the requests they raised.
+func (cfg *apiConfig) ListTrash(c *gin.Context) {
+	user, ok := cfg.authorizeUser(c)
+	if !ok {
+		return
+	}
+	access, ok := cfg.libAccessFor(c, user)
+	if !ok {
+		return
+	}
+
+	// optional ?type=obj or ?type=obj_req
+	kind := c.Query("type")
+	if kind != "" && kind != "obj" && kind != "obj_req" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid type"})
+		return
+	}
+	manager := contextPermissions(c)[permObjDelete]
+	if kind == "obj" && !manager {
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden: insufficient permissions"})
+		return
+	}
+
+	trash := Trash{
+		RetentionDays: cfg.trashRetentionDays,
+		Objs:          []MimixObj{},
+		ObjReqs:       []MimixObjReq{},
+	}
+
+	if manager && (kind == "" || kind == "obj") {
+		objs, err := cfg.dbQueries.ListDeletedObj(c.Request.Context())
+		if err != nil {
+			log.Printf("error listing deleted mimix objects: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not list trash"})
+			return
+		}
+		for _, obj := range access.readableObjs(objs) {
+			trash.Objs = append(trash.Objs, toMimixObj(obj))
+		}
+	}
+
+	if kind == "" || kind == "obj_req" {
+		reqs, err := cfg.dbQueries.ListDeletedMimixObjReq(c.Request.Context())
+		if err != nil {
+			log.Printf("error listing deleted mimix object requests: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not list trash"})
+			return
+		}
+		for _, req := range access.readableObjReqs(reqs) {
+			if manager || strings.EqualFold(req.Requester, user.Username) {
+				trash.ObjReqs = append(trash.ObjReqs, toMimixObjReq(req))
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, trash)
+}
+
+// trashIDParam parses the :id path param of a trash endpoint.
+func trashIDParam(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Printf("error parsing trash id: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// requireTrashedObjLib checks that the caller holds need on the library of
+// a trashed obj; one in a library they cannot read is not in their trash.
+func (cfg *apiConfig) requireTrashedObjLib(c *gin.Context, user database.GetUserByIDRow, id uuid.UUID, need database.LibPermission) bool {
+	obj, err := cfg.dbQueries.GetDeletedObjByID(c.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no matching obj in trash"})
+		return false
+	}
+	if err != nil {
+		log.Printf("error getting deleted mimix object: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get mimix object"})
+		return false
+	}
+	return cfg.requireLib(c, user, obj.Lib, need, "no matching obj in trash")
+}
+
+// requireTrashedObjReqLib is requireTrashedObjLib for obj requests.
+func (cfg *apiConfig) requireTrashedObjReqLib(c *gin.Context, user database.GetUserByIDRow, id uuid.UUID, need database.LibPermission) bool {
+	req, err := cfg.dbQueries.GetDeletedMimixObjReqByID(c.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no matching obj request in trash"})
+		return false
+	}
+	if err != nil {
+		log.Printf("error getting deleted mimix object request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get mimix object request"})
+		return false
+	}
+	return cfg.requireLib(c, user, req.Lib, need, "no matching obj request in trash")
+}
+
+func (cfg *apiConfig) RestoreObj(c *gin.Context) {
+	//same permission as RemoveObj
+	user, ok := cfg.authorizeUser(c, permObjDelete)
+	if !ok {
+		return
+	}
+
+	objID, ok := trashIDParam(c)
+	if !ok || !cfg.requireTrashedObjLib(c, user, objID, database.LibPermissionManage) {
+		return
+	}
+
+	var restored database.MimixObj
+	err := cfg.withTx(c.Request.Context(), func(q *database.Queries) error {
+		deleted, err := q.GetDeletedObjByID(c.Request.Context(), objID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errNotInTrash
+		}
+		if err != nil {
+			return err
+		}
+
+		// a new obj may have been registered under the same name meanwhile
+		_, err = q.GetObjByNameAndLib(c.Request.Context(), database.GetObjByNameAndLibParams{
+			Obj: deleted.Obj,
+			Lib: deleted.Lib,
+		})
+		if err == nil {
+			return errObjExists
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		restored, err = q.RestoreObjByID(c.Request.Context(), objID)
+		return err
+	})
+	if errors.Is(err, errNotInTrash) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no matching obj in trash"})
+		return
+	}
+	if errors.Is(err, errObjExists) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("error restoring mimix object: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not restore mimix object"})
+		return
+	}
+
+	c.Header("ETag", etag(restored.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "mimix object restored",
+		"data":    toMimixObj(restored),
+	})
+}
+
+func (cfg *apiConfig) RestoreObjReq(c *gin.Context) {
+	//same permission as RemoveMimixObjReq
+	user, ok := cfg.authorizeUser(c, permReqDelete)
+	if !ok {
+		return
+	}
+
+	objReqID, ok := trashIDParam(c)
+	if !ok || !cfg.requireTrashedObjReqLib(c, user, objReqID, database.LibPermissionRequest) {
+		return
+	}
+
+	restored, err := cfg.dbQueries.RestoreMimixObjReq(c.Request.Context(), objReqID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no matching obj request in trash"})
+		return
+	}
+	if err != nil {
+		log.Printf("error restoring mimix object request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not restore mimix object request"})
+		return
+	}
+
+	c.Header("ETag", etag(restored.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "mimix object request restored",
+		"data":    toMimixObjReq(restored),
+	})
+}
+
+func (cfg *apiConfig) PurgeObj(c *gin.Context) {
+	//permanent delete is dc only
+	user, ok := cfg.authorizeUser(c, permTrashPurge)
+	if !ok {
+		return
+	}
+
+	objID, ok := trashIDParam(c)
+	if !ok || !cfg.requireTrashedObjLib(c, user, objID, database.LibPermissionManage) {
+		return
+	}
+
+	err := cfg.withTx(c.Request.Context(), func(q *database.Queries) error {
+		// keep requests that were made from this obj, just drop the link
+		if err := q.ClearObjReqSource(c.Request.Context(), uuid.NullUUID{UUID: objID, Valid: true}); err != nil {
+			return err
+		}
+		rows, err := q.PurgeObjByID(c.Request.Context(), objID)
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return errNotInTrash
+		}
+		return nil
+	})
+	if errors.Is(err, errNotInTrash) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no matching obj in trash"})
+		return
+	}
+	if err != nil {
+		log.Printf("error purging mimix object: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not purge mimix object"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "mimix object permanently deleted",
+		"obj_id":  objID,
+	})
+}
+
+func (cfg *apiConfig) PurgeObjReq(c *gin.Context) {
+	//permanent delete is dc only
+	user, ok := cfg.authorizeUser(c, permTrashPurge)
+	if !ok {
+		return
+	}
+
+	objReqID, ok := trashIDParam(c)
+	if !ok || !cfg.requireTrashedObjReqLib(c, user, objReqID, database.LibPermissionManage) {
+		return
+	}
+
+	rows, err := cfg.dbQueries.PurgeMimixObjReq(c.Request.Context(), objReqID)
+	if err != nil {
+		log.Printf("error purging mimix object request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not purge mimix object request"})
+		return
+	}
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no matching obj request in trash"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "mimix object request permanently deleted",
+		"obj_req_id": objReqID,
+	})
+}
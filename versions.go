@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/paul39-33/imimix/internal/database"
+)
+
+// ObjectVersion is one entry of an obj's version timeline. Seq numbers the
+// entries of one obj from 1, oldest first.
+type ObjectVersion struct {
+	Seq         int        `json:"seq"`
+	ID          uuid.UUID  `json:"id"`
+	ObjVer      string     `json:"obj_ver"`
+	PromoteDate *time.Time `json:"promote_date,omitempty"`
+	Developer   string     `json:"developer"`
+	ObjReqID    *uuid.UUID `json:"obj_req_id,omitempty"`
+	Source      string     `json:"source"`
+	ChangedBy   string     `json:"changed_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// FieldChange is a field that differs between two versions of an obj.
+type FieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+func toObjectVersions(rows []database.ObjectVersion) []ObjectVersion {
+	versions := make([]ObjectVersion, 0, len(rows))
+	for i, row := range rows {
+		versions = append(versions, ObjectVersion{
+			Seq:         i + 1,
+			ID:          row.ID,
+			ObjVer:      row.ObjVer,
+			PromoteDate: NullTimeToPtr(row.PromoteDate),
+			Developer:   row.Developer,
+			ObjReqID:    NullUUIDToPtr(row.ObjReqID),
+			Source:      string(row.Source),
+			ChangedBy:   NullStringToString(row.ChangedBy),
+			CreatedAt:   row.CreatedAt,
+		})
+	}
+	return versions
+}
+
+// recordObjVersion appends an entry, changed by actor, to an obj's version
+// timeline.
+func recordObjVersion(ctx context.Context, q *database.Queries, v database.CreateObjectVersionParams, actor string) error {
+	v.ChangedBy = sql.NullString{String: actor, Valid: actor != ""}
+	return q.CreateObjectVersion(ctx, v)
+}
+
+// diffVersions lists the fields that changed from one entry to another.
+func diffVersions(from, to ObjectVersion) []FieldChange {
+	changes := []FieldChange{}
+	if from.ObjVer != to.ObjVer {
+		changes = append(changes, FieldChange{Field: "obj_ver", From: from.ObjVer, To: to.ObjVer})
+	}
+	if !equalTimePtr(from.PromoteDate, to.PromoteDate) {
+		changes = append(changes, FieldChange{Field: "promote_date", From: from.PromoteDate, To: to.PromoteDate})
+	}
+	if from.Developer != to.Developer {
+		changes = append(changes, FieldChange{Field: "developer", From: from.Developer, To: to.Developer})
+	}
+	if !equalUUIDPtr(from.ObjReqID, to.ObjReqID) {
+		changes = append(changes, FieldChange{Field: "obj_req_id", From: from.ObjReqID, To: to.ObjReqID})
+	}
+	return changes
+}
+
+func equalTimePtr(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func equalUUIDPtr(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// findVersion resolves a timeline entry by seq number or id.
+func findVersion(versions []ObjectVersion, ref string) (ObjectVersion, bool) {
+	if seq, err := strconv.Atoi(ref); err == nil {
+		if seq >= 1 && seq <= len(versions) {
+			return versions[seq-1], true
+		}
+		return ObjectVersion{}, false
+	}
+	id, err := uuid.Parse(ref)
+	if err != nil {
+		return ObjectVersion{}, false
+	}
+	for _, v := range versions {
+		if v.ID == id {
+			return v, true
+		}
+	}
+	return ObjectVersion{}, false
+}
+
+// loadObjVersions returns the timeline of the obj named by the :id path
+// param, writing the error response itself when it cannot.
+func (cfg *apiConfig) loadObjVersions(c *gin.Context) ([]ObjectVersion, bool) {
+	obj, ok := cfg.objRefParam(c)
+	if !ok {
+		return nil, false
+	}
+
+	rows, err := cfg.dbQueries.ListObjectVersions(c.Request.Context(), obj.objID.UUID)
+	if err != nil {
+		log.Printf("error listing obj versions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not get obj versions"})
+		return nil, false
+	}
+	return toObjectVersions(rows), true
+}
+
+// ListObjVersions returns the version timeline of an obj, oldest first.
+func (cfg *apiConfig) ListObjVersions(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c); !ok {
+		return
+	}
+
+	versions, ok := cfg.loadObjVersions(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, versions)
+}
+
+// DiffObjVersions compares two entries of an obj's timeline, named by seq
+// number or id in ?from= and ?to=. By default it compares the latest entry
+// with the one before it.
+func (cfg *apiConfig) DiffObjVersions(c *gin.Context) {
+	if _, ok := cfg.authorizeUser(c); !ok {
+		return
+	}
+
+	versions, ok := cfg.loadObjVersions(c)
+	if !ok {
+		return
+	}
+	if len(versions) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "obj has no versions"})
+		return
+	}
+
+	to := versions[len(versions)-1]
+	if ref := c.Query("to"); ref != "" {
+		if to, ok = findVersion(versions, ref); !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "version " + ref + " not found"})
+			return
+		}
+	}
+	from := versions[max(to.Seq-2, 0)]
+	if ref := c.Query("from"); ref != "" {
+		if from, ok = findVersion(versions, ref); !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "version " + ref + " not found"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":    from,
+		"to":      to,
+		"changes": diffVersions(from, to),
+	})
+}